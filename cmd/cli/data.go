@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"github.com/spf13/cobra"
+)
+
+func DataCmd() *cobra.Command {
+	shortDesc := "Import and export the rows of the models"
+	longDesc := "Import and export the rows of the registered models as CSV, JSON, JSON Lines, YAML, TOML, INI, .env or XML"
+	cmd := &cobra.Command{
+		Use:         "data",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(importDataCmd())
+
+	cmd.AddCommand(exportDataCmd())
+
+	return cmd
+}
+
+func importDataCmd() *cobra.Command {
+	var configFile, database, output, model, format string
+	var columns map[string]string
+	var batchSize, maxErrors int
+	var dryRun bool
+
+	shortDesc := "Import a file into the table of a model"
+	longDesc := "Insert the rows of a CSV, JSON, JSON Lines, YAML, TOML, INI, .env or XML file into the table of a registered model, converting the values to the types of its fields. The rows that fail are reported with their errors and the others are imported"
+
+	cmd := &cobra.Command{
+		Use:         "import <file>",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Args:        cobra.ExactArgs(1),
+		Example: `  gdbase data import products.csv --model products
+  gdbase data import legacy.csv --model clients --map "Client Name=name" --map notes=- --dry-run
+  cat orders.jsonl | gdbase data import - --model orders --format jsonl`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+				if format == "" {
+					format = ti.DataFormatOf(args[0])
+				}
+			}
+			if format == "" {
+				return fmt.Errorf("--format is required to read from stdin")
+			}
+
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			report, err := dbService.ImportData(ctx, in, svc.DataImportOptions{
+				Model:     model,
+				Format:    format,
+				Columns:   columns,
+				BatchSize: batchSize,
+				MaxErrors: maxErrors,
+				DryRun:    dryRun,
+			})
+			if report != nil {
+				if werr := writeOutput(cmd.OutOrStdout(), output, report, func(w io.Writer) error {
+					return writeDataReport(w, report)
+				}); werr != nil {
+					return werr
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if report.Failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d row(s) failed", report.Failed, report.Rows)
+			}
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format of the report: table, json or yaml")
+	cmd.Flags().StringVarP(&model, "model", "m", "", "Table (or Go type name) of the model")
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Format of the file; defaults to its extension")
+	cmd.Flags().StringToStringVar(&columns, "map", nil, "Map a column of the file to a field of the model (column=field, column=- to ignore it)")
+	cmd.Flags().IntVar(&batchSize, "batch-size", ti.DefaultDataBatchSize, "Rows inserted at once")
+	cmd.Flags().IntVar(&maxErrors, "max-errors", 0, "Stop after as many failed rows (0 never stops)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Read and convert the rows without writing them")
+	_ = cmd.MarkFlagRequired("model")
+	return cmd
+}
+
+func exportDataCmd() *cobra.Command {
+	var configFile, database, file, format, filter, sort string
+	var fields []string
+	var columns map[string]string
+	var limit int
+
+	shortDesc := "Export the rows of a model"
+	longDesc := "Write the rows of the table of a registered model as CSV, JSON, JSON Lines, YAML, TOML, INI, .env or XML, to stdout or to --file, optionally filtered and sorted with the filter language of the list endpoints"
+
+	cmd := &cobra.Command{
+		Use:         "export <model>",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Args:        cobra.ExactArgs(1),
+		Example: `  gdbase data export orders --format jsonl > orders.jsonl
+  gdbase data export products --file products.csv --fields sku,name,price --map name=Product
+  gdbase data export orders --filter "status:in:pending,failed" --sort -created_at --limit 100`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = "jsonl"
+				if file != "" {
+					format = ti.DataFormatOf(file)
+				}
+			}
+
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			out := cmd.OutOrStdout()
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+			report, err := dbService.ExportData(ctx, out, svc.DataExportOptions{
+				Model:   args[0],
+				Format:  format,
+				Fields:  fields,
+				Columns: columns,
+				Filter:  filter,
+				Sort:    sort,
+				Limit:   limit,
+			})
+			if err != nil {
+				return err
+			}
+			if file != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d row(s) exported to %s\n", report.Processed, file)
+			}
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVar(&file, "file", "", "File to write; defaults to stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Format: csv, jsonl, json, yaml, toml, ini, properties, env or xml (default: the extension of --file, or jsonl)")
+	cmd.Flags().StringSliceVar(&fields, "fields", nil, "Fields to export, in order (default: all)")
+	cmd.Flags().StringToStringVar(&columns, "map", nil, "Rename an exported field (field=column, field=- to drop it)")
+	cmd.Flags().StringVar(&filter, "filter", "", "Filter expression (field:op:value;...)")
+	cmd.Flags().StringVar(&sort, "sort", "", "Sort expression (-field,field)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Export at most this many rows (0 exports all)")
+	return cmd
+}
+
+func writeDataReport(w io.Writer, report *ti.DataReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FORMAT\tROWS\tIMPORTED\tFAILED")
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", report.Format, report.Rows, report.Processed, report.Failed)
+	if len(report.Errors) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "ROW\tFIELD\tERROR")
+		for _, e := range report.Errors {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", e.Row, e.Field, e.Message)
+		}
+	}
+	return tw.Flush()
+}
@@ -1,9 +1,21 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
+	_ "github.com/kubex-ecosystem/gdbase/internal/models" // registers the models compared by diff
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+	l "github.com/kubex-ecosystem/logz"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 func DatabaseCmd() *cobra.Command {
@@ -30,6 +42,18 @@ func DatabaseCmd() *cobra.Command {
 
 	cmd.AddCommand(statusDatabaseCmd())
 
+	cmd.AddCommand(describeDatabaseCmd())
+
+	cmd.AddCommand(diffDatabaseCmd())
+
+	cmd.AddCommand(tenantDatabaseCmd())
+
+	cmd.AddCommand(backupDatabaseCmd())
+
+	cmd.AddCommand(restoreDatabaseCmd())
+
+	cmd.AddCommand(seedDatabaseCmd())
+
 	return cmd
 }
 
@@ -81,3 +105,359 @@ func statusDatabaseCmd() *cobra.Command {
 	}
 	return cmd
 }
+
+func describeDatabaseCmd() *cobra.Command {
+	var configFile, database, output string
+
+	shortDesc := "Describe the database schema"
+	longDesc := "Describe tables, columns, indexes, foreign keys, views and enums of a live database"
+
+	cmd := &cobra.Command{
+		Use:         "describe [table...]",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			schema, err := dbService.DescribeSchema(ctx, args...)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, schema, func(w io.Writer) error {
+				return writeSchemaTable(w, schema)
+			})
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	return cmd
+}
+
+func diffDatabaseCmd() *cobra.Command {
+	var configFile, database, output, from, migrationsDir string
+	var ignore []string
+	var exitCode bool
+
+	shortDesc := "Compare the database schema with the GORM models"
+	longDesc := "Report missing and extra columns, type mismatches, nullability and index differences between the registered models and the live database (--from live) or a scratch database built from the migrations (--from migrations)"
+
+	cmd := &cobra.Command{
+		Use:         "diff",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Example: `  gdbase database diff -o json
+  gdbase database diff --from migrations --ignore extra_index,extra_column`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			var diff *svc.SchemaDiff
+			switch strings.ToLower(from) {
+			case svc.DiffSourceLive:
+				diff, err = dbService.DiffSchema(ctx)
+			case svc.DiffSourceMigrations:
+				var source svc.MigrationSource
+				if migrationsDir != "" {
+					source = svc.NewDirMigrationSource(migrationsDir)
+				}
+				diff, err = dbService.DiffMigrations(ctx, source)
+			default:
+				return fmt.Errorf("unsupported source: %s (use live or migrations)", from)
+			}
+			if err != nil {
+				return err
+			}
+			diff.Ignore(ignore...)
+
+			if err := writeOutput(cmd.OutOrStdout(), output, diff, func(w io.Writer) error {
+				return writeDiffTable(w, diff)
+			}); err != nil {
+				return err
+			}
+			if exitCode && diff.HasDrift() {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("schema drift detected: %d difference(s)", len(diff.Drifts))
+			}
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	cmd.Flags().StringVar(&from, "from", svc.DiffSourceLive, "Schema to compare: live or migrations")
+	cmd.Flags().StringVar(&migrationsDir, "migrations-dir", "", "Migrations applied with --from migrations; defaults to the embedded ones")
+	cmd.Flags().StringSliceVar(&ignore, "ignore", nil, "Drift kinds to ignore (e.g. extra_index,extra_column)")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", true, "Exit with an error when drift is found")
+	return cmd
+}
+
+func seedDatabaseCmd() *cobra.Command {
+	var configFile, database, output, dir, env string
+	var truncate bool
+
+	shortDesc := "Load the fixtures of a seed set into the database"
+	longDesc := "Upsert the YAML, JSON and CSV fixtures of --dir (plus the ones of --dir/<env>) into the tables of the registered models, resolving @table.key references between them. Running it again updates the same rows; --truncate empties the seeded tables first"
+
+	cmd := &cobra.Command{
+		Use:         "seed",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Example: `  gdbase database seed --dir ./seeds --env dev
+  gdbase database seed --env test --truncate -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			results, err := dbService.Seed(ctx, svc.SeedOptions{Dir: dir, Env: env, Truncate: truncate})
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, results, func(w io.Writer) error {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "TABLE\tINSERTED\tUPDATED\tUNCHANGED\tDELETED")
+				for _, r := range results {
+					fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", r.Table, r.Inserted, r.Updated, r.Unchanged, r.Deleted)
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	cmd.Flags().StringVar(&dir, "dir", svc.DefaultSeedDir, "Directory of the seed set")
+	cmd.Flags().StringVar(&env, "env", os.Getenv("GDBASE_ENV"), "Environment whose fixtures (--dir/<env>) override the base ones")
+	cmd.Flags().BoolVar(&truncate, "truncate", false, "Delete every row of the seeded tables before seeding")
+	return cmd
+}
+
+func tenantDatabaseCmd() *cobra.Command {
+	shortDesc := "Manage the tenants of the database"
+	longDesc := "Provision, migrate and list the tenants configured by the tenancy section (schema-per-tenant mode)"
+	cmd := &cobra.Command{
+		Use:         "tenant",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	cmd.AddCommand(tenantListCmd(), tenantProvisionCmd(), tenantMigrateCmd())
+	return cmd
+}
+
+func tenantListCmd() *cobra.Command {
+	var configFile, database, output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the provisioned tenants",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			tenants, err := dbService.ListTenants(ctx)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, tenants, func(w io.Writer) error {
+				for _, tenant := range tenants {
+					fmt.Fprintln(w, tenant)
+				}
+				return nil
+			})
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	return cmd
+}
+
+func tenantProvisionCmd() *cobra.Command {
+	var configFile, database, migrationsDir string
+	cmd := &cobra.Command{
+		Use:   "provision <tenant>",
+		Short: "Create the schema of a tenant and apply the migrations to it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			applied, err := dbService.ProvisionTenant(ctx, args[0], tenantMigrationSource(migrationsDir))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %d migration(s) applied\n", args[0], applied)
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVar(&migrationsDir, "migrations-dir", "", "Migrations applied to the tenant; defaults to the embedded ones")
+	return cmd
+}
+
+func tenantMigrateCmd() *cobra.Command {
+	var configFile, database, migrationsDir string
+	cmd := &cobra.Command{
+		Use:   "migrate [tenant...]",
+		Short: "Apply the pending migrations to the given tenants (all of them by default)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			applied, err := dbService.MigrateTenants(ctx, tenantMigrationSource(migrationsDir), args...)
+			for _, tenant := range slices.Sorted(maps.Keys(applied)) {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %d migration(s) applied\n", tenant, applied[tenant])
+			}
+			return err
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVar(&migrationsDir, "migrations-dir", "", "Migrations applied to the tenants; defaults to the embedded ones")
+	return cmd
+}
+
+func tenantMigrationSource(dir string) svc.MigrationSource {
+	if dir == "" {
+		return nil
+	}
+	return svc.NewDirMigrationSource(dir)
+}
+
+// addDatabaseFlags registers the flags that select the config file and the database.
+func addDatabaseFlags(cmd *cobra.Command, configFile, database *string) {
+	cmd.Flags().StringVar(configFile, "config-file", os.ExpandEnv(svc.DefaultGDBaseConfigPath), "Path to configuration file")
+	cmd.Flags().StringVarP(database, "database", "d", "", "Database to use (key or name in the configuration); defaults to the default database")
+}
+
+// openDatabaseService loads the configuration and returns a database service
+// and a context routed to the selected database.
+func openDatabaseService(ctx context.Context, configFile, database string) (context.Context, *svc.DBServiceImpl, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dbConfig, err := svc.NewDBConfigFromFile(ctx, configFile, false, l.GetLogger("GDBase"), false)
+	if err != nil {
+		return ctx, nil, err
+	}
+	dbService, err := svc.NewDatabaseServiceImpl(ctx, dbConfig, l.GetLogger("GDBase"))
+	if err != nil {
+		return ctx, nil, err
+	}
+	if database != "" {
+		ctx = svc.Using(ctx, database)
+	}
+	return ctx, dbService, nil
+}
+
+// writeOutput writes v as json or yaml, or with writeTable for the table format.
+func writeOutput(w io.Writer, format string, v any, writeTable func(io.Writer) error) error {
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(v)
+	case "table", "":
+		return writeTable(w)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+func writeSchemaTable(w io.Writer, schema *svc.Schema) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, table := range schema.Tables {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "TABLE %s\n", table.Name)
+		fmt.Fprintln(tw, "  COLUMN\tTYPE\tNULL\tDEFAULT\tKEY")
+		for _, c := range table.Columns {
+			def, key := "", ""
+			if c.Default != nil {
+				def = *c.Default
+			}
+			switch {
+			case c.PrimaryKey:
+				key = "PK"
+			case c.Unique:
+				key = "UNIQUE"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", c.Name, c.Type, yesNo(c.Nullable), def, key)
+		}
+		for _, idx := range table.Indexes {
+			kind := "INDEX"
+			if idx.Primary {
+				kind = "PRIMARY"
+			} else if idx.Unique {
+				kind = "UNIQUE"
+			}
+			fmt.Fprintf(tw, "  %s %s (%s)\n", kind, idx.Name, strings.Join(idx.Columns, ", "))
+		}
+		for _, fk := range table.ForeignKeys {
+			fmt.Fprintf(tw, "  FOREIGN KEY %s (%s) -> %s (%s)", fk.Name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+			if fk.OnDelete != "" {
+				fmt.Fprintf(tw, " ON DELETE %s", fk.OnDelete)
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	if len(schema.Views) > 0 {
+		fmt.Fprintln(tw)
+		for _, v := range schema.Views {
+			fmt.Fprintf(tw, "VIEW %s\n", v.Name)
+		}
+	}
+	if len(schema.Enums) > 0 {
+		fmt.Fprintln(tw)
+		for _, e := range schema.Enums {
+			fmt.Fprintf(tw, "ENUM %s (%s)\n", e.Name, strings.Join(e.Values, ", "))
+		}
+	}
+	return tw.Flush()
+}
+
+func writeDiffTable(w io.Writer, diff *svc.SchemaDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if diff.HasDrift() {
+		fmt.Fprintln(tw, "KIND\tTABLE\tCOLUMN/INDEX\tEXPECTED\tACTUAL")
+		for _, d := range diff.Drifts {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Kind, d.Table, d.Column+d.Index, d.Expected, d.Actual)
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "%d model(s) compared with the %s schema (%s): %d difference(s)\n", diff.Models, diff.Source, diff.Dialect, len(diff.Drifts))
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
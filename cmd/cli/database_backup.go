@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubex-ecosystem/gdbase/internal/models/cron"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// DefaultBackupKeyEnv is the environment variable holding the backup key.
+const DefaultBackupKeyEnv = "GDBASE_BACKUP_KEY"
+
+func backupDatabaseCmd() *cobra.Command {
+	var configFile, database, output, dir, container, keyEnv, maxAge, schedule, scheduleUser string
+	var compress, encrypt bool
+	var keepLast int
+
+	shortDesc := "Back up the database"
+	longDesc := "Dump the database (pg_dump inside the container of the Postgres managed by gdbase, an online copy for SQLite), optionally compressed and encrypted, with a manifest holding the checksums, schema version and source. --keep-last and --max-age prune the older backups, and --schedule registers the same backup as a cron job"
+
+	cmd := &cobra.Command{
+		Use:         "backup",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Example: `  gdbase database backup --keep-last 7
+  GDBASE_BACKUP_KEY=$(openssl rand -base64 32) gdbase database backup --encrypt
+  gdbase database backup --encrypt --max-age 30d --schedule "0 3 * * *" --schedule-user <user id>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseRetentionAge(maxAge)
+			if err != nil {
+				return err
+			}
+			opts := svc.BackupOptions{
+				Dir:       dir,
+				Compress:  compress,
+				Container: container,
+				Retention: svc.BackupRetention{KeepLast: keepLast, MaxAge: age},
+			}
+			if encrypt {
+				if opts.EncryptionKey, err = backupKeyFromEnv(keyEnv); err != nil {
+					return err
+				}
+			}
+
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			if schedule != "" {
+				// the scheduled run repeats this command without --schedule
+				command := []string{"gdbase", "database", "backup", "--compress=" + strconv.FormatBool(compress)}
+				for _, flag := range [][2]string{{"--config-file", configFile}, {"--database", database}, {"--dir", dir}, {"--container", container}, {"--max-age", maxAge}} {
+					if flag[1] != "" {
+						command = append(command, flag[0], flag[1])
+					}
+				}
+				if keepLast > 0 {
+					command = append(command, "--keep-last", strconv.Itoa(keepLast))
+				}
+				if encrypt {
+					command = append(command, "--encrypt", "--key-env", keyEnv)
+				}
+				job, err := scheduleBackup(ctx, dbService, database, schedule, scheduleUser, command)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "scheduled %s (%s): %s\n", job.Name, job.CronExpression, job.Command)
+				return nil
+			}
+
+			m, err := dbService.Backup(ctx, opts)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, m, func(w io.Writer) error {
+				return writeBackupsTable(w, []*svc.BackupManifest{m})
+			})
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	cmd.Flags().StringVar(&dir, "dir", svc.DefaultBackupDir, "Directory of the backups")
+	cmd.Flags().BoolVar(&compress, "compress", true, "Compress the backup with gzip")
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "Encrypt the backup with the key in --key-env")
+	cmd.Flags().StringVar(&keyEnv, "key-env", DefaultBackupKeyEnv, "Environment variable holding the backup key (32 bytes, base64)")
+	cmd.Flags().StringVar(&container, "container", svc.DefaultBackupContainer, "Container running the managed Postgres")
+	cmd.Flags().IntVar(&keepLast, "keep-last", 0, "Keep only the last N backups of the database (0 keeps all)")
+	cmd.Flags().StringVar(&maxAge, "max-age", "", "Remove the backups older than this (e.g. 72h or 30d)")
+	cmd.Flags().StringVar(&schedule, "schedule", "", "Register the backup as a cron job with this cron expression instead of running it")
+	cmd.Flags().StringVar(&scheduleUser, "schedule-user", "", "ID of the user owning the scheduled cron job")
+
+	cmd.AddCommand(backupListCmd())
+	return cmd
+}
+
+func backupListCmd() *cobra.Command {
+	var dir, database, output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the backups, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backups, err := svc.ListBackups(dir)
+			if err != nil {
+				return err
+			}
+			if database != "" {
+				filtered := backups[:0]
+				for _, m := range backups {
+					if m.Database == database {
+						filtered = append(filtered, m)
+					}
+				}
+				backups = filtered
+			}
+			return writeOutput(cmd.OutOrStdout(), output, backups, func(w io.Writer) error {
+				return writeBackupsTable(w, backups)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", svc.DefaultBackupDir, "Directory of the backups")
+	cmd.Flags().StringVarP(&database, "database", "d", "", "Only the backups of this database")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	return cmd
+}
+
+func restoreDatabaseCmd() *cobra.Command {
+	var configFile, database, container, keyEnv string
+	var force bool
+
+	shortDesc := "Restore a backup into the database"
+	longDesc := "Restore a backup made by 'gdbase database backup' (the backup file or its manifest) after checking its checksums and that the schema version of the database matches the backup"
+
+	cmd := &cobra.Command{
+		Use:         "restore <backup>",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Args:        cobra.ExactArgs(1),
+		Example: `  gdbase database restore ~/.kubex/gdbase/backups/gdbase-20260101T030000000Z.manifest.json
+  gdbase database restore backup.dump.gz.enc --key-env GDBASE_BACKUP_KEY --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := svc.ReadBackupManifest(args[0])
+			if err != nil {
+				return err
+			}
+			opts := svc.RestoreOptions{Container: container, Force: force}
+			if m.Encrypted {
+				if opts.EncryptionKey, err = backupKeyFromEnv(keyEnv); err != nil {
+					return err
+				}
+			}
+
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			if _, err := dbService.Restore(ctx, args[0], opts); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s restored (schema version %d)\n", m.ID, m.SchemaVersion)
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringVar(&keyEnv, "key-env", DefaultBackupKeyEnv, "Environment variable holding the backup key")
+	cmd.Flags().StringVar(&container, "container", svc.DefaultBackupContainer, "Container running the managed Postgres")
+	cmd.Flags().BoolVar(&force, "force", false, "Restore even if the schema versions differ")
+	return cmd
+}
+
+// scheduleBackup creates or updates the cron job gdbase-backup-<database>
+// running command.
+func scheduleBackup(ctx context.Context, dbService *svc.DBServiceImpl, database, expression, user string, command []string) (*cron.CronJob, error) {
+	if len(strings.Fields(expression)) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields", expression)
+	}
+	userID, err := uuid.Parse(user)
+	if err != nil {
+		return nil, fmt.Errorf("--schedule-user must be the ID of an existing user: %w", err)
+	}
+	repo := cron.NewCronJobRepoImpl(ctx, dbService)
+	if repo == nil {
+		return nil, fmt.Errorf("cron jobs are not available in this database")
+	}
+	service := cron.NewCronJobService(repo)
+
+	for i, arg := range command {
+		if strings.ContainsAny(arg, " \t\"'") {
+			command[i] = strconv.Quote(arg)
+		}
+	}
+	name := "gdbase-backup-" + defaultName(database, "default")
+	jobs, err := service.ListCronJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Name == name {
+			job.CronExpression, job.Command, job.IsRecurring, job.IsActive = expression, strings.Join(command, " "), true, true
+			job.UpdatedBy = userID
+			return service.UpdateCronJob(ctx, job)
+		}
+	}
+	return service.CreateCronJob(cron.WithUserID(ctx, userID), &cron.CronJob{
+		Name:           name,
+		Description:    "Scheduled backup of " + defaultName(database, "the default database"),
+		CronType:       "cron",
+		CronExpression: expression,
+		Command:        strings.Join(command, " "),
+		IsRecurring:    true,
+		IsActive:       true,
+	})
+}
+
+func backupKeyFromEnv(name string) ([]byte, error) {
+	key := os.Getenv(name)
+	if key == "" {
+		return nil, fmt.Errorf("backup key not set: export %s (32 bytes, base64)", name)
+	}
+	return []byte(key), nil
+}
+
+// parseRetentionAge parses a duration, accepting days (30d) as well.
+func parseRetentionAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid --max-age: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --max-age: %s", s)
+	}
+	return d, nil
+}
+
+func defaultName(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func writeBackupsTable(w io.Writer, backups []*svc.BackupManifest) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDATABASE\tFORMAT\tSCHEMA\tSIZE\tENCRYPTED\tCREATED\tFILE")
+	for _, m := range backups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", m.ID, m.Database, m.Format, m.SchemaVersion, m.Size, yesNo(m.Encrypted), m.CreatedAt.Local().Format(time.DateTime), m.Path())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+	"github.com/spf13/cobra"
+)
+
+func GenCmd() *cobra.Command {
+	shortDesc := "Code generation commands for GDBase"
+	longDesc := "Code generation commands for GDBase"
+	cmd := &cobra.Command{
+		Use:         "gen",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(genModelsCmd())
+
+	return cmd
+}
+
+func genModelsCmd() *cobra.Command {
+	var configFile, database, outDir, module string
+	var include, exclude []string
+	var overrides map[string]string
+	var force, dryRun bool
+
+	shortDesc := "Generate models, repositories and services from the database schema"
+	longDesc := "Generate one package per table of a live database, with the model (struct and I-interface), the GORM repository and the service"
+
+	cmd := &cobra.Command{
+		Use:         "models [table...]",
+		Short:       shortDesc,
+		Long:        longDesc,
+		Annotations: GetDescriptions([]string{shortDesc, longDesc}, (os.Getenv("GDBASE_HIDEBANNER") == "true")),
+		Example: `  gdbase gen models --include 'order_*' --exclude order_audit --out internal/models
+  gdbase gen models clients --type clients.id=github.com/google/uuid.UUID --type numeric=*t.Money`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, dbService, err := openDatabaseService(cmd.Context(), configFile, database)
+			if err != nil {
+				return err
+			}
+			defer dbService.CloseDBConnection(ctx)
+
+			schema, err := dbService.DescribeSchema(ctx, args...)
+			if err != nil {
+				return err
+			}
+			files, err := svc.GenerateModels(schema, svc.ModelGenOptions{
+				Include:       include,
+				Exclude:       exclude,
+				TypeOverrides: overrides,
+				Module:        module,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, f := range files {
+				target := filepath.Join(outDir, f.Path)
+				if dryRun {
+					fmt.Fprintf(out, "// %s\n%s\n", target, f.Content)
+					continue
+				}
+				if _, err := os.Stat(target); err == nil && !force {
+					fmt.Fprintf(out, "skipped %s (already exists, use --force to overwrite)\n", target)
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(target, f.Content, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "generated %s\n", target)
+			}
+			return nil
+		},
+	}
+	addDatabaseFlags(cmd, &configFile, &database)
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Tables to generate (glob patterns); defaults to every table")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Tables to skip (glob patterns)")
+	cmd.Flags().StringToStringVar(&overrides, "type", nil, "Go type of a column: table.column=type, column=type or dbtype=type")
+	cmd.Flags().StringVar(&outDir, "out", "internal/models", "Output directory; one package is created per table")
+	cmd.Flags().StringVar(&module, "module", svc.DefaultModelModule, "Module path the generated code imports gdbase from")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated code instead of writing it")
+	return cmd
+}
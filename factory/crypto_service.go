@@ -1,9 +1,12 @@
 package factory
 
 import (
+	"context"
+
 	crp "github.com/kubex-ecosystem/gdbase/internal/security/crypto"
 	krs "github.com/kubex-ecosystem/gdbase/internal/security/external"
 	sci "github.com/kubex-ecosystem/gdbase/internal/security/interfaces"
+	it "github.com/kubex-ecosystem/gdbase/internal/types"
 )
 
 type CryptoService = sci.ICryptoService
@@ -16,3 +19,15 @@ func NewCryptoService() CryptoService {
 func NewKeyringService(keyringServiceName, keyringServicePath string) KeyringService {
 	return krs.NewKeyringService(keyringServiceName, keyringServicePath)
 }
+
+// SecretResolver resolves the secret references of one scheme (keyring://, env://, file://, enc://).
+type SecretResolver = it.SecretResolver
+type SecretResolverFunc = it.SecretResolverFunc
+
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	it.RegisterSecretResolver(scheme, resolver)
+}
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	return it.ResolveSecret(ctx, value)
+}
+func EncryptSecret(value string) (string, error) { return it.EncryptSecret(value) }
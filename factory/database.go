@@ -2,13 +2,19 @@ package factory
 
 import (
 	"context"
+	"database/sql"
 	"embed"
+	"io"
+	"io/fs"
+	"iter"
+	"net/url"
 
 	ci "github.com/kubex-ecosystem/gdbase/internal/interfaces"
 	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	it "github.com/kubex-ecosystem/gdbase/internal/types"
 
 	l "github.com/kubex-ecosystem/logz"
+	"gorm.io/gorm"
 )
 
 var migrationFiles embed.FS
@@ -24,6 +30,225 @@ func NewDatabaseService(ctx context.Context, config *DBConfigImpl, logger l.Logg
 	return svc.NewDatabaseService(ctx, config, logger)
 }
 
+// Using routes repositories built with ctx to the named database.
+func Using(ctx context.Context, name string) context.Context { return svc.Using(ctx, name) }
+func GetDBByName(ctx context.Context, d *DBServiceImpl, name string) (*gorm.DB, error) {
+	return svc.GetDBByName(ctx, d, name)
+}
+
+// UsePrimary sends the reads made with ctx to the primary (read-after-write consistency).
+func UsePrimary(ctx context.Context) context.Context { return svc.UsePrimary(ctx) }
+func ForcePrimary(db *gorm.DB) *gorm.DB              { return svc.ForcePrimary(db) }
+
+type ReplicaHealth = svc.ReplicaHealth
+
+// TxOption configures a transaction started with DBService.WithTx.
+type TxOption = svc.TxOption
+
+func WithIsolation(level sql.IsolationLevel) TxOption { return svc.WithIsolation(level) }
+func WithReadOnly() TxOption                          { return svc.WithReadOnly() }
+func WithMaxRetries(n int) TxOption                   { return svc.WithMaxRetries(n) }
+
+// TxFromContext returns the transaction carried by ctx, if any.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) { return svc.TxFromContext(ctx) }
+
+// QueryInto runs query on d and scans every row into a T (see services.QueryInto).
+func QueryInto[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) ([]T, error) {
+	return svc.QueryInto[T](ctx, d, query, args...)
+}
+
+// QueryOne runs query on d and scans the first row into a T.
+func QueryOne[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) (T, error) {
+	return svc.QueryOne[T](ctx, d, query, args...)
+}
+
+// QueryIter streams the rows of query into T values.
+func QueryIter[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) iter.Seq2[T, error] {
+	return svc.QueryIter[T](ctx, d, query, args...)
+}
+
+// ConfigChange is emitted on the DBServiceImpl event bus when a reload changes a connection.
+type ConfigChange = svc.ConfigChange
+
+const DatabaseEvents = svc.DatabaseEvents
+
+// Schema is the live structure of a database (see DBService.DescribeSchema).
+type Schema = svc.Schema
+type TableSchema = svc.TableSchema
+type ColumnSchema = svc.ColumnSchema
+
+// SchemaDiff lists the differences between the registered models and a database schema.
+type SchemaDiff = svc.SchemaDiff
+type SchemaDrift = svc.SchemaDrift
+
+// RegisterSchemaModels registers the models compared by DiffSchema.
+func RegisterSchemaModels(models ...any) { svc.RegisterSchemaModels(models...) }
+
+// QueryLogConfig configures the GORM statement logging (DBConfig.QueryLog).
+type QueryLogConfig = svc.QueryLogConfig
+type GormLogger = svc.GormLogger
+
+// SlowQuery is emitted on the service EventBus (DatabaseEvents, SlowQueryEvent).
+type SlowQuery = svc.SlowQuery
+
+const SlowQueryEvent = svc.SlowQueryEvent
+
+// WithRequestID tags the statements run with ctx in the logs and slow query events.
+func WithRequestID(ctx context.Context, id string) context.Context { return svc.WithRequestID(ctx, id) }
+
+// TenancyConfig configures multi-tenancy (DBConfig.Tenancy).
+type TenancyConfig = svc.TenancyConfig
+
+var (
+	ErrTenantRequired = svc.ErrTenantRequired
+	ErrTenantMismatch = svc.ErrTenantMismatch
+)
+
+// WithTenant scopes the repository queries run with ctx to tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return svc.WithTenant(ctx, tenant)
+}
+
+// WithoutTenant lets the queries run with ctx reach every tenant.
+func WithoutTenant(ctx context.Context) context.Context { return svc.WithoutTenant(ctx) }
+
+// ErrStaleObject is matched by the errors of updates lost to a concurrent writer.
+var ErrStaleObject = svc.ErrStaleObject
+
+type StaleObjectError = svc.StaleObjectError
+
+// SaveOptimistic saves value checking its lock version (see services.LockField).
+func SaveOptimistic(db *gorm.DB, value any) error { return svc.SaveOptimistic(db, value) }
+
+// RetryOnStale retries fn while it fails with ErrStaleObject.
+func RetryOnStale(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	return svc.RetryOnStale(ctx, attempts, fn)
+}
+
+// Repository is the generic CRUD and query repository of a model.
+type Repository[T any] = svc.Repository[T]
+type Page[T any] = svc.Page[T]
+type QuerySpec = svc.QuerySpec
+type Filter = svc.Filter
+type FilterOp = svc.FilterOp
+type SortField = svc.SortField
+
+const (
+	OpEq      = svc.OpEq
+	OpNe      = svc.OpNe
+	OpGt      = svc.OpGt
+	OpGte     = svc.OpGte
+	OpLt      = svc.OpLt
+	OpLte     = svc.OpLte
+	OpIn      = svc.OpIn
+	OpNotIn   = svc.OpNotIn
+	OpLike    = svc.OpLike
+	OpILike   = svc.OpILike
+	OpIsNull  = svc.OpIsNull
+	OpNotNull = svc.OpNotNull
+)
+
+func NewRepository[T any](db *gorm.DB) *Repository[T] { return svc.NewRepository[T](db) }
+func NewQuerySpec() *QuerySpec                        { return svc.NewQuerySpec() }
+
+// Searchable models rank by text in Repository.Search (see EnsureSearchIndex).
+type Searchable = svc.Searchable
+type SearchField = svc.SearchField
+type SearchOptions = svc.SearchOptions
+type SearchResult[T any] = svc.SearchResult[T]
+type SearchHit[T any] = svc.SearchHit[T]
+type SearchMethod = svc.SearchMethod
+
+var ErrSearchFTS5Unavailable = svc.ErrSearchFTS5Unavailable
+
+func EnsureSearchIndex(ctx context.Context, db *gorm.DB, model Searchable) error {
+	return svc.EnsureSearchIndex(ctx, db, model)
+}
+
+// BackupManifest describes a backup made by DBServiceImpl.Backup.
+type BackupManifest = svc.BackupManifest
+type BackupOptions = svc.BackupOptions
+type BackupRetention = svc.BackupRetention
+type RestoreOptions = svc.RestoreOptions
+
+func ListBackups(dir string) ([]*BackupManifest, error) { return svc.ListBackups(dir) }
+func ReadBackupManifest(path string) (*BackupManifest, error) {
+	return svc.ReadBackupManifest(path)
+}
+func PruneBackups(dir, database string, policy BackupRetention) ([]*BackupManifest, error) {
+	return svc.PruneBackups(dir, database, policy)
+}
+
+// SeedFixture is a fixture file of a seed set (see DBServiceImpl.Seed).
+type SeedFixture = svc.SeedFixture
+type SeedOptions = svc.SeedOptions
+type SeedTableResult = svc.SeedTableResult
+type SeedPreparer = svc.SeedPreparer
+
+func LoadSeedSet(dir, env string) ([]*SeedFixture, error) { return svc.LoadSeedSet(dir, env) }
+func SeedDB(ctx context.Context, db *gorm.DB, fixtures []*SeedFixture, opts SeedOptions) ([]SeedTableResult, error) {
+	return svc.SeedDB(ctx, db, fixtures, opts)
+}
+
+// DataImportOptions configures the import of a file into a model (see DBServiceImpl.ImportData).
+type DataImportOptions = svc.DataImportOptions
+type DataExportOptions = svc.DataExportOptions
+type DataReport = it.DataReport
+type DataRowError = it.DataRowError
+type DataRecord = it.DataRecord
+type DataMapping = it.DataMapping
+type DataImporter = it.DataImporter
+type DataExporter = it.DataExporter
+
+func NewDataImporter(sink it.DataSink, mapping *DataMapping) DataImporter {
+	return it.NewDataImporter(sink, mapping)
+}
+func NewDataExporter(source it.DataSource, mapping *DataMapping) DataExporter {
+	return it.NewDataExporter(source, mapping)
+}
+func ImportDataDB(ctx context.Context, db *gorm.DB, r io.Reader, opts DataImportOptions) (*DataReport, error) {
+	return svc.ImportDataDB(ctx, db, r, opts)
+}
+func ExportDataDB(ctx context.Context, db *gorm.DB, w io.Writer, opts DataExportOptions) (*DataReport, error) {
+	return svc.ExportDataDB(ctx, db, w, opts)
+}
+
+// ListQuery carries the filter/sort/page parameters of a list endpoint (see ParseListQuery).
+type ListQuery = svc.ListQuery
+type Filterable = svc.Filterable
+
+func ParseListQuery(values url.Values) (ListQuery, error) { return svc.ParseListQuery(values) }
+func ParseFilterExpr(expr string, model Filterable) ([]Filter, error) {
+	return svc.ParseFilterExpr(expr, model)
+}
+func ParseSortExpr(expr string, model Filterable) ([]SortField, error) {
+	return svc.ParseSortExpr(expr, model)
+}
+
+// SQLCapture records the statements run with a context from WithSQLCapture (dry run optional).
+type SQLCapture = svc.SQLCapture
+type CapturedStatement = svc.CapturedStatement
+
+func NewSQLCapture(dryRun bool) *SQLCapture { return svc.NewSQLCapture(dryRun) }
+func WithSQLCapture(ctx context.Context, c *SQLCapture) context.Context {
+	return svc.WithSQLCapture(ctx, c)
+}
+
+// ModelGenOptions configures GenerateModels.
+type ModelGenOptions = svc.ModelGenOptions
+type GeneratedFile = svc.GeneratedFile
+
+// GenerateModels generates the model, repository and service packages of the tables of schema.
+func GenerateModels(schema *Schema, opts ModelGenOptions) ([]GeneratedFile, error) {
+	return svc.GenerateModels(schema, opts)
+}
+
+// DSN is a connection string built for a database dialect, with its redacted form for logs.
+type DSN = svc.DSN
+
+func BuildDSN(db *it.Database) (DSN, error) { return svc.BuildDSN(db) }
+func RedactDSN(dsn string) string           { return svc.RedactDSN(dsn) }
+
 type Rows interface {
 	Next() bool
 	Scan(dest ...interface{}) error
@@ -50,6 +275,21 @@ func SetupDatabaseServices(ctx context.Context, d svc.IDockerService, config *DB
 	return svc.SetupDatabaseServices(ctx, d, config)
 }
 
+type Migration = svc.Migration
+type MigrationSource = svc.MigrationSource
+type MigrationStatus = svc.MigrationStatus
+type Migrator = svc.Migrator
+
+func NewMigrator(db *sql.DB, dialect string) (*Migrator, error) { return svc.NewMigrator(db, dialect) }
+func EmbeddedMigrationSource() MigrationSource                  { return svc.EmbeddedMigrationSource() }
+func NewDirMigrationSource(dir string) MigrationSource          { return svc.NewDirMigrationSource(dir) }
+func NewFSMigrationSource(fsys fs.FS, dir string) MigrationSource {
+	return svc.NewFSMigrationSource(fsys, dir)
+}
+func TranslateMigrations(migrations []Migration, dialect string) ([]Migration, []string, error) {
+	return svc.TranslateMigrations(migrations, dialect)
+}
+
 func SetMigrationFiles(mf embed.FS) {
 	migrationFiles = mf
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/google/uuid"
+
 	m "github.com/kubex-ecosystem/gdbase/internal/models/cron"
 	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 )
@@ -32,6 +34,11 @@ func NewCronJobRepo(ctx context.Context, dbService *svc.DBServiceImpl) CronJobRe
 	return m.NewCronJobRepoImpl(ctx, dbService)
 }
 
+// WithCronUserID returns a copy of ctx carrying the user that creates the cron jobs.
+func WithCronUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return m.WithUserID(ctx, userID)
+}
+
 func NewCronJob(ctx context.Context, cron *CronJobModel, restrict bool) (*CronJobModel, error) {
 	if cn, ok := m.NewCronJob(ctx, cron, restrict).(*CronJobModel); ok {
 		return cn, nil
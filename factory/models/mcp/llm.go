@@ -21,7 +21,7 @@ func NewLLMService(llmRepo LLMRepo) LLMService {
 }
 
 func NewLLMRepo(ctx context.Context, dbService *svc.DBServiceImpl) LLMRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "mcp_llm")
 	if err != nil {
 		return nil
 	}
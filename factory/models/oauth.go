@@ -63,7 +63,7 @@ func NewAuthCodeRepo(ctx context.Context, dbService *svc.DBServiceImpl) AuthCode
 	if dbService == nil {
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "oauth_clients")
 	if err != nil {
 		return nil
 	}
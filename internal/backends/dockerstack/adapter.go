@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/kubex-ecosystem/gdbase/internal/provider"
@@ -18,8 +19,11 @@ import (
 
 // DockerStackProvider wraps legacy Docker services into new Provider interface
 type DockerStackProvider struct {
+	mu            sync.Mutex
 	logger        l.Logger
 	dockerService *svc.DockerService
+	client        svc.IDockerClient
+	lastHealth    HealthReport
 }
 
 // NewDockerStackProvider creates a new Docker-based provider
@@ -47,6 +51,22 @@ func (p *DockerStackProvider) Capabilities(ctx context.Context) (provider.Capabi
 			"network.internal": true,
 			"publish.ports":    true,
 			"volumes.persist":  true,
+			"engine.postgres":  true,
+			"engine.mongo":     true,
+			"engine.redis":     true,
+			"engine.rabbitmq":  true,
+			"dialect.postgres": true,
+			"tenancy.row":      true,
+			"tenancy.schema":   true,
+			"search.fulltext":  true,
+			// contrib extensions of postgres:17-alpine created by the migrations
+			"extensions.pgcrypto":      true,
+			"extensions.uuid-ossp":     true,
+			"extensions.pg_trgm":       true,
+			"extensions.btree_gist":    true,
+			"extensions.fuzzystrmatch": true,
+			"extensions.hstore":        true,
+			"extensions.citext":        true,
 		},
 	}, nil
 }
@@ -279,19 +299,3 @@ func (p *DockerStackProvider) RunPostgresMigrations(ctx context.Context, dsn str
 
 	return nil
 }
-
-// Health verifies connectivity to all services
-func (p *DockerStackProvider) Health(ctx context.Context, eps map[string]provider.Endpoint) error {
-	// TODO: Implement real health checks
-	// For now, just verify Docker service is initialized
-	if p.dockerService == nil {
-		return fmt.Errorf("docker service not initialized")
-	}
-	return nil
-}
-
-// Stop stops all managed containers
-func (p *DockerStackProvider) Stop(ctx context.Context, refs []provider.ServiceRef) error {
-	// TODO: Call docker service stop methods
-	return nil
-}
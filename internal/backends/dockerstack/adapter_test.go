@@ -0,0 +1,134 @@
+package dockerstack
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	c "github.com/docker/docker/api/types/container"
+	i "github.com/docker/docker/api/types/image"
+	n "github.com/docker/docker/api/types/network"
+	v "github.com/docker/docker/api/types/volume"
+	o "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+)
+
+// fakeDocker records the calls of Stop; gdbase-redis does not exist
+type fakeDocker struct {
+	calls []string
+}
+
+func (f *fakeDocker) call(op, id string) error {
+	f.calls = append(f.calls, op+" "+id)
+	if strings.HasPrefix(id, "gdbase-redis") {
+		return cerrdefs.ErrNotFound
+	}
+	return nil
+}
+
+func (f *fakeDocker) ContainerStop(ctx context.Context, id string, opts c.StopOptions) error {
+	return f.call("stop", id)
+}
+func (f *fakeDocker) ContainerRemove(ctx context.Context, id string, opts c.RemoveOptions) error {
+	return f.call("rm", id)
+}
+func (f *fakeDocker) VolumeRemove(ctx context.Context, id string, force bool) error {
+	return f.call("volume rm", id)
+}
+func (f *fakeDocker) ContainerList(context.Context, c.ListOptions) ([]c.Summary, error) {
+	return nil, nil
+}
+func (f *fakeDocker) ContainerCreate(context.Context, *c.Config, *c.HostConfig, *n.NetworkingConfig, *o.Platform, string) (c.CreateResponse, error) {
+	return c.CreateResponse{}, nil
+}
+func (f *fakeDocker) ContainerStart(context.Context, string, c.StartOptions) error { return nil }
+func (f *fakeDocker) VolumeCreate(context.Context, v.CreateOptions) (v.Volume, error) {
+	return v.Volume{}, nil
+}
+func (f *fakeDocker) VolumeList(context.Context, v.ListOptions) (v.ListResponse, error) {
+	return v.ListResponse{}, nil
+}
+func (f *fakeDocker) ImagePull(context.Context, string, i.PullOptions) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func TestHealthReport(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 256)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed.Close()
+
+	p := NewDockerStackProvider()
+	eps := map[string]provider.Endpoint{
+		"redis":  {DSN: "redis://" + ln.Addr().String(), Redacted: "redis://" + ln.Addr().String()},
+		"mongo":  {DSN: "mongodb://root:x@" + closed.Addr().String(), Redacted: "mongodb://root:***@" + closed.Addr().String()},
+		"legacy": {DSN: "memcached://127.0.0.1:11211"},
+	}
+	err = p.Health(context.Background(), eps)
+	report := p.LastHealth()
+	if err == nil || report.Healthy() || len(report.Services) != 3 {
+		t.Fatalf("unexpected report %+v: %v", report, err)
+	}
+	legacy, mongo, redis := report.Services[0], report.Services[1], report.Services[2]
+	if !redis.Healthy || redis.Engine != provider.EngineRedis || redis.Latency <= 0 {
+		t.Fatalf("unexpected redis health %+v", redis)
+	}
+	if mongo.Healthy || mongo.Engine != provider.EngineMongo || !strings.Contains(err.Error(), "mongodb://root:***@") {
+		t.Fatalf("unexpected mongo health %+v: %v", mongo, err)
+	}
+	if legacy.Healthy || !strings.Contains(legacy.Error, "unknown engine") {
+		t.Fatalf("unexpected legacy health %+v", legacy)
+	}
+}
+
+func TestStopServices(t *testing.T) {
+	fake := &fakeDocker{}
+	p := NewDockerStackProvider()
+	p.client = fake
+	refs := []provider.ServiceRef{{Name: "pg", Engine: provider.EnginePostgres}, {Name: "redis"}, {Name: "mongo", Engine: provider.EngineMongo}}
+
+	if err := p.Stop(context.Background(), refs); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if got := strings.Join(fake.calls, ","); got != "stop gdbase-pg,stop gdbase-redis" {
+		t.Fatalf("unexpected calls %s", got)
+	}
+
+	fake.calls = nil
+	if err := p.StopServices(context.Background(), refs[:1], StopOptions{RemoveVolumes: true}); err != nil {
+		t.Fatalf("StopServices: %v", err)
+	}
+	if got := strings.Join(fake.calls, ","); got != "stop gdbase-pg,rm gdbase-pg,volume rm gdbase-pg-data,volume rm gdbase-pg-init" {
+		t.Fatalf("unexpected calls %s", got)
+	}
+
+	fake.calls = nil
+	if err := p.StopServices(context.Background(), nil, StopOptions{}); err != nil || len(fake.calls) != 3 {
+		t.Fatalf("StopServices(all): %v %v", fake.calls, err)
+	}
+}
@@ -0,0 +1,110 @@
+package dockerstack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/kubex-ecosystem/gdbase/internal/backends/probe"
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+)
+
+// ServiceHealth is the result of the probe of one endpoint
+type ServiceHealth struct {
+	Service  string
+	Engine   provider.Engine
+	Redacted string
+	Healthy  bool
+	Latency  time.Duration
+	Error    string
+}
+
+// HealthReport gathers the probes of the endpoints, ordered by service
+type HealthReport struct {
+	CheckedAt time.Time
+	Services  []ServiceHealth
+}
+
+// Healthy tells whether every service answered its probe
+func (r HealthReport) Healthy() bool {
+	for _, s := range r.Services {
+		if !s.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Err lists the unhealthy services, or returns nil
+func (r HealthReport) Err() error {
+	var failed []string
+	for _, s := range r.Services {
+		if !s.Healthy {
+			failed = append(failed, fmt.Sprintf("%s (%s): %s", s.Service, s.Redacted, s.Error))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unhealthy services: %s", strings.Join(failed, "; "))
+}
+
+// CheckHealth probes every endpoint concurrently: SELECT 1 on Postgres, PING
+// on Redis, an AMQP open/close on RabbitMQ and the ping command on MongoDB
+func (p *DockerStackProvider) CheckHealth(ctx context.Context, eps map[string]provider.Endpoint) HealthReport {
+	report := HealthReport{CheckedAt: time.Now(), Services: make([]ServiceHealth, 0, len(eps))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, ep := range eps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := ServiceHealth{Service: name, Redacted: ep.Redacted}
+			engine, err := pb.Engine(ep.DSN)
+			if err == nil {
+				s.Engine = engine
+				start := time.Now()
+				err = pb.Ping(ctx, ep.DSN)
+				s.Latency = time.Since(start)
+			}
+			s.Healthy = err == nil
+			if err != nil {
+				s.Error = err.Error()
+			}
+			mu.Lock()
+			report.Services = append(report.Services, s)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Service < report.Services[j].Service })
+	return report
+}
+
+// Health probes every endpoint and keeps the report (see LastHealth)
+func (p *DockerStackProvider) Health(ctx context.Context, eps map[string]provider.Endpoint) error {
+	report := p.CheckHealth(ctx, eps)
+	for _, s := range report.Services {
+		if s.Healthy {
+			gl.Log("debug", fmt.Sprintf("DockerStack %s healthy in %s", s.Service, s.Latency))
+		} else {
+			gl.Log("warn", fmt.Sprintf("DockerStack %s unhealthy: %s", s.Service, s.Error))
+		}
+	}
+	p.mu.Lock()
+	p.lastHealth = report
+	p.mu.Unlock()
+	return report.Err()
+}
+
+// LastHealth returns the report of the last Health call
+func (p *DockerStackProvider) LastHealth() HealthReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastHealth
+}
@@ -0,0 +1,135 @@
+package dockerstack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	c "github.com/docker/docker/api/types/container"
+	k "github.com/docker/docker/client"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+)
+
+// RemoveVolumesEnv makes Stop remove the containers and their volumes
+const RemoveVolumesEnv = "GDBASE_DOCKER_REMOVE_VOLUMES"
+
+// StopOptions tunes StopServices
+type StopOptions struct {
+	// Timeout is the grace period before the container is killed
+	Timeout time.Duration
+	// RemoveVolumes removes the containers and their named volumes (the host
+	// directories of bind volumes are kept)
+	RemoveVolumes bool
+}
+
+// managedContainer is a container started by SetupDatabaseServices
+type managedContainer struct {
+	name    string
+	volumes []string
+}
+
+var managedContainers = map[provider.Engine]managedContainer{
+	provider.EnginePostgres: {name: "gdbase-pg", volumes: []string{"gdbase-pg-data", "gdbase-pg-init"}},
+	provider.EngineRedis:    {name: "gdbase-redis", volumes: []string{"gdbase-redis-data"}},
+	provider.EngineRabbit:   {name: "gdbase-rabbitmq", volumes: []string{"gdbase-rabbitmq"}},
+}
+
+// refEngines names the engine of the refs without one
+var refEngines = map[string]provider.Engine{
+	"pg":       provider.EnginePostgres,
+	"kubex_db": provider.EnginePostgres,
+	"mongo":    provider.EngineMongo,
+	"redis":    provider.EngineRedis,
+	"rabbit":   provider.EngineRabbit,
+}
+
+// Stop gracefully stops the containers of refs (all the managed containers
+// when refs is empty); the volumes are removed when $GDBASE_DOCKER_REMOVE_VOLUMES
+// is true
+func (p *DockerStackProvider) Stop(ctx context.Context, refs []provider.ServiceRef) error {
+	return p.StopServices(ctx, refs, StopOptions{
+		Timeout:       30 * time.Second,
+		RemoveVolumes: strings.EqualFold(os.Getenv(RemoveVolumesEnv), "true"),
+	})
+}
+
+// StopServices stops the containers of refs (all the managed containers when
+// refs is empty); the containers already stopped or removed are skipped
+func (p *DockerStackProvider) StopServices(ctx context.Context, refs []provider.ServiceRef, opts StopOptions) error {
+	cli, err := p.dockerClient()
+	if err != nil {
+		return err
+	}
+	var targets []managedContainer
+	if len(refs) == 0 {
+		for _, engine := range []provider.Engine{provider.EnginePostgres, provider.EngineRedis, provider.EngineRabbit} {
+			targets = append(targets, managedContainers[engine])
+		}
+	}
+	for _, ref := range refs {
+		engine := ref.Engine
+		if engine == "" {
+			engine = refEngines[ref.Name]
+		}
+		mc, ok := managedContainers[engine]
+		if !ok {
+			gl.Log("warn", fmt.Sprintf("DockerStack manages no container for %s (%s)", ref.Name, engine))
+			continue
+		}
+		targets = append(targets, mc)
+	}
+
+	seconds := int(opts.Timeout.Seconds())
+	var errs []string
+	for _, mc := range targets {
+		if err := cli.ContainerStop(ctx, mc.name, c.StopOptions{Timeout: &seconds}); err != nil && !errdefs.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("%s: %v", mc.name, err))
+			continue
+		}
+		gl.Log("info", fmt.Sprintf("DockerStack container %s stopped", mc.name))
+		if !opts.RemoveVolumes {
+			continue
+		}
+		if err := cli.ContainerRemove(ctx, mc.name, c.RemoveOptions{}); err != nil && !errdefs.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("%s: %v", mc.name, err))
+			continue
+		}
+		for _, vol := range mc.volumes {
+			if err := cli.VolumeRemove(ctx, vol, false); err != nil && !errdefs.IsNotFound(err) {
+				errs = append(errs, fmt.Sprintf("%s: %v", vol, err))
+				continue
+			}
+			gl.Log("info", fmt.Sprintf("DockerStack volume %s removed", vol))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to stop %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// dockerClient returns the client of the running docker service, or a new
+// one when Stop is called without Start (e.g. from another process)
+func (p *DockerStackProvider) dockerClient() (svc.IDockerClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+	if p.dockerService != nil && p.dockerService.Cli != nil {
+		return p.dockerService.Cli, nil
+	}
+	cli, err := k.NewClientWithOpts(k.FromEnv, k.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	p.client = cli
+	return cli, nil
+}
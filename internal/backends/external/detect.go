@@ -0,0 +1,303 @@
+package external
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/kubex-ecosystem/gdbase/internal/backends/probe"
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+)
+
+// Kinds of DSN: the SQL dialects of services and the other engines
+const (
+	kindMongo  = "mongo"
+	kindRedis  = "redis"
+	kindRabbit = "rabbitmq"
+)
+
+var (
+	mysqlDSNRe  = regexp.MustCompile(`^(?:[^:@/]*(?::[^@]*)?@)?(?:tcp|unix)\(([^)]*)\)/`)
+	pgKeyHostRe = regexp.MustCompile(`(?:^|\s)host=(\S+)`)
+	pgKeyPortRe = regexp.MustCompile(`(?:^|\s)port=(\d+)`)
+	majorRe     = regexp.MustCompile(`^\D*(\d+)`)
+)
+
+// dsnKind returns the SQL dialect (postgres, mysql, sqlserver, sqlite) or
+// the engine of a DSN.
+func dsnKind(dsn string) string {
+	scheme, _, _ := strings.Cut(dsn, "://")
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return svc.DialectPostgres
+	case "sqlserver":
+		return svc.DialectSQLServer
+	case "mongodb", "mongodb+srv":
+		return kindMongo
+	case "redis", "rediss":
+		return kindRedis
+	case "amqp", "amqps":
+		return kindRabbit
+	}
+	switch {
+	case mysqlDSNRe.MatchString(dsn):
+		return svc.DialectMySQL
+	case pgKeyHostRe.MatchString(dsn) || strings.Contains(dsn, "dbname="):
+		return svc.DialectPostgres
+	default:
+		return svc.DialectSQLite
+	}
+}
+
+// checkEngine verifies that the DSN of a service serves its engine; the
+// Postgres services accept any SQL database.
+func checkEngine(ref provider.ServiceRef, kind string) error {
+	want := ""
+	switch ref.Engine {
+	case provider.EngineMongo:
+		want = kindMongo
+	case provider.EngineRedis:
+		want = kindRedis
+	case provider.EngineRabbit:
+		want = kindRabbit
+	case provider.EnginePostgres:
+		if kind == kindMongo || kind == kindRedis || kind == kindRabbit {
+			return fmt.Errorf("external service %s is %s, not a SQL database", ref.Name, kind)
+		}
+	}
+	if want != "" && kind != want {
+		return fmt.Errorf("external service %s is %s, not %s", ref.Name, kind, ref.Engine)
+	}
+	return nil
+}
+
+// probe is what was detected on a service
+type probe struct {
+	version  string
+	features map[string]bool
+}
+
+// probeService pings a service and detects its version, extensions and
+// permissions
+func probeService(ctx context.Context, kind, dsn string) (*probe, error) {
+	if err := ping(ctx, kind, dsn); err != nil {
+		return nil, err
+	}
+	p := &probe{features: map[string]bool{"dialect." + kind: true}}
+	switch kind {
+	case kindMongo, kindRedis, kindRabbit:
+		p.features["engine."+kind] = true
+		return p, nil
+	}
+	if kind == svc.DialectPostgres {
+		p.features["engine.postgres"] = true
+	}
+
+	db, err := openSQL(kind, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	switch kind {
+	case svc.DialectPostgres:
+		err = probePostgres(ctx, db, p)
+	case svc.DialectMySQL:
+		err = probeMySQL(ctx, db, p)
+	case svc.DialectSQLServer:
+		err = probeSQLServer(ctx, db, p)
+	default:
+		err = probeSQLite(ctx, db, p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect the capabilities: %w", err)
+	}
+	if m := majorRe.FindStringSubmatch(p.version); m != nil {
+		p.features["server."+kind+"."+m[1]] = true
+	}
+	return p, nil
+}
+
+func probePostgres(ctx context.Context, db *sql.DB, p *probe) error {
+	var createSchema, createTable, superuser bool
+	err := db.QueryRowContext(ctx, `SELECT current_setting('server_version'),
+		has_database_privilege(current_database(), 'CREATE'),
+		has_schema_privilege(COALESCE(current_schema(), 'public'), 'CREATE'),
+		COALESCE((SELECT rolsuper FROM pg_roles WHERE rolname = current_user), false)`).
+		Scan(&p.version, &createSchema, &createTable, &superuser)
+	if err != nil {
+		return err
+	}
+	p.features["permissions.create_schema"] = createSchema || superuser
+	p.features["permissions.create_table"] = createTable || superuser
+	p.features["permissions.superuser"] = superuser
+
+	rows, err := db.QueryContext(ctx, `SELECT extname FROM pg_extension`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ext string
+		if err := rows.Scan(&ext); err != nil {
+			return err
+		}
+		p.features["extensions."+ext] = true
+	}
+	return rows.Err()
+}
+
+func probeMySQL(ctx context.Context, db *sql.DB, p *probe) error {
+	var user string
+	if err := db.QueryRowContext(ctx, `SELECT VERSION(), CURRENT_USER()`).Scan(&p.version, &user); err != nil {
+		return err
+	}
+	if strings.Contains(strings.ToLower(p.version), "mariadb") {
+		p.features["dialect.mariadb"] = true
+	}
+	at := strings.LastIndex(user, "@")
+	grantee := fmt.Sprintf("'%s'@'%s'", user[:max(at, 0)], user[at+1:])
+	rows, err := db.QueryContext(ctx, `SELECT 'global', PRIVILEGE_TYPE FROM information_schema.USER_PRIVILEGES WHERE GRANTEE = ?
+		UNION SELECT 'schema', PRIVILEGE_TYPE FROM information_schema.SCHEMA_PRIVILEGES WHERE GRANTEE = ? AND TABLE_SCHEMA = DATABASE()`,
+		grantee, grantee)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for _, f := range []string{"permissions.create_schema", "permissions.create_table", "permissions.superuser"} {
+		p.features[f] = false
+	}
+	for rows.Next() {
+		var scope, priv string
+		if err := rows.Scan(&scope, &priv); err != nil {
+			return err
+		}
+		switch {
+		case priv == "CREATE" && scope == "global":
+			p.features["permissions.create_schema"] = true
+			p.features["permissions.create_table"] = true
+		case priv == "CREATE":
+			p.features["permissions.create_table"] = true
+		case priv == "SUPER":
+			p.features["permissions.superuser"] = true
+		}
+	}
+	return rows.Err()
+}
+
+func probeSQLServer(ctx context.Context, db *sql.DB, p *probe) error {
+	var createTable, createSchema, sysadmin int
+	err := db.QueryRowContext(ctx, `SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128)),
+		COALESCE(HAS_PERMS_BY_NAME(DB_NAME(), 'DATABASE', 'CREATE TABLE'), 0),
+		COALESCE(HAS_PERMS_BY_NAME(DB_NAME(), 'DATABASE', 'CREATE SCHEMA'), 0),
+		COALESCE(IS_SRVROLEMEMBER('sysadmin'), 0)`).Scan(&p.version, &createTable, &createSchema, &sysadmin)
+	if err != nil {
+		return err
+	}
+	p.features["permissions.create_table"] = createTable == 1
+	p.features["permissions.create_schema"] = createSchema == 1
+	p.features["permissions.superuser"] = sysadmin == 1
+	return nil
+}
+
+// probeSQLite checks that tables can be created in a transaction rolled
+// back
+func probeSQLite(ctx context.Context, db *sql.DB, p *probe) error {
+	if err := db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&p.version); err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `CREATE TABLE gdbase_probe (id INTEGER)`)
+	p.features["permissions.create_table"] = err == nil
+	return nil
+}
+
+// ping checks that a service answers: SELECT 1 on the SQL databases, PING
+// on Redis, an AMQP handshake on RabbitMQ and the ping command on MongoDB
+func ping(ctx context.Context, kind, dsn string) error {
+	switch kind {
+	case kindRedis:
+		return pb.Redis(ctx, dsn)
+	case kindRabbit:
+		return pb.AMQP(ctx, dsn)
+	case kindMongo:
+		return pb.Mongo(ctx, dsn)
+	}
+	db, err := openSQL(kind, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var one int
+	return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// openSQL opens a SQL DSN; a SQLite file must exist (an external database
+// is never created).
+func openSQL(kind, dsn string) (*sql.DB, error) {
+	driver := kind
+	switch kind {
+	case svc.DialectPostgres:
+		driver = "pgx"
+	case svc.DialectSQLite:
+		driver = "sqlite3"
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		if !strings.HasPrefix(dsn, "file:") {
+			dsn = "file:" + dsn
+		}
+		if !strings.Contains(dsn, "mode=") {
+			dsn += sep + "mode=rw"
+		}
+	}
+	return sql.Open(driver, dsn)
+}
+
+// endpointOf builds the endpoint of a DSN
+func endpointOf(dsn string) provider.Endpoint {
+	ep := provider.Endpoint{DSN: dsn, Redacted: svc.RedactDSN(dsn)}
+	kind := dsnKind(dsn)
+	switch {
+	case kind == svc.DialectSQLite:
+		ep.Host = "localhost"
+	case strings.Contains(dsn, "://"):
+		if u, err := url.Parse(dsn); err == nil {
+			ep.Host = u.Hostname()
+			ep.Port, _ = strconv.Atoi(u.Port())
+		}
+	case kind == svc.DialectMySQL:
+		addr := mysqlDSNRe.FindStringSubmatch(dsn)[1]
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		ep.Host = host
+		ep.Port, _ = strconv.Atoi(port)
+	case kind == svc.DialectPostgres:
+		if m := pgKeyHostRe.FindStringSubmatch(dsn); m != nil {
+			ep.Host = m[1]
+		}
+		if m := pgKeyPortRe.FindStringSubmatch(dsn); m != nil {
+			ep.Port, _ = strconv.Atoi(m[1])
+		}
+	}
+	if ep.Port == 0 {
+		ep.Port = map[string]int{
+			svc.DialectPostgres: 5432, svc.DialectMySQL: 3306, svc.DialectSQLServer: 1433,
+			kindMongo: 27017, kindRedis: 6379, kindRabbit: 5672,
+		}[kind]
+	}
+	return ep
+}
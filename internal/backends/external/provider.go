@@ -0,0 +1,256 @@
+// Package external provides an attach-only backend for databases gdbase does
+// not own (a shared staging Postgres, RDS, an on-prem MySQL...)
+package external
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+)
+
+const (
+	// ConfigEnv points to a gdbase config file whose databases are attached
+	// by name
+	ConfigEnv = "GDBASE_EXTERNAL_CONFIG"
+	// RequireEnv lists (comma separated) the features Start requires, e.g.
+	// "extensions.pgcrypto,permissions.create_table"; a feature prefixed by a
+	// service name ("pg:extensions.pgvector") must be found on that service
+	RequireEnv = "GDBASE_EXTERNAL_REQUIRE"
+)
+
+var envNameRe = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// ExternalProvider attaches to existing databases: Start only validates the
+// connectivity and the capabilities of each service and Stop leaves them
+// running
+type ExternalProvider struct {
+	mu       sync.Mutex
+	dsns     map[string]string
+	require  []string
+	detected map[string]bool
+	notes    []string
+}
+
+// Provider is an alias to ExternalProvider, as in the other backends
+type Provider = ExternalProvider
+
+// New creates a new external provider instance, configured from the
+// environment
+func New() *Provider {
+	var require []string
+	for _, f := range strings.Split(os.Getenv(RequireEnv), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			require = append(require, f)
+		}
+	}
+	return NewExternalProvider(nil, require...)
+}
+
+// NewExternalProvider creates a provider attaching the services to dsns (by
+// service name; values may be secret references such as env://PG_DSN) and
+// requiring the given features
+func NewExternalProvider(dsns map[string]string, require ...string) *ExternalProvider {
+	if dsns == nil {
+		dsns = map[string]string{}
+	}
+	return &ExternalProvider{dsns: dsns, require: require}
+}
+
+// Name returns the provider name
+func (p *ExternalProvider) Name() string {
+	return "external"
+}
+
+// Capabilities returns what the provider detected on the attached services.
+// Before Start, the services of the configured DSNs are probed.
+func (p *ExternalProvider) Capabilities(ctx context.Context) (provider.Capabilities, error) {
+	p.mu.Lock()
+	detected := p.detected
+	p.mu.Unlock()
+	if detected == nil {
+		var refs []provider.ServiceRef
+		for _, name := range p.configured() {
+			refs = append(refs, provider.ServiceRef{Name: name})
+		}
+		if _, err := p.attach(ctx, provider.StartSpec{Services: refs}); err != nil {
+			return provider.Capabilities{}, err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	caps := provider.Capabilities{
+		Managed: false,
+		Notes: append([]string{
+			"Attach-only: validates existing databases, never starts or stops them",
+		}, p.notes...),
+		Features: map[string]bool{"attach.only": true},
+	}
+	for f, ok := range p.detected {
+		caps.Features[f] = ok
+	}
+	return caps, nil
+}
+
+// Start validates the connectivity and the capabilities of the services
+// (the features required by the provider and by spec) and returns their
+// endpoints
+func (p *ExternalProvider) Start(ctx context.Context, spec provider.StartSpec) (map[string]provider.Endpoint, error) {
+	eps, err := p.attach(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	caps := provider.Capabilities{Features: p.detected}
+	if missing := caps.Missing(append(slices.Clone(p.require), spec.Requires...)); len(missing) > 0 {
+		return nil, fmt.Errorf("external services lack the required features: %s", strings.Join(missing, ", "))
+	}
+	return eps, nil
+}
+
+// attach probes each service of spec and records what was detected
+func (p *ExternalProvider) attach(ctx context.Context, spec provider.StartSpec) (map[string]provider.Endpoint, error) {
+	endpoints := make(map[string]provider.Endpoint, len(spec.Services))
+	detected := map[string]bool{}
+	var notes []string
+	for _, ref := range spec.Services {
+		dsn, err := p.dsnFor(ctx, ref.Name, spec.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		kind := dsnKind(dsn)
+		if err := checkEngine(ref, kind); err != nil {
+			return nil, err
+		}
+		probe, err := probeService(ctx, kind, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach %s (%s): %w", ref.Name, svc.RedactDSN(dsn), err)
+		}
+		for f, ok := range probe.features {
+			// an unscoped feature is available when one of the services has it
+			detected[f] = detected[f] || ok
+			detected[ref.Name+":"+f] = ok
+		}
+		ep := endpointOf(dsn)
+		notes = append(notes, fmt.Sprintf("%s: %s %s (%s)", ref.Name, kind, probe.version, ep.Redacted))
+		endpoints[ref.Name] = ep
+		gl.Log("info", fmt.Sprintf("External %s attached: %s %s", ref.Name, kind, probe.version))
+	}
+
+	p.mu.Lock()
+	p.detected = detected
+	p.notes = notes
+	p.mu.Unlock()
+	return endpoints, nil
+}
+
+// dsnFor finds the DSN of a service: the secret "<name>_dsn" of the spec,
+// the DSNs given to the provider, $GDBASE_EXTERNAL_<NAME>_DSN, then the
+// database named after the service in $GDBASE_EXTERNAL_CONFIG
+func (p *ExternalProvider) dsnFor(ctx context.Context, name string, secrets map[string]string) (string, error) {
+	env := "GDBASE_EXTERNAL_" + strings.Trim(envNameRe.ReplaceAllString(strings.ToUpper(name), "_"), "_") + "_DSN"
+	raw := secrets[name+"_dsn"]
+	if raw == "" {
+		raw = p.dsns[name]
+	}
+	if raw == "" {
+		raw = os.Getenv(env)
+	}
+	if raw == "" {
+		dsn, err := configDSN(name)
+		if err != nil || dsn != "" {
+			return dsn, err
+		}
+		return "", fmt.Errorf("no DSN for external service %s: set the secret %s_dsn, %s or a database %s in %s", name, name, env, name, ConfigEnv)
+	}
+	return ti.ResolveSecret(ctx, raw)
+}
+
+// configured returns the names of the services with a DSN known without a
+// spec
+func (p *ExternalProvider) configured() []string {
+	names := map[string]bool{}
+	for name := range p.dsns {
+		names[name] = true
+	}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(key, "GDBASE_EXTERNAL_"); ok && strings.HasSuffix(name, "_DSN") {
+			names[strings.ToLower(strings.TrimSuffix(name, "_DSN"))] = true
+		}
+	}
+	if cfg, err := loadConfig(); err == nil && cfg != nil {
+		for key, db := range cfg.Databases {
+			if db != nil && db.Enabled {
+				names[key] = true
+			}
+		}
+	}
+	out := make([]string, 0, len(names))
+	for name := range names {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// configDSN builds the DSN of the database name (its key or Name) of the
+// config file of $GDBASE_EXTERNAL_CONFIG
+func configDSN(name string) (string, error) {
+	cfg, err := loadConfig()
+	if err != nil || cfg == nil {
+		return "", err
+	}
+	db, ok := cfg.Databases[name]
+	if !ok {
+		for _, candidate := range cfg.Databases {
+			if candidate != nil && candidate.Name == name {
+				db = candidate
+				break
+			}
+		}
+	}
+	if db == nil {
+		return "", nil
+	}
+	dsn, err := svc.BuildDSN(db)
+	if err != nil {
+		return "", fmt.Errorf("failed to build the DSN of %s: %w", name, err)
+	}
+	return dsn.Value, nil
+}
+
+func loadConfig() (*svc.DBConfig, error) {
+	path := os.Getenv(ConfigEnv)
+	if path == "" {
+		return nil, nil
+	}
+	return svc.NewDBConfigFromFile(context.Background(), path, false, nil, false)
+}
+
+// Health pings each endpoint
+func (p *ExternalProvider) Health(ctx context.Context, eps map[string]provider.Endpoint) error {
+	for name, ep := range eps {
+		if err := ping(ctx, dsnKind(ep.DSN), ep.DSN); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Stop does nothing: the external services are not owned by gdbase
+func (p *ExternalProvider) Stop(ctx context.Context, refs []provider.ServiceRef) error {
+	return nil
+}
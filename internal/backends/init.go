@@ -3,6 +3,8 @@ package backends
 
 import (
 	"github.com/kubex-ecosystem/gdbase/internal/backends/dockerstack"
+	"github.com/kubex-ecosystem/gdbase/internal/backends/external"
+	"github.com/kubex-ecosystem/gdbase/internal/backends/localhost"
 	"github.com/kubex-ecosystem/gdbase/internal/provider"
 )
 
@@ -10,8 +12,13 @@ func init() {
 	// Register dockerstack provider (default)
 	provider.Register(dockerstack.New())
 
+	// Register localhost provider (SQLite files, fallback without Docker)
+	provider.Register(localhost.New())
+
+	// Register external provider (attach-only, pre-existing databases)
+	provider.Register(external.New())
+
 	// TODO: Register other providers as they're implemented
-	// provider.Register(localhost.New())
 	// provider.Register(supabase.New())
 	// provider.Register(aws.New())
 }
@@ -0,0 +1,247 @@
+// Package localhost provides a backend without Docker: each Postgres service
+// is served by a SQLite file on the local disk
+package localhost
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DirEnv overrides the directory of the SQLite files
+const DirEnv = "GDBASE_LOCALHOST_DIR"
+
+// LocalhostProvider serves the Postgres services with embedded SQLite files,
+// migrated with the embedded migrations translated to SQLite
+type LocalhostProvider struct {
+	mu  sync.Mutex
+	dir string
+	dbs map[string]*sql.DB
+}
+
+// Provider is an alias to LocalhostProvider, as in the other backends
+type Provider = LocalhostProvider
+
+// New creates a new localhost provider instance
+func New() *Provider {
+	return NewLocalhostProvider("")
+}
+
+// NewLocalhostProvider creates a provider storing its files in dir (default:
+// $GDBASE_LOCALHOST_DIR or ~/.kubex/volumes/sqlite)
+func NewLocalhostProvider(dir string) *LocalhostProvider {
+	return &LocalhostProvider{dir: dir, dbs: map[string]*sql.DB{}}
+}
+
+// Name returns the provider name
+func (p *LocalhostProvider) Name() string {
+	return "localhost"
+}
+
+// Capabilities returns what this provider can do. The features the SQLite
+// translation of the migrations loses are reported as missing
+func (p *LocalhostProvider) Capabilities(ctx context.Context) (provider.Capabilities, error) {
+	caps := provider.Capabilities{
+		Managed: false,
+		Notes: []string{
+			"Zero-dependency local stack without Docker",
+			"Serves PostgreSQL services with a SQLite file per service",
+		},
+		Features: map[string]bool{
+			"dialect.sqlite":  true,
+			"sqlite.file":     true,
+			"volumes.persist": true,
+			"tenancy.row":     true,
+			"tenancy.schema":  false,
+			// 005_search creates its indexes in a DO block; EnsureSearchIndex
+			// builds FTS5 tables instead
+			"search.fulltext": false,
+		},
+	}
+	_, skipped, err := p.translate()
+	if err != nil {
+		return caps, err
+	}
+	for _, name := range sortedKeys(skipped) {
+		caps.Notes = append(caps.Notes, fmt.Sprintf("Migration %s: %d Postgres-only statements skipped", name, skipped[name]))
+	}
+	return caps, nil
+}
+
+// Dir returns the directory of the SQLite files
+func (p *LocalhostProvider) Dir() string {
+	if p.dir != "" {
+		return p.dir
+	}
+	return os.ExpandEnv(gl.GetEnvOrDefault(DirEnv, filepath.Join(gl.DefaultVolumesDir, "sqlite")))
+}
+
+// Start opens (or creates) the SQLite file of each service and applies the
+// pending migrations
+func (p *LocalhostProvider) Start(ctx context.Context, spec provider.StartSpec) (map[string]provider.Endpoint, error) {
+	for _, ref := range spec.Services {
+		if ref.Engine != provider.EnginePostgres {
+			return nil, fmt.Errorf("localhost provider does not support %s (service %s)", ref.Engine, ref.Name)
+		}
+		if !validServiceName.MatchString(serviceName(ref)) {
+			return nil, fmt.Errorf("invalid service name %q: use only letters, digits, _ and -", ref.Name)
+		}
+	}
+	dir := p.Dir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	migrations, err := p.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	endpoints := make(map[string]provider.Endpoint, len(spec.Services))
+	for _, ref := range spec.Services {
+		name := serviceName(ref)
+		dsn := "file:" + filepath.Join(dir, name+".db") + "?_busy_timeout=5000&_foreign_keys=on"
+		db, ok := p.dbs[name]
+		if !ok {
+			if db, err = sql.Open("sqlite3", dsn); err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", name, err)
+			}
+			p.dbs[name] = db
+		}
+		migrator, err := svc.NewMigrator(db, svc.DialectSQLite)
+		if err != nil {
+			return nil, err
+		}
+		applied, skipped, err := migrator.Up(ctx, migrations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run migrations on %s: %w", name, err)
+		}
+		gl.Log("info", fmt.Sprintf("Localhost %s: %d migrations applied, %d already applied", name, applied, skipped))
+		endpoints[name] = provider.Endpoint{DSN: dsn, Redacted: dsn, Host: "localhost"}
+	}
+	return endpoints, nil
+}
+
+// migrations loads the embedded migrations translated to SQLite
+func (p *LocalhostProvider) migrations() ([]svc.Migration, error) {
+	migrations, skipped, err := p.translate()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(skipped) {
+		gl.Log("warn", fmt.Sprintf("Localhost migration %s: %d statements skipped", name, skipped[name]))
+	}
+	return migrations, nil
+}
+
+// translate translates the embedded migrations to SQLite and counts the
+// statements skipped in each one
+func (p *LocalhostProvider) translate() ([]svc.Migration, map[string]int, error) {
+	migrations, err := svc.EmbeddedMigrationSource().Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	migrations, notes, err := svc.TranslateMigrations(migrations, svc.DialectSQLite)
+	if err != nil {
+		return nil, nil, err
+	}
+	skipped := map[string]int{}
+	for _, note := range notes {
+		name, _, _ := strings.Cut(note, ":")
+		skipped[name]++
+	}
+	return migrations, skipped, nil
+}
+
+// Health runs SELECT 1 on the file of each endpoint
+func (p *LocalhostProvider) Health(ctx context.Context, eps map[string]provider.Endpoint) error {
+	for name, ep := range eps {
+		p.mu.Lock()
+		db, ok := p.dbs[name]
+		p.mu.Unlock()
+		if !ok {
+			// an endpoint started by another process: the file must exist
+			path := strings.TrimPrefix(strings.SplitN(ep.DSN, "?", 2)[0], "file:")
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			var err error
+			if db, err = sql.Open("sqlite3", ep.DSN); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		err := db.QueryRowContext(ctx, "SELECT 1").Scan(new(int))
+		if !ok {
+			db.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Stop closes the files of the services (all of them when refs is empty);
+// the files are kept
+func (p *LocalhostProvider) Stop(ctx context.Context, refs []provider.ServiceRef) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.dbs))
+	if len(refs) == 0 {
+		for name := range p.dbs {
+			names = append(names, name)
+		}
+	}
+	for _, ref := range refs {
+		names = append(names, serviceName(ref))
+	}
+	var errs []string
+	for _, name := range names {
+		db, ok := p.dbs[name]
+		if !ok {
+			continue
+		}
+		delete(p.dbs, name)
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validServiceName keeps the service names, joined into the file paths, inside Dir
+var validServiceName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// serviceName names the file of a service
+func serviceName(ref provider.ServiceRef) string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	return "pg"
+}
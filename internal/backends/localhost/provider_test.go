@@ -0,0 +1,87 @@
+package localhost
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+)
+
+func TestLocalhostProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	p := NewLocalhostProvider(dir)
+	spec := provider.StartSpec{Services: []provider.ServiceRef{{Name: "pg", Engine: provider.EnginePostgres}}}
+
+	eps, err := p.Start(ctx, spec)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	ep, ok := eps["pg"]
+	if !ok || ep.Host != "localhost" {
+		t.Fatalf("unexpected endpoints %+v", eps)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pg.db")); err != nil {
+		t.Fatalf("database file: %v", err)
+	}
+	if err := p.Health(ctx, eps); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+
+	// the endpoint DSN opens the migrated database
+	db, err := sql.Open("sqlite3", ep.DSN)
+	if err != nil {
+		t.Fatalf("open %s: %v", ep.DSN, err)
+	}
+	var users int
+	if err := db.QueryRow("SELECT count(*) FROM users").Scan(&users); err != nil || users == 0 {
+		t.Fatalf("users: %d, %v", users, err)
+	}
+	if _, err := db.Exec("SELECT tenant_id FROM products LIMIT 1"); err != nil {
+		t.Fatalf("products.tenant_id: %v", err)
+	}
+	db.Close()
+
+	if err := p.Stop(ctx, nil); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	// a restart finds the migrations applied
+	if _, err := NewLocalhostProvider(dir).Start(ctx, spec); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if err := p.Health(ctx, eps); err != nil {
+		t.Fatalf("Health after Stop: %v", err)
+	}
+	missing := map[string]provider.Endpoint{"pg": {DSN: "file:" + filepath.Join(dir, "nope.db")}}
+	if err := p.Health(ctx, missing); err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+
+	caps, err := p.Capabilities(ctx)
+	if err != nil {
+		t.Fatalf("Capabilities: %v", err)
+	}
+	if missing := caps.Missing([]string{"dialect.sqlite", "tenancy.row", "pg:dialect.sqlite"}); len(missing) > 0 {
+		t.Fatalf("missing features %v", missing)
+	}
+	if missing := caps.Missing([]string{"engine.postgres", "search.fulltext"}); len(missing) != 2 {
+		t.Fatalf("SQLite must not satisfy %v", missing)
+	}
+
+	redis := provider.StartSpec{Services: []provider.ServiceRef{{Name: "redis", Engine: provider.EngineRedis}}}
+	if _, err := p.Start(ctx, redis); err == nil {
+		t.Fatalf("expected an error for redis")
+	}
+	for _, name := range []string{"../escape", "a/b", "pg.db", " pg"} {
+		bad := provider.StartSpec{Services: []provider.ServiceRef{{Name: name, Engine: provider.EnginePostgres}}}
+		if _, err := p.Start(ctx, bad); err == nil {
+			t.Fatalf("expected an error for the service name %q", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.db")); err == nil {
+		t.Fatalf("a service name escaped the directory")
+	}
+}
@@ -0,0 +1,141 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+)
+
+// opMsg is the opcode of the MongoDB OP_MSG messages
+const opMsg = 2013
+
+// mongoPing sends {ping: 1, $db: "admin"} in an OP_MSG and checks the ok
+// field of the reply
+func mongoPing(conn net.Conn) error {
+	var doc bytes.Buffer
+	doc.WriteByte(0x10) // int32
+	doc.WriteString("ping\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(1))
+	doc.WriteByte(0x02) // string
+	doc.WriteString("$db\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(len("admin")+1))
+	doc.WriteString("admin\x00")
+	doc.WriteByte(0)
+
+	body := make([]byte, 0, 5+4+doc.Len())
+	body = binary.LittleEndian.AppendUint32(body, 0) // flagBits
+	body = append(body, 0)                           // section kind 0: body
+	body = binary.LittleEndian.AppendUint32(body, uint32(4+doc.Len()))
+	body = append(body, doc.Bytes()...)
+
+	msg := make([]byte, 0, 16+len(body))
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(16+len(body)))
+	msg = binary.LittleEndian.AppendUint32(msg, 1) // requestID
+	msg = binary.LittleEndian.AppendUint32(msg, 0) // responseTo
+	msg = binary.LittleEndian.AppendUint32(msg, opMsg)
+	msg = append(msg, body...)
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	size := binary.LittleEndian.Uint32(header)
+	if size < 16+5+5 || size > 16<<20 {
+		return fmt.Errorf("mongodb: invalid reply of %d bytes", size)
+	}
+	if op := binary.LittleEndian.Uint32(header[12:]); op != opMsg {
+		return fmt.Errorf("mongodb: unexpected reply opcode %d", op)
+	}
+	reply := make([]byte, size-16)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[4] != 0 {
+		return fmt.Errorf("mongodb: unexpected reply section %d", reply[4])
+	}
+	ok, errmsg, err := mongoOK(reply[5:])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("mongodb: ping failed: %s", errmsg)
+	}
+	return nil
+}
+
+// mongoOK reads the ok and errmsg fields of a BSON document
+func mongoOK(doc []byte) (ok bool, errmsg string, err error) {
+	if len(doc) < 5 || int(binary.LittleEndian.Uint32(doc)) > len(doc) {
+		return false, "", fmt.Errorf("mongodb: truncated reply")
+	}
+	doc = doc[4:binary.LittleEndian.Uint32(doc)]
+	for len(doc) > 1 {
+		kind := doc[0]
+		end := bytes.IndexByte(doc[1:], 0)
+		if end < 0 {
+			break
+		}
+		name := string(doc[1 : 1+end])
+		value := doc[2+end:]
+		var n int
+		switch kind {
+		case 0x01: // double
+			n = 8
+			if name == "ok" && len(value) >= 8 {
+				ok = math.Float64frombits(binary.LittleEndian.Uint64(value)) == 1
+			}
+		case 0x02: // string
+			if len(value) < 4 {
+				return ok, errmsg, nil
+			}
+			n = 4 + int(binary.LittleEndian.Uint32(value))
+			if name == "errmsg" && n <= len(value) {
+				errmsg = string(value[4 : n-1])
+			}
+		case 0x03, 0x04: // document, array
+			if len(value) < 4 {
+				return ok, errmsg, nil
+			}
+			n = int(binary.LittleEndian.Uint32(value))
+		case 0x05: // binary
+			if len(value) < 4 {
+				return ok, errmsg, nil
+			}
+			n = 5 + int(binary.LittleEndian.Uint32(value))
+		case 0x07: // ObjectId
+			n = 12
+		case 0x08: // bool
+			n = 1
+			if name == "ok" && len(value) >= 1 {
+				ok = value[0] == 1
+			}
+		case 0x0A: // null
+		case 0x10: // int32
+			n = 4
+			if name == "ok" && len(value) >= 4 {
+				ok = binary.LittleEndian.Uint32(value) == 1
+			}
+		case 0x09, 0x11, 0x12: // datetime, timestamp, int64
+			n = 8
+			if name == "ok" && kind == 0x12 && len(value) >= 8 {
+				ok = binary.LittleEndian.Uint64(value) == 1
+			}
+		case 0x13: // decimal128
+			n = 16
+		default:
+			// the fields of a ping reply are known; stop on anything else
+			return ok, errmsg, nil
+		}
+		if n > len(value) {
+			break
+		}
+		doc = value[n:]
+	}
+	return ok, errmsg, nil
+}
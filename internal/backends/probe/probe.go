@@ -0,0 +1,204 @@
+// Package probe implements the connectivity checks shared by the backends:
+// SELECT 1 on Postgres, PING on Redis, an AMQP handshake on RabbitMQ and the
+// ping command on MongoDB
+package probe
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	_ "github.com/lib/pq"
+)
+
+// Timeout bounds a probe when the context has no deadline
+const Timeout = 10 * time.Second
+
+// Engine returns the engine of a DSN from its URL scheme
+func Engine(dsn string) (provider.Engine, error) {
+	scheme, _, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return "", fmt.Errorf("unknown engine for DSN without scheme")
+	}
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return provider.EnginePostgres, nil
+	case "mongodb", "mongodb+srv":
+		return provider.EngineMongo, nil
+	case "redis", "rediss":
+		return provider.EngineRedis, nil
+	case "amqp", "amqps":
+		return provider.EngineRabbit, nil
+	}
+	return "", fmt.Errorf("unknown engine for scheme %s", scheme)
+}
+
+// Ping runs the probe of the engine of dsn
+func Ping(ctx context.Context, dsn string) error {
+	engine, err := Engine(dsn)
+	if err != nil {
+		return err
+	}
+	switch engine {
+	case provider.EngineRedis:
+		return Redis(ctx, dsn)
+	case provider.EngineRabbit:
+		return AMQP(ctx, dsn)
+	case provider.EngineMongo:
+		return Mongo(ctx, dsn)
+	}
+	return SQL(ctx, "postgres", dsn)
+}
+
+// SQL runs SELECT 1 on a database/sql DSN
+func SQL(ctx context.Context, driver, dsn string) error {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	var one int
+	return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// AMQP opens and closes an AMQP connection
+func AMQP(ctx context.Context, dsn string) error {
+	timeout := Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	conn, err := amqp.DialConfig(dsn, amqp.Config{Dial: amqp.DefaultDial(timeout)})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Redis sends AUTH (when the URL has a password) and PING
+func Redis(ctx context.Context, dsn string) error {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(ctx, hostPort(u.Host, "6379"), u.Scheme == "rediss")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	command := func(args ...string) (string, error) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(args))
+		for _, a := range args {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+		}
+		if _, err := conn.Write([]byte(b.String())); err != nil {
+			return "", err
+		}
+		reply, err := r.ReadString('\n')
+		reply = strings.TrimSpace(reply)
+		if err == nil && strings.HasPrefix(reply, "-") {
+			err = fmt.Errorf("redis: %s", strings.TrimPrefix(reply, "-"))
+		}
+		return reply, err
+	}
+	if pass, ok := u.User.Password(); ok {
+		args := []string{"AUTH", pass}
+		if user := u.User.Username(); user != "" {
+			args = []string{"AUTH", user, pass}
+		}
+		if _, err := command(args...); err != nil {
+			return err
+		}
+	}
+	reply, err := command("PING")
+	if err != nil {
+		return err
+	}
+	if reply != "+PONG" {
+		return fmt.Errorf("redis: unexpected reply %q to PING", reply)
+	}
+	return nil
+}
+
+// Mongo sends the ping command (allowed without authentication) to the
+// first host of the DSN
+func Mongo(ctx context.Context, dsn string) error {
+	scheme, rest, _ := strings.Cut(dsn, "://")
+	hosts := rest
+	if i := strings.IndexAny(hosts, "/?"); i >= 0 {
+		hosts = hosts[:i]
+	}
+	if i := strings.LastIndex(hosts, "@"); i >= 0 {
+		hosts = hosts[i+1:]
+	}
+	host, _, _ := strings.Cut(hosts, ",")
+	addr := hostPort(host, "27017")
+	if strings.EqualFold(scheme, "mongodb+srv") {
+		_, srvs, err := net.DefaultResolver.LookupSRV(ctx, "mongodb", "tcp", host)
+		if err != nil {
+			return err
+		}
+		if len(srvs) == 0 {
+			return fmt.Errorf("mongodb: no SRV record for %s", host)
+		}
+		addr = net.JoinHostPort(strings.TrimSuffix(srvs[0].Target, "."), fmt.Sprint(srvs[0].Port))
+	}
+	conn, err := dial(ctx, addr, strings.EqualFold(scheme, "mongodb+srv") || strings.Contains(rest, "tls=true") || strings.Contains(rest, "ssl=true"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return mongoPing(conn)
+}
+
+// dial opens a TCP (or TLS) connection whose deadline is the one of ctx, or
+// Timeout
+func dial(ctx context.Context, addr string, useTLS bool) (net.Conn, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, Timeout)
+}
+
+// hostPort adds the default port to a host without one
+func hostPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
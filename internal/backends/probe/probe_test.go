@@ -0,0 +1,107 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+)
+
+// serve accepts connections on a local port and hands them to handle
+func serve(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// mongoReply answers one OP_MSG with {$clusterTime: {}, ok: <ok>, errmsg}
+func mongoReply(ok float64) func(net.Conn) {
+	return func(conn net.Conn) {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		io.ReadFull(conn, make([]byte, binary.LittleEndian.Uint32(header)-16))
+
+		var doc bytes.Buffer
+		doc.Write([]byte{0x03})
+		doc.WriteString("$clusterTime\x00")
+		doc.Write([]byte{5, 0, 0, 0, 0})
+		doc.Write([]byte{0x01})
+		doc.WriteString("ok\x00")
+		binary.Write(&doc, binary.LittleEndian, math.Float64bits(ok))
+		doc.Write([]byte{0x02})
+		doc.WriteString("errmsg\x00")
+		binary.Write(&doc, binary.LittleEndian, int32(len("denied")+1))
+		doc.WriteString("denied\x00")
+		doc.WriteByte(0)
+
+		msg := binary.LittleEndian.AppendUint32(nil, uint32(16+5+4+doc.Len()))
+		msg = binary.LittleEndian.AppendUint32(msg, 2)
+		msg = binary.LittleEndian.AppendUint32(msg, 1)
+		msg = binary.LittleEndian.AppendUint32(msg, opMsg)
+		msg = binary.LittleEndian.AppendUint32(msg, 0)
+		msg = append(msg, 0)
+		msg = binary.LittleEndian.AppendUint32(msg, uint32(4+doc.Len()))
+		conn.Write(append(msg, doc.Bytes()...))
+	}
+}
+
+func TestPing(t *testing.T) {
+	ctx := context.Background()
+	redis := serve(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "PING") {
+				conn.Write([]byte("+PONG\r\n"))
+			} else if strings.HasPrefix(line, "AUTH") {
+				conn.Write([]byte("+OK\r\n"))
+			}
+		}
+	})
+	for _, c := range []struct {
+		dsn string
+		err string
+	}{
+		{"redis://:pass@" + redis + "/0", ""},
+		{"mongodb://root:pass@" + serve(t, mongoReply(1)) + ",other:27017/gdbase?authSource=admin", ""},
+		{"mongodb://" + serve(t, mongoReply(0)), "ping failed: denied"},
+		{"mongodb://" + serve(t, func(conn net.Conn) { conn.Read(make([]byte, 1024)) }), "EOF"},
+		{"mysql://localhost", "unknown engine"},
+	} {
+		err := Ping(ctx, c.dsn)
+		if (c.err == "") != (err == nil) || err != nil && !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("%s: expected %q, got %v", c.dsn, c.err, err)
+		}
+	}
+	if e, _ := Engine("postgresql://u@h/db"); e != provider.EnginePostgres {
+		t.Fatalf("unexpected engine %s", e)
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"context"
 	"embed"
 	"errors"
+	"fmt"
 	"os"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,12 +17,23 @@ import (
 //go:embed all:embedded/*.sql
 var MigrationFiles embed.FS
 
+// FallbackBackend is tried after the configured backends when Strict is off
+// (e.g. dockerstack on a machine without Docker).
+const FallbackBackend = "localhost"
+
+// Health backoff of a started backend (vars so the tests can shorten them).
+var (
+	healthTimeout  = 15 * time.Second
+	healthInterval = 500 * time.Millisecond
+)
+
 type Config struct {
 	Backends      []string // ordem de preferência; e.g. ["dockerstack"] por enquanto
 	Strict        bool     // se true, não faz fallback silencioso
 	Services      []provider.ServiceRef
 	PreferredPort map[string]int
 	Secrets       map[string]string
+	Requires      []string // features exigidas de Capabilities; e.g. ["volumes.persist"]
 }
 
 func FromEnv() Config {
@@ -34,63 +46,168 @@ func FromEnv() Config {
 		backends[i] = strings.TrimSpace(backends[i])
 	}
 	strict := strings.EqualFold(os.Getenv("GDBASE_STRICT"), "true")
+	var requires []string
+	for _, f := range strings.Split(os.Getenv("GDBASE_REQUIRE"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			requires = append(requires, f)
+		}
+	}
 	return Config{
-		Backends: backends, Strict: strict,
+		Backends: backends, Strict: strict, Requires: requires,
 	}
 }
 
 type Result struct {
 	Backend   string
 	Endpoints map[string]provider.Endpoint
+	Rejected  []Rejection // backends preferidos descartados antes do escolhido
+}
+
+// Rejection explains why a backend was not selected
+type Rejection struct {
+	Backend string
+	Reason  string
+	Err     error
+}
+
+func (r Rejection) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", r.Backend, r.Reason, r.Err)
+	}
+	return fmt.Sprintf("%s: %s", r.Backend, r.Reason)
+}
+
+// SelectionError is returned when no backend was selected; it lists why
+// each candidate was rejected
+type SelectionError struct {
+	Strict     bool
+	Rejections []Rejection
 }
 
+func (e *SelectionError) Error() string {
+	msg := "no backend satisfies the requirements"
+	if e.Strict {
+		msg = "strict mode: backend rejected"
+	}
+	reasons := make([]string, len(e.Rejections))
+	for i, r := range e.Rejections {
+		reasons[i] = r.String()
+	}
+	return msg + ": " + strings.Join(reasons, "; ")
+}
+
+// Unwrap returns the errors of the rejections
+func (e *SelectionError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Rejections {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// Start picks, in the preference order of cfg.Backends, the first backend
+// whose Capabilities have every feature of cfg.Requires and that starts and
+// becomes healthy. A backend that starts but never becomes healthy is stopped
+// before the next one is tried. With Strict, the first rejection ends the
+// selection; else the local fallback is tried last.
 func Start(ctx context.Context, cfg Config) (Result, error) {
-	// ordenar por prioridade “fixa” caso queira; hoje respeita ordem vinda
-	cands := make([]string, 0, len(cfg.Backends))
+	cands := make([]string, 0, len(cfg.Backends)+1)
 	for _, b := range cfg.Backends {
-		if _, ok := provider.Get(b); ok {
+		if b != "" && !slices.Contains(cands, b) {
 			cands = append(cands, b)
 		}
 	}
+	// sem Strict, o fallback local entra por último
+	if _, ok := provider.Get(FallbackBackend); ok && !cfg.Strict && !slices.Contains(cands, FallbackBackend) {
+		cands = append(cands, FallbackBackend)
+	}
 	if len(cands) == 0 {
-		return Result{}, errors.New("no providers registered")
+		return Result{}, errors.New("no backends configured")
 	}
-	// opcional: ordenar alfabeticamente para determinismo quando empatar
-	sort.Strings(cands)
 
-	var lastErr error
+	var rejected []Rejection
+	reject := func(r Rejection) error {
+		rejected = append(rejected, r)
+		if cfg.Strict {
+			return &SelectionError{Strict: true, Rejections: rejected}
+		}
+		return nil
+	}
 	for _, name := range cands {
-		p, _ := provider.Get(name)
+		p, ok := provider.Get(name)
+		if !ok {
+			if err := reject(Rejection{Backend: name, Reason: "not registered"}); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
+		caps, err := p.Capabilities(ctx)
+		if err != nil {
+			if err := reject(Rejection{Backend: name, Reason: "capabilities unavailable", Err: err}); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
+		if missing := caps.Missing(cfg.Requires); len(missing) > 0 {
+			if err := reject(Rejection{Backend: name, Reason: "missing features " + strings.Join(missing, ", ")}); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
 		spec := provider.StartSpec{
 			Services:      cfg.Services,
 			PreferredPort: cfg.PreferredPort,
 			Secrets:       cfg.Secrets,
 			Labels:        map[string]string{"owner": "gdbase"},
+			Requires:      cfg.Requires,
 		}
 		eps, err := p.Start(ctx, spec)
 		if err != nil {
-			lastErr = err
-			if cfg.Strict {
+			if err := reject(Rejection{Backend: name, Reason: "start failed", Err: err}); err != nil {
 				return Result{}, err
 			}
 			continue
 		}
-		// health com backoff curto
-		deadline := time.Now().Add(15 * time.Second)
-		for {
-			if err = p.Health(ctx, eps); err == nil || time.Now().After(deadline) {
-				break
+		if err := waitHealthy(ctx, p, eps); err != nil {
+			// o backend subiu: derruba antes de tentar o próximo
+			stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), healthTimeout)
+			if stopErr := p.Stop(stopCtx, cfg.Services); stopErr != nil {
+				err = errors.Join(err, fmt.Errorf("stop: %w", stopErr))
 			}
-			time.Sleep(500 * time.Millisecond)
-		}
-		if err != nil {
-			lastErr = err
-			if cfg.Strict {
+			cancel()
+			if ctx.Err() != nil {
+				return Result{}, err
+			}
+			if err := reject(Rejection{Backend: name, Reason: "unhealthy", Err: err}); err != nil {
 				return Result{}, err
 			}
 			continue
 		}
-		return Result{Backend: name, Endpoints: eps}, nil
+		return Result{Backend: name, Endpoints: eps, Rejected: rejected}, nil
+	}
+	return Result{}, &SelectionError{Rejections: rejected}
+}
+
+// waitHealthy polls the health of eps every healthInterval until it passes,
+// healthTimeout runs out or ctx is done.
+func waitHealthy(ctx context.Context, p provider.Provider, eps map[string]provider.Endpoint) error {
+	deadline := time.NewTimer(healthTimeout)
+	defer deadline.Stop()
+	tick := time.NewTicker(healthInterval)
+	defer tick.Stop()
+	for {
+		err := p.Health(ctx, eps)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-deadline.C:
+			return err
+		case <-tick.C:
+		}
 	}
-	return Result{}, lastErr
 }
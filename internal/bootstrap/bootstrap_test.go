@@ -0,0 +1,120 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubex-ecosystem/gdbase/internal/provider"
+)
+
+type fakeProvider struct {
+	name     string
+	err      error
+	features []string
+	started  int
+	health   error
+	stopped  int
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+func (f *fakeProvider) Capabilities(context.Context) (provider.Capabilities, error) {
+	caps := provider.Capabilities{Features: map[string]bool{}}
+	for _, feature := range f.features {
+		caps.Features[feature] = true
+	}
+	return caps, nil
+}
+func (f *fakeProvider) Start(context.Context, provider.StartSpec) (map[string]provider.Endpoint, error) {
+	f.started++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return map[string]provider.Endpoint{"pg": {DSN: f.name}}, nil
+}
+func (f *fakeProvider) Health(context.Context, map[string]provider.Endpoint) error { return f.health }
+func (f *fakeProvider) Stop(context.Context, []provider.ServiceRef) error {
+	f.stopped++
+	return nil
+}
+
+func TestStartFallsBackToLocalhost(t *testing.T) {
+	noDocker := errors.New("docker not available")
+	provider.Register(&fakeProvider{name: "dockerstack", err: noDocker})
+	provider.Register(&fakeProvider{name: FallbackBackend})
+
+	res, err := Start(context.Background(), Config{Backends: []string{"dockerstack"}})
+	if err != nil || res.Backend != FallbackBackend {
+		t.Fatalf("expected the fallback, got %+v, %v", res, err)
+	}
+	if _, err := Start(context.Background(), Config{Backends: []string{"dockerstack"}, Strict: true}); !errors.Is(err, noDocker) {
+		t.Fatalf("strict: expected %v, got %v", noDocker, err)
+	}
+}
+
+func TestStartSelectsByCapabilities(t *testing.T) {
+	zeta := &fakeProvider{name: "zeta", features: []string{"volumes.persist", "extensions.pgvector"}}
+	alpha := &fakeProvider{name: "alpha", features: []string{"volumes.persist"}}
+	provider.Register(zeta)
+	provider.Register(alpha)
+	provider.Register(&fakeProvider{name: FallbackBackend})
+
+	// the preference order is kept (no alphabetical sort)
+	res, err := Start(context.Background(), Config{Backends: []string{"zeta", "alpha"}})
+	if err != nil || res.Backend != "zeta" || len(res.Rejected) != 0 {
+		t.Fatalf("expected zeta, got %+v, %v", res, err)
+	}
+
+	res, err = Start(context.Background(), Config{Backends: []string{"missing", "alpha", "zeta"}, Requires: []string{"extensions.pgvector"}})
+	if err != nil || res.Backend != "zeta" || len(res.Rejected) != 2 || alpha.started != 0 {
+		t.Fatalf("expected zeta after 2 rejections, got %+v, %v", res, err)
+	}
+	if got := res.Rejected[1].String(); got != "alpha: missing features extensions.pgvector" {
+		t.Fatalf("unexpected rejection %q", got)
+	}
+
+	_, err = Start(context.Background(), Config{Backends: []string{"alpha", "zeta"}, Requires: []string{"extensions.pgvector"}, Strict: true})
+	var sel *SelectionError
+	if !errors.As(err, &sel) || len(sel.Rejections) != 1 || !strings.Contains(err.Error(), "strict mode: backend rejected: alpha: missing features") {
+		t.Fatalf("strict: unexpected error %v", err)
+	}
+
+	// every candidate, the fallback included, is explained
+	_, err = Start(context.Background(), Config{Backends: []string{"alpha"}, Requires: []string{"extensions.postgis"}})
+	if !errors.As(err, &sel) || len(sel.Rejections) != 2 || sel.Rejections[1].Backend != FallbackBackend {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestStartStopsUnhealthyBackends(t *testing.T) {
+	timeout, interval := healthTimeout, healthInterval
+	t.Cleanup(func() { healthTimeout, healthInterval = timeout, interval })
+	healthTimeout, healthInterval = 50*time.Millisecond, 5*time.Millisecond
+
+	down := errors.New("connection refused")
+	sick := &fakeProvider{name: "sick", health: down}
+	provider.Register(sick)
+	provider.Register(&fakeProvider{name: FallbackBackend})
+
+	res, err := Start(context.Background(), Config{Backends: []string{"sick"}})
+	if err != nil || res.Backend != FallbackBackend || len(res.Rejected) != 1 || !errors.Is(res.Rejected[0].Err, down) {
+		t.Fatalf("expected the fallback after sick, got %+v, %v", res, err)
+	}
+	if sick.stopped != 1 {
+		t.Fatalf("sick stopped %d times", sick.stopped)
+	}
+
+	// a cancelled ctx ends the wait at once, the backend stopped
+	healthTimeout = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	began := time.Now()
+	if _, err := Start(ctx, Config{Backends: []string{"sick"}}); !errors.Is(err, context.Canceled) || time.Since(began) > time.Second {
+		t.Fatalf("expected a cancelled start, got %v after %v", err, time.Since(began))
+	}
+	if sick.stopped != 2 {
+		t.Fatalf("sick stopped %d times", sick.stopped)
+	}
+}
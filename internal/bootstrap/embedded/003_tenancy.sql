@@ -0,0 +1,23 @@
+-- Row-level tenancy (DBConfig.Tenancy mode "row"): tenant column on the tenant tables.
+DO $$
+DECLARE t text;
+BEGIN
+FOREACH t IN ARRAY ARRAY['clients', 'orders', 'products', 'mcp_conversations', 'mcp_messages'] LOOP
+    IF to_regclass(t) IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64)', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (tenant_id)', 'idx_' || t || '_tenant_id', t);
+    END IF;
+END LOOP;
+END$$;
+
+-- migrate:down
+DO $$
+DECLARE t text;
+BEGIN
+FOREACH t IN ARRAY ARRAY['clients', 'orders', 'products', 'mcp_conversations', 'mcp_messages'] LOOP
+    IF to_regclass(t) IS NOT NULL THEN
+        EXECUTE format('DROP INDEX IF EXISTS %I', 'idx_' || t || '_tenant_id');
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenant_id', t);
+    END IF;
+END LOOP;
+END$$;
@@ -0,0 +1,20 @@
+-- Row-level tenancy (DBConfig.Tenancy mode "row"): tenant column on the tenant tables.
+-- SQLite version of 003_tenancy.sql (the clients table is created by the models).
+ALTER TABLE orders ADD COLUMN tenant_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_id ON orders (tenant_id);
+ALTER TABLE products ADD COLUMN tenant_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_products_tenant_id ON products (tenant_id);
+ALTER TABLE mcp_conversations ADD COLUMN tenant_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_mcp_conversations_tenant_id ON mcp_conversations (tenant_id);
+ALTER TABLE mcp_messages ADD COLUMN tenant_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_mcp_messages_tenant_id ON mcp_messages (tenant_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_orders_tenant_id;
+ALTER TABLE orders DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_products_tenant_id;
+ALTER TABLE products DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_mcp_conversations_tenant_id;
+ALTER TABLE mcp_conversations DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_mcp_messages_tenant_id;
+ALTER TABLE mcp_messages DROP COLUMN tenant_id;
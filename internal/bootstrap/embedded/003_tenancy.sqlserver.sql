@@ -0,0 +1,36 @@
+-- Row-level tenancy (DBConfig.Tenancy mode "row"): tenant column on the tenant tables.
+-- SQL Server version of 003_tenancy.sql (the clients table is created by the models).
+ALTER TABLE orders ADD tenant_id NVARCHAR(64);
+GO
+CREATE INDEX idx_orders_tenant_id ON orders (tenant_id);
+GO
+ALTER TABLE products ADD tenant_id NVARCHAR(64);
+GO
+CREATE INDEX idx_products_tenant_id ON products (tenant_id);
+GO
+ALTER TABLE mcp_conversations ADD tenant_id NVARCHAR(64);
+GO
+CREATE INDEX idx_mcp_conversations_tenant_id ON mcp_conversations (tenant_id);
+GO
+ALTER TABLE mcp_messages ADD tenant_id NVARCHAR(64);
+GO
+CREATE INDEX idx_mcp_messages_tenant_id ON mcp_messages (tenant_id);
+GO
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_orders_tenant_id ON orders;
+GO
+ALTER TABLE orders DROP COLUMN tenant_id;
+GO
+DROP INDEX IF EXISTS idx_products_tenant_id ON products;
+GO
+ALTER TABLE products DROP COLUMN tenant_id;
+GO
+DROP INDEX IF EXISTS idx_mcp_conversations_tenant_id ON mcp_conversations;
+GO
+ALTER TABLE mcp_conversations DROP COLUMN tenant_id;
+GO
+DROP INDEX IF EXISTS idx_mcp_messages_tenant_id ON mcp_messages;
+GO
+ALTER TABLE mcp_messages DROP COLUMN tenant_id;
+GO
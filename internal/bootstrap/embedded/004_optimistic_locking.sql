@@ -0,0 +1,9 @@
+-- Optimistic locking (services.SaveOptimistic): version column of the concurrently updated tables.
+ALTER TABLE IF EXISTS cron_jobs ADD COLUMN IF NOT EXISTS lock_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE IF EXISTS job_queue ADD COLUMN IF NOT EXISTS lock_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE IF EXISTS orders ADD COLUMN IF NOT EXISTS lock_version INTEGER NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE IF EXISTS orders DROP COLUMN IF EXISTS lock_version;
+ALTER TABLE IF EXISTS job_queue DROP COLUMN IF EXISTS lock_version;
+ALTER TABLE IF EXISTS cron_jobs DROP COLUMN IF EXISTS lock_version;
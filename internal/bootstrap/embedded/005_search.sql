@@ -0,0 +1,51 @@
+-- Fuzzy search (services.Repository.Search): pg_trgm index on each search field and
+-- the 'simple' tsvector of the searchable tables, the same expressions as services.EnsureSearchIndex.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+DO $$
+DECLARE
+    rec record;
+    c text;
+    doc text;
+    complete boolean;
+BEGIN
+FOR rec IN SELECT * FROM (VALUES
+    ('products', ARRAY['name', 'sku', 'ean', 'description']),
+    ('clients', ARRAY['trading_name', 'code', 'contact_contact_name', 'contact_email']),
+    ('mcp_messages', ARRAY['content', 'sender_name', 'recipient_name'])
+) AS s(t, cols) LOOP
+    CONTINUE WHEN to_regclass(rec.t) IS NULL;
+    doc := '';
+    complete := true;
+    FOREACH c IN ARRAY rec.cols LOOP
+        IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = rec.t AND column_name = c) THEN
+            EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I USING GIN (%I gin_trgm_ops)', 'idx_' || rec.t || '_' || c || '_trgm', rec.t, c);
+            doc := doc || CASE WHEN doc = '' THEN '' ELSE ' || '' '' || ' END || format('COALESCE(%I, '''')', c);
+        ELSE
+            complete := false;
+        END IF;
+    END LOOP;
+    -- the tsvector only serves the searches on all the fields
+    IF complete THEN
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I USING GIN (to_tsvector(''simple'', %s))', 'idx_' || rec.t || '_search_tsv', rec.t, doc);
+    END IF;
+END LOOP;
+END$$;
+
+-- migrate:down
+DO $$
+DECLARE
+    rec record;
+    c text;
+BEGIN
+FOR rec IN SELECT * FROM (VALUES
+    ('products', ARRAY['name', 'sku', 'ean', 'description']),
+    ('clients', ARRAY['trading_name', 'code', 'contact_contact_name', 'contact_email']),
+    ('mcp_messages', ARRAY['content', 'sender_name', 'recipient_name'])
+) AS s(t, cols) LOOP
+    FOREACH c IN ARRAY rec.cols LOOP
+        EXECUTE format('DROP INDEX IF EXISTS %I', 'idx_' || rec.t || '_' || c || '_trgm');
+    END LOOP;
+    EXECUTE format('DROP INDEX IF EXISTS %I', 'idx_' || rec.t || '_search_tsv');
+END LOOP;
+END$$;
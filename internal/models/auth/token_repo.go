@@ -31,7 +31,7 @@ func NewTokenRepo(ctx context.Context, dbService svc.DBService) ITokenRepo {
 		gl.Log("error", "TokenRepo: DBService cannot be nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService.(*svc.DBServiceImpl))
+	db, err := svc.GetDBForRepo(ctx, dbService.(*svc.DBServiceImpl), "auth_tokens")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("TokenRepo: failed to get DB: %v", err))
 		return nil
@@ -66,7 +66,7 @@ func (r *TokenRepoImpl) SetRefreshToken(ctx context.Context, userID string, toke
 		return fmt.Errorf("token validation failed: %w", err)
 	}
 
-	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Create(token).Error; err != nil {
 		return fmt.Errorf("failed to insert refresh token: %w", err)
 	}
 
@@ -80,7 +80,7 @@ func (r *TokenRepoImpl) GetRefreshToken(ctx context.Context, tokenID string) (*R
 	}
 
 	var token RefreshTokenModel
-	if err := r.db.WithContext(ctx).Where("token_id = ?", tokenID).First(&token).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("token_id = ?", tokenID).First(&token).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -96,7 +96,7 @@ func (r *TokenRepoImpl) DeleteRefreshToken(ctx context.Context, userID string, t
 		return fmt.Errorf("database connection is nil")
 	}
 
-	result := r.db.WithContext(ctx).
+	result := svc.DBFromContext(ctx, r.db).
 		Where("user_id = ? AND token_id = ?", userID, tokenID).
 		Delete(&RefreshTokenModel{})
 
@@ -113,7 +113,7 @@ func (r *TokenRepoImpl) DeleteUserRefreshTokens(ctx context.Context, userID stri
 		return fmt.Errorf("database connection is nil")
 	}
 
-	result := r.db.WithContext(ctx).
+	result := svc.DBFromContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Delete(&RefreshTokenModel{})
 
@@ -130,7 +130,7 @@ func (r *TokenRepoImpl) CleanupExpiredTokens(ctx context.Context) error {
 		return fmt.Errorf("database connection is nil")
 	}
 
-	result := r.db.WithContext(ctx).
+	result := svc.DBFromContext(ctx, r.db).
 		Where("expires_at < ?", time.Now()).
 		Delete(&RefreshTokenModel{})
 
@@ -3,6 +3,7 @@ package clients
 import (
 	"time"
 
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	t "github.com/kubex-ecosystem/gdbase/internal/types"
 )
 
@@ -86,6 +87,8 @@ type IClientDetailed interface {
 	TableName() string
 	GetID() string
 	SetID(id string)
+	GetTenantID() *string
+	SetTenantID(tenantID *string)
 	GetCode() *string
 	SetCode(code *string)
 	GetTradingName() *string
@@ -119,6 +122,7 @@ type IClientDetailed interface {
 // ClientDetailed represents a detailed client structure
 type ClientDetailed struct {
 	ID            string        `json:"id" xml:"id" yaml:"id" gorm:"column:id;primaryKey"`
+	TenantID      *string       `json:"tenantId,omitempty" xml:"tenantId,omitempty" yaml:"tenantId,omitempty" gorm:"column:tenant_id;type:varchar(64);index"`
 	Code          *string       `json:"code,omitempty" xml:"code,omitempty" yaml:"code,omitempty" gorm:"column:code"`
 	TradingName   *string       `json:"tradingName,omitempty" xml:"tradingName,omitempty" yaml:"tradingName,omitempty" gorm:"column:trading_name"`
 	DocumentType  ClientType    `json:"documentType" xml:"documentType" yaml:"documentType" gorm:"column:document_type"`
@@ -138,9 +142,28 @@ type ClientDetailed struct {
 	LastSync      time.Time     `json:"lastSync" xml:"lastSync" yaml:"lastSync" gorm:"column:last_sync"`
 }
 
-func (c *ClientDetailed) TableName() string            { return "clients" }
+func (c *ClientDetailed) TableName() string { return "clients" }
+
+// SearchFields lists the fields of the client search (see services.Repository.Search):
+// the trading name, the document (code) and the contact.
+func (c *ClientDetailed) SearchFields() []svc.SearchField {
+	return []svc.SearchField{{Name: "tradingName"}, {Name: "code"}, {Name: "contactName", Weight: 0.8}, {Name: "email", Weight: 0.6}}
+}
+
+// FilterableFields lists the fields accepted by the list filters (see services.ParseFilterExpr).
+func (c *ClientDetailed) FilterableFields() []string {
+	return []string{"id", "code", "tradingName", "documentType", "status", "email", "contactName", "creditLimit", "totalSpent", "lastOrderDate", "createdAt", "updatedAt"}
+}
+
+// SortableFields lists the fields accepted by the list sort.
+func (c *ClientDetailed) SortableFields() []string {
+	return []string{"code", "tradingName", "status", "creditLimit", "totalSpent", "lastOrderDate", "createdAt", "updatedAt"}
+}
+
 func (c *ClientDetailed) GetID() string                { return c.ID }
 func (c *ClientDetailed) SetID(id string)              { c.ID = id }
+func (c *ClientDetailed) GetTenantID() *string         { return c.TenantID }
+func (c *ClientDetailed) SetTenantID(tenantID *string) { c.TenantID = tenantID }
 func (c *ClientDetailed) GetCode() *string             { return c.Code }
 func (c *ClientDetailed) SetCode(code *string)         { c.Code = code }
 func (c *ClientDetailed) GetTradingName() *string      { return c.TradingName }
@@ -26,6 +26,12 @@ type IClientRepo interface {
 	Close() error
 	// Lista os clientes em um formato de tabela simples ou outro formato que desejar.
 	List(query interface{}, args ...interface{}) (interface{}, error)
+	// Busca uma página de clientes pela especificação (filtros, ordenação e paginação).
+	Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[ClientDetailed], error)
+	// Busca os clientes pelo nome fantasia, documento ou contato, os mais relevantes primeiro.
+	Search(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[ClientDetailed], error)
+	// Retorna o repositório no contexto (e na transação) de ctx.
+	WithContext(ctx context.Context) IClientRepo
 }
 
 // ClientRepo é a implementação de IClientRepo usando GORM.
@@ -39,7 +45,7 @@ func NewClientRepo(ctx context.Context, dbService *svc.DBServiceImpl) IClientRep
 		gl.Log("error", "ClientRepo: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "clients")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("ClientRepo: failed to get DB from dbService: %v", err))
 		return nil
@@ -95,6 +101,26 @@ func (cr *ClientRepo) Delete(id string) error {
 	return nil
 }
 
+func (cr *ClientRepo) Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[ClientDetailed], error) {
+	page, err := svc.NewRepository[ClientDetailed](cr.db).List(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("ClientRepo: failed to query clients: %w", err)
+	}
+	return page, nil
+}
+
+func (cr *ClientRepo) Search(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[ClientDetailed], error) {
+	result, err := svc.NewRepository[ClientDetailed](cr.db).Search(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ClientRepo: failed to search clients: %w", err)
+	}
+	return result, nil
+}
+
+func (cr *ClientRepo) WithContext(ctx context.Context) IClientRepo {
+	return &ClientRepo{db: svc.DBFromContext(ctx, cr.db)}
+}
+
 func (cr *ClientRepo) Close() error {
 	sqlDB, err := cr.db.DB()
 	if err != nil {
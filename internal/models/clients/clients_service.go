@@ -1,8 +1,11 @@
 package clients
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 )
 
 // IClientService define os métodos disponíveis para gerenciar clientes.
@@ -17,6 +20,10 @@ type IClientService interface {
 	DeleteClient(id string) error
 	// Lista todos os clientes.
 	ListClients() ([]*ClientDetailed, error)
+	// Busca uma página de clientes com os filtros e a ordenação da consulta (ver services.ParseFilterExpr).
+	QueryClients(ctx context.Context, query svc.ListQuery) (*svc.Page[ClientDetailed], error)
+	// Busca clientes por texto (nomes parciais, documento, contato), os mais relevantes primeiro.
+	SearchClients(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[ClientDetailed], error)
 }
 
 // ClientService é a implementação de IClientService.
@@ -75,3 +82,23 @@ func (cs *ClientService) ListClients() ([]*ClientDetailed, error) {
 	}
 	return clients, nil
 }
+
+func (cs *ClientService) QueryClients(ctx context.Context, query svc.ListQuery) (*svc.Page[ClientDetailed], error) {
+	spec, err := query.Spec(&ClientDetailed{})
+	if err != nil {
+		return nil, err
+	}
+	page, err := cs.repo.Query(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("ClientService: error querying clients: %w", err)
+	}
+	return page, nil
+}
+
+func (cs *ClientService) SearchClients(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[ClientDetailed], error) {
+	result, err := cs.repo.Search(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ClientService: error searching clients: %w", err)
+	}
+	return result, nil
+}
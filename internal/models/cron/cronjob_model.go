@@ -32,6 +32,7 @@ type ICronJobModel interface {
 	SetLastExecutedBy(lastExecutedBy uuid.UUID)
 	GetUserID() uuid.UUID
 	SetUserID(userID uuid.UUID)
+	GetLockVersion() int
 	CronJobObject() *CronJob
 }
 
@@ -73,6 +74,8 @@ type CronJob struct {
 	Headers t.JSONBImpl `json:"headers" binding:"omitempty"`
 
 	Metadata t.JSONBImpl `json:"metadata" binding:"omitempty"`
+
+	LockVersion int `json:"lock_version" gorm:"not null;default:0" binding:"-"`
 }
 
 func NewCronJob(ctx context.Context, cron *CronJob, restrict bool) ICronJobModel {
@@ -110,6 +113,7 @@ func (c *CronJob) GetLastExecutedBy() uuid.UUID                { return c.LastEx
 func (c *CronJob) SetLastExecutedBy(lastExecutedBy uuid.UUID)  { c.LastExecutedBy = lastExecutedBy }
 func (c *CronJob) GetUserID() uuid.UUID                        { return c.UserID }
 func (c *CronJob) SetUserID(userID uuid.UUID)                  { c.UserID = userID }
+func (c *CronJob) GetLockVersion() int                         { return c.LockVersion }
 func (c *CronJob) GetScheduledCronJobs() []CronJob {
 	return []CronJob{*c}
 }
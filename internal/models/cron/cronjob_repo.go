@@ -26,7 +26,7 @@ type CronJobRepo struct {
 }
 
 func NewCronJobRepoImpl(ctx context.Context, dbService *svc.DBServiceImpl) *CronJobRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "cron_jobs")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("CronJobRepo: failed to get DB: %v", err))
 		return nil
@@ -37,8 +37,27 @@ func NewCronJobRepo(ctx context.Context, dbService *svc.DBServiceImpl) ICronJobR
 	return NewCronJobRepoImpl(ctx, dbService)
 }
 
-func (r *CronJobRepo) Create(ctx context.Context, job *CronJob) (*CronJob, error) {
+// userIDCtxKey is the context key of the user creating the cron jobs.
+type userIDCtxKey struct{}
+
+// WithUserID returns a copy of ctx carrying the user that creates the cron
+// jobs (see CronJobRepo.Create).
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDCtxKey{}, userID)
+}
+
+// UserIDFromContext returns the user set by WithUserID (or, for the older
+// callers, under the "userID" key).
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if userID, ok := ctx.Value(userIDCtxKey{}).(uuid.UUID); ok {
+		return userID, true
+	}
 	userID, ok := ctx.Value("userID").(uuid.UUID)
+	return userID, ok
+}
+
+func (r *CronJobRepo) Create(ctx context.Context, job *CronJob) (*CronJob, error) {
+	userID, ok := UserIDFromContext(ctx)
 	if !ok {
 		return nil, gorm.ErrRecordNotFound
 	}
@@ -60,7 +79,7 @@ func (r *CronJobRepo) Create(ctx context.Context, job *CronJob) (*CronJob, error
 			return nil, err
 		}
 	}
-	if err := r.DB.WithContext(ctx).Create(job).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.DB).Create(job).Error; err != nil {
 		return nil, err
 	}
 	return job, nil
@@ -68,7 +87,7 @@ func (r *CronJobRepo) Create(ctx context.Context, job *CronJob) (*CronJob, error
 
 func (r *CronJobRepo) FindByID(ctx context.Context, id uuid.UUID) (*CronJob, error) {
 	var job CronJob
-	if err := r.DB.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.DB).First(&job, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &job, nil
@@ -76,21 +95,21 @@ func (r *CronJobRepo) FindByID(ctx context.Context, id uuid.UUID) (*CronJob, err
 
 func (r *CronJobRepo) FindAll(ctx context.Context) ([]*CronJob, error) {
 	var jobs []*CronJob
-	if err := r.DB.WithContext(ctx).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.DB).Find(&jobs).Error; err != nil {
 		return nil, err
 	}
 	return jobs, nil
 }
 
 func (r *CronJobRepo) Update(ctx context.Context, job *CronJob) (*CronJob, error) {
-	if err := r.DB.WithContext(ctx).Save(job).Error; err != nil {
+	if err := svc.SaveOptimistic(svc.DBFromContext(ctx, r.DB), job); err != nil {
 		return nil, err
 	}
 	return job, nil
 }
 
 func (r *CronJobRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := r.DB.WithContext(ctx).Delete(&CronJob{}, "id = ?", id).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.DB).Delete(&CronJob{}, "id = ?", id).Error; err != nil {
 		return err
 	}
 	return nil
@@ -98,7 +117,7 @@ func (r *CronJobRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *CronJobRepo) GetScheduledCronJobs(ctx context.Context) ([]*CronJob, error) {
 	var jobs []*CronJob
-	if err := r.DB.WithContext(ctx).Where("is_active = ?", true).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.DB).Where("is_active = ?", true).Find(&jobs).Error; err != nil {
 		return nil, err
 	}
 	return jobs, nil
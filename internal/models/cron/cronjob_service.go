@@ -177,36 +177,24 @@ func (s *CronJobService) DeleteCronJob(ctx context.Context, id uuid.UUID) error
 }
 
 func (s *CronJobService) EnableCronJob(ctx context.Context, id uuid.UUID) error {
-	job, err := s.Repo.FindByID(ctx, id)
-	if err != nil {
-		return err
-	}
-	job.IsActive = true
-	_, err = s.Repo.Update(ctx, job)
-	return err
+	return s.updateCronJob(ctx, id, func(job *CronJob) {
+		job.IsActive = true
+	})
 }
 
 func (s *CronJobService) DisableCronJob(ctx context.Context, id uuid.UUID) error {
-	job, err := s.Repo.FindByID(ctx, id)
-	if err != nil {
-		return err
-	}
-	job.IsActive = false
-	_, err = s.Repo.Update(ctx, job)
-	return err
+	return s.updateCronJob(ctx, id, func(job *CronJob) {
+		job.IsActive = false
+	})
 }
 
 func (s *CronJobService) ExecuteCronJobManually(ctx context.Context, id uuid.UUID) error {
-	job, err := s.Repo.FindByID(ctx, id)
-	if err != nil {
-		return err
-	}
 	// Simulate execution logic here (e.g., log execution or trigger a worker)
-	job.LastRunStatus = "success"
-	now := time.Now().UTC()
-	job.LastRunTime = &now
-	_, err = s.Repo.Update(ctx, job)
-	return err
+	return s.updateCronJob(ctx, id, func(job *CronJob) {
+		job.LastRunStatus = "success"
+		now := time.Now().UTC()
+		job.LastRunTime = &now
+	})
 }
 
 func (s *CronJobService) ListActiveCronJobs(ctx context.Context) ([]*CronJob, error) {
@@ -224,13 +212,23 @@ func (s *CronJobService) ListActiveCronJobs(ctx context.Context) ([]*CronJob, er
 }
 
 func (s *CronJobService) RescheduleCronJob(ctx context.Context, id uuid.UUID, newExpression string) error {
-	job, err := s.Repo.FindByID(ctx, id)
-	if err != nil {
+	return s.updateCronJob(ctx, id, func(job *CronJob) {
+		job.CronExpression = newExpression
+	})
+}
+
+// updateCronJob applies change to the current version of the job, reading it
+// again when a concurrent writer updated it first (svc.ErrStaleObject).
+func (s *CronJobService) updateCronJob(ctx context.Context, id uuid.UUID, change func(job *CronJob)) error {
+	return svc.RetryOnStale(ctx, svc.DefaultStaleAttempts, func(ctx context.Context) error {
+		job, err := s.Repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		change(job)
+		_, err = s.Repo.Update(ctx, job)
 		return err
-	}
-	job.CronExpression = newExpression
-	_, err = s.Repo.Update(ctx, job)
-	return err
+	})
 }
 
 func (s *CronJobService) ValidateCronExpression(ctx context.Context, expression string) error {
@@ -26,6 +26,7 @@ type IDiscordRepo interface {
 	Close() error
 	List(where ...interface{}) (xtt.TableDataHandler, error)
 	GetContextDBService() *svc.DBServiceImpl
+	WithContext(ctx context.Context) IDiscordRepo
 }
 
 type DiscordRepo struct {
@@ -38,7 +39,7 @@ func NewDiscordRepo(ctx context.Context, dbService *svc.DBServiceImpl) IDiscordR
 		gl.Log("error", "DiscordModel repository: gorm DB is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "discord")
 	if err != nil {
 		gl.Log("error", "DiscordModel repository: error getting gorm DB: "+err.Error())
 		return nil
@@ -137,6 +138,10 @@ func (dr *DiscordRepo) Delete(id string) error {
 	return nil
 }
 
+func (dr *DiscordRepo) WithContext(ctx context.Context) IDiscordRepo {
+	return &DiscordRepo{g: svc.DBFromContext(ctx, dr.g)}
+}
+
 func (dr *DiscordRepo) Close() error {
 	sqlDB, err := dr.g.DB()
 	if err != nil {
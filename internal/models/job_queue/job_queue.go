@@ -58,6 +58,7 @@ type IJobQueue interface {
 	SetJobRetries(jobRetries int)
 	GetJobTimeout() int
 	SetJobTimeout(jobTimeout int)
+	GetLockVersion() int
 }
 
 type JobQueue struct {
@@ -86,6 +87,7 @@ type JobQueue struct {
 	JobHeaders     t.JSONBImpl `json:"job_headers" xml:"job_headers" yaml:"job_headers" gorm:"column:job_headers"`
 	JobRetries     int         `json:"job_retries" xml:"job_retries" yaml:"job_retries" gorm:"column:job_retries;default:0"`
 	JobTimeout     int         `json:"job_timeout" xml:"job_timeout" yaml:"job_timeout" gorm:"column:job_timeout;default:0"`
+	LockVersion    int         `json:"lock_version" xml:"lock_version" yaml:"lock_version" gorm:"column:lock_version;not null;default:0"`
 }
 
 func NewJobQueueModel() IJobQueue {
@@ -146,6 +148,7 @@ func (j *JobQueue) GetJobRetries() int                         { return j.JobRet
 func (j *JobQueue) SetJobRetries(jobRetries int)               { j.JobRetries = jobRetries }
 func (j *JobQueue) GetJobTimeout() int                         { return j.JobTimeout }
 func (j *JobQueue) SetJobTimeout(jobTimeout int)               { j.JobTimeout = jobTimeout }
+func (j *JobQueue) GetLockVersion() int                        { return j.LockVersion }
 
 type IExecutionLog interface {
 	TableName() string
@@ -10,7 +10,6 @@ import (
 	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 type IJobQueueRepo interface {
@@ -51,255 +50,142 @@ type IJobQueueRepo interface {
 	ValidateJobSchedule(ctx context.Context, schedule string) error
 }
 
+// JobQueueRepository embeds the generic repository (Create, Get, Update with
+// optimistic locking, Upsert, Find, List...) and adds the job queue queries.
 type JobQueueRepository struct {
-	db *gorm.DB
+	*svc.Repository[JobQueue]
 }
 
 func NewJobQueueRepository(ctx context.Context, dbService *svc.DBServiceImpl) IJobQueueRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "job_queue")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("JobQueueRepository: failed to get DB: %v", err))
 		return nil
 	}
-	return &JobQueueRepository{db: db}
+	return &JobQueueRepository{Repository: svc.NewRepository[JobQueue](db)}
 }
 
 // Implement repository methods here
 
-func (repo *JobQueueRepository) Create(ctx context.Context, job *JobQueue) (*JobQueue, error) {
-	if err := repo.db.Create(job).Error; err != nil {
-		return nil, err
-	}
-	return job, nil
-}
 func (repo *JobQueueRepository) FindByID(ctx context.Context, id uuid.UUID) (*JobQueue, error) {
-	var job JobQueue
-	if err := repo.db.First(&job, "id = ?", id).Error; err != nil {
-		return nil, err
-	}
-	return &job, nil
+	return repo.Get(ctx, id)
 }
 func (repo *JobQueueRepository) FindAll(ctx context.Context) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
-}
-func (repo *JobQueueRepository) Update(ctx context.Context, job *JobQueue) (*JobQueue, error) {
-	if err := repo.db.Save(job).Error; err != nil {
-		return nil, err
-	}
-	return job, nil
+	return repo.Find(ctx, nil)
 }
 func (repo *JobQueueRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := repo.db.Delete(&JobQueue{}, "id = ?", id).Error; err != nil {
-		return err
-	}
-	return nil
+	return repo.Repository.Delete(ctx, id)
 }
 func (repo *JobQueueRepository) FindByStatus(ctx context.Context, status string) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ?", status).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status))
 }
 func (repo *JobQueueRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ?", userID).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID))
 }
 func (repo *JobQueueRepository) FindByType(ctx context.Context, jobType string) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("type = ?", jobType).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("job_type", jobType))
 }
 func (repo *JobQueueRepository) FindByCreatedAt(ctx context.Context, createdAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("created_at = ?", createdAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("created_at", createdAt))
 }
 func (repo *JobQueueRepository) FindByUpdatedAt(ctx context.Context, updatedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("updated_at = ?", updatedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("updated_at", updatedAt))
 }
 func (repo *JobQueueRepository) FindByCreatedBy(ctx context.Context, createdBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("created_by = ?", createdBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("created_by", createdBy))
 }
 func (repo *JobQueueRepository) FindByUpdatedBy(ctx context.Context, updatedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("updated_by = ?", updatedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("updated_by", updatedBy))
 }
 func (repo *JobQueueRepository) FindByLastExecutedAt(ctx context.Context, lastExecutedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("last_executed_at = ?", lastExecutedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("last_executed_at", lastExecutedAt))
 }
 func (repo *JobQueueRepository) FindByLastExecutedBy(ctx context.Context, lastExecutedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("last_executed_by = ?", lastExecutedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("last_executed_by", lastExecutedBy))
 }
 func (repo *JobQueueRepository) FindByStatusAndUserID(ctx context.Context, status string, userID uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND user_id = ?", status, userID).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("user_id", userID))
 }
 func (repo *JobQueueRepository) FindByStatusAndType(ctx context.Context, status string, jobType string) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND type = ?", status, jobType).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("job_type", jobType))
 }
 func (repo *JobQueueRepository) FindByStatusAndCreatedAt(ctx context.Context, status string, createdAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND created_at = ?", status, createdAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("created_at", createdAt))
 }
 func (repo *JobQueueRepository) FindByStatusAndCreatedBy(ctx context.Context, status string, createdBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND created_by = ?", status, createdBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("created_by", createdBy))
 }
 func (repo *JobQueueRepository) FindByStatusAndUpdatedAt(ctx context.Context, status string, updatedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND updated_at = ?", status, updatedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("updated_at", updatedAt))
 }
 func (repo *JobQueueRepository) FindByStatusAndUpdatedBy(ctx context.Context, status string, updatedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND updated_by = ?", status, updatedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("updated_by", updatedBy))
 }
 func (repo *JobQueueRepository) FindByStatusAndLastExecutedAt(ctx context.Context, status string, lastExecutedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND last_executed_at = ?", status, lastExecutedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("last_executed_at", lastExecutedAt))
 }
 func (repo *JobQueueRepository) FindByStatusAndLastExecutedBy(ctx context.Context, status string, lastExecutedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("status = ? AND last_executed_by = ?", status, lastExecutedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("status", status).Eq("last_executed_by", lastExecutedBy))
 }
 func (repo *JobQueueRepository) FindByUserIDAndType(ctx context.Context, userID uuid.UUID, jobType string) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND type = ?", userID, jobType).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("job_type", jobType))
 }
 func (repo *JobQueueRepository) FindByUserIDAndCreatedAt(ctx context.Context, userID uuid.UUID, createdAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND created_at = ?", userID, createdAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("created_at", createdAt))
 }
 func (repo *JobQueueRepository) FindByUserIDAndCreatedBy(ctx context.Context, userID uuid.UUID, createdBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND created_by = ?", userID, createdBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("created_by", createdBy))
 }
 func (repo *JobQueueRepository) FindByUserIDAndUpdatedAt(ctx context.Context, userID uuid.UUID, updatedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND updated_at = ?", userID, updatedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("updated_at", updatedAt))
 }
 func (repo *JobQueueRepository) FindByUserIDAndUpdatedBy(ctx context.Context, userID uuid.UUID, updatedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND updated_by = ?", userID, updatedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("updated_by", updatedBy))
 }
 func (repo *JobQueueRepository) FindByUserIDAndLastExecutedAt(ctx context.Context, userID uuid.UUID, lastExecutedAt time.Time) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND last_executed_at = ?", userID, lastExecutedAt).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("last_executed_at", lastExecutedAt))
 }
 func (repo *JobQueueRepository) FindByUserIDAndLastExecutedBy(ctx context.Context, userID uuid.UUID, lastExecutedBy uuid.UUID) ([]*JobQueue, error) {
-	var jobs []*JobQueue
-	if err := repo.db.Where("user_id = ? AND last_executed_by = ?", userID, lastExecutedBy).Find(&jobs).Error; err != nil {
-		return nil, err
-	}
-	return jobs, nil
+	return repo.Find(ctx, svc.NewQuerySpec().Eq("user_id", userID).Eq("last_executed_by", lastExecutedBy))
 }
 func (repo *JobQueueRepository) ExecuteJobManually(ctx context.Context, jobID uuid.UUID) error {
-	var job JobQueue
-	if err := repo.db.First(&job, "id = ?", jobID).Error; err != nil {
-		return err
-	}
-	job.Status = "executing"
-	if err := repo.db.Save(&job).Error; err != nil {
-		return err
-	}
 	// Execute the job logic here
-	return nil
+	return repo.transition(ctx, jobID, func(job *JobQueue) error {
+		if job.Status == "executing" {
+			return fmt.Errorf("job %s is already executing", jobID)
+		}
+		job.Status = "executing"
+		return nil
+	})
 }
 func (repo *JobQueueRepository) RetryFailedJob(ctx context.Context, jobID uuid.UUID) error {
-	var job JobQueue
-	if err := repo.db.First(&job, "id = ?", jobID).Error; err != nil {
-		return err
-	}
-	job.Status = "retrying"
-	if err := repo.db.Save(&job).Error; err != nil {
-		return err
-	}
 	// Retry the job logic here
-	return nil
+	return repo.transition(ctx, jobID, func(job *JobQueue) error {
+		job.Status = "retrying"
+		return nil
+	})
 }
 func (repo *JobQueueRepository) RescheduleJob(ctx context.Context, jobID uuid.UUID, newSchedule time.Time) error {
-	var job JobQueue
-	if err := repo.db.First(&job, "id = ?", jobID).Error; err != nil {
-		return err
-	}
-	job.ScheduledAt = newSchedule
-	if err := repo.db.Save(&job).Error; err != nil {
+	return repo.transition(ctx, jobID, func(job *JobQueue) error {
+		job.ScheduledAt = newSchedule
+		return nil
+	})
+}
+
+// transition applies change to the current version of the job, reading it
+// again when a concurrent writer updated it first.
+func (repo *JobQueueRepository) transition(ctx context.Context, jobID uuid.UUID, change func(job *JobQueue) error) error {
+	return svc.RetryOnStale(ctx, svc.DefaultStaleAttempts, func(ctx context.Context) error {
+		job, err := repo.Get(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := change(job); err != nil {
+			return err
+		}
+		_, err = repo.Update(ctx, job)
 		return err
-	}
-	return nil
+	})
 }
 func (repo *JobQueueRepository) ValidateJobSchedule(ctx context.Context, schedule string) error {
 	if schedule == "" {
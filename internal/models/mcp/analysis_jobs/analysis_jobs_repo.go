@@ -41,7 +41,7 @@ type AnalysisJobRepository struct {
 }
 
 func NewAnalysisJobRepository(ctx context.Context, dbService *svc.DBServiceImpl) IAnalysisJobRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "mcp_analysis_jobs")
 	if err != nil {
 		return nil
 	}
@@ -52,7 +52,7 @@ func (r *AnalysisJobRepository) Create(ctx context.Context, job *AnalysisJob) (*
 	if job == nil {
 		return nil, errors.New("repository: job cannot be nil")
 	}
-	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Create(job).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to create analysis job: %w", err)
 	}
 	return job, nil
@@ -63,7 +63,7 @@ func (r *AnalysisJobRepository) FindByID(ctx context.Context, id uuid.UUID) (*An
 		return nil, errors.New("repository: id cannot be empty")
 	}
 	var job AnalysisJob
-	if err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).First(&job, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("repository: analysis job not found: %w", err)
 		}
@@ -74,7 +74,7 @@ func (r *AnalysisJobRepository) FindByID(ctx context.Context, id uuid.UUID) (*An
 
 func (r *AnalysisJobRepository) FindAll(ctx context.Context) ([]*AnalysisJob, error) {
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find all analysis jobs: %w", err)
 	}
 	return jobs, nil
@@ -87,7 +87,7 @@ func (r *AnalysisJobRepository) Update(ctx context.Context, job *AnalysisJob) (*
 	if job.ID == uuid.Nil {
 		return nil, errors.New("repository: job ID cannot be empty")
 	}
-	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Save(job).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to update analysis job: %w", err)
 	}
 	return job, nil
@@ -97,7 +97,7 @@ func (r *AnalysisJobRepository) Delete(ctx context.Context, id uuid.UUID) error
 	if id == uuid.Nil {
 		return errors.New("repository: id cannot be empty")
 	}
-	if err := r.db.WithContext(ctx).Delete(&AnalysisJob{}, "id = ?", id).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Delete(&AnalysisJob{}, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("repository: failed to delete analysis job: %w", err)
 	}
 	return nil
@@ -108,7 +108,7 @@ func (r *AnalysisJobRepository) FindByStatus(ctx context.Context, status string)
 		return nil, errors.New("repository: status cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", status).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by status: %w", err)
 	}
 	return jobs, nil
@@ -119,7 +119,7 @@ func (r *AnalysisJobRepository) FindByJobType(ctx context.Context, jobType strin
 		return nil, errors.New("repository: job type cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("job_type = ?", jobType).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("job_type = ?", jobType).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by type: %w", err)
 	}
 	return jobs, nil
@@ -130,7 +130,7 @@ func (r *AnalysisJobRepository) FindByUserID(ctx context.Context, userID uuid.UU
 		return nil, errors.New("repository: user ID cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_id = ?", userID).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by user ID: %w", err)
 	}
 	return jobs, nil
@@ -141,7 +141,7 @@ func (r *AnalysisJobRepository) FindByProjectID(ctx context.Context, projectID u
 		return nil, errors.New("repository: project ID cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("project_id = ?", projectID).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by project ID: %w", err)
 	}
 	return jobs, nil
@@ -155,7 +155,7 @@ func (r *AnalysisJobRepository) FindByStatusAndJobType(ctx context.Context, stat
 		return nil, errors.New("repository: job type cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("status = ? AND job_type = ?", status, jobType).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ? AND job_type = ?", status, jobType).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by status and type: %w", err)
 	}
 	return jobs, nil
@@ -169,7 +169,7 @@ func (r *AnalysisJobRepository) FindByUserIDAndStatus(ctx context.Context, userI
 		return nil, errors.New("repository: status cannot be empty")
 	}
 	var jobs []*AnalysisJob
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, status).Find(&jobs).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_id = ? AND status = ?", userID, status).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("repository: failed to find jobs by user ID and status: %w", err)
 	}
 	return jobs, nil
@@ -194,7 +194,7 @@ func (r *AnalysisJobRepository) UpdateProgress(ctx context.Context, id uuid.UUID
 	if progress < 0 || progress > 100 {
 		return errors.New("repository: progress must be between 0 and 100")
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Update("progress", progress).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Update("progress", progress).Error; err != nil {
 		return fmt.Errorf("repository: failed to update progress: %w", err)
 	}
 	return nil
@@ -207,7 +207,7 @@ func (r *AnalysisJobRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 	if status == "" {
 		return errors.New("repository: status cannot be empty")
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Update("status", status).Error; err != nil {
 		return fmt.Errorf("repository: failed to update status: %w", err)
 	}
 	return nil
@@ -221,7 +221,7 @@ func (r *AnalysisJobRepository) MarkAsStarted(ctx context.Context, id uuid.UUID)
 		"status":     "RUNNING",
 		"started_at": time.Now(),
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 		return fmt.Errorf("repository: failed to mark job as started: %w", err)
 	}
 	return nil
@@ -239,7 +239,7 @@ func (r *AnalysisJobRepository) MarkAsCompleted(ctx context.Context, id uuid.UUI
 	if outputData != nil {
 		updates["output_data"] = outputData
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 		return fmt.Errorf("repository: failed to mark job as completed: %w", err)
 	}
 	return nil
@@ -253,7 +253,7 @@ func (r *AnalysisJobRepository) MarkAsFailed(ctx context.Context, id uuid.UUID,
 		"status":        "FAILED",
 		"error_message": errorMessage,
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 		return fmt.Errorf("repository: failed to mark job as failed: %w", err)
 	}
 	return nil
@@ -263,7 +263,7 @@ func (r *AnalysisJobRepository) IncrementRetryCount(ctx context.Context, id uuid
 	if id == uuid.Nil {
 		return errors.New("repository: id cannot be empty")
 	}
-	if err := r.db.WithContext(ctx).Model(&AnalysisJob{}).Where("id = ?", id).Update("retry_count", gorm.Expr("retry_count + 1")).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&AnalysisJob{}).Where("id = ?", id).Update("retry_count", gorm.Expr("retry_count + 1")).Error; err != nil {
 		return fmt.Errorf("repository: failed to increment retry count: %w", err)
 	}
 	return nil
@@ -25,6 +25,7 @@ type ILLMRepo interface {
 	Close() error
 	List(where ...interface{}) (xtt.TableDataHandler, error)
 	GetContextDBService() is.DBService
+	WithContext(ctx context.Context) ILLMRepo
 }
 
 type LLMRepo struct {
@@ -133,6 +134,10 @@ func (lr *LLMRepo) Delete(id string) error {
 	return nil
 }
 
+func (lr *LLMRepo) WithContext(ctx context.Context) ILLMRepo {
+	return &LLMRepo{g: is.DBFromContext(ctx, lr.g)}
+}
+
 func (lr *LLMRepo) Close() error {
 	sqlDB, err := lr.g.DB()
 	if err != nil {
@@ -26,6 +26,7 @@ type IPreferencesRepo interface {
 	Close() error
 	List(where ...interface{}) (xtt.TableDataHandler, error)
 	GetContextDBService() *t.DBServiceImpl
+	WithContext(ctx context.Context) IPreferencesRepo
 }
 
 type PreferencesRepo struct {
@@ -38,7 +39,7 @@ func NewPreferencesRepo(ctx context.Context, dbService *svc.DBServiceImpl) IPref
 		gl.Log("error", "PreferencesModel repository: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "mcp_preferences")
 	if err != nil {
 		return nil
 	}
@@ -139,6 +140,10 @@ func (pr *PreferencesRepo) Delete(id string) error {
 	return nil
 }
 
+func (pr *PreferencesRepo) WithContext(ctx context.Context) IPreferencesRepo {
+	return &PreferencesRepo{g: svc.DBFromContext(ctx, pr.g)}
+}
+
 func (pr *PreferencesRepo) Close() error {
 	sqlDB, err := pr.g.DB()
 	if err != nil {
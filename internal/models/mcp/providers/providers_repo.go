@@ -26,6 +26,7 @@ type IProvidersRepo interface {
 	Close() error
 	List(where ...interface{}) (xtt.TableDataHandler, error)
 	GetContextDBService() *svc.DBServiceImpl
+	WithContext(ctx context.Context) IProvidersRepo
 }
 
 type ProvidersRepo struct {
@@ -38,7 +39,7 @@ func NewProvidersRepo(ctx context.Context, dbService *svc.DBServiceImpl) IProvid
 		gl.Log("error", "ProvidersModel repository: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "mcp_providers")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("ProvidersModel repository: failed to get DB from dbService: %v", err))
 		return nil
@@ -139,6 +140,10 @@ func (pr *ProvidersRepo) Delete(id string) error {
 	return nil
 }
 
+func (pr *ProvidersRepo) WithContext(ctx context.Context) IProvidersRepo {
+	return &ProvidersRepo{g: svc.DBFromContext(ctx, pr.g)}
+}
+
 func (pr *ProvidersRepo) Close() error {
 	sqlDB, err := pr.g.DB()
 	if err != nil {
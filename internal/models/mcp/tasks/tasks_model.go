@@ -175,7 +175,18 @@ func NewTasksModel() *TasksModel {
 	}
 }
 
-func (t *TasksModel) TableName() string                   { return "mcp_sync_tasks" }
+func (t *TasksModel) TableName() string { return "mcp_sync_tasks" }
+
+// FilterableFields lists the fields accepted by the list filters (see services.ParseFilterExpr).
+func (t *TasksModel) FilterableFields() []string {
+	return []string{"id", "mcp_provider", "target_task", "task_type", "task_status", "task_priority", "task_next_run", "task_last_run", "task_last_run_status", "task_activated", "active", "created_by"}
+}
+
+// SortableFields lists the fields accepted by the list sort.
+func (t *TasksModel) SortableFields() []string {
+	return []string{"mcp_provider", "target_task", "task_status", "task_priority", "task_next_run", "task_last_run"}
+}
+
 func (t *TasksModel) GetID() string                       { return t.ID }
 func (t *TasksModel) SetID(id string)                     { t.ID = id }
 func (t *TasksModel) GetMCPProvider() string              { return t.MCPProvider }
@@ -23,6 +23,10 @@ type ITasksRepo interface {
 	Close() error
 	List(where ...any) (xtt.TableDataHandler, error)
 	GetContextDBService() *svc.DBServiceImpl
+	// Query returns a page of the tasks matching spec (filters, sort and pagination).
+	Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[TasksModel], error)
+	// WithContext returns a copy of the repo for ctx (see svc.DBFromContext).
+	WithContext(ctx context.Context) ITasksRepo
 }
 
 type TasksRepo struct {
@@ -35,7 +39,7 @@ func NewTasksRepo(ctx context.Context, dbService *svc.DBServiceImpl) *TasksRepo
 		gl.Log("error", "TasksModel repository: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "mcp_tasks")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("TasksModel repository: failed to get DB from dbService: %v", err))
 		return nil
@@ -149,6 +153,10 @@ func (tr *TasksRepo) Delete(id string) error {
 	return nil
 }
 
+func (tr *TasksRepo) WithContext(ctx context.Context) ITasksRepo {
+	return &TasksRepo{g: svc.DBFromContext(ctx, tr.g)}
+}
+
 func (tr *TasksRepo) Close() error {
 	sqlDB, err := tr.g.DB()
 	if err != nil {
@@ -192,6 +200,14 @@ func (tr *TasksRepo) List(where ...any) (xtt.TableDataHandler, error) {
 	return xtt.NewTableHandlerFromRows([]string{"#", "ID", "Provider", "Target", "Type", "Cron", "Status", "Active", "Next Run", "Last Run", "Last Status"}, tableHandlerMap), nil
 }
 
+func (tr *TasksRepo) Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[TasksModel], error) {
+	page, err := svc.NewRepository[TasksModel](tr.g).List(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("TasksModel repository: failed to query tasks: %w", err)
+	}
+	return page, nil
+}
+
 func (tr *TasksRepo) GetContextDBService() *svc.DBServiceImpl {
 	dbService, dbServiceErr := svc.NewDatabaseService(context.Background(), svc.NewDBConfigWithDBConnection(tr.g), l.GetLogger("GdoBase"))
 	if dbServiceErr != nil {
@@ -1,10 +1,12 @@
 package tasks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	t "github.com/kubex-ecosystem/gdbase/types"
 )
 
@@ -27,6 +29,8 @@ type ITasksService interface {
 	UpdateTask(task ITasksModel) (ITasksModel, error)
 	DeleteTask(id string) error
 	ListTasks(opts *TaskSearchOptions) ([]ITasksModel, error)
+	// QueryTasks returns a page of tasks filtered and sorted by query (see services.ParseFilterExpr).
+	QueryTasks(ctx context.Context, query svc.ListQuery) (*svc.Page[TasksModel], error)
 	GetTasksByProvider(provider string) ([]ITasksModel, error)
 	GetTasksByTarget(target string) ([]ITasksModel, error)
 	GetTasksByProviderAndTarget(provider, target string) ([]ITasksModel, error)
@@ -134,6 +138,18 @@ func (ts *TasksService) ListTasks(opts *TaskSearchOptions) ([]ITasksModel, error
 	return tasks, nil
 }
 
+func (ts *TasksService) QueryTasks(ctx context.Context, query svc.ListQuery) (*svc.Page[TasksModel], error) {
+	spec, err := query.Spec(&TasksModel{})
+	if err != nil {
+		return nil, err
+	}
+	page, err := ts.repo.Query(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tasks: %w", err)
+	}
+	return page, nil
+}
+
 func (ts *TasksService) GetTasksByProvider(provider string) ([]ITasksModel, error) {
 	tasks, err := ts.repo.FindAll("provider = ?", provider)
 	if err != nil {
@@ -47,6 +47,8 @@ type IConversationModel interface {
 	TableName() string
 	GetID() string
 	SetID(id string)
+	GetTenantID() *string
+	SetTenantID(tenantID *string)
 	GetPlatform() Platform
 	SetPlatform(platform Platform)
 	GetPlatformConversationID() string
@@ -90,6 +92,7 @@ type IConversationModel interface {
 // ConversationModel represents a unified conversation entity across all platforms
 type ConversationModel struct {
 	ID                     string             `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID               *string            `gorm:"type:varchar(64);index" json:"tenant_id,omitempty"`
 	Platform               Platform           `gorm:"type:text;not null;index" json:"platform" example:"DISCORD"`
 	PlatformConversationID string             `gorm:"type:text;not null;index" json:"platform_conversation_id" example:"123456789012345678"`
 	IntegrationID          string             `gorm:"type:uuid;not null;index" json:"integration_id" example:"123e4567-e89b-12d3-a456-426614174001"`
@@ -140,6 +143,8 @@ func (c *ConversationModel) TableName() string { return "mcp_conversations" }
 
 func (c *ConversationModel) GetID() string                     { return c.ID }
 func (c *ConversationModel) SetID(id string)                   { c.ID = id }
+func (c *ConversationModel) GetTenantID() *string              { return c.TenantID }
+func (c *ConversationModel) SetTenantID(tenantID *string)      { c.TenantID = tenantID }
 func (c *ConversationModel) GetPlatform() Platform             { return c.Platform }
 func (c *ConversationModel) SetPlatform(platform Platform)     { c.Platform = platform }
 func (c *ConversationModel) GetPlatformConversationID() string { return c.PlatformConversationID }
@@ -29,6 +29,8 @@ type IConversationRepo interface {
 	UpdateLastMessage(ctx context.Context, id string, messageID string) error
 	IncrementMessageCount(ctx context.Context, id string) error
 	FindByTargetTaskID(ctx context.Context, targetTaskID string) ([]*ConversationModel, error)
+	// SearchMessages returns the messages matching query by content or sender/recipient name, best first.
+	SearchMessages(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[MessageModel], error)
 }
 
 type ConversationRepository struct {
@@ -40,7 +42,7 @@ func NewConversationRepository(ctx context.Context, dbService *svc.DBServiceImpl
 		gl.Log("error", "Conversation repository: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "messaging")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("Conversation repository: failed to get DB from dbService: %v", err))
 		return nil
@@ -56,7 +58,7 @@ func (r *ConversationRepository) Create(ctx context.Context, conversation *Conve
 
 	conversation.Sanitize()
 
-	if err := r.db.WithContext(ctx).Create(conversation).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Create(conversation).Error; err != nil {
 		gl.Log("error", "Failed to create conversation", err)
 		return nil, fmt.Errorf("failed to create conversation: %w", err)
 	}
@@ -73,7 +75,7 @@ func (r *ConversationRepository) Update(ctx context.Context, conversation *Conve
 
 	conversation.Sanitize()
 
-	if err := r.db.WithContext(ctx).Save(conversation).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Save(conversation).Error; err != nil {
 		gl.Log("error", "Failed to update conversation", err)
 		return nil, fmt.Errorf("failed to update conversation: %w", err)
 	}
@@ -83,7 +85,7 @@ func (r *ConversationRepository) Update(ctx context.Context, conversation *Conve
 }
 
 func (r *ConversationRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Delete(&ConversationModel{}, "id = ?", id)
+	result := svc.DBFromContext(ctx, r.db).Delete(&ConversationModel{}, "id = ?", id)
 	if result.Error != nil {
 		gl.Log("error", "Failed to delete conversation", result.Error)
 		return fmt.Errorf("failed to delete conversation: %w", result.Error)
@@ -100,7 +102,7 @@ func (r *ConversationRepository) Delete(ctx context.Context, id string) error {
 
 func (r *ConversationRepository) FindByID(ctx context.Context, id string) (*ConversationModel, error) {
 	var conversation ConversationModel
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&conversation).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("id = ?", id).First(&conversation).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("conversation not found")
 		}
@@ -113,7 +115,7 @@ func (r *ConversationRepository) FindByID(ctx context.Context, id string) (*Conv
 
 func (r *ConversationRepository) FindByPlatformAndConversationID(ctx context.Context, platform Platform, platformConversationID string) (*ConversationModel, error) {
 	var conversation ConversationModel
-	if err := r.db.WithContext(ctx).Where("platform = ? AND platform_conversation_id = ?", platform, platformConversationID).First(&conversation).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("platform = ? AND platform_conversation_id = ?", platform, platformConversationID).First(&conversation).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("conversation not found for platform: %s, conversation ID: %s", platform, platformConversationID)
 		}
@@ -126,7 +128,7 @@ func (r *ConversationRepository) FindByPlatformAndConversationID(ctx context.Con
 
 func (r *ConversationRepository) FindByIntegrationID(ctx context.Context, integrationID string) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("integration_id = ?", integrationID).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("integration_id = ?", integrationID).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by integration ID", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -136,7 +138,7 @@ func (r *ConversationRepository) FindByIntegrationID(ctx context.Context, integr
 
 func (r *ConversationRepository) FindByPlatform(ctx context.Context, platform Platform) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("platform = ?", platform).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("platform = ?", platform).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by platform", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -146,7 +148,7 @@ func (r *ConversationRepository) FindByPlatform(ctx context.Context, platform Pl
 
 func (r *ConversationRepository) FindByStatus(ctx context.Context, status ConversationStatus) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", status).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by status", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -156,7 +158,7 @@ func (r *ConversationRepository) FindByStatus(ctx context.Context, status Conver
 
 func (r *ConversationRepository) FindByConversationType(ctx context.Context, conversationType ConversationType) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("conversation_type = ?", conversationType).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("conversation_type = ?", conversationType).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by conversation type", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -166,7 +168,7 @@ func (r *ConversationRepository) FindByConversationType(ctx context.Context, con
 
 func (r *ConversationRepository) FindByUserID(ctx context.Context, userID string) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_id = ?", userID).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by user ID", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -176,7 +178,7 @@ func (r *ConversationRepository) FindByUserID(ctx context.Context, userID string
 
 func (r *ConversationRepository) FindActiveConversations(ctx context.Context) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("status = ?", ConversationStatusActive).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", ConversationStatusActive).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find active conversations", err)
 		return nil, fmt.Errorf("failed to find active conversations: %w", err)
 	}
@@ -186,7 +188,7 @@ func (r *ConversationRepository) FindActiveConversations(ctx context.Context) ([
 
 func (r *ConversationRepository) FindRecentConversations(ctx context.Context, limit int) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	query := r.db.WithContext(ctx).Order("last_message_at DESC")
+	query := svc.DBFromContext(ctx, r.db).Order("last_message_at DESC")
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -201,7 +203,7 @@ func (r *ConversationRepository) FindRecentConversations(ctx context.Context, li
 
 func (r *ConversationRepository) FindAll(ctx context.Context) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find all conversations", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
@@ -211,7 +213,7 @@ func (r *ConversationRepository) FindAll(ctx context.Context) ([]*ConversationMo
 
 func (r *ConversationRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&ConversationModel{}).Count(&count).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&ConversationModel{}).Count(&count).Error; err != nil {
 		gl.Log("error", "Failed to count conversations", err)
 		return 0, fmt.Errorf("failed to count conversations: %w", err)
 	}
@@ -220,7 +222,7 @@ func (r *ConversationRepository) Count(ctx context.Context) (int64, error) {
 }
 
 func (r *ConversationRepository) UpdateStatus(ctx context.Context, id string, status ConversationStatus) error {
-	result := r.db.WithContext(ctx).Model(&ConversationModel{}).Where("id = ?", id).Update("status", status)
+	result := svc.DBFromContext(ctx, r.db).Model(&ConversationModel{}).Where("id = ?", id).Update("status", status)
 	if result.Error != nil {
 		gl.Log("error", "Failed to update conversation status", result.Error)
 		return fmt.Errorf("failed to update conversation status: %w", result.Error)
@@ -241,7 +243,7 @@ func (r *ConversationRepository) UpdateLastMessage(ctx context.Context, id strin
 		"updated_at":      "NOW()",
 	}
 
-	result := r.db.WithContext(ctx).Model(&ConversationModel{}).Where("id = ?", id).Updates(updates)
+	result := svc.DBFromContext(ctx, r.db).Model(&ConversationModel{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
 		gl.Log("error", "Failed to update conversation last message", result.Error)
 		return fmt.Errorf("failed to update conversation last message: %w", result.Error)
@@ -255,7 +257,7 @@ func (r *ConversationRepository) UpdateLastMessage(ctx context.Context, id strin
 }
 
 func (r *ConversationRepository) IncrementMessageCount(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Model(&ConversationModel{}).Where("id = ?", id).
+	result := svc.DBFromContext(ctx, r.db).Model(&ConversationModel{}).Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"message_count": gorm.Expr("message_count + 1"),
 			"updated_at":    "NOW()",
@@ -275,10 +277,20 @@ func (r *ConversationRepository) IncrementMessageCount(ctx context.Context, id s
 
 func (r *ConversationRepository) FindByTargetTaskID(ctx context.Context, targetTaskID string) ([]*ConversationModel, error) {
 	var conversations []*ConversationModel
-	if err := r.db.WithContext(ctx).Where("target_task_id = ?", targetTaskID).Find(&conversations).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("target_task_id = ?", targetTaskID).Find(&conversations).Error; err != nil {
 		gl.Log("error", "Failed to find conversations by target task ID", err)
 		return nil, fmt.Errorf("failed to find conversations: %w", err)
 	}
 
 	return conversations, nil
 }
+
+func (r *ConversationRepository) SearchMessages(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[MessageModel], error) {
+	result, err := svc.NewRepository[MessageModel](r.db).Search(ctx, query, opts)
+	if err != nil {
+		gl.Log("error", "Failed to search messages", err)
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+
+	return result, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	t "github.com/kubex-ecosystem/gdbase/internal/types"
 )
 
@@ -53,6 +54,8 @@ type IMessageModel interface {
 	TableName() string
 	GetID() string
 	SetID(id string)
+	GetTenantID() *string
+	SetTenantID(tenantID *string)
 	GetConversationID() string
 	SetConversationID(conversationID string)
 	GetPlatform() Platform
@@ -106,6 +109,7 @@ type IMessageModel interface {
 // MessageModel represents a unified message entity across all platforms
 type MessageModel struct {
 	ID                string           `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID          *string          `gorm:"type:varchar(64);index" json:"tenant_id,omitempty"`
 	ConversationID    string           `gorm:"type:uuid;not null;index;references:mcp_conversations(id)" json:"conversation_id"`
 	Platform          Platform         `gorm:"type:text;not null;index" json:"platform" example:"DISCORD"`
 	PlatformMessageID string           `gorm:"type:text;not null;index" json:"platform_message_id" example:"123456789012345678"`
@@ -162,10 +166,17 @@ func NewMessageModel() *MessageModel {
 
 func (m *MessageModel) TableName() string { return "mcp_messages" }
 
+// SearchFields lists the fields of the message search (see services.Repository.Search).
+func (m *MessageModel) SearchFields() []svc.SearchField {
+	return []svc.SearchField{{Name: "content"}, {Name: "sender_name", Weight: 0.6}, {Name: "recipient_name", Weight: 0.4}}
+}
+
 // Basic getters and setters
 
 func (m *MessageModel) GetID() string                           { return m.ID }
 func (m *MessageModel) SetID(id string)                         { m.ID = id }
+func (m *MessageModel) GetTenantID() *string                    { return m.TenantID }
+func (m *MessageModel) SetTenantID(tenantID *string)            { m.TenantID = tenantID }
 func (m *MessageModel) GetConversationID() string               { return m.ConversationID }
 func (m *MessageModel) SetConversationID(conversationID string) { m.ConversationID = conversationID }
 func (m *MessageModel) GetPlatform() Platform                   { return m.Platform }
@@ -1,11 +1,13 @@
 package oauth
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	gl "github.com/kubex-ecosystem/gdbase/internal/module/logger"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +21,7 @@ type IAuthCodeRepo interface {
 	DeleteExpired() error
 	Delete(code string) error
 	Close() error
+	WithContext(ctx context.Context) IAuthCodeRepo
 }
 
 // AuthCodeRepo implements IAuthCodeRepo
@@ -145,6 +148,10 @@ func (r *AuthCodeRepo) Delete(code string) error {
 	return nil
 }
 
+func (r *AuthCodeRepo) WithContext(ctx context.Context) IAuthCodeRepo {
+	return &AuthCodeRepo{db: svc.DBFromContext(ctx, r.db)}
+}
+
 func (r *AuthCodeRepo) Close() error {
 	sqlDB, err := r.db.DB()
 	if err != nil {
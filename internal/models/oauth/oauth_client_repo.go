@@ -20,6 +20,7 @@ type IOAuthClientRepo interface {
 	Update(client IOAuthClient) (IOAuthClient, error)
 	Delete(id string) error
 	Close() error
+	WithContext(ctx context.Context) IOAuthClientRepo
 }
 
 // OAuthClientRepo implements IOAuthClientRepo
@@ -33,7 +34,7 @@ func NewOAuthClientRepo(ctx context.Context, dbService *svc.DBServiceImpl) IOAut
 		gl.Log("error", "OAuthClientRepo: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "oauth_clients")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("OAuthClientRepo: failed to get db: %v", err))
 		return nil
@@ -172,6 +173,10 @@ func (r *OAuthClientRepo) Delete(id string) error {
 	return nil
 }
 
+func (r *OAuthClientRepo) WithContext(ctx context.Context) IOAuthClientRepo {
+	return &OAuthClientRepo{db: svc.DBFromContext(ctx, r.db)}
+}
+
 func (r *OAuthClientRepo) Close() error {
 	sqlDB, err := r.db.DB()
 	if err != nil {
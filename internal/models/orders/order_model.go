@@ -95,6 +95,7 @@ func (oi *OrderItem) SetNotes(v *string)     { oi.Notes = v }
 type IOrder interface {
 	TableName() string
 	GetID() string
+	GetTenantID() *string
 	GetCode() *string
 	GetClientID() string
 	GetUserID() string
@@ -114,7 +115,9 @@ type IOrder interface {
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	GetSyncedAt() *time.Time
+	GetLockVersion() int
 
+	SetTenantID(*string)
 	SetCode(*string)
 	SetClientID(string)
 	SetUserID(string)
@@ -147,6 +150,7 @@ type OrderPayment struct {
 // Order represents a complete order
 type Order struct {
 	ID                 string         `json:"id" xml:"id" yaml:"id" gorm:"column:id;primaryKey"`
+	TenantID           *string        `json:"tenantId,omitempty" xml:"tenantId,omitempty" yaml:"tenantId,omitempty" gorm:"column:tenant_id;type:varchar(64);index"`
 	Code               *string        `json:"code,omitempty" xml:"code,omitempty" yaml:"code,omitempty" gorm:"column:code"`
 	ClientID           string         `json:"clientId" xml:"clientId" yaml:"clientId" gorm:"column:client_id"`
 	UserID             string         `json:"userId" xml:"userId" yaml:"userId" gorm:"column:user_id"`
@@ -166,15 +170,28 @@ type Order struct {
 	CreatedAt          time.Time      `json:"createdAt" xml:"createdAt" yaml:"createdAt" gorm:"column:created_at"`
 	UpdatedAt          time.Time      `json:"updatedAt" xml:"updatedAt" yaml:"updatedAt" gorm:"column:updated_at"`
 	SyncedAt           *time.Time     `json:"syncedAt,omitempty" xml:"syncedAt,omitempty" yaml:"syncedAt,omitempty" gorm:"column:synced_at"`
+	LockVersion        int            `json:"lockVersion" xml:"lockVersion" yaml:"lockVersion" gorm:"column:lock_version;not null;default:0"`
 }
 
 // Métodos de IOrder
 
-func (o *Order) TableName() string   { return "orders" }
-func (o *Order) GetID() string       { return o.ID }
-func (o *Order) GetCode() *string    { return o.Code }
-func (o *Order) GetClientID() string { return o.ClientID }
-func (o *Order) GetUserID() string   { return o.UserID }
+func (o *Order) TableName() string { return "orders" }
+
+// FilterableFields lists the fields accepted by the list filters (see services.ParseFilterExpr).
+func (o *Order) FilterableFields() []string {
+	return []string{"id", "code", "clientId", "userId", "status", "total", "syncStatus", "deliveryDate", "createdAt", "updatedAt"}
+}
+
+// SortableFields lists the fields accepted by the list sort.
+func (o *Order) SortableFields() []string {
+	return []string{"code", "status", "total", "deliveryDate", "createdAt", "updatedAt"}
+}
+
+func (o *Order) GetID() string        { return o.ID }
+func (o *Order) GetTenantID() *string { return o.TenantID }
+func (o *Order) GetCode() *string     { return o.Code }
+func (o *Order) GetClientID() string  { return o.ClientID }
+func (o *Order) GetUserID() string    { return o.UserID }
 func (o *Order) GetItems() []IOrderItem {
 	items := make([]IOrderItem, len(o.Items))
 	for i := range o.Items {
@@ -197,7 +214,9 @@ func (o *Order) GetSyncErrorMessage() *string   { return o.SyncErrorMessage }
 func (o *Order) GetCreatedAt() time.Time        { return o.CreatedAt }
 func (o *Order) GetUpdatedAt() time.Time        { return o.UpdatedAt }
 func (o *Order) GetSyncedAt() *time.Time        { return o.SyncedAt }
+func (o *Order) GetLockVersion() int            { return o.LockVersion }
 func (o *Order) SetCode(v *string)              { o.Code = v }
+func (o *Order) SetTenantID(v *string)          { o.TenantID = v }
 func (o *Order) SetClientID(v string)           { o.ClientID = v }
 func (o *Order) SetUserID(v string)             { o.UserID = v }
 func (o *Order) SetItems(v []IOrderItem) {
@@ -1,8 +1,10 @@
 package orders
 
 import (
+	"context"
 	"fmt"
 
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +17,10 @@ type IOrderRepo interface {
 	Update(o *Order) (*Order, error)
 	Delete(id string) error
 	Close() error
+	// Query returns a page of the orders matching spec (filters, sort and pagination).
+	Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[Order], error)
+	// WithContext returns the repo on the transaction of ctx, if any.
+	WithContext(ctx context.Context) IOrderRepo
 }
 
 type OrderRepo struct {
@@ -61,7 +67,7 @@ func (or *OrderRepo) Update(o *Order) (*Order, error) {
 	if o == nil {
 		return nil, fmt.Errorf("OrderRepo: Order is nil")
 	}
-	err := or.g.Save(o).Error
+	err := svc.SaveOptimistic(or.g, o)
 	if err != nil {
 		return nil, fmt.Errorf("OrderRepo: failed to update Order: %w", err)
 	}
@@ -76,6 +82,18 @@ func (or *OrderRepo) Delete(id string) error {
 	return nil
 }
 
+func (or *OrderRepo) Query(ctx context.Context, spec *svc.QuerySpec) (*svc.Page[Order], error) {
+	page, err := svc.NewRepository[Order](or.g).List(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo: failed to query orders: %w", err)
+	}
+	return page, nil
+}
+
+func (or *OrderRepo) WithContext(ctx context.Context) IOrderRepo {
+	return &OrderRepo{svc.DBFromContext(ctx, or.g)}
+}
+
 func (or *OrderRepo) Close() error {
 	sqlDB, err := or.g.DB()
 	if err != nil {
@@ -1,8 +1,11 @@
 package orders
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 )
 
 type IOrderService interface {
@@ -11,6 +14,8 @@ type IOrderService interface {
 	UpdateOrder(order *Order) (*Order, error)
 	DeleteOrder(id string) error
 	ListOrders() ([]*Order, error)
+	// QueryOrders returns a page of orders filtered and sorted by query (see services.ParseFilterExpr).
+	QueryOrders(ctx context.Context, query svc.ListQuery) (*svc.Page[Order], error)
 }
 
 type OrderService struct {
@@ -63,3 +68,15 @@ func (os *OrderService) ListOrders() ([]*Order, error) {
 	}
 	return orders, nil
 }
+
+func (os *OrderService) QueryOrders(ctx context.Context, query svc.ListQuery) (*svc.Page[Order], error) {
+	spec, err := query.Spec(&Order{})
+	if err != nil {
+		return nil, err
+	}
+	page, err := os.repo.Query(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("error querying orders: %w", err)
+	}
+	return page, nil
+}
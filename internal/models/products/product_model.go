@@ -4,6 +4,7 @@ package products
 import (
 	"time"
 
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	t "github.com/kubex-ecosystem/gdbase/internal/types"
 )
 
@@ -64,6 +65,7 @@ func (c *ProductCategory) SetParentID(pid *string) { c.ParentID = pid }
 // Product represents a product
 type Product struct {
 	ID                    string           `json:"id" xml:"id" yaml:"id" gorm:"column:id;primaryKey"`
+	TenantID              *string          `json:"tenantId,omitempty" xml:"tenantId,omitempty" yaml:"tenantId,omitempty" gorm:"column:tenant_id;type:varchar(64);index"`
 	Code                  string           `json:"code" xml:"code" yaml:"code" gorm:"column:code"`
 	SKU                   string           `json:"sku" xml:"sku" yaml:"sku" gorm:"column:sku"`
 	EAN                   *string          `json:"ean,omitempty" xml:"ean,omitempty" yaml:"ean,omitempty" gorm:"column:ean"`
@@ -89,6 +91,8 @@ type IProduct interface {
 	TableName() string
 	GetID() string
 	SetID(id string)
+	GetTenantID() *string
+	SetTenantID(tenantID *string)
 	GetCode() string
 	SetCode(code string)
 	GetSKU() string
@@ -127,26 +131,34 @@ type IProduct interface {
 	SetUpdatedAt(t time.Time)
 }
 
-func (p *Product) TableName() string          { return "products" }
-func (p *Product) GetID() string              { return p.ID }
-func (p *Product) SetID(id string)            { p.ID = id }
-func (p *Product) GetCode() string            { return p.Code }
-func (p *Product) SetCode(code string)        { p.Code = code }
-func (p *Product) GetSKU() string             { return p.SKU }
-func (p *Product) SetSKU(sku string)          { p.SKU = sku }
-func (p *Product) GetEAN() *string            { return p.EAN }
-func (p *Product) SetEAN(ean *string)         { p.EAN = ean }
-func (p *Product) GetName() string            { return p.Name }
-func (p *Product) SetName(name string)        { p.Name = name }
-func (p *Product) GetDescription() string     { return p.Description }
-func (p *Product) SetDescription(desc string) { p.Description = desc }
-func (p *Product) GetImageURL() *string       { return p.ImageURL }
-func (p *Product) SetImageURL(url *string)    { p.ImageURL = url }
-func (p *Product) GetUnit() string            { return p.Unit }
-func (p *Product) SetUnit(unit string)        { p.Unit = unit }
-func (p *Product) GetWeight() *float64        { return p.Weight }
-func (p *Product) SetWeight(weight *float64)  { p.Weight = weight }
-func (p *Product) GetDimensions() IDimensions { return p.Dimensions }
+func (p *Product) TableName() string { return "products" }
+
+// SearchFields lists the fields of the product search (see services.Repository.Search).
+func (p *Product) SearchFields() []svc.SearchField {
+	return []svc.SearchField{{Name: "name"}, {Name: "sku"}, {Name: "ean"}, {Name: "description", Weight: 0.5}}
+}
+
+func (p *Product) GetID() string                { return p.ID }
+func (p *Product) SetID(id string)              { p.ID = id }
+func (p *Product) GetTenantID() *string         { return p.TenantID }
+func (p *Product) SetTenantID(tenantID *string) { p.TenantID = tenantID }
+func (p *Product) GetCode() string              { return p.Code }
+func (p *Product) SetCode(code string)          { p.Code = code }
+func (p *Product) GetSKU() string               { return p.SKU }
+func (p *Product) SetSKU(sku string)            { p.SKU = sku }
+func (p *Product) GetEAN() *string              { return p.EAN }
+func (p *Product) SetEAN(ean *string)           { p.EAN = ean }
+func (p *Product) GetName() string              { return p.Name }
+func (p *Product) SetName(name string)          { p.Name = name }
+func (p *Product) GetDescription() string       { return p.Description }
+func (p *Product) SetDescription(desc string)   { p.Description = desc }
+func (p *Product) GetImageURL() *string         { return p.ImageURL }
+func (p *Product) SetImageURL(url *string)      { p.ImageURL = url }
+func (p *Product) GetUnit() string              { return p.Unit }
+func (p *Product) SetUnit(unit string)          { p.Unit = unit }
+func (p *Product) GetWeight() *float64          { return p.Weight }
+func (p *Product) SetWeight(weight *float64)    { p.Weight = weight }
+func (p *Product) GetDimensions() IDimensions   { return p.Dimensions }
 func (p *Product) SetDimensions(dim IDimensions) {
 	if v, ok := dim.(*Dimensions); ok {
 		p.Dimensions = v
@@ -16,7 +16,11 @@ type IProductRepo interface {
 	FindAll(where ...interface{}) ([]*Product, error)
 	Update(p *Product) (*Product, error)
 	Delete(id string) error
+	// Search returns the products matching query by name, SKU, EAN or description, best first.
+	Search(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[Product], error)
 	Close() error
+	// WithContext scopes the repo to ctx and its transaction.
+	WithContext(ctx context.Context) IProductRepo
 }
 
 type ProductRepo struct {
@@ -27,7 +31,7 @@ func NewProductRepo(ctx context.Context, dbService *svc.DBServiceImpl) IProductR
 	if dbService == nil {
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "products")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("ProductRepo: failed to get DB: %v", err))
 		return nil
@@ -83,6 +87,18 @@ func (pr *ProductRepo) Delete(id string) error {
 	return nil
 }
 
+func (pr *ProductRepo) Search(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[Product], error) {
+	result, err := svc.NewRepository[Product](pr.g).Search(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ProductRepo: failed to search products: %w", err)
+	}
+	return result, nil
+}
+
+func (pr *ProductRepo) WithContext(ctx context.Context) IProductRepo {
+	return &ProductRepo{svc.DBFromContext(ctx, pr.g)}
+}
+
 func (pr *ProductRepo) Close() error {
 	sqlDB, err := pr.g.DB()
 	if err != nil {
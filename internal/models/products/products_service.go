@@ -1,8 +1,11 @@
 package products
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 )
 
 type IProductService interface {
@@ -11,6 +14,8 @@ type IProductService interface {
 	UpdateProduct(product *Product) (*Product, error)
 	DeleteProduct(id string) error
 	ListProducts() ([]*Product, error)
+	// SearchProducts returns the products matching query (partial names, codes and typos), best first.
+	SearchProducts(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[Product], error)
 }
 
 type ProductService struct {
@@ -63,3 +68,11 @@ func (ps *ProductService) ListProducts() ([]*Product, error) {
 	}
 	return products, nil
 }
+
+func (ps *ProductService) SearchProducts(ctx context.Context, query string, opts svc.SearchOptions) (*svc.SearchResult[Product], error) {
+	result, err := ps.repo.Search(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error searching products: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"github.com/kubex-ecosystem/gdbase/internal/models/auth"
+	"github.com/kubex-ecosystem/gdbase/internal/models/clients"
+	"github.com/kubex-ecosystem/gdbase/internal/models/cron"
+	"github.com/kubex-ecosystem/gdbase/internal/models/discord"
+	jobqueue "github.com/kubex-ecosystem/gdbase/internal/models/job_queue"
+	analysisjobs "github.com/kubex-ecosystem/gdbase/internal/models/mcp/analysis_jobs"
+	"github.com/kubex-ecosystem/gdbase/internal/models/mcp/llm"
+	"github.com/kubex-ecosystem/gdbase/internal/models/mcp/notifications"
+	"github.com/kubex-ecosystem/gdbase/internal/models/mcp/preferences"
+	"github.com/kubex-ecosystem/gdbase/internal/models/mcp/providers"
+	"github.com/kubex-ecosystem/gdbase/internal/models/mcp/tasks"
+	"github.com/kubex-ecosystem/gdbase/internal/models/messaging"
+	"github.com/kubex-ecosystem/gdbase/internal/models/oauth"
+	"github.com/kubex-ecosystem/gdbase/internal/models/orders"
+	"github.com/kubex-ecosystem/gdbase/internal/models/products"
+	"github.com/kubex-ecosystem/gdbase/internal/models/telegram"
+	user "github.com/kubex-ecosystem/gdbase/internal/models/users"
+	"github.com/kubex-ecosystem/gdbase/internal/models/webhooks"
+	"github.com/kubex-ecosystem/gdbase/internal/models/whatsapp"
+	svc "github.com/kubex-ecosystem/gdbase/internal/services"
+)
+
+func init() {
+	// Models backed by a table, checked by `gdbase database diff`.
+	// notification.Notification is kept in memory and has no table.
+	svc.RegisterSchemaModels(
+		&auth.RefreshTokenModel{},
+		&clients.ClientDetailed{},
+		&cron.CronJob{},
+		&discord.DiscordModel{},
+		&jobqueue.JobQueue{},
+		&jobqueue.ExecutionLog{},
+		&analysisjobs.AnalysisJob{},
+		&llm.LLMModel{},
+		&notifications.NotificationRule{},
+		&notifications.NotificationTemplate{},
+		&notifications.NotificationHistory{},
+		&preferences.PreferencesModel{},
+		&providers.ProvidersModel{},
+		&tasks.TasksModel{},
+		&messaging.ConversationModel{},
+		&messaging.MessageModel{},
+		&oauth.OAuthClientModel{},
+		&oauth.AuthCodeModel{},
+		&orders.Order{},
+		&orders.OrderItem{},
+		&products.Product{},
+		&products.ProductCategory{},
+		&products.PriceTable{},
+		&telegram.TelegramModel{},
+		&user.UserModel{},
+		&webhooks.Webhook{},
+		&whatsapp.WhatsAppModel{},
+	)
+}
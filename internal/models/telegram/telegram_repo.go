@@ -34,7 +34,7 @@ type TelegramRepository struct {
 }
 
 func NewTelegramRepository(ctx context.Context, dbService *svc.DBServiceImpl) ITelegramRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "telegram")
 	if err != nil {
 		gl.Log("error", "Failed to get DB from DBService", err)
 		return nil
@@ -50,7 +50,7 @@ func (r *TelegramRepository) Create(ctx context.Context, telegram *TelegramModel
 
 	telegram.Sanitize()
 
-	if err := r.db.WithContext(ctx).Create(telegram).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Create(telegram).Error; err != nil {
 		gl.Log("error", "Failed to create telegram integration", err)
 		return nil, fmt.Errorf("failed to create telegram integration: %w", err)
 	}
@@ -67,7 +67,7 @@ func (r *TelegramRepository) Update(ctx context.Context, telegram *TelegramModel
 
 	telegram.Sanitize()
 
-	if err := r.db.WithContext(ctx).Save(telegram).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Save(telegram).Error; err != nil {
 		gl.Log("error", "Failed to update telegram integration", err)
 		return nil, fmt.Errorf("failed to update telegram integration: %w", err)
 	}
@@ -77,7 +77,7 @@ func (r *TelegramRepository) Update(ctx context.Context, telegram *TelegramModel
 }
 
 func (r *TelegramRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Delete(&TelegramModel{}, "id = ?", id)
+	result := svc.DBFromContext(ctx, r.db).Delete(&TelegramModel{}, "id = ?", id)
 	if result.Error != nil {
 		gl.Log("error", "Failed to delete telegram integration", result.Error)
 		return fmt.Errorf("failed to delete telegram integration: %w", result.Error)
@@ -94,7 +94,7 @@ func (r *TelegramRepository) Delete(ctx context.Context, id string) error {
 
 func (r *TelegramRepository) FindByID(ctx context.Context, id string) (*TelegramModel, error) {
 	var telegram TelegramModel
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&telegram).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("id = ?", id).First(&telegram).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("telegram integration not found")
 		}
@@ -107,7 +107,7 @@ func (r *TelegramRepository) FindByID(ctx context.Context, id string) (*Telegram
 
 func (r *TelegramRepository) FindByTelegramUserID(ctx context.Context, telegramUserID string) (*TelegramModel, error) {
 	var telegram TelegramModel
-	if err := r.db.WithContext(ctx).Where("telegram_user_id = ?", telegramUserID).First(&telegram).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("telegram_user_id = ?", telegramUserID).First(&telegram).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("telegram integration not found for user ID: %s", telegramUserID)
 		}
@@ -120,7 +120,7 @@ func (r *TelegramRepository) FindByTelegramUserID(ctx context.Context, telegramU
 
 func (r *TelegramRepository) FindByUsername(ctx context.Context, username string) (*TelegramModel, error) {
 	var telegram TelegramModel
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&telegram).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("username = ?", username).First(&telegram).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("telegram integration not found for username: %s", username)
 		}
@@ -133,7 +133,7 @@ func (r *TelegramRepository) FindByUsername(ctx context.Context, username string
 
 func (r *TelegramRepository) FindByChatID(ctx context.Context, chatID string) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("chat_id = ?", chatID).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("chat_id = ?", chatID).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find telegram integrations by chat ID", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
@@ -143,7 +143,7 @@ func (r *TelegramRepository) FindByChatID(ctx context.Context, chatID string) ([
 
 func (r *TelegramRepository) FindByUserID(ctx context.Context, userID string) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_id = ?", userID).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find telegram integrations by user ID", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
@@ -153,7 +153,7 @@ func (r *TelegramRepository) FindByUserID(ctx context.Context, userID string) ([
 
 func (r *TelegramRepository) FindByStatus(ctx context.Context, status TelegramStatus) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", status).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find telegram integrations by status", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
@@ -163,7 +163,7 @@ func (r *TelegramRepository) FindByStatus(ctx context.Context, status TelegramSt
 
 func (r *TelegramRepository) FindByIntegrationType(ctx context.Context, integrationType TelegramIntegrationType) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("integration_type = ?", integrationType).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("integration_type = ?", integrationType).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find telegram integrations by integration type", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
@@ -173,7 +173,7 @@ func (r *TelegramRepository) FindByIntegrationType(ctx context.Context, integrat
 
 func (r *TelegramRepository) FindActiveIntegrations(ctx context.Context) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("status = ?", TelegramStatusActive).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", TelegramStatusActive).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find active telegram integrations", err)
 		return nil, fmt.Errorf("failed to find active telegram integrations: %w", err)
 	}
@@ -183,7 +183,7 @@ func (r *TelegramRepository) FindActiveIntegrations(ctx context.Context) ([]*Tel
 
 func (r *TelegramRepository) FindAll(ctx context.Context) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find all telegram integrations", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
@@ -193,7 +193,7 @@ func (r *TelegramRepository) FindAll(ctx context.Context) ([]*TelegramModel, err
 
 func (r *TelegramRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&TelegramModel{}).Count(&count).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&TelegramModel{}).Count(&count).Error; err != nil {
 		gl.Log("error", "Failed to count telegram integrations", err)
 		return 0, fmt.Errorf("failed to count telegram integrations: %w", err)
 	}
@@ -202,7 +202,7 @@ func (r *TelegramRepository) Count(ctx context.Context) (int64, error) {
 }
 
 func (r *TelegramRepository) UpdateStatus(ctx context.Context, id string, status TelegramStatus) error {
-	result := r.db.WithContext(ctx).Model(&TelegramModel{}).Where("id = ?", id).Update("status", status)
+	result := svc.DBFromContext(ctx, r.db).Model(&TelegramModel{}).Where("id = ?", id).Update("status", status)
 	if result.Error != nil {
 		gl.Log("error", "Failed to update telegram integration status", result.Error)
 		return fmt.Errorf("failed to update telegram integration status: %w", result.Error)
@@ -217,7 +217,7 @@ func (r *TelegramRepository) UpdateStatus(ctx context.Context, id string, status
 }
 
 func (r *TelegramRepository) UpdateLastActivity(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Model(&TelegramModel{}).Where("id = ?", id).Update("last_activity", "NOW()")
+	result := svc.DBFromContext(ctx, r.db).Model(&TelegramModel{}).Where("id = ?", id).Update("last_activity", "NOW()")
 	if result.Error != nil {
 		gl.Log("error", "Failed to update telegram integration last activity", result.Error)
 		return fmt.Errorf("failed to update telegram integration last activity: %w", result.Error)
@@ -232,7 +232,7 @@ func (r *TelegramRepository) UpdateLastActivity(ctx context.Context, id string)
 
 func (r *TelegramRepository) FindByTargetTaskID(ctx context.Context, targetTaskID string) ([]*TelegramModel, error) {
 	var telegrams []*TelegramModel
-	if err := r.db.WithContext(ctx).Where("target_task_id = ?", targetTaskID).Find(&telegrams).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("target_task_id = ?", targetTaskID).Find(&telegrams).Error; err != nil {
 		gl.Log("error", "Failed to find telegram integrations by target task ID", err)
 		return nil, fmt.Errorf("failed to find telegram integrations: %w", err)
 	}
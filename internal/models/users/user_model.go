@@ -120,6 +120,22 @@ func (um *UserModel) CheckPasswordHash(password string) bool {
 	}
 	return false
 }
+
+// PrepareSeed hashes the plain password of a seed fixture with SetPassword,
+// keeping the stored hash when the password did not change.
+func (um *UserModel) PrepareSeed(fields map[string]any, existing any) error {
+	if _, ok := fields["password"]; !ok {
+		return nil
+	}
+	if _, err := bcrypt.Cost([]byte(um.Password)); err == nil {
+		return nil // already a hash
+	}
+	if prev, ok := existing.(*UserModel); ok && prev.CheckPasswordHash(um.Password) {
+		um.Password = prev.Password
+		return nil
+	}
+	return um.SetPassword(um.Password)
+}
 func (um *UserModel) Sanitize() {
 	um.Password = ""
 	um.Active = false
@@ -23,6 +23,7 @@ type IUserRepo interface {
 	Close() error
 	List(where ...interface{}) (xtt.TableDataHandler, error)
 	GetContextDBService() *svc.DBServiceImpl
+	WithContext(ctx context.Context) IUserRepo
 }
 
 type UserRepo struct {
@@ -35,7 +36,7 @@ func NewUserRepo(ctx context.Context, dbService *svc.DBServiceImpl) IUserRepo {
 		gl.Log("error", "UserModel repository: dbService is nil")
 		return nil
 	}
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "users")
 	if err != nil {
 		gl.Log("error", fmt.Sprintf("UserModel repository: failed to get DB from dbService: %v", err))
 		return nil
@@ -110,6 +111,10 @@ func (ur *UserRepo) Delete(id string) error {
 	}
 	return nil
 }
+func (ur *UserRepo) WithContext(ctx context.Context) IUserRepo {
+	return &UserRepo{g: svc.DBFromContext(ctx, ur.g)}
+}
+
 func (ur *UserRepo) Close() error {
 	sqlDB, err := ur.g.DB()
 	if err != nil {
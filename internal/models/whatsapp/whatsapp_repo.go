@@ -37,7 +37,7 @@ type WhatsAppRepository struct {
 }
 
 func NewWhatsAppRepository(ctx context.Context, dbService *svc.DBServiceImpl) IWhatsAppRepo {
-	db, err := svc.GetDB(ctx, dbService)
+	db, err := svc.GetDBForRepo(ctx, dbService, "whatsapp")
 	if err != nil {
 		gl.Log("error", "Failed to get DB from DBService", err)
 		return nil
@@ -53,7 +53,7 @@ func (r *WhatsAppRepository) Create(ctx context.Context, whatsapp *WhatsAppModel
 
 	whatsapp.Sanitize()
 
-	if err := r.db.WithContext(ctx).Create(whatsapp).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Create(whatsapp).Error; err != nil {
 		gl.Log("error", "Failed to create WhatsApp integration", err)
 		return nil, fmt.Errorf("failed to create WhatsApp integration: %w", err)
 	}
@@ -70,7 +70,7 @@ func (r *WhatsAppRepository) Update(ctx context.Context, whatsapp *WhatsAppModel
 
 	whatsapp.Sanitize()
 
-	if err := r.db.WithContext(ctx).Save(whatsapp).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Save(whatsapp).Error; err != nil {
 		gl.Log("error", "Failed to update WhatsApp integration", err)
 		return nil, fmt.Errorf("failed to update WhatsApp integration: %w", err)
 	}
@@ -80,7 +80,7 @@ func (r *WhatsAppRepository) Update(ctx context.Context, whatsapp *WhatsAppModel
 }
 
 func (r *WhatsAppRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Delete(&WhatsAppModel{}, "id = ?", id)
+	result := svc.DBFromContext(ctx, r.db).Delete(&WhatsAppModel{}, "id = ?", id)
 	if result.Error != nil {
 		gl.Log("error", "Failed to delete WhatsApp integration", result.Error)
 		return fmt.Errorf("failed to delete WhatsApp integration: %w", result.Error)
@@ -97,7 +97,7 @@ func (r *WhatsAppRepository) Delete(ctx context.Context, id string) error {
 
 func (r *WhatsAppRepository) FindByID(ctx context.Context, id string) (*WhatsAppModel, error) {
 	var whatsapp WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&whatsapp).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("id = ?", id).First(&whatsapp).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("WhatsApp integration not found")
 		}
@@ -110,7 +110,7 @@ func (r *WhatsAppRepository) FindByID(ctx context.Context, id string) (*WhatsApp
 
 func (r *WhatsAppRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*WhatsAppModel, error) {
 	var whatsapp WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("phone_number = ?", phoneNumber).First(&whatsapp).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("phone_number = ?", phoneNumber).First(&whatsapp).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("WhatsApp integration not found for phone number: %s", phoneNumber)
 		}
@@ -123,7 +123,7 @@ func (r *WhatsAppRepository) FindByPhoneNumber(ctx context.Context, phoneNumber
 
 func (r *WhatsAppRepository) FindByPhoneNumberID(ctx context.Context, phoneNumberID string) (*WhatsAppModel, error) {
 	var whatsapp WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("phone_number_id = ?", phoneNumberID).First(&whatsapp).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("phone_number_id = ?", phoneNumberID).First(&whatsapp).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("WhatsApp integration not found for phone number ID: %s", phoneNumberID)
 		}
@@ -136,7 +136,7 @@ func (r *WhatsAppRepository) FindByPhoneNumberID(ctx context.Context, phoneNumbe
 
 func (r *WhatsAppRepository) FindByBusinessID(ctx context.Context, businessID string) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("whatsapp_business_id = ?", businessID).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("whatsapp_business_id = ?", businessID).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by business ID", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -146,7 +146,7 @@ func (r *WhatsAppRepository) FindByBusinessID(ctx context.Context, businessID st
 
 func (r *WhatsAppRepository) FindByUserID(ctx context.Context, userID string) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_id = ?", userID).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by user ID", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -156,7 +156,7 @@ func (r *WhatsAppRepository) FindByUserID(ctx context.Context, userID string) ([
 
 func (r *WhatsAppRepository) FindByStatus(ctx context.Context, status WhatsAppStatus) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", status).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by status", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -166,7 +166,7 @@ func (r *WhatsAppRepository) FindByStatus(ctx context.Context, status WhatsAppSt
 
 func (r *WhatsAppRepository) FindByIntegrationType(ctx context.Context, integrationType WhatsAppIntegrationType) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("integration_type = ?", integrationType).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("integration_type = ?", integrationType).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by integration type", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -176,7 +176,7 @@ func (r *WhatsAppRepository) FindByIntegrationType(ctx context.Context, integrat
 
 func (r *WhatsAppRepository) FindActiveIntegrations(ctx context.Context) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("status = ?", WhatsAppStatusActive).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("status = ?", WhatsAppStatusActive).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find active WhatsApp integrations", err)
 		return nil, fmt.Errorf("failed to find active WhatsApp integrations: %w", err)
 	}
@@ -186,7 +186,7 @@ func (r *WhatsAppRepository) FindActiveIntegrations(ctx context.Context) ([]*Wha
 
 func (r *WhatsAppRepository) FindBusinessIntegrations(ctx context.Context) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("user_type = ?", WhatsAppUserTypeBusiness).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("user_type = ?", WhatsAppUserTypeBusiness).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find business WhatsApp integrations", err)
 		return nil, fmt.Errorf("failed to find business WhatsApp integrations: %w", err)
 	}
@@ -196,7 +196,7 @@ func (r *WhatsAppRepository) FindBusinessIntegrations(ctx context.Context) ([]*W
 
 func (r *WhatsAppRepository) FindAll(ctx context.Context) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find all WhatsApp integrations", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -206,7 +206,7 @@ func (r *WhatsAppRepository) FindAll(ctx context.Context) ([]*WhatsAppModel, err
 
 func (r *WhatsAppRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&WhatsAppModel{}).Count(&count).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Model(&WhatsAppModel{}).Count(&count).Error; err != nil {
 		gl.Log("error", "Failed to count WhatsApp integrations", err)
 		return 0, fmt.Errorf("failed to count WhatsApp integrations: %w", err)
 	}
@@ -215,7 +215,7 @@ func (r *WhatsAppRepository) Count(ctx context.Context) (int64, error) {
 }
 
 func (r *WhatsAppRepository) UpdateStatus(ctx context.Context, id string, status WhatsAppStatus) error {
-	result := r.db.WithContext(ctx).Model(&WhatsAppModel{}).Where("id = ?", id).Update("status", status)
+	result := svc.DBFromContext(ctx, r.db).Model(&WhatsAppModel{}).Where("id = ?", id).Update("status", status)
 	if result.Error != nil {
 		gl.Log("error", "Failed to update WhatsApp integration status", result.Error)
 		return fmt.Errorf("failed to update WhatsApp integration status: %w", result.Error)
@@ -230,7 +230,7 @@ func (r *WhatsAppRepository) UpdateStatus(ctx context.Context, id string, status
 }
 
 func (r *WhatsAppRepository) UpdateLastActivity(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Model(&WhatsAppModel{}).Where("id = ?", id).Update("last_activity", "NOW()")
+	result := svc.DBFromContext(ctx, r.db).Model(&WhatsAppModel{}).Where("id = ?", id).Update("last_activity", "NOW()")
 	if result.Error != nil {
 		gl.Log("error", "Failed to update WhatsApp integration last activity", result.Error)
 		return fmt.Errorf("failed to update WhatsApp integration last activity: %w", result.Error)
@@ -245,7 +245,7 @@ func (r *WhatsAppRepository) UpdateLastActivity(ctx context.Context, id string)
 
 func (r *WhatsAppRepository) FindByTargetTaskID(ctx context.Context, targetTaskID string) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("target_task_id = ?", targetTaskID).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("target_task_id = ?", targetTaskID).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by target task ID", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -255,7 +255,7 @@ func (r *WhatsAppRepository) FindByTargetTaskID(ctx context.Context, targetTaskI
 
 func (r *WhatsAppRepository) FindByAppID(ctx context.Context, appID string) ([]*WhatsAppModel, error) {
 	var whatsapps []*WhatsAppModel
-	if err := r.db.WithContext(ctx).Where("app_id = ?", appID).Find(&whatsapps).Error; err != nil {
+	if err := svc.DBFromContext(ctx, r.db).Where("app_id = ?", appID).Find(&whatsapps).Error; err != nil {
 		gl.Log("error", "Failed to find WhatsApp integrations by app ID", err)
 		return nil, fmt.Errorf("failed to find WhatsApp integrations: %w", err)
 	}
@@ -270,7 +270,7 @@ func (r *WhatsAppRepository) UpdateAccessToken(ctx context.Context, id string, a
 		"updated_at":       "NOW()",
 	}
 
-	result := r.db.WithContext(ctx).Model(&WhatsAppModel{}).Where("id = ?", id).Updates(updates)
+	result := svc.DBFromContext(ctx, r.db).Model(&WhatsAppModel{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
 		gl.Log("error", "Failed to update WhatsApp integration access token", result.Error)
 		return fmt.Errorf("failed to update WhatsApp integration access token: %w", result.Error)
@@ -71,6 +71,8 @@ func (m *GDBase) Command() *cobra.Command {
 	cmd.AddCommand(version.CliCommand())
 	cmd.AddCommand(cli.DockerCmd())
 	cmd.AddCommand(cli.DatabaseCmd())
+	cmd.AddCommand(cli.DataCmd())
+	cmd.AddCommand(cli.GenCmd())
 	cmd.AddCommand(cli.UtilsCmds())
 	cmd.AddCommand(cli.SSHCmds())
 
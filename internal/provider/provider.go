@@ -1,7 +1,10 @@
 // Package provider defines interfaces and types for managing service providers like Postgres, MongoDB, Redis, and RabbitMQ.
 package provider
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 type Engine string
 
@@ -30,11 +33,41 @@ type Capabilities struct {
 	Features map[string]bool // ex: "extensions.pgcrypto": true
 }
 
+// Missing returns the features of required the capabilities lack. A feature
+// scoped to a service ("pg:extensions.pgvector") is looked up in the features
+// of that service when the provider reports them per service ("pg:..."), and
+// in the provider features otherwise
+func (c Capabilities) Missing(required []string) []string {
+	var missing []string
+	for _, f := range required {
+		if c.Features[f] {
+			continue
+		}
+		service, feature, scoped := strings.Cut(f, ":")
+		if scoped && !c.perService(service) && c.Features[feature] {
+			continue
+		}
+		missing = append(missing, f)
+	}
+	return missing
+}
+
+// perService tells whether the features of service are reported apart
+func (c Capabilities) perService(service string) bool {
+	for f := range c.Features {
+		if strings.HasPrefix(f, service+":") {
+			return true
+		}
+	}
+	return false
+}
+
 type StartSpec struct {
 	Services      []ServiceRef // quais serviços subir/anexar
 	PreferredPort map[string]int
 	Secrets       map[string]string // senhas já geradas pelo GoBE
 	Labels        map[string]string // rastreabilidade
+	Requires      []string          // features exigidas (ex: "volumes.persist", "extensions.pgvector")
 }
 
 type Provider interface {
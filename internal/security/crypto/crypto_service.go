@@ -411,6 +411,8 @@ func EncodeBase64(data []byte) string {
 }
 
 // DecodeBase64 decodes a Base64 URL encoded string
+// The decoded bytes are returned untrimmed: keys and ciphertexts are binary
+// and may start or end with whitespace bytes
 func DecodeBase64(encoded string) ([]byte, error) {
 	decodedData, err := base64.
 		URLEncoding.
@@ -423,8 +425,6 @@ func DecodeBase64(encoded string) ([]byte, error) {
 		return nil, err
 	}
 
-	decodedData = bytes.TrimSpace(decodedData)
-
 	if len(decodedData) == 0 {
 		return nil, fmt.Errorf("decoded data is empty")
 	}
@@ -0,0 +1,865 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	crp "github.com/kubex-ecosystem/gdbase/internal/security/crypto"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"golang.org/x/crypto/chacha20poly1305"
+	"gorm.io/gorm"
+)
+
+// A backup is one file plus its manifest (<id>.manifest.json) in the backup
+// directory. The file is the raw dump (pg_dump custom format for the Postgres
+// managed by gdbase, a copy of the database for SQLite), optionally gzipped
+// and then encrypted with the CryptoService.
+//
+// Encrypted backups are written in chunks of BackupChunkSize bytes (see
+// backupEncrypter), so that neither side holds the whole dump in memory.
+
+const (
+	// DefaultBackupDir is where the backups are written when no dir is given.
+	DefaultBackupDir = "$HOME/.kubex/gdbase/backups"
+	// DefaultBackupContainer is the container of the Postgres managed by gdbase.
+	DefaultBackupContainer = "gdbase-pg"
+	// BackupChunkSize is the size of the encrypted chunks.
+	BackupChunkSize = 1 << 20
+
+	backupEncHeader  = "GDBASE-BACKUP-ENC 1"
+	backupChunkMagic = "GDBK"
+)
+
+// Formats of the dump in a backup.
+const (
+	BackupFormatPgDump = "pg_dump"
+	BackupFormatSQLite = "sqlite"
+)
+
+// BackupManifest describes a backup file. Checksum is the sha256 of the file
+// as stored, ContentChecksum the sha256 of the dump before compression and
+// encryption.
+type BackupManifest struct {
+	ID              string    `json:"id" yaml:"id"`
+	Database        string    `json:"database" yaml:"database"`
+	Dialect         string    `json:"dialect" yaml:"dialect"`
+	Source          string    `json:"source" yaml:"source"`
+	Format          string    `json:"format" yaml:"format"`
+	SchemaVersion   int64     `json:"schemaVersion" yaml:"schemaVersion"`
+	Compression     string    `json:"compression,omitempty" yaml:"compression,omitempty"`
+	Encrypted       bool      `json:"encrypted" yaml:"encrypted"`
+	File            string    `json:"file" yaml:"file"`
+	Size            int64     `json:"size" yaml:"size"`
+	Checksum        string    `json:"checksum" yaml:"checksum"`
+	ContentChecksum string    `json:"contentChecksum" yaml:"contentChecksum"`
+	CreatedAt       time.Time `json:"createdAt" yaml:"createdAt"`
+
+	// dir the manifest was read from
+	dir string
+}
+
+// Path returns the path of the backup file.
+func (m *BackupManifest) Path() string { return filepath.Join(m.dir, m.File) }
+
+// BackupRetention is the retention policy of the backups of a database: keep
+// at most KeepLast backups and none older than MaxAge (zero disables each
+// rule). The newest backup is never removed.
+type BackupRetention struct {
+	KeepLast int           `json:"keepLast,omitempty" yaml:"keepLast,omitempty"`
+	MaxAge   time.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+}
+
+// BackupOptions configures Backup.
+type BackupOptions struct {
+	// Dir defaults to DefaultBackupDir
+	Dir      string
+	Compress bool
+	// EncryptionKey is a 32 byte key, raw or base64 (empty disables encryption)
+	EncryptionKey []byte
+	// Container defaults to DefaultBackupContainer
+	Container string
+	Retention BackupRetention
+}
+
+// RestoreOptions configures Restore.
+type RestoreOptions struct {
+	EncryptionKey []byte
+	Container     string
+	// Force skips the schema version check
+	Force bool
+}
+
+// Backup dumps the selected database into opts.Dir, writes its manifest and
+// applies the retention policy.
+func (d *DBServiceImpl) Backup(ctx context.Context, opts BackupOptions) (*BackupManifest, error) {
+	dbConf, err := d.selectedDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	var key []byte
+	if len(opts.EncryptionKey) > 0 {
+		if key, err = backupKey(opts.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+	dialect := NormalizeDialect(db.Dialector.Name())
+	version, err := schemaVersion(ctx, db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	dir := os.ExpandEnv(opts.Dir)
+	if dir == "" {
+		dir = os.ExpandEnv(DefaultBackupDir)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("❌ Erro ao criar diretório de backup '%s': %v", dir, err)
+	}
+
+	now := time.Now().UTC()
+	m := &BackupManifest{
+		ID:            fmt.Sprintf("%s-%s%03dZ", backupFileName(dbConf.Name), now.Format("20060102T150405"), now.Nanosecond()/1e6),
+		Database:      dbConf.Name,
+		Dialect:       dialect,
+		SchemaVersion: version,
+		CreatedAt:     now,
+		dir:           dir,
+	}
+	rawPath := filepath.Join(dir, "."+m.ID+".raw")
+	defer os.Remove(rawPath)
+
+	ext := ""
+	switch dialect {
+	case DialectPostgres:
+		container := defaultString(opts.Container, DefaultBackupContainer)
+		m.Format, m.Source, ext = BackupFormatPgDump, fmt.Sprintf("docker://%s/%s", container, dbConf.Name), ".dump"
+		if err := dumpPostgres(ctx, container, dbConf, rawPath); err != nil {
+			return nil, err
+		}
+	case DialectSQLite:
+		m.Format, m.Source, ext = BackupFormatSQLite, "sqlite://"+sqliteFile(dbConf), ".db"
+		// VACUUM INTO copies a consistent snapshot without blocking the writers
+		if err := db.Exec("VACUUM INTO ?", rawPath).Error; err != nil {
+			return nil, fmt.Errorf("❌ Erro ao copiar banco SQLite: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("❌ Backup não suportado para '%s' (use o Postgres gerenciado pelo gdbase ou SQLite)", dialect)
+	}
+
+	if opts.Compress {
+		m.Compression, ext = "gzip", ext+".gz"
+	}
+	if key != nil {
+		m.Encrypted, ext = true, ext+".enc"
+	}
+	m.File = m.ID + ext
+	if err := encodeBackup(rawPath, m, key); err != nil {
+		_ = os.Remove(m.Path())
+		return nil, err
+	}
+	if err := writeBackupManifest(m); err != nil {
+		_ = os.Remove(m.Path())
+		return nil, err
+	}
+	gl.Log("info", fmt.Sprintf("💾 Backup de '%s' criado: %s (%d bytes)", m.Database, m.Path(), m.Size))
+
+	if opts.Retention != (BackupRetention{}) {
+		if _, err := PruneBackups(dir, m.Database, opts.Retention); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// Restore applies the backup at path (the backup file or its manifest) to the
+// selected database, after checking the checksums and that the schema
+// version of the database is compatible with the backup.
+func (d *DBServiceImpl) Restore(ctx context.Context, path string, opts RestoreOptions) (*BackupManifest, error) {
+	m, err := ReadBackupManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyBackup(m); err != nil {
+		return nil, err
+	}
+	var key []byte
+	if m.Encrypted {
+		if len(opts.EncryptionKey) == 0 {
+			return nil, fmt.Errorf("❌ O backup '%s' está criptografado: informe a chave", m.ID)
+		}
+		if key, err = backupKey(opts.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+	dbConf, err := d.selectedDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	dialect := NormalizeDialect(db.Dialector.Name())
+	if dialect != m.Dialect {
+		return nil, fmt.Errorf("❌ O backup '%s' é de %s e o banco '%s' é %s", m.ID, m.Dialect, dbConf.Name, dialect)
+	}
+	if !opts.Force {
+		version, err := schemaVersion(ctx, db, dialect)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckBackupCompatible(m, version); err != nil {
+			return nil, err
+		}
+	}
+
+	rawPath := filepath.Join(os.TempDir(), fmt.Sprintf("gdbase-restore-%d.raw", time.Now().UnixNano()))
+	defer os.Remove(rawPath)
+	if err := decodeBackup(m, key, rawPath); err != nil {
+		return nil, err
+	}
+
+	switch dialect {
+	case DialectPostgres:
+		err = restorePostgres(ctx, defaultString(opts.Container, DefaultBackupContainer), dbConf, rawPath)
+	case DialectSQLite:
+		err = restoreSQLite(ctx, db, rawPath)
+	default:
+		err = fmt.Errorf("❌ Restore não suportado para '%s'", dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	gl.Log("info", fmt.Sprintf("♻️ Backup '%s' restaurado em '%s'", m.ID, dbConf.Name))
+	return m, nil
+}
+
+// CheckBackupCompatible reports whether a backup can be restored over a
+// database at schema version current: the versions must match, unless the
+// database has no migrations applied yet.
+func CheckBackupCompatible(m *BackupManifest, current int64) error {
+	if current == 0 || current == m.SchemaVersion {
+		return nil
+	}
+	if current < m.SchemaVersion {
+		return fmt.Errorf("❌ O backup '%s' está na versão de schema %d e o banco na %d: rode 'gdbase database migrate up' antes (ou use --force)", m.ID, m.SchemaVersion, current)
+	}
+	return fmt.Errorf("❌ O backup '%s' está na versão de schema %d e o banco na %d: faça o rollback das migrações ou use --force", m.ID, m.SchemaVersion, current)
+}
+
+// ListBackups returns the manifests in dir, newest first.
+func ListBackups(dir string) ([]*BackupManifest, error) {
+	dir = os.ExpandEnv(dir)
+	if dir == "" {
+		dir = os.ExpandEnv(DefaultBackupDir)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []*BackupManifest
+	for _, p := range paths {
+		m, err := ReadBackupManifest(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// PruneBackups removes the backups of database in dir that fall outside the
+// retention policy and returns them.
+func PruneBackups(dir, database string, policy BackupRetention) ([]*BackupManifest, error) {
+	all, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []*BackupManifest
+	kept := 0
+	for _, m := range all {
+		if m.Database != database {
+			continue
+		}
+		expired := policy.MaxAge > 0 && time.Since(m.CreatedAt) > policy.MaxAge
+		if kept == 0 || ((policy.KeepLast <= 0 || kept < policy.KeepLast) && !expired) {
+			kept++
+			continue
+		}
+		for _, p := range []string{m.Path(), backupManifestPath(m)} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("❌ Erro ao remover backup '%s': %v", m.ID, err)
+			}
+		}
+		gl.Log("info", fmt.Sprintf("🗑️ Backup '%s' removido pela política de retenção", m.ID))
+		removed = append(removed, m)
+	}
+	return removed, nil
+}
+
+// ReadBackupManifest reads the manifest at path, or the manifest of the
+// backup file at path.
+func ReadBackupManifest(path string) (*BackupManifest, error) {
+	manifestPath := path
+	if !strings.HasSuffix(path, ".manifest.json") {
+		manifestPath = filepath.Join(filepath.Dir(path), backupID(filepath.Base(path))+".manifest.json")
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao ler manifesto do backup '%s': %v", path, err)
+	}
+	var m BackupManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("❌ Manifesto inválido '%s': %v", manifestPath, err)
+	}
+	m.dir = filepath.Dir(manifestPath)
+	return &m, nil
+}
+
+// VerifyBackup checks the size and checksum of the backup file against its
+// manifest.
+func VerifyBackup(m *BackupManifest) error {
+	f, err := os.Open(m.Path())
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao abrir backup '%s': %v", m.ID, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler backup '%s': %v", m.ID, err)
+	}
+	if sum := "sha256:" + hex.EncodeToString(h.Sum(nil)); n != m.Size || sum != m.Checksum {
+		return fmt.Errorf("❌ Backup '%s' corrompido: checksum %s, esperado %s", m.ID, sum, m.Checksum)
+	}
+	return nil
+}
+
+// ## Postgres
+
+// backupExec runs a command of the docker CLI, streaming stdin and stdout (a
+// var so the tests can replace it).
+var backupExec = func(ctx context.Context, env []string, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin, cmd.Stdout = stdin, stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %v: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// pgExecArgs returns the docker exec arguments running tool in container
+// against dbConf. The password goes through the environment of the docker
+// CLI, never through its arguments.
+func pgExecArgs(ctx context.Context, container string, dbConf *ti.Database, tool string, toolArgs ...string) ([]string, []string, error) {
+	if !IsServiceRunning(container) {
+		return nil, nil, fmt.Errorf("❌ O container '%s' não está rodando", container)
+	}
+	if err := checkManagedPostgres(ctx, container, dbConf); err != nil {
+		return nil, nil, err
+	}
+	args := []string{"exec", "-i"}
+	var env []string
+	if dbConf.Password != "" {
+		pass, err := ti.ResolveSecret(ctx, dbConf.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		args, env = append(args, "-e", "PGPASSWORD"), []string{"PGPASSWORD=" + pass}
+	}
+	args = append(args, container, tool, "-U", defaultString(dbConf.Username, "postgres"), "-d", dbConf.Name)
+	return append(args, toolArgs...), env, nil
+}
+
+// checkManagedPostgres refuses a database that is not served by container:
+// the host must be local and the port the one the container publishes its
+// 5432 on, otherwise pg_dump/pg_restore would run against another database.
+func checkManagedPostgres(ctx context.Context, container string, dbConf *ti.Database) error {
+	switch strings.Trim(strings.ToLower(dbConf.Host), "[]") {
+	case "", "localhost", "127.0.0.1", "::1", "0.0.0.0":
+	default:
+		return fmt.Errorf("❌ O banco '%s' está em '%s', não no container '%s' gerenciado pelo gdbase", dbConf.Name, dbConf.Host, container)
+	}
+	var out bytes.Buffer
+	if err := backupExec(ctx, nil, nil, &out, "docker", "port", container, "5432/tcp"); err != nil {
+		return fmt.Errorf("❌ Erro ao consultar a porta do container '%s': %v", container, err)
+	}
+	port := portString(dbConf.Port, "5432")
+	var published []string
+	for _, line := range strings.Fields(out.String()) {
+		p := line[strings.LastIndex(line, ":")+1:]
+		if p == port {
+			return nil
+		}
+		published = append(published, p)
+	}
+	return fmt.Errorf("❌ O banco '%s' usa a porta %s, mas o container '%s' publica o Postgres em [%s]", dbConf.Name, port, container, strings.Join(published, ", "))
+}
+
+func dumpPostgres(ctx context.Context, container string, dbConf *ti.Database, rawPath string) error {
+	args, env, err := pgExecArgs(ctx, container, dbConf, "pg_dump", "-Fc")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(rawPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := backupExec(ctx, env, nil, f, "docker", args...); err != nil {
+		return fmt.Errorf("❌ Erro ao executar pg_dump: %v", err)
+	}
+	return f.Close()
+}
+
+func restorePostgres(ctx context.Context, container string, dbConf *ti.Database, rawPath string) error {
+	args, env, err := pgExecArgs(ctx, container, dbConf, "pg_restore", "--clean", "--if-exists", "--no-owner", "--single-transaction")
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(rawPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := backupExec(ctx, env, f, io.Discard, "docker", args...); err != nil {
+		return fmt.Errorf("❌ Erro ao executar pg_restore: %v", err)
+	}
+	return nil
+}
+
+// ## SQLite
+
+// restoreSQLite copies the database at rawPath over the live database with
+// the online backup API of SQLite, so the open connections see the restored
+// data.
+func restoreSQLite(ctx context.Context, db *gorm.DB, rawPath string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	dst, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	srcDB, err := sql.Open("sqlite3", "file:"+rawPath+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	src, err := srcDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = dst.Raw(func(dstConn any) error {
+		return src.Raw(func(srcConn any) error {
+			to, ok1 := dstConn.(*sqlite3.SQLiteConn)
+			from, ok2 := srcConn.(*sqlite3.SQLiteConn)
+			if !ok1 || !ok2 {
+				return fmt.Errorf("driver SQLite sem suporte à API de backup")
+			}
+			b, err := to.Backup("main", from, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := b.Step(-1)
+				if err != nil {
+					_ = b.Finish()
+					return err
+				}
+				if done {
+					return b.Finish()
+				}
+			}
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao restaurar banco SQLite: %v", err)
+	}
+	return nil
+}
+
+func sqliteFile(dbConf *ti.Database) string {
+	return defaultString(dbConf.FilePath, dbConf.Path)
+}
+
+// ## Encoding
+
+// encodeBackup writes the dump at rawPath to m.Path(), compressing and
+// encrypting it as the manifest says, and fills in the checksums.
+func encodeBackup(rawPath string, m *BackupManifest, key []byte) error {
+	in, err := os.Open(rawPath)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler dump: %v", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(m.Path(), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao criar arquivo de backup: %v", err)
+	}
+	defer out.Close()
+
+	fileHash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(out, fileHash)}
+	var w io.WriteCloser = nopWriteCloser{counter}
+	var closers []io.Closer
+	if key != nil {
+		enc, err := newBackupEncrypter(w, key)
+		if err != nil {
+			return err
+		}
+		w = enc
+		closers = append(closers, enc)
+	}
+	if m.Compression == "gzip" {
+		gz := gzip.NewWriter(w)
+		w = gz
+		closers = append([]io.Closer{gz}, closers...)
+	}
+	contentHash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, contentHash), in); err != nil {
+		return fmt.Errorf("❌ Erro ao gravar backup: %v", err)
+	}
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			return fmt.Errorf("❌ Erro ao gravar backup: %v", err)
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	m.Size = counter.n
+	m.Checksum = "sha256:" + hex.EncodeToString(fileHash.Sum(nil))
+	m.ContentChecksum = "sha256:" + hex.EncodeToString(contentHash.Sum(nil))
+	return nil
+}
+
+// decodeBackup writes the dump of m to rawPath and checks its checksum.
+func decodeBackup(m *BackupManifest, key []byte, rawPath string) error {
+	in, err := os.Open(m.Path())
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao abrir backup '%s': %v", m.ID, err)
+	}
+	defer in.Close()
+	var r io.Reader = in
+	if m.Encrypted {
+		if r, err = newBackupDecrypter(in, key); err != nil {
+			return err
+		}
+	}
+	if m.Compression == "gzip" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("❌ Backup '%s' inválido: %v", m.ID, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	out, err := os.OpenFile(rawPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), r); err != nil {
+		return fmt.Errorf("❌ Erro ao ler backup '%s': %v", m.ID, err)
+	}
+	if sum := "sha256:" + hex.EncodeToString(h.Sum(nil)); sum != m.ContentChecksum {
+		return fmt.Errorf("❌ Conteúdo do backup '%s' não confere: checksum %s, esperado %s", m.ID, sum, m.ContentChecksum)
+	}
+	return out.Close()
+}
+
+// backupKey normalizes a backup key (32 raw bytes or their base64, standard
+// or URL) to the unpadded URL base64 the CryptoService decodes unambiguously.
+func backupKey(key []byte) ([]byte, error) {
+	s := strings.TrimSpace(string(key))
+	raw := []byte(s)
+	for _, enc := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == 32 {
+			raw = b
+			break
+		}
+	}
+	if !crp.NewCryptoServiceType().IsKeyValid(raw) {
+		return nil, fmt.Errorf("❌ Chave de backup inválida: use 32 bytes (ou o base64 deles)")
+	}
+	return []byte(crp.EncodeBase64(raw)), nil
+}
+
+// The encrypted file is envelope encrypted: a random data key, wrapped by the
+// CryptoService with the backup key, goes in the header, and the chunks are
+// sealed with the data key (XChaCha20-Poly1305, the algorithm of the service)
+// as records of flag + length + nonce + ciphertext. The chunk index and the
+// last chunk flag are authenticated, so reordered or truncated files fail.
+
+const (
+	backupRecordData byte = 'D'
+	backupRecordLast byte = 'L'
+)
+
+// wrapBackupKey generates a data key and returns it with its wrapped form.
+func wrapBackupKey(key []byte) ([]byte, string, error) {
+	cs := crp.NewCryptoServiceType()
+	dataKey, err := cs.GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	_, wrapped, err := cs.Encrypt([]byte(crp.EncodeBase64(dataKey)), key)
+	if err != nil {
+		return nil, "", fmt.Errorf("❌ Erro ao criptografar backup: %v", err)
+	}
+	return dataKey, wrapped, nil
+}
+
+func unwrapBackupKey(wrapped string, key []byte) ([]byte, error) {
+	if len(wrapped) < 64 || !backupLineRe.MatchString(wrapped) {
+		return nil, fmt.Errorf("chave de dados inválida")
+	}
+	dataKey, _, err := crp.NewCryptoServiceType().Decrypt([]byte(wrapped), key)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("chave de dados inválida")
+	}
+	return []byte(dataKey), nil
+}
+
+var backupLineRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func backupChunkAAD(index uint64, flag byte) []byte {
+	return append(binary.BigEndian.AppendUint64([]byte(backupChunkMagic), index), flag)
+}
+
+type backupEncrypter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+}
+
+func newBackupEncrypter(w io.Writer, key []byte) (*backupEncrypter, error) {
+	dataKey, wrapped, err := wrapBackupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, backupEncHeader+"\n"+wrapped+"\n"); err != nil {
+		return nil, err
+	}
+	return &backupEncrypter{w: w, aead: aead}, nil
+}
+
+func (e *backupEncrypter) Write(p []byte) (int, error) {
+	e.buf = append(e.buf, p...)
+	// the last chunk is sealed by Close
+	for len(e.buf) > BackupChunkSize {
+		if err := e.seal(e.buf[:BackupChunkSize], backupRecordData); err != nil {
+			return 0, err
+		}
+		e.buf = e.buf[BackupChunkSize:]
+	}
+	return len(p), nil
+}
+
+func (e *backupEncrypter) Close() error {
+	err := e.seal(e.buf, backupRecordLast)
+	e.buf = nil
+	return err
+}
+
+func (e *backupEncrypter) seal(chunk []byte, flag byte) error {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(chunk)+e.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := e.aead.Seal(nonce, nonce, chunk, backupChunkAAD(e.index, flag))
+	e.index++
+	head := binary.BigEndian.AppendUint32([]byte{flag}, uint32(len(sealed)))
+	if _, err := e.w.Write(head); err != nil {
+		return err
+	}
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+type backupDecrypter struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	done  bool
+}
+
+func newBackupDecrypter(r io.Reader, key []byte) (*backupDecrypter, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	header, err := br.ReadString('\n')
+	if err != nil || strings.TrimSpace(header) != backupEncHeader {
+		return nil, fmt.Errorf("❌ Backup criptografado inválido: cabeçalho ausente")
+	}
+	wrapped, err := br.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("❌ Backup criptografado inválido: chave de dados ausente")
+	}
+	dataKey, err := unwrapBackupKey(strings.TrimSpace(wrapped), key)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao descriptografar backup (chave incorreta?): %v", err)
+	}
+	aead, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &backupDecrypter{r: br, aead: aead}, nil
+}
+
+func (d *backupDecrypter) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *backupDecrypter) open() error {
+	head := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, head); err != nil {
+		return fmt.Errorf("❌ Backup criptografado truncado no bloco %d", d.index+1)
+	}
+	flag, size := head[0], binary.BigEndian.Uint32(head[1:])
+	nonceSize := d.aead.NonceSize()
+	if (flag != backupRecordData && flag != backupRecordLast) || size < uint32(nonceSize+d.aead.Overhead()) || size > BackupChunkSize+1024 {
+		return fmt.Errorf("❌ Bloco %d do backup inválido", d.index+1)
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("❌ Backup criptografado truncado no bloco %d", d.index+1)
+	}
+	chunk, err := d.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], backupChunkAAD(d.index, flag))
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao descriptografar bloco %d do backup: %v", d.index+1, err)
+	}
+	d.buf, d.index, d.done = chunk, d.index+1, flag == backupRecordLast
+	if d.done {
+		if _, err := d.r.Peek(1); err != io.EOF {
+			return fmt.Errorf("❌ Backup criptografado inválido: dados após o último bloco")
+		}
+	}
+	return nil
+}
+
+// ## Helpers
+
+func schemaVersion(ctx context.Context, db *gorm.DB, dialect string) (int64, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	m, err := NewMigrator(sqlDB, dialect)
+	if err != nil {
+		return 0, err
+	}
+	v, err := m.Version(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("❌ Erro ao obter versão do schema: %v", err)
+	}
+	return v, nil
+}
+
+func writeBackupManifest(m *BackupManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := backupManifestPath(m)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("❌ Erro ao gravar manifesto do backup: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func backupManifestPath(m *BackupManifest) string {
+	return filepath.Join(m.dir, m.ID+".manifest.json")
+}
+
+// backupID strips the extensions of a backup file name.
+func backupID(file string) string {
+	for _, ext := range []string{".enc", ".gz", ".dump", ".db"} {
+		file = strings.TrimSuffix(file, ext)
+	}
+	return file
+}
+
+var backupNameRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func backupFileName(name string) string {
+	if name = backupNameRe.ReplaceAllString(name, "_"); name == "" {
+		return "database"
+	}
+	return name
+}
+
+func defaultString(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
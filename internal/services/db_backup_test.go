@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	crp "github.com/kubex-ecosystem/gdbase/internal/security/crypto"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+)
+
+func TestBackupRestoreSQLite(t *testing.T) {
+	ctx := Using(context.Background(), "transactional")
+	d := newRoutingTestService(t)
+	db := d.db["transactional"]
+	if err := db.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)").Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := db.Exec("INSERT INTO notes (body) VALUES ('first'), ('second')").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	dir := t.TempDir()
+	key := crp.EncodeBase64(bytes.Repeat([]byte{7}, 32))
+	m, err := d.Backup(ctx, BackupOptions{Dir: dir, Compress: true, EncryptionKey: []byte(key)})
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if m.Database != "transactional" || m.Format != BackupFormatSQLite || !m.Encrypted || m.Compression != "gzip" ||
+		!strings.HasSuffix(m.File, ".db.gz.enc") || !strings.HasPrefix(m.Checksum, "sha256:") {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+
+	if err := db.Exec("DELETE FROM notes").Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := d.Restore(ctx, m.Path(), RestoreOptions{}); err == nil {
+		t.Fatalf("expected a missing key error")
+	}
+	if _, err := d.Restore(ctx, m.Path(), RestoreOptions{EncryptionKey: bytes.Repeat([]byte{8}, 32)}); err == nil {
+		t.Fatalf("expected a wrong key error")
+	}
+	if _, err := d.Restore(ctx, backupManifestPath(m), RestoreOptions{EncryptionKey: []byte(key)}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	var n int64
+	if err := db.Raw("SELECT COUNT(*) FROM notes").Scan(&n).Error; err != nil || n != 2 {
+		t.Fatalf("restored %d notes, %v", n, err)
+	}
+
+	// a tampered file fails the checksum
+	if err := os.WriteFile(m.Path(), []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := d.Restore(ctx, m.Path(), RestoreOptions{EncryptionKey: []byte(key)}); err == nil || !strings.Contains(err.Error(), "corrompido") {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+}
+
+func TestBackupEncryption(t *testing.T) {
+	key, err := backupKey(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("backupKey: %v", err)
+	}
+	for _, size := range []int{0, 1, BackupChunkSize, 2*BackupChunkSize + 3} {
+		data := bytes.Repeat([]byte("gdbase "), size/7+1)[:size]
+		var file bytes.Buffer
+		enc, err := newBackupEncrypter(&file, key)
+		if err != nil {
+			t.Fatalf("newBackupEncrypter: %v", err)
+		}
+		if _, err := enc.Write(data); err != nil || enc.Close() != nil {
+			t.Fatalf("encrypt %d bytes: %v", size, err)
+		}
+		sealed := file.Bytes()
+		if bytes.Contains(sealed, []byte("gdbase gdbase")) {
+			t.Fatalf("%d bytes: plain text in the encrypted file", size)
+		}
+
+		dec, err := newBackupDecrypter(bytes.NewReader(sealed), key)
+		if err != nil {
+			t.Fatalf("newBackupDecrypter: %v", err)
+		}
+		if got, err := io.ReadAll(dec); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("decrypt %d bytes: %d bytes, %v", size, len(got), err)
+		}
+		// truncated files fail
+		dec, _ = newBackupDecrypter(bytes.NewReader(sealed[:len(sealed)-1]), key)
+		if _, err := io.ReadAll(dec); err == nil {
+			t.Fatalf("%d bytes: expected a truncation error", size)
+		}
+	}
+
+	wrong, _ := backupKey(bytes.Repeat([]byte{2}, 32))
+	var file bytes.Buffer
+	enc, _ := newBackupEncrypter(&file, key)
+	_ = enc.Close()
+	if _, err := newBackupDecrypter(&file, wrong); err == nil {
+		t.Fatalf("expected a wrong key error")
+	}
+	if _, err := backupKey([]byte("short")); err == nil {
+		t.Fatalf("expected an invalid key error")
+	}
+}
+
+func TestCheckBackupCompatible(t *testing.T) {
+	m := &BackupManifest{ID: "b", SchemaVersion: 4}
+	for current, ok := range map[int64]bool{0: true, 4: true, 3: false, 5: false} {
+		if err := CheckBackupCompatible(m, current); (err == nil) != ok {
+			t.Fatalf("version %d: %v", current, err)
+		}
+	}
+}
+
+func TestCheckManagedPostgres(t *testing.T) {
+	orig := backupExec
+	t.Cleanup(func() { backupExec = orig })
+	backupExec = func(_ context.Context, _ []string, _ io.Reader, stdout io.Writer, _ string, args ...string) error {
+		if strings.Join(args, " ") != "port gdbase-pg 5432/tcp" {
+			t.Fatalf("unexpected docker args %v", args)
+		}
+		_, err := io.WriteString(stdout, "0.0.0.0:5433\n[::]:5433\n")
+		return err
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		db ti.Database
+		ok bool
+	}{
+		{ti.Database{Name: "kubex", Port: "5433"}, true},
+		{ti.Database{Name: "kubex", Host: "127.0.0.1", Port: 5433}, true},
+		{ti.Database{Name: "kubex", Host: "localhost"}, false},
+		{ti.Database{Name: "kubex", Host: "db.example.com", Port: "5433"}, false},
+	} {
+		if err := checkManagedPostgres(ctx, DefaultBackupContainer, &tc.db); (err == nil) != tc.ok {
+			t.Fatalf("%s:%v: %v", tc.db.Host, tc.db.Port, err)
+		}
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	for i, age := range []time.Duration{0, time.Hour, 2 * time.Hour, 48 * time.Hour} {
+		for _, db := range []string{"main", "other"} {
+			m := &BackupManifest{ID: db + "-" + string(rune('a'+i)), Database: db, File: db + "-" + string(rune('a'+i)) + ".db", CreatedAt: now.Add(-age), dir: dir}
+			if err := os.WriteFile(m.Path(), []byte("x"), 0o600); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := writeBackupManifest(m); err != nil {
+				t.Fatalf("manifest: %v", err)
+			}
+		}
+	}
+
+	removed, err := PruneBackups(dir, "main", BackupRetention{KeepLast: 3, MaxAge: 24 * time.Hour})
+	if err != nil || len(removed) != 1 || removed[0].ID != "main-d" {
+		t.Fatalf("removed %+v, %v", removed, err)
+	}
+	removed, err = PruneBackups(dir, "main", BackupRetention{KeepLast: 1})
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("removed %+v, %v", removed, err)
+	}
+	all, _ := ListBackups(dir)
+	if len(all) != 5 || all[0].ID != "main-a" {
+		t.Fatalf("left %d backups", len(all))
+	}
+	if _, err := os.Stat(removed[0].Path()); !os.IsNotExist(err) {
+		t.Fatalf("backup file not removed: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// RedactedValue replaces the captured arguments bound to sensitive columns.
+const RedactedValue = "***"
+
+// Sources of a CapturedStatement.
+const (
+	CaptureSourceGORM      = "gorm"
+	CaptureSourceMigration = "migration"
+)
+
+// DefaultSensitiveColumns matches the columns whose arguments are redacted.
+var DefaultSensitiveColumns = regexp.MustCompile(`(?i)pass(word|wd)?|secret|token|api_?key|private|salt|hash|credential|cookie`)
+
+// CapturedStatement is a statement recorded by a SQLCapture. SQL keeps the
+// bind parameters of the dialect; Args holds their values, with the ones bound
+// to sensitive columns replaced by RedactedValue.
+type CapturedStatement struct {
+	Dialect  string    `json:"dialect" yaml:"dialect"`
+	SQL      string    `json:"sql" yaml:"sql"`
+	Args     []any     `json:"args,omitempty" yaml:"args,omitempty"`
+	Source   string    `json:"source" yaml:"source"`
+	Caller   string    `json:"caller,omitempty" yaml:"caller,omitempty"`
+	Executed bool      `json:"executed" yaml:"executed"`
+	Time     time.Time `json:"time" yaml:"time"`
+}
+
+// SQLCapture records the statements run through a context returned by
+// WithSQLCapture. In dry-run mode the statements are recorded instead of
+// executed: writes do nothing and reads return no rows.
+type SQLCapture struct {
+	mu         sync.Mutex
+	dryRun     bool
+	sensitive  *regexp.Regexp
+	statements []CapturedStatement
+}
+
+// NewSQLCapture returns an empty capture; dryRun keeps the statements from
+// reaching the database.
+func NewSQLCapture(dryRun bool) *SQLCapture {
+	return &SQLCapture{dryRun: dryRun, sensitive: DefaultSensitiveColumns}
+}
+
+// WithSensitiveColumns replaces the pattern of the columns whose arguments are redacted.
+func (c *SQLCapture) WithSensitiveColumns(re *regexp.Regexp) *SQLCapture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sensitive = re
+	return c
+}
+
+// DryRun reports whether the captured statements are kept from executing.
+func (c *SQLCapture) DryRun() bool { return c != nil && c.dryRun }
+
+// Statements returns the statements recorded so far.
+func (c *SQLCapture) Statements() []CapturedStatement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CapturedStatement(nil), c.statements...)
+}
+
+// Reset drops the recorded statements.
+func (c *SQLCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = nil
+}
+
+// Record adds a statement, redacting the arguments bound to sensitive columns.
+func (c *SQLCapture) Record(dialect, source, caller, query string, args ...any) {
+	if c == nil || strings.TrimSpace(query) == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, CapturedStatement{
+		Dialect:  dialect,
+		SQL:      query,
+		Args:     redactArgs(query, dialect, args, c.sensitive),
+		Source:   source,
+		Caller:   caller,
+		Executed: !c.dryRun,
+		Time:     time.Now().UTC(),
+	})
+}
+
+// WriteSQL writes the statements as a script, with the arguments inlined and
+// the caller of each statement in a comment.
+func (c *SQLCapture) WriteSQL(w io.Writer) error {
+	for _, st := range c.Statements() {
+		comment := st.Source
+		if st.Caller != "" {
+			comment += " " + st.Caller
+		}
+		query := strings.TrimRight(strings.TrimSpace(st.SQL), ";")
+		if len(st.Args) > 0 {
+			query = logger.ExplainSQL(query, bindVarPattern(st.Dialect), `'`, st.Args...)
+		}
+		if _, err := fmt.Fprintf(w, "-- %s\n%s;\n\n", comment, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the statements as a JSON array.
+func (c *SQLCapture) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Statements())
+}
+
+type captureCtxKey struct{}
+
+const captureSettingKey = "gdbase:sql_capture"
+
+// WithSQLCapture returns a copy of ctx whose connections (GetDB, GetDBByName,
+// GetDBForRepo, DBFromContext and the migrations of RunMigrations) record their
+// statements in c.
+func WithSQLCapture(ctx context.Context, c *SQLCapture) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, captureCtxKey{}, c)
+}
+
+// SQLCaptureFromContext returns the capture set with WithSQLCapture, if any.
+func SQLCaptureFromContext(ctx context.Context) (*SQLCapture, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(captureCtxKey{}).(*SQLCapture)
+	return c, ok && c != nil
+}
+
+// CaptureSQL starts capturing on the service: it returns a context carrying a
+// new capture, to hand to the repositories and migrations to review.
+func (d *DBServiceImpl) CaptureSQL(ctx context.Context, dryRun bool) (context.Context, *SQLCapture) {
+	c := NewSQLCapture(dryRun)
+	return WithSQLCapture(ctx, c), c
+}
+
+// StartSQLCapture makes every connection handed out by the service (GetDB,
+// GetDBByName, GetDBForRepo) record its statements in a new capture until
+// StopSQLCapture. A capture set on the context with WithSQLCapture wins.
+func (d *DBServiceImpl) StartSQLCapture(dryRun bool) *SQLCapture {
+	c := NewSQLCapture(dryRun)
+	d.mutexes.MuLock()
+	d.capture = c
+	d.mutexes.MuUnlock()
+	return c
+}
+
+// StopSQLCapture ends the capture started with StartSQLCapture and returns it.
+// Repositories keep the connection they were built with, so they must be
+// rebuilt to leave the capture.
+func (d *DBServiceImpl) StopSQLCapture() *SQLCapture {
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	c := d.capture
+	d.capture = nil
+	return c
+}
+
+// captureContext adds the capture of the service to ctx when it has none.
+func (d *DBServiceImpl) captureContext(ctx context.Context) context.Context {
+	if d == nil || d.mutexes == nil {
+		return ctx
+	}
+	if _, ok := SQLCaptureFromContext(ctx); ok {
+		return ctx
+	}
+	d.mutexes.MuLock()
+	c := d.capture
+	d.mutexes.MuUnlock()
+	if c == nil {
+		return ctx
+	}
+	return WithSQLCapture(ctx, c)
+}
+
+// withCapture returns a session of db recording in the capture of ctx (in dry
+// run when the capture is).
+func withCapture(ctx context.Context, db *gorm.DB) *gorm.DB {
+	c, ok := SQLCaptureFromContext(ctx)
+	if !ok || db == nil {
+		return db
+	}
+	registerCaptureCallbacks(db)
+	return db.Session(&gorm.Session{DryRun: c.dryRun, Context: ctx}).Set(captureSettingKey, c)
+}
+
+type capturePlugin struct{}
+
+func (capturePlugin) Name() string { return captureSettingKey }
+
+func (capturePlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:create").Register("gdbase:capture_create", captureCallback),
+		cb.Query().After("gorm:query").Register("gdbase:capture_query", captureCallback),
+		cb.Update().After("gorm:update").Register("gdbase:capture_update", captureCallback),
+		cb.Delete().After("gorm:delete").Register("gdbase:capture_delete", captureCallback),
+		cb.Row().After("gorm:row").Register("gdbase:capture_row", captureCallback),
+		cb.Raw().After("gorm:raw").Register("gdbase:capture_raw", captureCallback),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var captureRegisterMu sync.Mutex
+
+// registerCaptureCallbacks installs the capture callbacks on the connection of
+// db once; they do nothing for sessions without a capture.
+func registerCaptureCallbacks(db *gorm.DB) {
+	captureRegisterMu.Lock()
+	defer captureRegisterMu.Unlock()
+	if _, ok := db.Config.Plugins[captureSettingKey]; ok {
+		return
+	}
+	_ = db.Use(capturePlugin{})
+}
+
+func captureCallback(db *gorm.DB) {
+	v, ok := db.Get(captureSettingKey)
+	if !ok {
+		return
+	}
+	c, ok := v.(*SQLCapture)
+	if !ok || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	c.Record(db.Dialector.Name(), CaptureSourceGORM, captureCaller(), db.Statement.SQL.String(), db.Statement.Vars...)
+}
+
+// captureCaller returns the first frame outside GORM, database/sql and this
+// package, i.e. the repository or service that issued the statement.
+func captureCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		switch {
+		case strings.Contains(f.File, "gorm.io/"),
+			strings.Contains(f.File, "/database/sql/"),
+			strings.Contains(f.File, "/runtime/"),
+			strings.Contains(f.Function, "gdbase/internal/services.") && !strings.HasSuffix(f.File, "_test.go"):
+		default:
+			return fmt.Sprintf("%s:%d %s", f.File, f.Line, f.Function[strings.LastIndexByte(f.Function, '/')+1:])
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// captureExecer records the statements of a migration and runs them unless
+// the capture is a dry run.
+type captureExecer struct {
+	ex      execer
+	capture *SQLCapture
+	dialect string
+	caller  string
+}
+
+func (e captureExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.capture.Record(e.dialect, CaptureSourceMigration, e.caller, query, args...)
+	if e.capture.dryRun {
+		return driver.RowsAffected(0), nil
+	}
+	return e.ex.ExecContext(ctx, query, args...)
+}
+
+var (
+	pgBindVar        = regexp.MustCompile(`\$(\d+)`)
+	sqlServerBindVar = regexp.MustCompile(`@p(\d+)`)
+)
+
+// bindVarPattern returns the numbered bind parameter of the dialect, nil for "?".
+func bindVarPattern(dialect string) *regexp.Regexp {
+	switch dialect {
+	case DialectPostgres:
+		return pgBindVar
+	case DialectSQLServer:
+		return sqlServerBindVar
+	}
+	return nil
+}
+
+var (
+	comparedColumnRe = regexp.MustCompile(`(?i)[\x60"\[]?(\w+)[\x60"\]]?\s*(?:=|<>|!=|<=|>=|<|>|\blike|\bin\s*\()\s*(?:[^()]*?,\s*)?$`)
+	insertColumnsRe  = regexp.MustCompile(`(?is)insert\s+into\s+[^(]+\(([^)]*)\)\s*(?:output\s+[^)]*?\s+)?values\s*`)
+)
+
+// redactArgs copies args, replacing the values bound to columns matched by
+// sensitive. The column of a parameter is the one it is compared with
+// (password = ?) or its position in the column list of an INSERT.
+func redactArgs(query, dialect string, args []any, sensitive *regexp.Regexp) []any {
+	if len(args) == 0 {
+		return nil
+	}
+	out := make([]any, len(args))
+	for i, a := range args {
+		out[i] = captureValue(a)
+	}
+	if sensitive == nil {
+		return out
+	}
+
+	var insertCols []string
+	valuesAt := -1
+	if m := insertColumnsRe.FindStringSubmatchIndex(query); m != nil {
+		for _, col := range strings.Split(query[m[2]:m[3]], ",") {
+			insertCols = append(insertCols, strings.Trim(strings.TrimSpace(col), "`\"[]"))
+		}
+		valuesAt = m[1]
+	}
+
+	for _, p := range bindVarPositions(query, dialect) {
+		if p.arg < 0 || p.arg >= len(out) {
+			continue
+		}
+		column := ""
+		if m := comparedColumnRe.FindStringSubmatch(query[:p.pos]); m != nil {
+			column = m[1]
+		} else if valuesAt >= 0 && p.pos >= valuesAt && len(insertCols) > 0 {
+			column = insertCols[tuplePosition(query[valuesAt:p.pos])%len(insertCols)]
+		}
+		if column != "" && sensitive.MatchString(column) {
+			out[p.arg] = RedactedValue
+		}
+	}
+	return out
+}
+
+// captureValue makes an argument printable and JSON friendly.
+func captureValue(v any) any {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case driver.Valuer:
+		if val, err := x.Value(); err == nil {
+			if b, ok := val.([]byte); ok {
+				return string(b)
+			}
+			return val
+		}
+	}
+	return v
+}
+
+type bindVar struct {
+	pos int // offset of the parameter in the query
+	arg int // index of its argument
+}
+
+func bindVarPositions(query, dialect string) []bindVar {
+	var out []bindVar
+	if re := bindVarPattern(dialect); re != nil {
+		for _, m := range re.FindAllStringSubmatchIndex(query, -1) {
+			var n int
+			fmt.Sscanf(query[m[2]:m[3]], "%d", &n)
+			out = append(out, bindVar{pos: m[0], arg: n - 1})
+		}
+		return out
+	}
+	inQuote := false
+	for i, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '?' && !inQuote:
+			out = append(out, bindVar{pos: i, arg: len(out)})
+		}
+	}
+	return out
+}
+
+// tuplePosition returns the index, inside its VALUES tuple, of the value that
+// ends the text s.
+func tuplePosition(s string) int {
+	depth, pos := 0, 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+			if depth == 1 {
+				pos = 0
+			}
+		case ')':
+			depth--
+		case ',':
+			if depth == 1 {
+				pos++
+			}
+		}
+	}
+	return pos
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type captureAccount struct {
+	ID           uint
+	Email        string
+	PasswordHash string
+}
+
+func TestSQLCaptureDryRun(t *testing.T) {
+	d := newRoutingTestService(t)
+	if err := d.db["transactional"].AutoMigrate(&captureAccount{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+
+	ctx, capture := d.CaptureSQL(Using(context.Background(), "transactional"), true)
+	db, err := GetDB(ctx, d)
+	if err != nil {
+		t.Fatalf("GetDB: %v", err)
+	}
+	if err := db.Create(&captureAccount{Email: "a@b.c", PasswordHash: "s3cr3t"}).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var found []captureAccount
+	if err := DBFromContext(ctx, d.db["transactional"]).Where("email = ?", "a@b.c").Find(&found).Error; err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+
+	var count int64
+	d.db["transactional"].Model(&captureAccount{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run executed the insert: %d rows", count)
+	}
+
+	stmts := capture.Statements()
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 captured statements, got %+v", stmts)
+	}
+	insert := stmts[0]
+	if !strings.HasPrefix(insert.SQL, "INSERT INTO `capture_accounts`") || insert.Executed || insert.Source != CaptureSourceGORM {
+		t.Fatalf("unexpected insert capture: %+v", insert)
+	}
+	if insert.Args[0] != "a@b.c" || insert.Args[1] != RedactedValue {
+		t.Fatalf("password_hash not redacted: %v", insert.Args)
+	}
+	if !strings.Contains(insert.Caller, "db_capture_test.go") {
+		t.Fatalf("caller should point at the test, got %q", insert.Caller)
+	}
+	if stmts[1].Args[0] != "a@b.c" {
+		t.Fatalf("unexpected select args: %v", stmts[1].Args)
+	}
+
+	var script bytes.Buffer
+	if err := capture.WriteSQL(&script); err != nil {
+		t.Fatalf("WriteSQL: %v", err)
+	}
+	if !strings.Contains(script.String(), `VALUES ('a@b.c','***')`) || strings.Contains(script.String(), "s3cr3t") {
+		t.Fatalf("unexpected script:\n%s", script.String())
+	}
+	var decoded []CapturedStatement
+	var js bytes.Buffer
+	if err := capture.WriteJSON(&js); err != nil || json.Unmarshal(js.Bytes(), &decoded) != nil || len(decoded) != 2 {
+		t.Fatalf("WriteJSON: %v\n%s", err, js.String())
+	}
+
+	// Service-wide capture
+	started := d.StartSQLCapture(true)
+	repoDB, err := GetDBForRepo(context.Background(), d, "job_queue")
+	if err != nil {
+		t.Fatalf("GetDBForRepo: %v", err)
+	}
+	repoDB.Exec("DELETE FROM capture_accounts")
+	if d.StopSQLCapture() != started || len(started.Statements()) != 1 || started.Statements()[0].Executed {
+		t.Fatalf("unexpected service capture: %+v", started.Statements())
+	}
+
+	// Without the capture the connection is untouched
+	plain, _ := GetDB(Using(context.Background(), "transactional"), d)
+	if plain != d.db["transactional"] {
+		t.Fatalf("GetDB without capture should return the connection itself")
+	}
+}
+
+func TestSQLCaptureMigrations(t *testing.T) {
+	db := openTestSQLite(t)
+	m, err := NewMigrator(db, "sqlite")
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	migs := []Migration{{Version: 1, Name: "init", Up: "CREATE TABLE a (id INTEGER);"}}
+
+	capture := NewSQLCapture(true)
+	ctx := WithSQLCapture(context.Background(), capture)
+	applied, _, err := m.Up(ctx, migs)
+	if err != nil || applied != 1 {
+		t.Fatalf("dry-run Up = %d, %v", applied, err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name IN ('a', ?)", DefaultMigrationsTable).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("dry run created tables: %d, %v", n, err)
+	}
+
+	stmts := capture.Statements()
+	if len(stmts) != 3 {
+		t.Fatalf("expected table, script and bookkeeping statements, got %+v", stmts)
+	}
+	if stmts[1].SQL != migs[0].Up || stmts[1].Caller != "1_init (up)" || stmts[1].Source != CaptureSourceMigration {
+		t.Fatalf("unexpected script capture: %+v", stmts[1])
+	}
+
+	// A capture that is not a dry run records and executes
+	capture = NewSQLCapture(false)
+	if applied, _, err := m.Up(WithSQLCapture(context.Background(), capture), migs); err != nil || applied != 1 {
+		t.Fatalf("Up = %d, %v", applied, err)
+	}
+	if v, _ := m.Version(context.Background()); v != 1 || len(capture.Statements()) != 3 {
+		t.Fatalf("version %d, captured %d", v, len(capture.Statements()))
+	}
+}
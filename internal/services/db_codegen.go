@@ -0,0 +1,623 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// DefaultModelModule is the module the generated packages import gdbase from.
+const DefaultModelModule = "github.com/kubex-ecosystem/gdbase"
+
+// ModelGenOptions configures GenerateModels.
+type ModelGenOptions struct {
+	// Include and Exclude select tables by name, with path.Match patterns
+	// (e.g. "user_*"); an empty Include selects every table.
+	Include []string
+	Exclude []string
+	// TypeOverrides maps "table.column", "column" or a database type (e.g.
+	// "uuid") to the Go type of the field, checked in that order. Types of other
+	// packages are written with their import path: "github.com/google/uuid.UUID";
+	// t.X refers to the public gdbase types package (e.g. "*t.Money").
+	TypeOverrides map[string]string
+	// Module is the module path the generated code imports gdbase packages
+	// from; defaults to DefaultModelModule. Only the public packages are
+	// imported, so the code compiles outside gdbase.
+	Module string
+}
+
+// GeneratedFile is a source file produced by GenerateModels; Path is relative
+// to the output directory.
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+// GenerateModels generates, for every selected table of schema, a package in
+// the Model → Repo → Service layout of internal/models: "<table>/<entity>_model.go"
+// (struct, I-interface with getters and setters), "<table>/<table>_repo.go" (GORM
+// repository routed with GetDBForRepo) and "<table>/<table>_service.go".
+// JSON columns are mapped to types.JSONBImpl.
+func GenerateModels(schema *Schema, opts ModelGenOptions) ([]GeneratedFile, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("❌ Esquema do banco de dados não informado")
+	}
+	if opts.Module == "" {
+		opts.Module = DefaultModelModule
+	}
+	var files []GeneratedFile
+	for i := range schema.Tables {
+		table := &schema.Tables[i]
+		selected, err := selectTable(table.Name, opts.Include, opts.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+		m, err := newGenModel(table, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range []struct{ name, tmpl string }{
+			{toSnake(m.Entity) + "_model.go", "model"},
+			{m.Table + "_repo.go", "repo"},
+			{m.Table + "_service.go", "service"},
+		} {
+			src, err := renderModel(f.tmpl, m)
+			if err != nil {
+				return nil, fmt.Errorf("❌ Erro ao gerar %s para '%s': %w", f.tmpl, table.Name, err)
+			}
+			files = append(files, GeneratedFile{Path: filepath.Join(m.Dir, f.name), Content: src})
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("❌ Nenhuma tabela selecionada")
+	}
+	return files, nil
+}
+
+func selectTable(name string, include, exclude []string) (bool, error) {
+	match := func(patterns []string) (bool, error) {
+		for _, p := range patterns {
+			ok, err := path.Match(strings.ToLower(p), strings.ToLower(name))
+			if err != nil {
+				return false, fmt.Errorf("❌ Padrão de tabela inválido %q: %w", p, err)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if len(include) > 0 {
+		ok, err := match(include)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	excluded, err := match(exclude)
+	return !excluded, err
+}
+
+type genModel struct {
+	Table    string
+	Dir      string
+	Package  string
+	Entity   string
+	Plural   string
+	Receiver string
+	Fields   []genField
+	Imports  []genImport
+	// PK is the single primary key field, nil when the key is composite or missing.
+	PK       *genField
+	Required []genField
+	Module   string
+}
+
+type genField struct {
+	Name   string
+	Column string
+	Type   string
+	Param  string
+	Tag    string
+}
+
+type genImport struct {
+	Alias string
+	Path  string
+}
+
+func newGenModel(table *TableSchema, opts ModelGenOptions) (*genModel, error) {
+	dir := strings.ToLower(table.Name)
+	entity := toCamel(singularize(dir), true)
+	if entity == "" {
+		return nil, fmt.Errorf("❌ Nome de tabela inválido: %q", table.Name)
+	}
+	m := &genModel{
+		Table:    dir,
+		Dir:      dir,
+		Package:  packageName(dir),
+		Entity:   entity,
+		Plural:   toCamel(dir, true),
+		Receiver: strings.ToLower(entity[:1]),
+		Module:   opts.Module,
+	}
+	if m.Receiver == "t" {
+		// t is the alias of the types package
+		m.Receiver = "m"
+	}
+	if m.Plural == m.Entity {
+		m.Plural += "List"
+	}
+	imports := map[string]string{}
+	for _, col := range table.Columns {
+		goType, gormType, imp := columnGoType(table.Name, col, opts.TypeOverrides)
+		if imp != nil {
+			imports[imp.Path] = imp.Alias
+		}
+		switch elem := strings.TrimLeft(goType, "*[]"); {
+		case strings.HasPrefix(elem, "time."):
+			imports["time"] = ""
+		case strings.HasPrefix(elem, "t."):
+			// t is the alias of the public gdbase types package (t.JSONBImpl, t.Money)
+			imports[opts.Module+"/types"] = "t"
+		}
+		json := toCamel(col.Name, false)
+		if col.Nullable && strings.HasPrefix(goType, "*") {
+			json += ",omitempty"
+		}
+		gormTag := "column:" + col.Name
+		if col.PrimaryKey {
+			gormTag += ";primaryKey"
+			if col.AutoIncrement {
+				gormTag += ";autoIncrement"
+			}
+		}
+		if gormType != "" {
+			gormTag += ";type:" + gormType
+		}
+		f := genField{
+			Name:   fieldName(col.Name),
+			Column: col.Name,
+			Type:   goType,
+			Param:  safeParam(toCamel(col.Name, false)),
+			Tag:    fmt.Sprintf("`json:%q xml:%q yaml:%q gorm:%q`", json, json, json, gormTag),
+		}
+		if f.Param == m.Receiver {
+			f.Param += "Value"
+		}
+		m.Fields = append(m.Fields, f)
+		if goType == "string" && !col.Nullable && !col.PrimaryKey && col.Default == nil {
+			m.Required = append(m.Required, f)
+		}
+	}
+	if len(table.PrimaryKey) == 1 {
+		for i := range m.Fields {
+			if strings.EqualFold(m.Fields[i].Column, table.PrimaryKey[0]) {
+				m.PK = &m.Fields[i]
+			}
+		}
+	}
+	for p, alias := range imports {
+		m.Imports = append(m.Imports, genImport{Alias: alias, Path: p})
+	}
+	sort.Slice(m.Imports, func(i, j int) bool { return m.Imports[i].Path < m.Imports[j].Path })
+	return m, nil
+}
+
+// columnGoType returns the Go type of col, the gorm type to declare (for JSON
+// columns) and the import an override needs.
+func columnGoType(table string, col ColumnSchema, overrides map[string]string) (string, string, *genImport) {
+	base := baseColumnType(col.Type)
+	for _, key := range []string{table + "." + col.Name, col.Name, base} {
+		if override, ok := overrides[key]; ok && override != "" {
+			goType, imp := parseTypeOverride(override)
+			return goType, "", imp
+		}
+	}
+
+	var goType, gormType string
+	nillable := false
+	switch {
+	case base == "json" || base == "jsonb":
+		goType, gormType, nillable = "t.JSONBImpl", base, true
+	case base == "tinyint" && strings.Contains(strings.ToLower(col.Type), "(1)"),
+		base == "bool", base == "boolean", base == "bit":
+		goType = "bool"
+	case base == "bigint", base == "int8", base == "bigserial", base == "serial8":
+		goType = "int64"
+	case base == "smallint", base == "int2", base == "tinyint", base == "smallserial":
+		goType = "int16"
+	case base == "int", base == "integer", base == "int4", base == "mediumint", base == "serial", base == "serial4":
+		goType = "int"
+	case base == "real", base == "float4":
+		goType = "float32"
+	case base == "numeric", base == "decimal", base == "money", strings.HasPrefix(base, "double"),
+		strings.HasPrefix(base, "float"):
+		goType = "float64"
+	case strings.HasPrefix(base, "timestamp"), strings.HasPrefix(base, "datetime"),
+		strings.HasPrefix(base, "time "), base == "date", base == "time", base == "timetz":
+		goType = "time.Time"
+	case base == "bytea", strings.Contains(base, "blob"), strings.Contains(base, "binary"), base == "image":
+		goType, nillable = "[]byte", true
+	default:
+		// text, varchar, char, uuid, enum and everything without a closer Go type
+		goType = "string"
+	}
+	if col.Nullable && !col.PrimaryKey && !nillable {
+		goType = "*" + goType
+	}
+	return goType, gormType, nil
+}
+
+// baseColumnType lowers a database type to its name: "character varying(255)"
+// gives "character varying", "int(10) unsigned" gives "int".
+func baseColumnType(dbType string) string {
+	base := strings.ToLower(strings.TrimSpace(dbType))
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = base[:i]
+	}
+	base = strings.TrimSuffix(strings.TrimSpace(base), " unsigned")
+	return strings.TrimSpace(base)
+}
+
+// parseTypeOverride splits "*github.com/google/uuid.UUID" into the type as
+// written in the source (*uuid.UUID) and its import. time.X and t.X (the gdbase
+// types package) need no import path.
+func parseTypeOverride(override string) (string, *genImport) {
+	prefix := ""
+	for _, p := range []string{"*", "[]"} {
+		for strings.HasPrefix(override, p) {
+			prefix += p
+			override = override[len(p):]
+		}
+	}
+	slash := strings.LastIndexByte(override, '/')
+	dot := strings.LastIndexByte(override, '.')
+	if slash < 0 || dot < slash {
+		return prefix + override, nil
+	}
+	importPath := override[:dot]
+	alias := packageName(path.Base(importPath))
+	return prefix + alias + override[dot:], &genImport{Path: importPath}
+}
+
+func renderModel(name string, m *genModel) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := modelTemplates.ExecuteTemplate(&buf, name, m); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// commonInitialisms are kept upper case in Go identifiers.
+var commonInitialisms = map[string]bool{
+	"api": true, "cpu": true, "ean": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sku": true, "sql": true, "ssh": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// toCamel converts a snake_case name to CamelCase (exported) or camelCase,
+// keeping the common initialisms upper case in exported names: image_url gives
+// ImageURL or imageUrl.
+func toCamel(s string, exported bool) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for i, p := range parts {
+		p = strings.ToLower(p)
+		switch {
+		case i == 0 && !exported:
+			b.WriteString(p)
+		case exported && commonInitialisms[p]:
+			b.WriteString(strings.ToUpper(p))
+		default:
+			b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+		}
+	}
+	out := b.String()
+	if out != "" && unicode.IsDigit(rune(out[0])) {
+		out = "T" + out
+	}
+	return out
+}
+
+func toSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// singularize turns the plural English table names into entity names.
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"),
+		strings.HasSuffix(s, "shes"), strings.HasSuffix(s, "uses"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+func packageName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "m" + out
+	}
+	return out
+}
+
+// fieldName returns the Go field of a column; the fields that would collide
+// with the methods of the model (TableName) get a Value suffix.
+func fieldName(column string) string {
+	name := toCamel(column, true)
+	if name == "TableName" {
+		name += "Value"
+	}
+	return name
+}
+
+func safeParam(s string) string {
+	switch s {
+	case "", "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+		"func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+		"struct", "switch", "type", "var", "t", "err", "string", "int", "bool", "error", "len", "new":
+		return s + "Value"
+	}
+	return s
+}
+
+var modelTemplates = template.Must(template.New("models").Parse(`
+{{define "model"}}// Package {{.Package}} contains the model, repository and service of the {{.Table}} table.
+package {{.Package}}
+{{if .Imports}}
+import (
+{{- range .Imports}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+{{- end}}
+)
+{{end}}
+// {{.Entity}} represents a row of the {{.Table}} table
+type {{.Entity}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+
+// I{{.Entity}} interface for abstraction
+type I{{.Entity}} interface {
+	TableName() string
+{{- range .Fields}}
+	Get{{.Name}}() {{.Type}}
+	Set{{.Name}}({{.Param}} {{.Type}})
+{{- end}}
+}
+{{$e := .Entity}}{{$r := .Receiver}}
+func ({{$r}} *{{$e}}) TableName() string { return "{{.Table}}" }
+{{- range .Fields}}
+func ({{$r}} *{{$e}}) Get{{.Name}}() {{.Type}} { return {{$r}}.{{.Name}} }
+func ({{$r}} *{{$e}}) Set{{.Name}}({{.Param}} {{.Type}}) { {{$r}}.{{.Name}} = {{.Param}} }
+{{- end}}
+{{end}}
+
+{{define "repo"}}package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	svc "{{.Module}}/services"
+
+	l "github.com/kubex-ecosystem/logz"
+	"gorm.io/gorm"
+)
+{{$e := .Entity}}
+type I{{$e}}Repo interface {
+	Create({{.Receiver}} *{{$e}}) (*{{$e}}, error)
+	FindOne(where ...interface{}) (*{{$e}}, error)
+	FindAll(where ...interface{}) ([]*{{$e}}, error)
+	Update({{.Receiver}} *{{$e}}) (*{{$e}}, error)
+{{- if .PK}}
+	Delete(id {{.PK.Type}}) error
+{{- else}}
+	Delete(where ...interface{}) error
+{{- end}}
+	Close() error
+	WithContext(ctx context.Context) I{{$e}}Repo
+}
+
+type {{$e}}Repo struct {
+	g *gorm.DB
+}
+
+func New{{$e}}Repo(ctx context.Context, dbService *svc.DBServiceImpl) I{{$e}}Repo {
+	if dbService == nil {
+		return nil
+	}
+	db, err := svc.GetDBForRepo(ctx, dbService, "{{.Table}}")
+	if err != nil {
+		l.ErrorCtx(fmt.Sprintf("{{$e}}Repo: failed to get DB: %v", err), nil)
+		return nil
+	}
+	return &{{$e}}Repo{db}
+}
+
+func (rp *{{$e}}Repo) Create({{.Receiver}} *{{$e}}) (*{{$e}}, error) {
+	if {{.Receiver}} == nil {
+		return nil, fmt.Errorf("{{$e}}Repo: {{$e}} is nil")
+	}
+	err := rp.g.Create({{.Receiver}}).Error
+	if err != nil {
+		return nil, fmt.Errorf("{{$e}}Repo: failed to create {{$e}}: %w", err)
+	}
+	return {{.Receiver}}, nil
+}
+
+func (rp *{{$e}}Repo) FindOne(where ...interface{}) (*{{$e}}, error) {
+	var {{.Receiver}} {{$e}}
+	err := rp.where(where).First(&{{.Receiver}}).Error
+	if err != nil {
+		return nil, fmt.Errorf("{{$e}}Repo: failed to find {{$e}}: %w", err)
+	}
+	return &{{.Receiver}}, nil
+}
+
+func (rp *{{$e}}Repo) FindAll(where ...interface{}) ([]*{{$e}}, error) {
+	var list []*{{$e}}
+	err := rp.where(where).Find(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("{{$e}}Repo: failed to find all {{.Table}}: %w", err)
+	}
+	return list, nil
+}
+
+func (rp *{{$e}}Repo) Update({{.Receiver}} *{{$e}}) (*{{$e}}, error) {
+	if {{.Receiver}} == nil {
+		return nil, fmt.Errorf("{{$e}}Repo: {{$e}} is nil")
+	}
+	err := rp.g.Save({{.Receiver}}).Error
+	if err != nil {
+		return nil, fmt.Errorf("{{$e}}Repo: failed to update {{$e}}: %w", err)
+	}
+	return {{.Receiver}}, nil
+}
+{{if .PK}}
+func (rp *{{$e}}Repo) Delete(id {{.PK.Type}}) error {
+	err := rp.g.Delete(&{{$e}}{}, "{{.PK.Column}} = ?", id).Error
+	if err != nil {
+		return fmt.Errorf("{{$e}}Repo: failed to delete {{$e}}: %w", err)
+	}
+	return nil
+}
+{{else}}
+func (rp *{{$e}}Repo) Delete(where ...interface{}) error {
+	if len(where) == 0 {
+		return fmt.Errorf("{{$e}}Repo: delete without conditions")
+	}
+	err := rp.where(where).Delete(&{{$e}}{}).Error
+	if err != nil {
+		return fmt.Errorf("{{$e}}Repo: failed to delete {{$e}}: %w", err)
+	}
+	return nil
+}
+{{end}}
+func (rp *{{$e}}Repo) WithContext(ctx context.Context) I{{$e}}Repo {
+	return &{{$e}}Repo{svc.DBFromContext(ctx, rp.g)}
+}
+
+func (rp *{{$e}}Repo) Close() error {
+	sqlDB, err := rp.g.DB()
+	if err != nil {
+		l.ErrorCtx(fmt.Sprintf("{{$e}}Repo: failed to get DB from gorm.DB: %v", err), nil)
+		return err
+	}
+	return sqlDB.Close()
+}
+
+func (rp *{{$e}}Repo) where(where []interface{}) *gorm.DB {
+	if len(where) == 0 {
+		return rp.g
+	}
+	return rp.g.Where(where[0], where[1:]...)
+}
+{{end}}
+
+{{define "service"}}package {{.Package}}
+
+import (
+{{- if .Required}}
+	"errors"
+{{- end}}
+	"fmt"
+)
+{{$e := .Entity}}{{$r := .Receiver}}
+type I{{$e}}Service interface {
+	Create{{$e}}({{$r}} *{{$e}}) (*{{$e}}, error)
+{{- if .PK}}
+	Get{{$e}}ByID(id {{.PK.Type}}) (*{{$e}}, error)
+{{- end}}
+	Update{{$e}}({{$r}} *{{$e}}) (*{{$e}}, error)
+{{- if .PK}}
+	Delete{{$e}}(id {{.PK.Type}}) error
+{{- end}}
+	List{{.Plural}}() ([]*{{$e}}, error)
+}
+
+type {{$e}}Service struct {
+	repo I{{$e}}Repo
+}
+
+func New{{$e}}Service(repo I{{$e}}Repo) I{{$e}}Service {
+	return &{{$e}}Service{repo: repo}
+}
+
+func (sv *{{$e}}Service) Create{{$e}}({{$r}} *{{$e}}) (*{{$e}}, error) {
+{{- if .Required}}
+	if {{range $i, $f := .Required}}{{if $i}} || {{end}}{{$r}}.{{$f.Name}} == ""{{end}} {
+		return nil, errors.New("missing required fields")
+	}
+{{- end}}
+	created, err := sv.repo.Create({{$r}})
+	if err != nil {
+		return nil, fmt.Errorf("error creating {{.Table}}: %w", err)
+	}
+	return created, nil
+}
+{{if .PK}}
+func (sv *{{$e}}Service) Get{{$e}}ByID(id {{.PK.Type}}) (*{{$e}}, error) {
+	{{$r}}, err := sv.repo.FindOne("{{.PK.Column}} = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching {{.Table}}: %w", err)
+	}
+	return {{$r}}, nil
+}
+{{end}}
+func (sv *{{$e}}Service) Update{{$e}}({{$r}} *{{$e}}) (*{{$e}}, error) {
+	updated, err := sv.repo.Update({{$r}})
+	if err != nil {
+		return nil, fmt.Errorf("error updating {{.Table}}: %w", err)
+	}
+	return updated, nil
+}
+{{if .PK}}
+func (sv *{{$e}}Service) Delete{{$e}}(id {{.PK.Type}}) error {
+	err := sv.repo.Delete(id)
+	if err != nil {
+		return fmt.Errorf("error deleting {{.Table}}: %w", err)
+	}
+	return nil
+}
+{{end}}
+func (sv *{{$e}}Service) List{{.Plural}}() ([]*{{$e}}, error) {
+	list, err := sv.repo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("error listing {{.Table}}: %w", err)
+	}
+	return list, nil
+}
+{{end}}
+`))
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGenerateModels(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+	for _, stmt := range []string{
+		`CREATE TABLE price_categories (id TEXT PRIMARY KEY, name VARCHAR(120) NOT NULL, image_url TEXT, metadata JSON, created_at DATETIME NOT NULL, external_id TEXT, price NUMERIC)`,
+		`CREATE TABLE audit_logs (id INTEGER PRIMARY KEY AUTOINCREMENT, payload BLOB, table_name TEXT)`,
+	} {
+		if _, err := d.Exec(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	schema, err := d.DescribeSchema(ctx)
+	if err != nil {
+		t.Fatalf("DescribeSchema: %v", err)
+	}
+
+	files, err := GenerateModels(schema, ModelGenOptions{
+		Include:       []string{"price_*", "audit_logs"},
+		Exclude:       []string{"audit_*"},
+		TypeOverrides: map[string]string{"price_categories.external_id": "*github.com/google/uuid.UUID", "price": "*t.Money"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateModels: %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range files {
+		got[f.Path] = string(f.Content)
+	}
+	if len(got) != 3 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	model := got["price_categories/price_category_model.go"]
+	for _, want := range []string{
+		"package pricecategories",
+		`t "github.com/kubex-ecosystem/gdbase/types"`,
+		`"github.com/google/uuid"`,
+		"type PriceCategory struct",
+		"ID         string",
+		"`json:\"id\" xml:\"id\" yaml:\"id\" gorm:\"column:id;primaryKey\"`",
+		"ImageURL   *string",
+		`json:"imageUrl,omitempty"`,
+		"Metadata   t.JSONBImpl",
+		`gorm:"column:metadata;type:json"`,
+		"CreatedAt  time.Time",
+		"ExternalID *uuid.UUID",
+		"Price      *t.Money",
+		"type IPriceCategory interface",
+		`func (p *PriceCategory) TableName() string`,
+		"func (p *PriceCategory) SetImageURL(imageUrl *string)",
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("model misses %q:\n%s", want, model)
+		}
+	}
+	repo := got["price_categories/price_categories_repo.go"]
+	for _, want := range []string{
+		`svc.GetDBForRepo(ctx, dbService, "price_categories")`,
+		"func (rp *PriceCategoryRepo) Delete(id string) error",
+		"WithContext(ctx context.Context) IPriceCategoryRepo",
+	} {
+		if !strings.Contains(repo, want) {
+			t.Errorf("repo misses %q:\n%s", want, repo)
+		}
+	}
+	for _, f := range files {
+		if strings.Contains(string(f.Content), "/internal/") {
+			t.Errorf("%s imports an internal package:\n%s", f.Path, f.Content)
+		}
+	}
+	service := got["price_categories/price_categories_service.go"]
+	for _, want := range []string{
+		`if p.Name == "" {`,
+		"GetPriceCategoryByID(id string) (*PriceCategory, error)",
+		"ListPriceCategories() ([]*PriceCategory, error)",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service misses %q:\n%s", want, service)
+		}
+	}
+
+	files, err = GenerateModels(schema, ModelGenOptions{Include: []string{"audit_logs"}, Module: "example.com/app"})
+	if err != nil {
+		t.Fatalf("GenerateModels: %v", err)
+	}
+	model = string(files[0].Content)
+	for _, want := range []string{
+		"TableNameValue *string",
+		`gorm:"column:table_name"`,
+		"func (a *AuditLog) TableName() string",
+		"func (a *AuditLog) GetTableNameValue() *string",
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("model misses %q:\n%s", want, model)
+		}
+	}
+	if repo := string(files[1].Content); !strings.Contains(repo, `svc "example.com/app/services"`) {
+		t.Errorf("repo does not import the services of the module:\n%s", repo)
+	}
+
+	if _, err := GenerateModels(schema, ModelGenOptions{Include: []string{"missing"}}); err == nil {
+		t.Fatalf("expected error when no table is selected")
+	}
+}
+
+func TestColumnGoType(t *testing.T) {
+	for _, tc := range []struct {
+		col  ColumnSchema
+		want string
+	}{
+		{ColumnSchema{Name: "a", Type: "character varying(255)"}, "string"},
+		{ColumnSchema{Name: "a", Type: "bigint", Nullable: true}, "*int64"},
+		{ColumnSchema{Name: "a", Type: "tinyint(1)"}, "bool"},
+		{ColumnSchema{Name: "a", Type: "int(10) unsigned"}, "int"},
+		{ColumnSchema{Name: "a", Type: "numeric(10,2)"}, "float64"},
+		{ColumnSchema{Name: "a", Type: "timestamp with time zone", Nullable: true}, "*time.Time"},
+		{ColumnSchema{Name: "a", Type: "jsonb", Nullable: true}, "t.JSONBImpl"},
+		{ColumnSchema{Name: "a", Type: "bytea", Nullable: true}, "[]byte"},
+		{ColumnSchema{Name: "a", Type: "interval"}, "string"},
+	} {
+		if got, _, _ := columnGoType("x", tc.col, nil); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.col.Type, got, tc.want)
+		}
+	}
+}
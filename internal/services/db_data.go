@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Data import and export move the rows of a registered model from and to
+// files (see types.DataImporter and types.DataExporter): CSV, JSON, JSON
+// Lines, YAML, TOML, INI, .env and XML. The fields are named by their json
+// tag (or column, or Go name); the values read are coerced to the type of
+// the field, and each failed row is reported without stopping the import.
+// Imported rows go through SeedPreparer like fixture rows (users get their
+// password hashed).
+
+// DataImportOptions configures ImportData.
+type DataImportOptions struct {
+	// Model is the table (or Go type name) of the model
+	Model string
+	// Format is the format of the data (csv, jsonl, json, yaml...)
+	Format string
+	// Columns maps the columns of the file to the fields of the model ("-"
+	// ignores a column)
+	Columns map[string]string
+	// BatchSize is the number of rows inserted at once
+	BatchSize int
+	// MaxErrors stops the import after as many failed rows (0 never stops)
+	MaxErrors int
+	// DryRun reads and converts the rows without writing them
+	DryRun bool
+	// Models defaults to the models registered with RegisterSchemaModels
+	Models []any
+}
+
+// DataExportOptions configures ExportData.
+type DataExportOptions struct {
+	// Model is the table (or Go type name) of the model
+	Model string
+	// Format is the format of the data (csv, jsonl, json, yaml...)
+	Format string
+	// Fields are the fields exported, in order (default: all the fields
+	// with a json name, in declaration order)
+	Fields []string
+	// Columns renames the exported fields ("-" drops a field)
+	Columns map[string]string
+	// Filter and Sort use the filter language of the list endpoints (see
+	// ParseFilterExpr); the model must be Filterable
+	Filter string
+	Sort   string
+	// Limit caps the rows exported (0 exports all)
+	Limit int
+	// Models defaults to the models registered with RegisterSchemaModels
+	Models []any
+}
+
+// ImportData inserts the rows read from r into the table of opts.Model in
+// the selected database.
+func (d *DBServiceImpl) ImportData(ctx context.Context, r io.Reader, opts DataImportOptions) (*ti.DataReport, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return ImportDataDB(ctx, db, r, opts)
+}
+
+// ExportData writes the rows of the table of opts.Model in the selected
+// database to w.
+func (d *DBServiceImpl) ExportData(ctx context.Context, w io.Writer, opts DataExportOptions) (*ti.DataReport, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return ExportDataDB(ctx, db, w, opts)
+}
+
+// ImportDataDB inserts the rows read from r into the table of opts.Model,
+// in batches. A batch that fails is written again row by row, so that only
+// the failing rows are reported.
+func ImportDataDB(ctx context.Context, db *gorm.DB, r io.Reader, opts DataImportOptions) (*ti.DataReport, error) {
+	if opts.Format == "" {
+		return nil, fmt.Errorf("❌ Formato dos dados não informado")
+	}
+	sch, err := dataModel(db, opts.Models, opts.Model)
+	if err != nil {
+		return nil, err
+	}
+	fields := seedFields(sch)
+	types := make(map[string]reflect.Type, len(fields))
+	for name, field := range fields {
+		if field.Creatable {
+			types[name] = field.FieldType
+		}
+	}
+	db = db.WithContext(ctx)
+
+	sink := func(batch []ti.DataRecord) ([]error, error) {
+		errs := make([]error, len(batch))
+		models := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(sch.ModelType)), 0, len(batch))
+		var rows []int
+	records:
+		for i, rec := range batch {
+			model := reflect.New(sch.ModelType)
+			for name, v := range rec {
+				if err := fields[name].Set(ctx, model, v); err != nil {
+					errs[i] = fmt.Errorf("campo '%s': %v", name, err)
+					continue records
+				}
+			}
+			// rows without a string or UUID primary key get a new one
+			if pk := sch.PrioritizedPrimaryField; pk != nil {
+				if _, zero := pk.ValueOf(ctx, model); zero {
+					switch {
+					case pk.FieldType == reflect.TypeOf(uuid.UUID{}):
+						_ = pk.Set(ctx, model, uuid.New())
+					case pk.FieldType.Kind() == reflect.String:
+						_ = pk.Set(ctx, model, uuid.NewString())
+					}
+				}
+			}
+			if p, ok := model.Interface().(SeedPreparer); ok {
+				if err := p.PrepareSeed(rec, nil); err != nil {
+					errs[i] = err
+					continue
+				}
+			}
+			models = reflect.Append(models, model)
+			rows = append(rows, i)
+		}
+		if opts.DryRun || models.Len() == 0 {
+			return errs, nil
+		}
+		if db.Create(models.Interface()).Error == nil {
+			return errs, nil
+		}
+		for j, i := range rows {
+			if err := db.Create(models.Index(j).Interface()).Error; err != nil {
+				errs[i] = err
+			}
+		}
+		return errs, nil
+	}
+
+	imp := ti.NewDataImporter(sink, &ti.DataMapping{
+		Columns:   opts.Columns,
+		Types:     types,
+		BatchSize: opts.BatchSize,
+		MaxErrors: opts.MaxErrors,
+	})
+	err = imp.Import(r, opts.Format)
+	return imp.Report(), err
+}
+
+// ExportDataDB writes the rows of the table of opts.Model to w, streaming
+// them from the database.
+func ExportDataDB(ctx context.Context, db *gorm.DB, w io.Writer, opts DataExportOptions) (*ti.DataReport, error) {
+	if opts.Format == "" {
+		return nil, fmt.Errorf("❌ Formato dos dados não informado")
+	}
+	sch, err := dataModel(db, opts.Models, opts.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	// the exported fields, by their record name
+	var names []string
+	byName := map[string]*schema.Field{}
+	for _, field := range sch.Fields {
+		if field.DBName == "" || !field.Readable {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.DBName
+		}
+		names = append(names, name)
+		byName[name] = field
+	}
+	if len(opts.Fields) > 0 {
+		fields := seedFields(sch)
+		selected := make([]string, 0, len(opts.Fields))
+		for _, f := range opts.Fields {
+			field, ok := fields[f]
+			if !ok {
+				return nil, fmt.Errorf("❌ Campo '%s' desconhecido no modelo '%s'", f, sch.Table)
+			}
+			i := slices.IndexFunc(names, func(name string) bool { return byName[name] == field })
+			if i < 0 {
+				return nil, fmt.Errorf("❌ Campo '%s' não exportável no modelo '%s'", f, sch.Table)
+			}
+			selected = append(selected, names[i])
+		}
+		names = selected
+	}
+
+	spec := &QuerySpec{Limit: opts.Limit}
+	if opts.Filter != "" || opts.Sort != "" {
+		filterable, ok := reflect.New(sch.ModelType).Interface().(Filterable)
+		if !ok {
+			return nil, fmt.Errorf("❌ O modelo '%s' não aceita filtros", sch.Table)
+		}
+		if spec.Filters, err = ParseFilterExpr(opts.Filter, filterable); err != nil {
+			return nil, err
+		}
+		if spec.Sort, err = ParseSortExpr(opts.Sort, filterable); err != nil {
+			return nil, err
+		}
+	}
+	q, err := spec.filtered(db.WithContext(ctx).Model(reflect.New(sch.ModelType).Interface()), sch)
+	if err != nil {
+		return nil, err
+	}
+	if q, err = spec.ordered(q, sch); err != nil {
+		return nil, err
+	}
+	if spec.Limit > 0 {
+		q = q.Limit(spec.Limit)
+	}
+
+	source := func(emit func(ti.DataRecord) error) error {
+		rows, err := q.Rows()
+		if err != nil {
+			return fmt.Errorf("❌ Erro ao ler '%s': %v", sch.Table, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			model := reflect.New(sch.ModelType)
+			if err := q.ScanRows(rows, model.Interface()); err != nil {
+				return fmt.Errorf("❌ Erro ao ler '%s': %v", sch.Table, err)
+			}
+			rec := make(ti.DataRecord, len(names))
+			for _, name := range names {
+				rec[name], _ = byName[name].ValueOf(ctx, model)
+			}
+			if err := emit(rec); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	exp := ti.NewDataExporter(source, &ti.DataMapping{Fields: names, Columns: opts.Columns})
+	err = exp.Export(w, opts.Format)
+	return exp.Report(), err
+}
+
+// dataModel returns the schema of the model whose table (or Go type name)
+// is name.
+func dataModel(db *gorm.DB, models []any, name string) (*schema.Schema, error) {
+	if len(models) == 0 {
+		models = SchemaModels()
+	}
+	var tables []string
+	for _, model := range models {
+		table := seedModelTable(db, model)
+		tables = append(tables, table)
+		if table != name && !strings.EqualFold(reflect.Indirect(reflect.ValueOf(model)).Type().Name(), name) {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("❌ Modelo inválido %s: %v", modelName(model), err)
+		}
+		return stmt.Schema, nil
+	}
+	slices.Sort(tables)
+	return nil, fmt.Errorf("❌ Nenhum modelo registrado para '%s' (tabelas: %s)", name, strings.Join(slices.Compact(tables), ", "))
+}
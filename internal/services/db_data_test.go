@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type dataProduct struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	SKU       string     `gorm:"uniqueIndex" json:"sku"`
+	Name      string     `json:"name"`
+	Price     float64    `json:"price"`
+	Active    bool       `json:"active"`
+	Secret    string     `json:"-"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (dataProduct) TableName() string          { return "products" }
+func (dataProduct) FilterableFields() []string { return []string{"price", "active"} }
+func (dataProduct) SortableFields() []string   { return []string{"price"} }
+
+func openDataTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "data.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&dataProduct{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	return db
+}
+
+func TestImportExportData(t *testing.T) {
+	ctx := context.Background()
+	db := openDataTestDB(t)
+	models := []any{&dataProduct{}}
+
+	csv := "code,name,price,active,expiresAt\n" +
+		"B-1,Bolt,0.25,yes,2026-01-02\n" +
+		"N-1,Nut,abc,no,\n" +
+		"B-1,Duplicate,1,no,\n" +
+		"W-1,Washer,2,true,\n"
+	opts := DataImportOptions{Model: "products", Format: "csv", Columns: map[string]string{"code": "sku"}, Models: models}
+
+	// a dry run writes nothing
+	dry := opts
+	dry.DryRun = true
+	report, err := ImportDataDB(ctx, db, strings.NewReader(csv), dry)
+	if err != nil || report.Processed != 3 {
+		t.Fatalf("dry run: %+v, %v", report, err)
+	}
+	var n int64
+	db.Model(&dataProduct{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("dry run wrote %d rows", n)
+	}
+
+	report, err = ImportDataDB(ctx, db, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("ImportDataDB: %v", err)
+	}
+	if report.Rows != 4 || report.Processed != 2 || report.Failed != 2 || len(report.Errors) != 2 ||
+		report.Errors[0].Row != 2 || report.Errors[0].Field != "price" || report.Errors[1].Row != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	var bolt dataProduct
+	db.Where("sku = ?", "B-1").First(&bolt)
+	if bolt.Name != "Bolt" || !bolt.Active || bolt.ExpiresAt == nil || bolt.ExpiresAt.Day() != 2 {
+		t.Fatalf("unexpected row %+v", bolt)
+	}
+
+	var out bytes.Buffer
+	report, err = ExportDataDB(ctx, db, &out, DataExportOptions{
+		Model: "dataProduct", Format: "jsonl", Fields: []string{"sku", "price"},
+		Filter: "active:true", Sort: "-price", Models: models,
+	})
+	if err != nil || report.Processed != 2 {
+		t.Fatalf("ExportDataDB: %+v, %v", report, err)
+	}
+	if want := "{\"sku\":\"W-1\",\"price\":2}\n{\"sku\":\"B-1\",\"price\":0.25}\n"; out.String() != want {
+		t.Fatalf("export:\n%s", out.String())
+	}
+
+	// the export of every field imports back into an empty table
+	out.Reset()
+	if _, err := ExportDataDB(ctx, db, &out, DataExportOptions{Model: "products", Format: "csv", Models: models}); err != nil {
+		t.Fatalf("ExportDataDB csv: %v", err)
+	}
+	if strings.Contains(out.String(), "Secret") || !strings.HasPrefix(out.String(), "id,sku,name,price,active,expiresAt\n") {
+		t.Fatalf("csv export:\n%s", out.String())
+	}
+	other := openDataTestDB(t)
+	report, err = ImportDataDB(ctx, other, &out, DataImportOptions{Model: "products", Format: "csv", Models: models})
+	if err != nil || report.Processed != 2 || report.Failed != 0 {
+		t.Fatalf("reimport: %+v, %v", report, err)
+	}
+	var copied dataProduct
+	other.First(&copied, bolt.ID)
+	if copied.SKU != "B-1" || copied.ExpiresAt == nil || !copied.ExpiresAt.Equal(*bolt.ExpiresAt) {
+		t.Fatalf("reimported %+v", copied)
+	}
+
+	for _, c := range []struct {
+		opts DataExportOptions
+		msg  string
+	}{
+		{DataExportOptions{Model: "nope", Format: "csv"}, "Nenhum modelo"},
+		{DataExportOptions{Model: "products", Format: "csv", Fields: []string{"Secret"}}, "não exportável"},
+		{DataExportOptions{Model: "products", Format: "csv", Filter: "name:x"}, "name"},
+		{DataExportOptions{Model: "products", Format: "pdf"}, "não suportado"},
+	} {
+		c.opts.Models = models
+		if _, err := ExportDataDB(ctx, db, &out, c.opts); err == nil || !strings.Contains(err.Error(), c.msg) {
+			t.Fatalf("%+v: expected %q, got %v", c.opts, c.msg, err)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Kinds of SchemaDrift.
+const (
+	DriftMissingTable  = "missing_table"
+	DriftMissingColumn = "missing_column"
+	DriftExtraColumn   = "extra_column"
+	DriftTypeMismatch  = "type_mismatch"
+	DriftNullability   = "nullability"
+	DriftPrimaryKey    = "primary_key"
+	DriftMissingIndex  = "missing_index"
+	DriftExtraIndex    = "extra_index"
+	DriftInvalidModel  = "invalid_model"
+)
+
+// Sources a SchemaDiff is computed against.
+const (
+	DiffSourceLive       = "live"
+	DiffSourceMigrations = "migrations"
+)
+
+// SchemaDrift is one difference between a model and its table. Expected is
+// what the model declares, Actual what the database has.
+type SchemaDrift struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Model    string `json:"model" yaml:"model"`
+	Table    string `json:"table" yaml:"table"`
+	Column   string `json:"column,omitempty" yaml:"column,omitempty"`
+	Index    string `json:"index,omitempty" yaml:"index,omitempty"`
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty" yaml:"actual,omitempty"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+// SchemaDiff is the result of comparing the registered models with a database.
+type SchemaDiff struct {
+	Source  string        `json:"source" yaml:"source"`
+	Dialect string        `json:"dialect" yaml:"dialect"`
+	Models  int           `json:"models" yaml:"models"`
+	Drifts  []SchemaDrift `json:"drifts" yaml:"drifts"`
+}
+
+// HasDrift reports whether any difference was found.
+func (d *SchemaDiff) HasDrift() bool { return d != nil && len(d.Drifts) > 0 }
+
+// Ignore drops the drifts of the given kinds.
+func (d *SchemaDiff) Ignore(kinds ...string) {
+	if d == nil || len(kinds) == 0 {
+		return
+	}
+	skip := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		skip[strings.TrimSpace(k)] = true
+	}
+	kept := d.Drifts[:0]
+	for _, drift := range d.Drifts {
+		if !skip[drift.Kind] {
+			kept = append(kept, drift)
+		}
+	}
+	d.Drifts = kept
+}
+
+var (
+	schemaModelsMu sync.RWMutex
+	schemaModels   []any
+)
+
+// RegisterSchemaModels registers GORM models (pointers to structs) checked by
+// DiffSchema when no model is given explicitly.
+func RegisterSchemaModels(models ...any) {
+	schemaModelsMu.Lock()
+	defer schemaModelsMu.Unlock()
+	for _, m := range models {
+		if m != nil {
+			schemaModels = append(schemaModels, m)
+		}
+	}
+}
+
+// SchemaModels returns the models registered with RegisterSchemaModels.
+func SchemaModels() []any {
+	schemaModelsMu.RLock()
+	defer schemaModelsMu.RUnlock()
+	return append([]any(nil), schemaModels...)
+}
+
+// DiffSchema compares models (the registered ones when none is given) with
+// the live database selected by ctx (see GetDB).
+func (d *DBServiceImpl) DiffSchema(ctx context.Context, models ...any) (*SchemaDiff, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := DiffSchema(db, models...)
+	if err != nil {
+		return nil, err
+	}
+	diff.Source = DiffSourceLive
+	return diff, nil
+}
+
+// DiffMigrations builds a scratch database of the same engine as the one
+// selected by ctx, applies the migrations of source (the embedded ones when
+// nil), compares models with it and drops it. SQLite scratch databases are
+// temporary files; the other engines get a temporary database on the same
+// server, so the configured user needs to be allowed to create databases.
+func (d *DBServiceImpl) DiffMigrations(ctx context.Context, source MigrationSource, models ...any) (*SchemaDiff, error) {
+	if d == nil {
+		return nil, fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	live, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	dbConf, err := d.selectedDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if source == nil {
+		source = EmbeddedMigrationSource()
+	}
+	migrations, err := source.Load()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao carregar migrations: %w", err)
+	}
+
+	scratch, cleanup, err := openScratchDatabase(ctx, live, dbConf)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	sqlDB, err := scratch.DB()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao obter conexão SQL: %v", err)
+	}
+	migrator, err := NewMigrator(sqlDB, scratch.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	migrations, err = dialectMigrations(migrations, migrator.Dialect())
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao traduzir migrations: %w", err)
+	}
+	if _, _, err := migrator.Up(ctx, migrations); err != nil {
+		return nil, fmt.Errorf("❌ Erro ao executar migrations no banco temporário: %w", err)
+	}
+	diff, err := DiffSchema(scratch, models...)
+	if err != nil {
+		return nil, err
+	}
+	diff.Source = DiffSourceMigrations
+	return diff, nil
+}
+
+// selectedDatabase returns the config of the database GetDB picks for ctx.
+func (d *DBServiceImpl) selectedDatabase(ctx context.Context) (*ti.Database, error) {
+	name, ok := ConnectionFromContext(ctx)
+	if !ok {
+		name, ok = d.GetDefaultDBName()
+	}
+	if !ok {
+		// same fallback as GetDB: the only enabled database
+		databases, _ := d.routes()
+		if enabled := enabledDatabases(&DBConfig{Databases: databases}); len(enabled) == 1 {
+			for n := range enabled {
+				name = n
+			}
+		}
+	}
+	dbConf, found := d.lookupDatabase(name)
+	if !found {
+		return nil, fmt.Errorf("❌ Banco de dados '%s' não encontrado na configuração", name)
+	}
+	return dbConf, nil
+}
+
+// openScratchDatabase creates an empty database of the engine of live.
+func openScratchDatabase(ctx context.Context, live *gorm.DB, dbConf *ti.Database) (*gorm.DB, func(), error) {
+	dialect := live.Dialector.Name()
+	scratchName := fmt.Sprintf("gdbase_diff_%d", time.Now().UnixNano())
+	cfg := *dbConf
+	cfg.Replicas = nil
+
+	if dialect == DialectSQLite {
+		dir, err := os.MkdirTemp("", "gdbase-diff-")
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Path, cfg.FilePath, cfg.Dsn, cfg.ConnectionString = filepath.Join(dir, scratchName+".db"), "", "", ""
+		db, _, err := connectDatabase(ctx, &cfg, live.Logger)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, nil, err
+		}
+		return db, func() {
+			closeGormDB(db)
+			_ = os.RemoveAll(dir)
+		}, nil
+	}
+
+	if cfg.Dsn != "" || cfg.ConnectionString != "" {
+		return nil, nil, fmt.Errorf("❌ Banco temporário indisponível para '%s': configure host, porta e usuário em vez de dsn/connection_string", dbConf.Name)
+	}
+	if cfg.KeyringEntry == "" {
+		// the password entry is derived from the name, which changes below
+		cfg.KeyringEntry = DatabaseKeyringEntry(dbConf)
+	}
+	cfg.Name = scratchName
+	if err := live.WithContext(ctx).Exec("CREATE DATABASE " + scratchName).Error; err != nil {
+		return nil, nil, fmt.Errorf("❌ Erro ao criar banco temporário: %w", err)
+	}
+	drop := func() {
+		if err := live.Exec("DROP DATABASE " + scratchName).Error; err != nil {
+			gl.Log("warn", fmt.Sprintf("Erro ao remover banco temporário %s: %v", scratchName, err))
+		}
+	}
+	db, _, err := connectDatabase(ctx, &cfg, live.Logger)
+	if err != nil {
+		drop()
+		return nil, nil, err
+	}
+	return db, func() {
+		closeGormDB(db)
+		drop()
+	}, nil
+}
+
+func closeGormDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+// DiffSchema compares models (the registered ones when none is given) with
+// the tables of db. Only the tables of the models are inspected; tables
+// without a model are not reported.
+func DiffSchema(db *gorm.DB, models ...any) (*SchemaDiff, error) {
+	if db == nil {
+		return nil, fmt.Errorf("❌ Banco de dados não inicializado")
+	}
+	if len(models) == 0 {
+		models = SchemaModels()
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("❌ Nenhum model registrado para comparar")
+	}
+	live, err := DescribeSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	enums := make(map[string]bool, len(live.Enums))
+	for _, e := range live.Enums {
+		enums[strings.ToLower(e.Name)] = true
+	}
+
+	diff := &SchemaDiff{Dialect: live.Dialect, Models: len(models), Drifts: make([]SchemaDrift, 0)}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			diff.Drifts = append(diff.Drifts, SchemaDrift{
+				Kind: DriftInvalidModel, Model: modelName(model),
+				Message: fmt.Sprintf("model cannot be parsed: %v", err),
+			})
+			continue
+		}
+		diff.Drifts = append(diff.Drifts, diffModel(db, stmt.Schema, live, enums)...)
+	}
+	sort.SliceStable(diff.Drifts, func(i, j int) bool {
+		a, b := diff.Drifts[i], diff.Drifts[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return diff, nil
+}
+
+func modelName(model any) string {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
+
+func diffModel(db *gorm.DB, sch *schema.Schema, live *Schema, enums map[string]bool) []SchemaDrift {
+	model := sch.ModelType.String()
+	drift := func(kind, column, expected, actual, msg string, args ...any) SchemaDrift {
+		return SchemaDrift{Kind: kind, Model: model, Table: sch.Table, Column: column,
+			Expected: expected, Actual: actual, Message: fmt.Sprintf(msg, args...)}
+	}
+
+	table, ok := live.Table(sch.Table)
+	if !ok {
+		return []SchemaDrift{drift(DriftMissingTable, "", sch.Table, "", "table %s does not exist", sch.Table)}
+	}
+
+	var out []SchemaDrift
+	modelColumns := make(map[string]bool)
+	for _, field := range modelFields(sch) {
+		modelColumns[strings.ToLower(field.DBName)] = true
+		col, ok := table.Column(field.DBName)
+		if !ok {
+			out = append(out, drift(DriftMissingColumn, field.DBName, field.Name, "",
+				"column %s.%s of field %s does not exist", sch.Table, field.DBName, field.Name))
+			continue
+		}
+
+		expectedType := db.Dialector.DataTypeOf(field)
+		if expectedType != "" {
+			want, got := columnTypeFamily(expectedType, enums), columnTypeFamily(col.Type, enums)
+			if !compatibleTypeFamilies(want, got) {
+				out = append(out, drift(DriftTypeMismatch, field.DBName, expectedType, col.Type,
+					"column %s.%s is %s, field %s expects %s", sch.Table, field.DBName, col.Type, field.Name, expectedType))
+			}
+		}
+
+		wantNullable := !field.NotNull && !field.PrimaryKey && nillableField(field)
+		if col.Nullable != wantNullable && !col.PrimaryKey {
+			if col.Nullable {
+				out = append(out, drift(DriftNullability, field.DBName, "NOT NULL", "NULL",
+					"column %s.%s accepts NULL but field %s (%s) cannot hold it", sch.Table, field.DBName, field.Name, field.FieldType))
+			} else {
+				out = append(out, drift(DriftNullability, field.DBName, "NULL", "NOT NULL",
+					"column %s.%s is NOT NULL but field %s (%s) is nullable", sch.Table, field.DBName, field.Name, field.FieldType))
+			}
+		}
+	}
+	for _, col := range table.Columns {
+		if !modelColumns[strings.ToLower(col.Name)] {
+			out = append(out, drift(DriftExtraColumn, col.Name, "", col.Type,
+				"column %s.%s has no field in %s", sch.Table, col.Name, model))
+		}
+	}
+
+	wantPK := strings.Join(sch.PrimaryFieldDBNames, ",")
+	if gotPK := strings.Join(table.PrimaryKey, ","); wantPK != "" && !strings.EqualFold(wantPK, gotPK) {
+		out = append(out, drift(DriftPrimaryKey, "", wantPK, gotPK,
+			"primary key of %s is (%s), model declares (%s)", sch.Table, gotPK, wantPK))
+	}
+
+	return append(out, diffIndexes(sch, table, drift)...)
+}
+
+// modelFields returns the fields of sch that are columns.
+func modelFields(sch *schema.Schema) []*schema.Field {
+	var out []*schema.Field
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		if _, isRelation := sch.Relationships.Relations[field.Name]; isRelation {
+			continue
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+// nillableField reports whether the Go type of field can hold a NULL.
+func nillableField(field *schema.Field) bool {
+	switch field.FieldType.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	// sql.NullString and friends
+	return strings.HasPrefix(field.FieldType.Name(), "Null")
+}
+
+type indexKey struct {
+	columns string
+	unique  bool
+}
+
+func diffIndexes(sch *schema.Schema, table *TableSchema, drift func(kind, column, expected, actual, msg string, args ...any) SchemaDrift) []SchemaDrift {
+	var expected []struct {
+		name string
+		key  indexKey
+	}
+	for _, idx := range sch.ParseIndexes() {
+		cols := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			cols = append(cols, strings.ToLower(f.DBName))
+		}
+		expected = append(expected, struct {
+			name string
+			key  indexKey
+		}{idx.Name, indexKey{strings.Join(cols, ","), strings.EqualFold(idx.Class, "UNIQUE")}})
+	}
+	for _, field := range modelFields(sch) {
+		if field.Unique && !field.PrimaryKey {
+			expected = append(expected, struct {
+				name string
+				key  indexKey
+			}{"unique " + field.DBName, indexKey{strings.ToLower(field.DBName), true}})
+		}
+	}
+
+	liveKeys := make(map[string]indexKey)
+	for _, idx := range table.Indexes {
+		if idx.Primary {
+			continue
+		}
+		cols := make([]string, len(idx.Columns))
+		for i, c := range idx.Columns {
+			cols[i] = strings.ToLower(c)
+		}
+		liveKeys[idx.Name] = indexKey{strings.Join(cols, ","), idx.Unique}
+	}
+	for _, col := range table.Columns {
+		if col.Unique && !col.PrimaryKey {
+			liveKeys["unique "+col.Name] = indexKey{strings.ToLower(col.Name), true}
+		}
+	}
+
+	var out []SchemaDrift
+	matched := make(map[string]bool)
+	for _, want := range expected {
+		found := false
+		for name, got := range liveKeys {
+			if got.columns == want.key.columns && (got.unique || !want.key.unique) {
+				matched[name] = true
+				found = true
+			}
+		}
+		if !found {
+			out = append(out, SchemaDrift{Kind: DriftMissingIndex, Model: sch.ModelType.String(), Table: sch.Table,
+				Index: want.name, Expected: want.key.columns,
+				Message: fmt.Sprintf("index %s (%s) of the model does not exist", want.name, want.key.columns)})
+		}
+	}
+	names := make([]string, 0, len(liveKeys))
+	for name := range liveKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// unique columns show up both as a column flag and as their index
+		if matched[name] || strings.HasPrefix(name, "unique ") {
+			continue
+		}
+		got := liveKeys[name]
+		if matched["unique "+got.columns] && got.unique {
+			continue
+		}
+		out = append(out, SchemaDrift{Kind: DriftExtraIndex, Model: sch.ModelType.String(), Table: sch.Table,
+			Index: name, Actual: got.columns,
+			Message: fmt.Sprintf("index %s (%s) is not declared by the model", name, got.columns)})
+	}
+	return out
+}
+
+// columnTypeFamily reduces a database type to a family comparable across the
+// spellings of the dialects (varchar(255) and text are both "text").
+func columnTypeFamily(dbType string, enums map[string]bool) string {
+	raw := strings.ToLower(strings.TrimSpace(dbType))
+	if strings.HasSuffix(raw, "[]") || strings.HasPrefix(raw, "_") || strings.HasPrefix(raw, "array") {
+		return "array"
+	}
+	if strings.HasPrefix(raw, "enum") || strings.HasPrefix(raw, "set(") || enums[raw] {
+		return "enum"
+	}
+	base := baseColumnType(raw)
+	switch {
+	case base == "tinyint" && strings.Contains(raw, "(1)"), base == "bool", base == "boolean", base == "bit":
+		return "bool"
+	case base == "int", base == "integer", base == "int2", base == "int4", base == "int8", base == "smallint",
+		base == "bigint", base == "mediumint", base == "tinyint", strings.HasSuffix(base, "serial"), base == "serial4", base == "serial8":
+		return "integer"
+	case base == "numeric", base == "decimal", base == "real", base == "money", base == "smallmoney",
+		strings.HasPrefix(base, "float"), strings.HasPrefix(base, "double"):
+		return "number"
+	case strings.Contains(base, "char"), strings.Contains(base, "text"), base == "citext", base == "string",
+		base == "clob", base == "ntext", base == "xml":
+		return "text"
+	case base == "uuid", base == "uniqueidentifier":
+		return "uuid"
+	case strings.HasPrefix(base, "timestamp"), strings.HasPrefix(base, "datetime"), strings.HasPrefix(base, "time"),
+		base == "date", base == "smalldatetime":
+		return "time"
+	case base == "json", base == "jsonb":
+		return "json"
+	case base == "bytea", strings.Contains(base, "blob"), strings.Contains(base, "binary"), base == "image":
+		return "bytes"
+	}
+	return base
+}
+
+// compatibleTypeFamilies reports whether a column of family got can hold a
+// field mapped to family want. Text fields are accepted on the engine specific
+// string-like types (uuid, enum, json), which GORM only knows as strings.
+func compatibleTypeFamilies(want, got string) bool {
+	if want == got {
+		return true
+	}
+	switch want {
+	case "text":
+		return got == "uuid" || got == "enum" || got == "json"
+	case "enum", "uuid":
+		return got == "text"
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type diffTestClient struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Name      string    `gorm:"column:name;not null;uniqueIndex:idx_diff_clients_name"`
+	Email     *string   `gorm:"column:email"`
+	Age       int       `gorm:"column:age"`
+	Status    string    `gorm:"column:status"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (diffTestClient) TableName() string { return "diff_clients" }
+
+type diffTestMissing struct {
+	ID int `gorm:"primaryKey"`
+}
+
+func (diffTestMissing) TableName() string { return "diff_missing" }
+
+func driftKinds(diff *SchemaDiff) map[string]string {
+	out := make(map[string]string)
+	for _, d := range diff.Drifts {
+		out[d.Kind+":"+d.Table+"."+d.Column+d.Index] = d.Actual
+	}
+	return out
+}
+
+func TestDiffSchemaLive(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+	for _, stmt := range []string{
+		// email is NOT NULL (field is a pointer), age is text, status is nullable
+		// (field is a string), legacy has no field and the unique index is missing
+		`CREATE TABLE diff_clients (id TEXT PRIMARY KEY, name TEXT NOT NULL, email TEXT NOT NULL, age TEXT NOT NULL, status TEXT, legacy INTEGER)`,
+		`CREATE INDEX idx_diff_clients_legacy ON diff_clients(legacy)`,
+	} {
+		if _, err := d.Exec(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	diff, err := d.DiffSchema(ctx, &diffTestClient{}, &diffTestMissing{})
+	if err != nil {
+		t.Fatalf("DiffSchema: %v", err)
+	}
+	if diff.Source != DiffSourceLive || !diff.HasDrift() {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	got := driftKinds(diff)
+	for _, want := range []string{
+		"missing_table:diff_missing.",
+		"missing_column:diff_clients.created_at",
+		"extra_column:diff_clients.legacy",
+		"type_mismatch:diff_clients.age",
+		"nullability:diff_clients.email",
+		"nullability:diff_clients.status",
+		"missing_index:diff_clients.idx_diff_clients_name",
+		"extra_index:diff_clients.idx_diff_clients_legacy",
+	} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("drift %s not reported: %+v", want, diff.Drifts)
+		}
+	}
+	if len(got) != 8 {
+		t.Errorf("unexpected drifts: %+v", diff.Drifts)
+	}
+
+	diff.Ignore(DriftExtraColumn, DriftExtraIndex)
+	if _, ok := driftKinds(diff)["extra_column:diff_clients.legacy"]; ok || len(diff.Drifts) != 6 {
+		t.Errorf("Ignore kept %+v", diff.Drifts)
+	}
+}
+
+func TestDiffMigrations(t *testing.T) {
+	d := newRoutingTestService(t)
+	d.config.Databases["transactional"].IsDefault = true
+	source := NewMapMigrationSource(map[string]string{
+		"001_clients.sql": `CREATE TABLE diff_clients (id TEXT PRIMARY KEY, name TEXT NOT NULL, email TEXT, age INTEGER NOT NULL, status TEXT NOT NULL, created_at DATETIME NOT NULL);
+CREATE UNIQUE INDEX idx_diff_clients_name ON diff_clients(name);`,
+	})
+
+	diff, err := d.DiffMigrations(context.Background(), source, &diffTestClient{})
+	if err != nil {
+		t.Fatalf("DiffMigrations: %v", err)
+	}
+	if diff.Source != DiffSourceMigrations || diff.HasDrift() {
+		t.Fatalf("expected no drift, got %+v", diff.Drifts)
+	}
+	// the scratch database is dropped: the live one never sees the table
+	if has := d.db["transactional"].Migrator().HasTable("diff_clients"); has {
+		t.Fatalf("migrations leaked into the live database")
+	}
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+)
+
+const redactedPassword = "***"
+
+// DSN is a connection string built for one database, with the database/sql
+// driver to open it and a redacted form safe for logs (same "***" convention
+// used by provider.Endpoint.Redacted).
+type DSN struct {
+	Dialect  string
+	Driver   string
+	Value    string
+	Redacted string
+}
+
+// sqlDriverName returns the database/sql driver registered for a dialect.
+func sqlDriverName(dialect string) string {
+	switch dialect {
+	case DialectPostgres:
+		return "pgx"
+	case DialectSQLite:
+		return "sqlite3"
+	default:
+		return dialect
+	}
+}
+
+// BuildDSN builds the connection string of a database according to its dialect.
+// An explicit ConnectionString (or Dsn) is used as is; otherwise the DSN is built
+// from host/port/credentials, TLS material, timezone, connect timeout,
+// application name and the extra driver Params. When no password is set, it is
+// looked up in the keyring entry of that database (see DatabaseKeyringEntry).
+func BuildDSN(dbConfig *ti.Database) (DSN, error) {
+	if dbConfig == nil {
+		return DSN{}, fmt.Errorf("database config is nil")
+	}
+	dialect := NormalizeDialect(dbConfig.Type)
+	if dialect == "" {
+		dialect = NormalizeDialect(dbConfig.Driver)
+	}
+	out := DSN{Dialect: dialect, Driver: sqlDriverName(dialect)}
+
+	if raw := firstNonEmpty(dbConfig.ConnectionString, dbConfig.Dsn); raw != "" {
+		raw, err := ti.ResolveSecret(context.Background(), raw)
+		if err != nil {
+			return out, err
+		}
+		out.Value = raw
+		out.Redacted = RedactDSN(raw)
+		return out, nil
+	}
+
+	password, err := ti.ResolveSecret(context.Background(), dbConfig.Password)
+	if err != nil {
+		return out, err
+	}
+	if password == "" && dialect != DialectSQLite {
+		if pass, err := getPasswordFromKeyring(DatabaseKeyringEntry(dbConfig)); err != nil {
+			gl.Log("error", fmt.Sprintf("❌ Erro ao recuperar senha do banco de dados '%s': %v", dbConfig.Name, err))
+		} else {
+			password = pass
+		}
+	}
+
+	var build func(*ti.Database, string) (string, error)
+	switch dialect {
+	case DialectPostgres:
+		build = buildPostgresDSN
+	case DialectMySQL:
+		build = buildMySQLDSN
+	case DialectSQLite:
+		build = buildSQLiteDSN
+	case DialectSQLServer:
+		build = buildSQLServerDSN
+	default:
+		return out, fmt.Errorf("banco de dados não suportado: %s", dbConfig.Type)
+	}
+
+	if out.Value, err = build(dbConfig, password); err != nil {
+		return out, err
+	}
+	if password == "" {
+		out.Redacted = out.Value
+	} else if out.Redacted, err = build(dbConfig, redactedPassword); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// DatabaseKeyringEntry returns the keyring entry that holds the password of a
+// database: KeyringEntry when set, otherwise "<name>_<Engine>" (e.g. kubex_db_Postgres).
+func DatabaseKeyringEntry(dbConfig *ti.Database) string {
+	if dbConfig.KeyringEntry != "" {
+		return dbConfig.KeyringEntry
+	}
+	label := map[string]string{
+		DialectPostgres:  "Postgres",
+		DialectMySQL:     "MySQL",
+		DialectSQLite:    "SQLite",
+		DialectSQLServer: "SQLServer",
+	}[NormalizeDialect(dbConfig.Type)]
+	if label == "" {
+		label = dbConfig.Type
+	}
+	return fmt.Sprintf("%s_%s", dbConfig.Name, label)
+}
+
+func buildPostgresDSN(db *ti.Database, password string) (string, error) {
+	kv := []string{
+		pgPair("host", firstNonEmpty(db.Host, "localhost")),
+		pgPair("port", portString(db.Port, "5432")),
+		pgPair("user", db.Username),
+	}
+	if password != "" {
+		kv = append(kv, pgPair("password", password))
+	}
+	kv = append(kv, pgPair("dbname", db.Name), pgPair("sslmode", firstNonEmpty(db.SSLMode, "disable")))
+	for _, p := range [][2]string{
+		{"sslcert", db.SSLCert},
+		{"sslkey", db.SSLKey},
+		{"sslrootcert", db.SSLRootCert},
+		{"TimeZone", db.TimeZone},
+		{"application_name", db.ApplicationName},
+	} {
+		if p[1] != "" {
+			kv = append(kv, pgPair(p[0], os.ExpandEnv(p[1])))
+		}
+	}
+	if db.ConnectTimeout > 0 {
+		kv = append(kv, pgPair("connect_timeout", strconv.Itoa(db.ConnectTimeout)))
+	}
+	for _, k := range sortedKeys(db.Params) {
+		kv = append(kv, pgPair(k, db.Params[k]))
+	}
+	return strings.Join(kv, " "), nil
+}
+
+// pgPair formats a key=value pair, quoting values as libpq expects.
+func pgPair(key, value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return key + "=" + value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return fmt.Sprintf("%s='%s'", key, value)
+}
+
+func buildMySQLDSN(db *ti.Database, password string) (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = db.Username
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%s", firstNonEmpty(db.Host, "localhost"), portString(db.Port, "3306"))
+	cfg.DBName = db.Name
+	cfg.ParseTime = true
+	cfg.Params = map[string]string{}
+
+	if db.TimeZone != "" {
+		loc, err := time.LoadLocation(db.TimeZone)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone %q: %w", db.TimeZone, err)
+		}
+		cfg.Loc = loc
+	}
+	if db.ConnectTimeout > 0 {
+		cfg.Timeout = time.Duration(db.ConnectTimeout) * time.Second
+	}
+	if db.ApplicationName != "" {
+		cfg.ConnectionAttributes = "program_name:" + db.ApplicationName
+	}
+
+	switch strings.ToLower(db.SSLMode) {
+	case "", "disable", "false":
+	case "require", "skip-verify":
+		cfg.TLSConfig = "skip-verify"
+	case "preferred", "prefer":
+		cfg.TLSConfig = "preferred"
+	default: // verify-ca, verify-full, true
+		cfg.TLSConfig = "true"
+		if db.SSLRootCert != "" || db.SSLCert != "" {
+			name := "gdbase-" + firstNonEmpty(db.Name, "default")
+			tlsCfg, err := loadTLSConfig(db, strings.ToLower(db.SSLMode) != "verify-ca")
+			if err != nil {
+				return "", err
+			}
+			if err := mysql.RegisterTLSConfig(name, tlsCfg); err != nil {
+				return "", fmt.Errorf("failed to register TLS config: %w", err)
+			}
+			cfg.TLSConfig = name
+		}
+	}
+	for k, v := range db.Params {
+		cfg.Params[k] = v
+	}
+	return cfg.FormatDSN(), nil
+}
+
+func buildSQLiteDSN(db *ti.Database, _ string) (string, error) {
+	path := os.ExpandEnv(firstNonEmpty(db.Path, db.FilePath, db.Name))
+	if path == "" {
+		return "", fmt.Errorf("sqlite database without path")
+	}
+	q := url.Values{}
+	if db.TimeZone != "" {
+		q.Set("_loc", db.TimeZone)
+	}
+	if db.ConnectTimeout > 0 {
+		q.Set("_busy_timeout", strconv.Itoa(db.ConnectTimeout*1000))
+	}
+	for k, v := range db.Params {
+		q.Set(k, v)
+	}
+	if len(q) == 0 {
+		return path, nil
+	}
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	return path + "?" + q.Encode(), nil
+}
+
+func buildSQLServerDSN(db *ti.Database, password string) (string, error) {
+	u := &url.URL{
+		Scheme: "sqlserver",
+		Host:   fmt.Sprintf("%s:%s", firstNonEmpty(db.Host, "localhost"), portString(db.Port, "1433")),
+	}
+	if password != "" {
+		u.User = url.UserPassword(db.Username, password)
+	} else if db.Username != "" {
+		u.User = url.User(db.Username)
+	}
+	q := url.Values{}
+	if db.Name != "" {
+		q.Set("database", db.Name)
+	}
+	switch strings.ToLower(db.SSLMode) {
+	case "":
+	case "disable", "false":
+		q.Set("encrypt", "disable")
+	case "require", "skip-verify":
+		q.Set("encrypt", "true")
+		q.Set("TrustServerCertificate", "true")
+	default:
+		q.Set("encrypt", "true")
+		q.Set("TrustServerCertificate", "false")
+		if db.SSLRootCert != "" {
+			q.Set("certificate", os.ExpandEnv(db.SSLRootCert))
+		}
+	}
+	if db.ApplicationName != "" {
+		q.Set("app name", db.ApplicationName)
+	}
+	if db.ConnectTimeout > 0 {
+		q.Set("connection timeout", strconv.Itoa(db.ConnectTimeout))
+	}
+	for k, v := range db.Params {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// loadTLSConfig builds a tls.Config from the CA/client certificate files of a database.
+func loadTLSConfig(db *ti.Database, verifyHost bool) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if verifyHost {
+		cfg.ServerName = db.Host
+	}
+	if db.SSLRootCert != "" {
+		pem, err := os.ReadFile(os.ExpandEnv(db.SSLRootCert))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssl root cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid ssl root cert %s", db.SSLRootCert)
+		}
+		cfg.RootCAs = pool
+	}
+	if db.SSLCert != "" && db.SSLKey != "" {
+		cert, err := tls.LoadX509KeyPair(os.ExpandEnv(db.SSLCert), os.ExpandEnv(db.SSLKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+var (
+	pgPasswordRe    = regexp.MustCompile(`(?i)(password=)('(?:[^'\\]|\\.)*'|\S+)`)
+	mysqlPasswordRe = regexp.MustCompile(`^([^:@/]*):([^@]*)@`)
+)
+
+// RedactDSN masks the password of a connection string in URL, key=value or
+// MySQL (user:pass@tcp(...)) form.
+func RedactDSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			if _, hasPass := u.User.Password(); hasPass {
+				u.User = url.UserPassword(u.User.Username(), redactedPassword)
+				// keep the mask readable instead of percent-encoded
+				return strings.Replace(u.String(), url.QueryEscape(redactedPassword), redactedPassword, 1)
+			}
+			q := u.Query()
+			if q.Has("password") {
+				q.Set("password", redactedPassword)
+				u.RawQuery = q.Encode()
+				return strings.Replace(u.String(), url.QueryEscape(redactedPassword), redactedPassword, 1)
+			}
+			return dsn
+		}
+	}
+	if pgPasswordRe.MatchString(dsn) {
+		return pgPasswordRe.ReplaceAllString(dsn, "${1}"+redactedPassword)
+	}
+	return mysqlPasswordRe.ReplaceAllString(dsn, "${1}:"+redactedPassword+"@")
+}
+
+// portString converts the loosely typed Port of the config into a string.
+func portString(port any, def string) string {
+	switch p := port.(type) {
+	case nil:
+		return def
+	case string:
+		if p == "" {
+			return def
+		}
+		return p
+	case int:
+		return strconv.Itoa(p)
+	case int64:
+		return strconv.FormatInt(p, 10)
+	case float64:
+		return strconv.FormatInt(int64(p), 10)
+	default:
+		return fmt.Sprintf("%v", p)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+)
+
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       *ti.Database
+		driver   string
+		contains []string
+	}{
+		{
+			name: "postgres",
+			db: &ti.Database{Type: "postgresql", Host: "db", Port: "5433", Username: "app", Password: "s3cr et",
+				Name: "kubex", SSLMode: "require", TimeZone: "UTC", ConnectTimeout: 5, ApplicationName: "gdbase"},
+			driver:   "pgx",
+			contains: []string{"host=db", "port=5433", "password='s3cr et'", "sslmode=require", "TimeZone=UTC", "connect_timeout=5", "application_name=gdbase"},
+		},
+		{
+			name:     "mysql",
+			db:       &ti.Database{Type: "mariadb", Host: "db", Port: 3307, Username: "app", Password: "pw", Name: "kubex", TimeZone: "America/Sao_Paulo"},
+			driver:   "mysql",
+			contains: []string{"app:pw@tcp(db:3307)/kubex", "parseTime=true", "loc=America%2FSao_Paulo"},
+		},
+		{
+			name:     "sqlite",
+			db:       &ti.Database{Type: "sqlite", Path: "/tmp/app.db", ConnectTimeout: 2},
+			driver:   "sqlite3",
+			contains: []string{"file:/tmp/app.db?", "_busy_timeout=2000"},
+		},
+		{
+			name:     "sqlserver",
+			db:       &ti.Database{Type: "sqlserver", Host: "db", Username: "sa", Password: "pw", Name: "kubex", SSLMode: "disable"},
+			driver:   "sqlserver",
+			contains: []string{"sqlserver://sa:pw@db:1433", "database=kubex", "encrypt=disable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := BuildDSN(tt.db)
+			if err != nil {
+				t.Fatalf("BuildDSN: %v", err)
+			}
+			if dsn.Driver != tt.driver {
+				t.Fatalf("driver = %q, want %q", dsn.Driver, tt.driver)
+			}
+			for _, part := range tt.contains {
+				if !strings.Contains(dsn.Value, part) {
+					t.Errorf("DSN %q does not contain %q", dsn.Value, part)
+				}
+			}
+			if tt.db.Password != "" && strings.Contains(dsn.Redacted, tt.db.Password) {
+				t.Errorf("redacted DSN leaks the password: %q", dsn.Redacted)
+			}
+		})
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	for in, want := range map[string]string{
+		"postgres://app:pw@db:5432/kubex":  "postgres://app:***@db:5432/kubex",
+		"host=db password=pw dbname=kubex": "host=db password=*** dbname=kubex",
+		"app:pw@tcp(db:3306)/kubex":        "app:***@tcp(db:3306)/kubex",
+		"/var/lib/app.db":                  "/var/lib/app.db",
+	} {
+		if got := RedactDSN(in); got != want {
+			t.Errorf("RedactDSN(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The filter language of the list endpoints:
+//
+//	filter: status:in:pending,failed;created_at:gte:2026-01-01;name:ilike:acme
+//	sort:   -created_at,name
+//
+// A filter is field:op:value, or field:value for eq; clauses are separated by
+// ';' and the values of in/nin by ','. A backslash escapes ';', ',', ':' or
+// itself. The ops are eq, ne, gt, gte, lt, lte, in, nin, like, ilike (both
+// match a substring, or a pattern when the value has '*'), null and notnull
+// (no value); an unknown op is an error. A sort field prefixed with '-' is
+// descending.
+//
+// Only the fields whitelisted by the model (Filterable) are accepted, and the
+// values are bound as parameters, never written into the SQL.
+
+// Filterable is implemented by the models exposed to the filter language: the
+// public names (json, column or Go field) of the fields that may be filtered
+// and sorted.
+type Filterable interface {
+	FilterableFields() []string
+	SortableFields() []string
+}
+
+// ListQuery carries the list parameters of an endpoint.
+type ListQuery struct {
+	Filter string `json:"filter,omitempty"`
+	Sort   string `json:"sort,omitempty"`
+	Page   int    `json:"page,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	// Cursor selects keyset pagination (use "" with Keyset for the first page)
+	Cursor string `json:"cursor,omitempty"`
+	Keyset bool   `json:"keyset,omitempty"`
+}
+
+// MaxListLimit caps ListQuery.Limit.
+const MaxListLimit = 500
+
+// ParseListQuery reads a ListQuery from the filter, sort, page, limit and
+// cursor parameters of a query string.
+func ParseListQuery(values url.Values) (ListQuery, error) {
+	q := ListQuery{
+		Filter: values.Get("filter"),
+		Sort:   values.Get("sort"),
+		Cursor: values.Get("cursor"),
+		Keyset: values.Has("cursor"),
+	}
+	for name, dst := range map[string]*int{"page": &q.Page, "limit": &q.Limit} {
+		if raw := values.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return q, fmt.Errorf("❌ Parâmetro '%s' inválido: '%s'", name, raw)
+			}
+			*dst = n
+		}
+	}
+	return q, nil
+}
+
+// Spec compiles the query for model into a QuerySpec.
+func (q ListQuery) Spec(model Filterable) (*QuerySpec, error) {
+	filters, err := ParseFilterExpr(q.Filter, model)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := ParseSortExpr(q.Sort, model)
+	if err != nil {
+		return nil, err
+	}
+	spec := &QuerySpec{Filters: filters, Sort: sort, Page: q.Page, Limit: min(q.Limit, MaxListLimit)}
+	if q.Keyset || q.Cursor != "" {
+		spec.Keyset, spec.Cursor = true, q.Cursor
+	}
+	return spec, nil
+}
+
+var filterOps = map[string]FilterOp{
+	"eq": OpEq, "ne": OpNe, "gt": OpGt, "gte": OpGte, "lt": OpLt, "lte": OpLte,
+	"in": OpIn, "nin": OpNotIn, "like": OpLike, "ilike": OpILike, "null": OpIsNull, "notnull": OpNotNull,
+}
+
+// ParseFilterExpr parses a filter expression, accepting only the filterable
+// fields of model.
+func ParseFilterExpr(expr string, model Filterable) ([]Filter, error) {
+	var allowed []string
+	if model != nil {
+		allowed = model.FilterableFields()
+	}
+	var filters []Filter
+	for i, clause := range splitEscaped(expr, ';', -1) {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+		parts := splitEscaped(clause, ':', 3)
+		field := strings.TrimSpace(unescapeFilter(parts[0]))
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("❌ Filtro %d: campo '%s' não pode ser filtrado (permitidos: %s)", i+1, field, strings.Join(allowed, ", "))
+		}
+		if len(parts) == 1 {
+			return nil, fmt.Errorf("❌ Filtro %d inválido '%s': use campo:operador:valor", i+1, unescapeFilter(clause))
+		}
+		opName, value := "eq", strings.Join(parts[1:], ":")
+		if _, isOp := filterOps[strings.ToLower(strings.TrimSpace(parts[1]))]; isOp {
+			opName, value = parts[1], ""
+			if len(parts) == 3 {
+				value = parts[2]
+			}
+		} else if len(parts) == 3 && looksLikeOp(parts[1]) {
+			return nil, fmt.Errorf("❌ Filtro %d: operador '%s' desconhecido (permitidos: %s)", i+1, parts[1], strings.Join(filterOpNames(), ", "))
+		}
+		op := filterOps[strings.ToLower(strings.TrimSpace(opName))]
+		f := Filter{Field: field, Op: op}
+		switch op {
+		case OpIsNull, OpNotNull:
+			if value != "" {
+				return nil, fmt.Errorf("❌ Filtro %d: '%s' não recebe valor", i+1, opName)
+			}
+		case OpIn, OpNotIn:
+			var values []string
+			for _, v := range splitEscaped(value, ',', -1) {
+				values = append(values, unescapeFilter(v))
+			}
+			f.Value = values
+		default:
+			if value == "" {
+				return nil, fmt.Errorf("❌ Filtro %d: valor ausente para '%s'", i+1, field)
+			}
+			f.Value = unescapeFilter(value)
+			if op == OpLike || op == OpILike {
+				f.Value = likePattern(f.Value.(string))
+			}
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// ParseSortExpr parses a comma separated sort ("-created_at,name"), accepting
+// only the sortable fields of model.
+func ParseSortExpr(expr string, model Filterable) ([]SortField, error) {
+	var allowed []string
+	if model != nil {
+		allowed = model.SortableFields()
+	}
+	var sort []SortField
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sf := SortField{Field: strings.TrimPrefix(part, "+")}
+		if strings.HasPrefix(part, "-") {
+			sf = SortField{Field: part[1:], Desc: true}
+		}
+		if !slices.Contains(allowed, sf.Field) {
+			return nil, fmt.Errorf("❌ Campo '%s' não pode ser ordenado (permitidos: %s)", sf.Field, strings.Join(allowed, ", "))
+		}
+		sort = append(sort, sf)
+	}
+	return sort, nil
+}
+
+// looksLikeOp tells whether the second part of field:x:y is meant as an
+// operator (a bare word) rather than the start of an eq value with ':'.
+func looksLikeOp(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func filterOpNames() []string {
+	names := make([]string, 0, len(filterOps))
+	for name := range filterOps {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// likeEscaper escapes the LIKE wildcards of a value (see likeEscape).
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// likePattern turns the value of like/ilike into a LIKE pattern: '*' is the
+// wildcard, and a value without it matches a substring. The '%' and '_' of
+// the value match literally.
+func likePattern(v string) string {
+	v = likeEscaper.Replace(v)
+	if strings.Contains(v, "*") {
+		return strings.ReplaceAll(v, "*", "%")
+	}
+	return "%" + v + "%"
+}
+
+// splitEscaped splits s at the unescaped sep into at most n parts (all of them
+// when n < 0), keeping the escapes.
+func splitEscaped(s string, sep byte, n int) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s) && (n < 0 || len(parts) < n-1); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescapeFilter(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type taskWhitelist struct{}
+
+func (taskWhitelist) FilterableFields() []string {
+	return []string{"title", "status", "priority", "dueAt", "notes"}
+}
+func (taskWhitelist) SortableFields() []string { return []string{"priority", "dueAt"} }
+
+func TestParseFilterExpr(t *testing.T) {
+	filters, err := ParseFilterExpr(`status:in:open,done;dueAt:gte:2026-01-01T03:00:00Z;title:ilike:TASK;notes:null;title:a\;b\:c`, taskWhitelist{})
+	if err != nil {
+		t.Fatalf("ParseFilterExpr: %v", err)
+	}
+	want := []Filter{
+		{Field: "status", Op: OpIn, Value: []string{"open", "done"}},
+		{Field: "dueAt", Op: OpGte, Value: "2026-01-01T03:00:00Z"},
+		{Field: "title", Op: OpILike, Value: "%TASK%"},
+		{Field: "notes", Op: OpIsNull},
+		{Field: "title", Op: OpEq, Value: "a;b:c"},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Fatalf("filters = %+v, want %+v", filters, want)
+	}
+
+	for expr, msg := range map[string]string{
+		"password:eq:x":       "não pode ser filtrado",
+		"status":              "use campo:operador:valor",
+		"status:eq:":          "valor ausente",
+		"notes:null:x":        "não recebe valor",
+		"title:eq:x;tenant:1": "'tenant'",
+		"status:foo:bar":      "operador 'foo' desconhecido",
+	} {
+		if _, err := ParseFilterExpr(expr, taskWhitelist{}); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q: expected %q, got %v", expr, msg, err)
+		}
+	}
+	filters, err = ParseFilterExpr(`title:like:50%_off\\;dueAt:2026-01-01T03:00:00Z`, taskWhitelist{})
+	if err != nil {
+		t.Fatalf("ParseFilterExpr: %v", err)
+	}
+	want = []Filter{
+		{Field: "title", Op: OpLike, Value: `%50\%\_off\\%`},
+		{Field: "dueAt", Op: OpEq, Value: "2026-01-01T03:00:00Z"},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Fatalf("filters = %+v, want %+v", filters, want)
+	}
+	if _, err := ParseSortExpr("-priority,title", taskWhitelist{}); err == nil {
+		t.Fatalf("expected title not to be sortable")
+	}
+}
+
+func TestListQuerySpec(t *testing.T) {
+	ctx := context.Background()
+	repo := newTaskRepository(t)
+
+	values, _ := url.ParseQuery("filter=" + url.QueryEscape("status:in:open;priority:gte:1;dueAt:lt:2026-01-01T06:30:00Z;title:ilike:TASK") + "&sort=-dueAt&limit=2")
+	q, err := ParseListQuery(values)
+	if err != nil {
+		t.Fatalf("ParseListQuery: %v", err)
+	}
+	spec, err := q.Spec(taskWhitelist{})
+	if err != nil {
+		t.Fatalf("Spec: %v", err)
+	}
+	page, err := repo.List(ctx, spec)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if page.Total != 2 || len(page.Data) != 2 || page.Data[0].Title != "task 5" || page.Data[1].Title != "task 1" {
+		t.Fatalf("unexpected page: total %d, %+v", page.Total, page.Data)
+	}
+
+	// values are bound, never part of the SQL
+	spec, _ = ListQuery{Filter: `title:x' OR 1=1 --`}.Spec(taskWhitelist{})
+	if n, err := repo.Count(ctx, spec); err != nil || n != 0 {
+		t.Fatalf("injection attempt matched %d rows, %v", n, err)
+	}
+	// the wildcards of a value match literally
+	for _, filter := range []string{"title:like:task_1", "title:ilike:%"} {
+		spec, _ = ListQuery{Filter: filter}.Spec(taskWhitelist{})
+		if n, err := repo.Count(ctx, spec); err != nil || n != 0 {
+			t.Fatalf("%s matched %d rows, %v", filter, n, err)
+		}
+	}
+	spec, _ = ListQuery{Filter: "title:like:task*1"}.Spec(taskWhitelist{})
+	if n, err := repo.Count(ctx, spec); err != nil || n != 1 {
+		t.Fatalf("task*1 matched %d rows, %v", n, err)
+	}
+	spec, _ = ListQuery{Filter: "priority:gt:high"}.Spec(taskWhitelist{})
+	if _, err := repo.List(ctx, spec); err == nil || !strings.Contains(err.Error(), "Valor inválido 'high'") {
+		t.Fatalf("expected a conversion error, got %v", err)
+	}
+	if _, err := ParseListQuery(url.Values{"page": {"-1"}}); err == nil {
+		t.Fatalf("expected an invalid page error")
+	}
+}
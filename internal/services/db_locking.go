@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// LockVersionColumn is the column picked up by convention as the optimistic
+// lock of a model. Any other integer or time field can opt in with the
+// `gdbase:"lock"` struct tag (e.g. UpdatedAt for an updated_at compare-and-swap).
+const LockVersionColumn = "lock_version"
+
+const lockTag = "lock"
+
+// DefaultStaleAttempts is the number of attempts used by the repositories that
+// retry an update lost to a concurrent writer.
+const DefaultStaleAttempts = 3
+
+// ErrStaleObject is matched (errors.Is) by the *StaleObjectError returned when
+// an optimistic update finds the row changed or deleted since it was read.
+var ErrStaleObject = errors.New("stale object")
+
+// StaleObjectError describes a lost optimistic update: the record was read
+// with Expected in Column, which no longer matches the database.
+type StaleObjectError struct {
+	Table    string
+	Keys     []any
+	Column   string
+	Expected any
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("❌ Registro %v de '%s' alterado por outra operação (%s esperado: %v)", e.Keys, e.Table, e.Column, e.Expected)
+}
+
+func (e *StaleObjectError) Unwrap() error { return ErrStaleObject }
+
+// LockField returns the optimistic lock field of a model schema: the field
+// tagged `gdbase:"lock"` or the LockVersionColumn, nil when it has none.
+func LockField(s *schema.Schema) *schema.Field {
+	if s == nil {
+		return nil
+	}
+	for _, f := range s.Fields {
+		for _, opt := range strings.Split(f.Tag.Get("gdbase"), ",") {
+			if strings.TrimSpace(opt) == lockTag {
+				return f
+			}
+		}
+	}
+	return s.LookUpField(LockVersionColumn)
+}
+
+// SaveOptimistic saves value (a pointer to a model) like db.Save, except that
+// models with a lock field (see LockField) are updated only while the lock
+// still holds the value they were read with: the version is incremented (or
+// the time refreshed) in the same UPDATE, and a *StaleObjectError is returned
+// when another writer got there first. Models without a lock field, and new
+// records, are saved as usual.
+func SaveOptimistic(db *gorm.DB, value any) error {
+	if db == nil {
+		return fmt.Errorf("❌ Conexão com o banco de dados não inicializada")
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("❌ SaveOptimistic requer um ponteiro para struct, recebido %T", value)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+	field := LockField(stmt.Schema)
+	if field == nil {
+		return db.Save(value).Error
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rv = rv.Elem()
+
+	keys := make([]any, 0, len(stmt.Schema.PrimaryFields))
+	for _, pk := range stmt.Schema.PrimaryFields {
+		if v, zero := pk.ValueOf(ctx, rv); !zero {
+			keys = append(keys, v)
+		}
+	}
+	expected, unset := field.ValueOf(ctx, rv)
+	if len(keys) == 0 {
+		return createVersioned(db, value, field, rv, unset)
+	}
+
+	next, err := nextLockValue(field, expected)
+	if err != nil {
+		return err
+	}
+	if err := field.Set(ctx, rv, next); err != nil {
+		return err
+	}
+	res := db.Model(value).Select("*").
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: expected}).
+		Updates(value)
+	if res.Error == nil && res.RowsAffected > 0 {
+		return nil
+	}
+	_ = field.Set(ctx, rv, expected)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	// a record that was never saved with a lock may simply not exist yet
+	if unset {
+		var count int64
+		exists := db.Model(value)
+		for _, pk := range stmt.Schema.PrimaryFields {
+			if v, zero := pk.ValueOf(ctx, rv); !zero {
+				exists = exists.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: v})
+			}
+		}
+		if err := exists.Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return createVersioned(db, value, field, rv, unset)
+		}
+	}
+	return &StaleObjectError{Table: stmt.Table, Keys: keys, Column: field.DBName, Expected: lockValue(expected)}
+}
+
+// createVersioned inserts a new record, starting its version at 1.
+func createVersioned(db *gorm.DB, value any, field *schema.Field, rv reflect.Value, unset bool) error {
+	if unset && isVersionField(field) {
+		if err := field.Set(db.Statement.Context, rv, 1); err != nil {
+			return err
+		}
+	}
+	return db.Create(value).Error
+}
+
+// lockValue dereferences the value of a pointer lock field.
+func lockValue(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer {
+		return v
+	}
+	if rv.IsNil() {
+		return nil
+	}
+	return rv.Elem().Interface()
+}
+
+func isVersionField(field *schema.Field) bool {
+	return field.DataType == schema.Int || field.DataType == schema.Uint
+}
+
+// nextLockValue returns the value written by an optimistic update.
+func nextLockValue(field *schema.Field, current any) (any, error) {
+	switch {
+	case isVersionField(field):
+		v := reflect.Indirect(reflect.ValueOf(current))
+		if !v.IsValid() {
+			return 1, nil
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int() + 1, nil
+		default:
+			return v.Uint() + 1, nil
+		}
+	case field.DataType == schema.Time:
+		return time.Now(), nil
+	default:
+		return nil, fmt.Errorf("❌ Campo de lock '%s' deve ser inteiro ou data/hora", field.Name)
+	}
+}
+
+// RetryOnStale runs fn up to attempts times while it fails with ErrStaleObject.
+// fn must read the record again on every call, so that the update is applied
+// to the current version.
+func RetryOnStale(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(ctx); !errors.Is(err, ErrStaleObject) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return err
+		}
+	}
+	return err
+}
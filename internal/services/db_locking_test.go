@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type versionedJob struct {
+	ID          uint
+	Status      string
+	LockVersion int
+}
+
+type casNote struct {
+	ID        uint
+	Body      string
+	UpdatedAt time.Time `gdbase:"lock"`
+}
+
+func openLockingDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "locking.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&versionedJob{}, &casNote{}, &captureAccount{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	return db
+}
+
+func TestSaveOptimisticVersion(t *testing.T) {
+	db := openLockingDB(t)
+	job := versionedJob{Status: "pending"}
+	if err := SaveOptimistic(db, &job); err != nil || job.LockVersion != 1 {
+		t.Fatalf("create = version %d, %v", job.LockVersion, err)
+	}
+
+	var first, second versionedJob
+	db.First(&first, job.ID)
+	db.First(&second, job.ID)
+	first.Status = "running"
+	if err := SaveOptimistic(db, &first); err != nil || first.LockVersion != 2 {
+		t.Fatalf("update = version %d, %v", first.LockVersion, err)
+	}
+	second.Status = "cancelled"
+	err := SaveOptimistic(db, &second)
+	var stale *StaleObjectError
+	if !errors.Is(err, ErrStaleObject) || !errors.As(err, &stale) || stale.Column != LockVersionColumn || stale.Expected != 1 {
+		t.Fatalf("expected a stale object error, got %v", err)
+	}
+	if second.LockVersion != 1 {
+		t.Fatalf("lock version not restored: %d", second.LockVersion)
+	}
+
+	// retrying with a fresh read applies the update on the current version
+	attempts := 0
+	err = RetryOnStale(context.Background(), 3, func(ctx context.Context) error {
+		attempts++
+		var current versionedJob
+		if attempts > 1 {
+			db.First(&current, job.ID)
+		} else {
+			current = second
+		}
+		current.Status = "cancelled"
+		return SaveOptimistic(db.WithContext(ctx), &current)
+	})
+	var saved versionedJob
+	db.First(&saved, job.ID)
+	if err != nil || attempts != 2 || saved.Status != "cancelled" || saved.LockVersion != 3 {
+		t.Fatalf("RetryOnStale = %v after %d attempts, saved %+v", err, attempts, saved)
+	}
+
+	// like Save, a record with its key set but not stored yet is created
+	if err := SaveOptimistic(db, &versionedJob{ID: 42, Status: "new"}); err != nil {
+		t.Fatalf("create with key: %v", err)
+	}
+	var created versionedJob
+	if db.First(&created, 42).Error != nil || created.LockVersion != 1 {
+		t.Fatalf("record with key not created: %+v", created)
+	}
+
+	// models without a lock field are saved as usual
+	if err := SaveOptimistic(db, &captureAccount{ID: 7, Email: "a@b.c"}); err != nil {
+		t.Fatalf("plain save: %v", err)
+	}
+}
+
+func TestSaveOptimisticUpdatedAt(t *testing.T) {
+	db := openLockingDB(t)
+	note := casNote{Body: "a"}
+	if err := SaveOptimistic(db, &note); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var first, second casNote
+	db.First(&first, note.ID)
+	db.First(&second, note.ID)
+	first.Body = "b"
+	if err := SaveOptimistic(db, &first); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !first.UpdatedAt.After(second.UpdatedAt) {
+		t.Fatalf("updated_at not refreshed")
+	}
+	second.Body = "c"
+	if err := SaveOptimistic(db, &second); !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+}
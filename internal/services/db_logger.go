@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	evs "github.com/kubex-ecosystem/gdbase/internal/events"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	l "github.com/kubex-ecosystem/logz"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SlowQueryEvent is the DatabaseEvents event emitted with a SlowQuery when a
+// statement takes longer than the slow threshold of its database.
+const SlowQueryEvent = "slow_query"
+
+const defaultSlowQueryThreshold = 200 * time.Millisecond
+
+// QueryLogConfig configures how the GORM statements are logged.
+type QueryLogConfig struct {
+	// Level is the GORM log level: silent, error, warn (default) or info (every statement)
+	Level string `json:"level,omitempty" yaml:"level,omitempty" xml:"level,omitempty" toml:"level,omitempty" mapstructure:"level,omitempty"`
+	// SlowThreshold is the default slow query threshold (e.g. "500ms", default 200ms); Database.SlowQueryThreshold overrides it
+	SlowThreshold string `json:"slow_threshold,omitempty" yaml:"slow_threshold,omitempty" xml:"slow_threshold,omitempty" toml:"slow_threshold,omitempty" mapstructure:"slow_threshold,omitempty"`
+	// SampleRate is the fraction (0-1] of the regular statements logged at info level; slow and failed ones are always logged
+	SampleRate float64 `json:"sample_rate,omitempty" yaml:"sample_rate,omitempty" xml:"sample_rate,omitempty" toml:"sample_rate,omitempty" mapstructure:"sample_rate,omitempty"`
+	// RedactColumns is the pattern of the columns whose arguments are redacted (default DefaultSensitiveColumns)
+	RedactColumns string `json:"redact_columns,omitempty" yaml:"redact_columns,omitempty" xml:"redact_columns,omitempty" toml:"redact_columns,omitempty" mapstructure:"redact_columns,omitempty"`
+	// Parameterized logs the statements with their placeholders instead of the arguments
+	Parameterized bool `json:"parameterized,omitempty" yaml:"parameterized,omitempty" xml:"parameterized,omitempty" toml:"parameterized,omitempty" mapstructure:"parameterized,omitempty"`
+	// LogRecordNotFound logs gorm.ErrRecordNotFound as an error
+	LogRecordNotFound bool `json:"log_record_not_found,omitempty" yaml:"log_record_not_found,omitempty" xml:"log_record_not_found,omitempty" toml:"log_record_not_found,omitempty" mapstructure:"log_record_not_found,omitempty"`
+}
+
+// SlowQuery is the payload of SlowQueryEvent.
+type SlowQuery struct {
+	Database  string
+	SQL       string
+	Duration  time.Duration
+	Threshold time.Duration
+	Rows      int64
+	Caller    string
+	RequestID string
+	Time      time.Time
+}
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID added to the
+// statement logs and slow query events.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set with WithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(requestIDCtxKey{}).(string)
+	return id, ok && id != ""
+}
+
+// GormLogger is a gorm logger.Interface writing structured entries to logz:
+// the database name, duration, rows, caller and request ID of every statement,
+// with the arguments bound to sensitive columns redacted.
+type GormLogger struct {
+	logger        l.Logger
+	database      string
+	dialect       string
+	level         logger.LogLevel
+	slow          time.Duration
+	sampleRate    float64
+	redact        *regexp.Regexp
+	parameterized bool
+	logNotFound   bool
+	bus           *evs.EventBus
+}
+
+// NewGormLogger builds a logger for the named database from cfg (nil uses the defaults).
+func NewGormLogger(lgr l.Logger, database, dialect string, cfg *QueryLogConfig) *GormLogger {
+	if lgr == nil {
+		lgr = l.GetLogger("GDBase")
+	}
+	if cfg == nil {
+		cfg = &QueryLogConfig{}
+	}
+	g := &GormLogger{
+		logger:        lgr,
+		database:      database,
+		dialect:       NormalizeDialect(dialect),
+		level:         parseGormLogLevel(cfg.Level),
+		slow:          defaultSlowQueryThreshold,
+		sampleRate:    cfg.SampleRate,
+		redact:        DefaultSensitiveColumns,
+		parameterized: cfg.Parameterized,
+		logNotFound:   cfg.LogRecordNotFound,
+	}
+	if g.sampleRate <= 0 || g.sampleRate > 1 {
+		g.sampleRate = 1
+	}
+	if d, err := time.ParseDuration(cfg.SlowThreshold); err == nil && d >= 0 {
+		g.slow = d
+	}
+	if cfg.RedactColumns != "" {
+		if re, err := regexp.Compile(cfg.RedactColumns); err == nil {
+			g.redact = re
+		} else {
+			lgr.WarnCtx(fmt.Sprintf("invalid query_log.redact_columns, using the default: %v", err), nil)
+		}
+	}
+	return g
+}
+
+// WithSlowThreshold returns a copy of the logger flagging the statements slower
+// than d; zero disables slow query detection.
+func (g *GormLogger) WithSlowThreshold(d time.Duration) *GormLogger {
+	c := *g
+	c.slow = d
+	return &c
+}
+
+// WithEventBus returns a copy of the logger emitting SlowQueryEvent on bus.
+func (g *GormLogger) WithEventBus(bus *evs.EventBus) *GormLogger {
+	c := *g
+	c.bus = bus
+	return &c
+}
+
+func parseGormLogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info", "debug":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// LogMode implements logger.Interface.
+func (g *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	c := *g
+	c.level = level
+	return &c
+}
+
+// Info implements logger.Interface.
+func (g *GormLogger) Info(ctx context.Context, msg string, data ...any) {
+	if g.level >= logger.Info {
+		g.logger.InfoCtx(fmt.Sprintf(msg, data...), g.fields(ctx))
+	}
+}
+
+// Warn implements logger.Interface.
+func (g *GormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if g.level >= logger.Warn {
+		g.logger.WarnCtx(fmt.Sprintf(msg, data...), g.fields(ctx))
+	}
+}
+
+// Error implements logger.Interface.
+func (g *GormLogger) Error(ctx context.Context, msg string, data ...any) {
+	if g.level >= logger.Error {
+		g.logger.ErrorCtx(fmt.Sprintf(msg, data...), g.fields(ctx))
+	}
+}
+
+// ParamsFilter redacts the arguments bound to sensitive columns before GORM
+// inlines them in the logged statement.
+func (g *GormLogger) ParamsFilter(_ context.Context, sql string, params ...any) (string, []any) {
+	if g.parameterized {
+		return sql, nil
+	}
+	return sql, redactArgs(sql, g.dialect, params, g.redact)
+}
+
+// Trace implements logger.Interface: failed statements are logged as errors,
+// slow ones as warnings (and emitted on the event bus) and, at info level, a
+// sample of the others.
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := g.slow > 0 && elapsed > g.slow
+	failed := err != nil && (g.logNotFound || !errors.Is(err, gorm.ErrRecordNotFound))
+
+	switch {
+	case failed && g.level >= logger.Error:
+	case slow && (g.level >= logger.Warn || g.bus != nil):
+	case g.level >= logger.Info && (g.sampleRate >= 1 || rand.Float64() < g.sampleRate):
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	fields := g.fields(ctx)
+	fields["sql"] = sql
+	fields["rows"] = rows
+	fields["duration_ms"] = float64(elapsed.Microseconds()) / 1000
+	fields["caller"] = captureCaller()
+
+	switch {
+	case failed && g.level >= logger.Error:
+		fields["error"] = err.Error()
+		g.logger.ErrorCtx("query failed", fields)
+	case slow:
+		fields["slow_threshold_ms"] = g.slow.Milliseconds()
+		if g.level >= logger.Warn {
+			g.logger.WarnCtx("slow query", fields)
+		}
+		if g.bus != nil {
+			requestID, _ := RequestIDFromContext(ctx)
+			g.bus.Emit(DatabaseEvents, SlowQueryEvent, SlowQuery{
+				Database:  g.database,
+				SQL:       sql,
+				Duration:  elapsed,
+				Threshold: g.slow,
+				Rows:      rows,
+				Caller:    fields["caller"].(string),
+				RequestID: requestID,
+				Time:      begin,
+			})
+		}
+	default:
+		g.logger.InfoCtx("query", fields)
+	}
+}
+
+func (g *GormLogger) fields(ctx context.Context) map[string]any {
+	fields := map[string]any{"component": "gorm"}
+	if g.database != "" {
+		fields["database"] = g.database
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		fields["request_id"] = id
+	}
+	return fields
+}
+
+// gormLogger returns the logger of a database, configured by DBConfig.QueryLog
+// and Database.SlowQueryThreshold.
+func (d *DBServiceImpl) gormLogger(dbConfig *ti.Database) *GormLogger {
+	var cfg *QueryLogConfig
+	if d.config != nil {
+		cfg = d.config.QueryLog
+	}
+	g := NewGormLogger(d.Logger, dbConfig.Name, dbConfig.Type, cfg).WithEventBus(d.GetEventBus())
+	if dbConfig.SlowQueryThreshold != "" {
+		if parsed, err := time.ParseDuration(dbConfig.SlowQueryThreshold); err == nil && parsed >= 0 {
+			g = g.WithSlowThreshold(parsed)
+		}
+	}
+	return g
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	evs "github.com/kubex-ecosystem/gdbase/internal/events"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	l "github.com/kubex-ecosystem/logz"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]any
+}
+
+type recordingLogger struct {
+	l.Logger
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (r *recordingLogger) record(level, msg string, fields map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, logEntry{level, msg, fields})
+}
+
+func (r *recordingLogger) InfoCtx(msg string, fields map[string]any)  { r.record("info", msg, fields) }
+func (r *recordingLogger) WarnCtx(msg string, fields map[string]any)  { r.record("warn", msg, fields) }
+func (r *recordingLogger) ErrorCtx(msg string, fields map[string]any) { r.record("error", msg, fields) }
+
+func (r *recordingLogger) take() []logEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.entries
+	r.entries = nil
+	return out
+}
+
+func TestGormLogger(t *testing.T) {
+	rec := &recordingLogger{Logger: l.NewLogger("test")}
+	bus := evs.NewEventBus()
+	slowQueries := make(chan SlowQuery, 8)
+	bus.On(DatabaseEvents, SlowQueryEvent, func(args ...any) { slowQueries <- args[0].(SlowQuery) })
+
+	gormLog := NewGormLogger(rec, "main", "sqlite", &QueryLogConfig{Level: "info"}).WithEventBus(bus)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "log.db")), &gorm.Config{Logger: gormLog})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&captureAccount{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	rec.take()
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	if err := db.WithContext(ctx).Create(&captureAccount{Email: "a@b.c", PasswordHash: "s3cr3t"}).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	entries := rec.take()
+	if len(entries) != 1 || entries[0].level != "info" {
+		t.Fatalf("expected one info entry, got %+v", entries)
+	}
+	f := entries[0].fields
+	sql, _ := f["sql"].(string)
+	if f["database"] != "main" || f["request_id"] != "req-1" || f["rows"] != int64(1) {
+		t.Fatalf("unexpected fields: %+v", f)
+	}
+	if !strings.Contains(sql, `"a@b.c","***"`) || strings.Contains(sql, "s3cr3t") {
+		t.Fatalf("password not redacted: %s", sql)
+	}
+	if !strings.Contains(f["caller"].(string), "db_logger_test.go") {
+		t.Fatalf("unexpected caller %v", f["caller"])
+	}
+
+	// Failed statements are errors; warn level hides the regular ones
+	quiet := db.Session(&gorm.Session{Logger: gormLog.LogMode(logger.Warn)})
+	quiet.Exec("SELECT * FROM missing_table")
+	quiet.Find(&[]captureAccount{})
+	entries = rec.take()
+	if len(entries) != 1 || entries[0].level != "error" || entries[0].fields["error"] == nil {
+		t.Fatalf("expected only the failure, got %+v", entries)
+	}
+
+	// Slow queries are warnings and events, even when sampled out
+	slow := gormLog.WithSlowThreshold(time.Nanosecond)
+	slow.sampleRate = 0.0001
+	db.Session(&gorm.Session{Logger: slow}).WithContext(ctx).Find(&[]captureAccount{})
+	entries = rec.take()
+	if len(entries) != 1 || entries[0].msg != "slow query" {
+		t.Fatalf("expected a slow query warning, got %+v", entries)
+	}
+	select {
+	case ev := <-slowQueries:
+		if ev.Database != "main" || ev.RequestID != "req-1" || ev.Threshold != time.Nanosecond || !strings.Contains(ev.SQL, "capture_accounts") {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("slow query event not emitted")
+	}
+}
+
+func TestDatabaseSlowQueryThreshold(t *testing.T) {
+	d := newRoutingTestService(t)
+	d.config.QueryLog = &QueryLogConfig{SlowThreshold: "2s", RedactColumns: "(?i)email"}
+	g := d.gormLogger(d.config.Databases["analytics"])
+	if g.slow != 2*time.Second || g.database != "analytics" || !g.redact.MatchString("Email") {
+		t.Fatalf("QueryLog not applied: %+v", g)
+	}
+	d.config.Databases["analytics"].SlowQueryThreshold = "50ms"
+	if g := d.gormLogger(d.config.Databases["analytics"]); g.slow != 50*time.Millisecond {
+		t.Fatalf("per-database threshold not applied: %v", g.slow)
+	}
+}
+
+func TestServiceLoggerIsPerConnection(t *testing.T) {
+	global := logger.Default
+	d := newRoutingTestService(t)
+	db, err := d.connect(context.Background(), &ti.Database{Name: "ledger", Type: "sqlite", Path: filepath.Join(t.TempDir(), "ledger.db")})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer closeGormDB(db)
+	if logger.Default != global {
+		t.Fatalf("the GORM default logger was replaced")
+	}
+	if g, ok := db.Logger.(*GormLogger); !ok || g.database != "ledger" {
+		t.Fatalf("connection logger = %#v", db.Logger)
+	}
+}
@@ -0,0 +1,846 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubex-ecosystem/gdbase/internal/bootstrap"
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+)
+
+// DefaultMigrationsTable is the bookkeeping table used to track applied migrations.
+const DefaultMigrationsTable = "schema_migrations"
+
+var (
+	// ErrMigrationChecksumMismatch is returned when an applied migration was edited after being applied.
+	ErrMigrationChecksumMismatch = errors.New("migration checksum mismatch")
+	// ErrMigrationNoDown is returned when a rollback is requested for a migration without a down script.
+	ErrMigrationNoDown = errors.New("migration has no down script")
+	// ErrMigrationLocked is returned when another process holds the migration lock past the lock timeout.
+	ErrMigrationLocked = errors.New("migration lock held by another process")
+)
+
+// DefaultMigrationLockTimeout is how long Up, Down and Baseline wait for the
+// migration lock held by another process.
+const DefaultMigrationLockTimeout = time.Minute
+
+// migrationLockPoll is the interval between two attempts to take the lock.
+const migrationLockPoll = 500 * time.Millisecond
+
+// Supported SQL dialects, as reported by gorm.Dialector.Name().
+const (
+	DialectPostgres  = "postgres"
+	DialectMySQL     = "mysql"
+	DialectSQLite    = "sqlite"
+	DialectSQLServer = "sqlserver"
+)
+
+// NormalizeDialect maps the database type/driver names accepted in the config
+// (postgresql, mariadb, sqlite3, mssql...) to one of the Dialect* constants.
+func NormalizeDialect(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "postgres", "postgresql", "pgx", "pq":
+		return DialectPostgres
+	case "mysql", "mariadb":
+		return DialectMySQL
+	case "sqlite", "sqlite3":
+		return DialectSQLite
+	case "sqlserver", "mssql":
+		return DialectSQLServer
+	default:
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+}
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	// Dialect restricts the migration to one dialect; empty means any dialect.
+	Dialect string
+	Up      string
+	Down    string
+	// NoTx disables the per-migration transaction (e.g. CREATE INDEX CONCURRENTLY).
+	NoTx bool
+	// Source is the up script Up was translated from (see TranslateMigrations).
+	Source string
+}
+
+// Checksum returns the SHA-256 of the up script, used to detect edited
+// migrations. A translated migration is identified by its source script, so a
+// change of the translator does not flag the migrations already applied.
+func (m Migration) Checksum() string {
+	script := m.Up
+	if m.Source != "" {
+		script = m.Source
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(script)))
+	return hex.EncodeToString(sum[:])
+}
+
+// AppliedMigration is a row of the migrations table.
+type AppliedMigration struct {
+	Version     int64
+	Name        string
+	Checksum    string
+	AppliedAt   time.Time
+	ExecutionMs int64
+}
+
+// MigrationStatus reports the state of a known migration against the database.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool
+}
+
+// MigrationSource loads migrations from somewhere (embedded FS, disk, memory).
+type MigrationSource interface {
+	Load() ([]Migration, error)
+}
+
+// Migration file names follow <version>_<name>[.<dialect>][.up|.down].sql,
+// e.g. 001_init.sql, 003_orders.sqlite.sql, 004_tags.up.sql / 004_tags.down.sql.
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([^.]+)((?:\.[A-Za-z0-9]+)*)\.sql$`)
+
+const (
+	migrationDownMarker = "-- migrate:down"
+	migrationUpMarker   = "-- migrate:up"
+	migrationNoTxMarker = "-- migrate:no-transaction"
+)
+
+type fsMigrationSource struct {
+	fsys fs.FS
+	dir  string
+}
+
+// NewFSMigrationSource reads migration files from dir inside fsys.
+func NewFSMigrationSource(fsys fs.FS, dir string) MigrationSource {
+	if dir == "" {
+		dir = "."
+	}
+	return &fsMigrationSource{fsys: fsys, dir: dir}
+}
+
+// NewDirMigrationSource reads migration files from a directory on disk.
+func NewDirMigrationSource(dir string) MigrationSource {
+	return NewFSMigrationSource(os.DirFS(os.ExpandEnv(dir)), ".")
+}
+
+// EmbeddedMigrationSource returns the migrations embedded in bootstrap.MigrationFiles.
+func EmbeddedMigrationSource() MigrationSource {
+	return NewFSMigrationSource(bootstrap.MigrationFiles, "embedded")
+}
+
+func (s *fsMigrationSource) Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(s.fsys, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations dir %q: %w", s.dir, err)
+	}
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		content, err := fs.ReadFile(s.fsys, path.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", e.Name(), err)
+		}
+		files[e.Name()] = string(content)
+	}
+	return ParseMigrationFiles(files)
+}
+
+type mapMigrationSource map[string]string
+
+// NewMapMigrationSource wraps an in-memory set of files (file name -> content).
+func NewMapMigrationSource(files map[string]string) MigrationSource {
+	return mapMigrationSource(files)
+}
+
+func (s mapMigrationSource) Load() ([]Migration, error) { return ParseMigrationFiles(s) }
+
+// ParseMigrationFiles builds migrations from file names and contents. Files that
+// don't match the naming convention are ignored; up/down pairs are merged.
+func ParseMigrationFiles(files map[string]string) ([]Migration, error) {
+	type key struct {
+		version int64
+		dialect string
+	}
+	byKey := make(map[key]*Migration)
+
+	for name, content := range files {
+		base := path.Base(name)
+		match := migrationFileRe.FindStringSubmatch(base)
+		if match == nil {
+			gl.Log("debug", fmt.Sprintf("Ignoring file %s: not a migration", base))
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", base, err)
+		}
+
+		var dialect, direction string
+		for _, part := range strings.Split(strings.TrimPrefix(match[3], "."), ".") {
+			switch strings.ToLower(part) {
+			case "":
+			case "up", "down":
+				direction = strings.ToLower(part)
+			default:
+				dialect = NormalizeDialect(part)
+			}
+		}
+
+		k := key{version, dialect}
+		mig, ok := byKey[k]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2], Dialect: dialect}
+			byKey[k] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", version, mig.Name, match[2])
+		}
+
+		if strings.Contains(content, migrationNoTxMarker) {
+			mig.NoTx = true
+		}
+		switch direction {
+		case "up":
+			mig.Up = content
+		case "down":
+			mig.Down = content
+		default:
+			mig.Up, mig.Down = splitUpDown(content)
+		}
+	}
+
+	out := make([]Migration, 0, len(byKey))
+	for _, m := range byKey {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Version != out[j].Version {
+			return out[i].Version < out[j].Version
+		}
+		return out[i].Dialect < out[j].Dialect
+	})
+	return out, nil
+}
+
+// migrationFileDialect returns the dialect of a migration file name, empty
+// for the files of every dialect.
+func migrationFileDialect(name string) string {
+	match := migrationFileRe.FindStringSubmatch(path.Base(name))
+	if match == nil {
+		return ""
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(match[3], "."), ".") {
+		switch strings.ToLower(part) {
+		case "", "up", "down":
+		default:
+			return NormalizeDialect(part)
+		}
+	}
+	return ""
+}
+
+// splitUpDown splits a single-file migration on the "-- migrate:down" marker.
+func splitUpDown(content string) (string, string) {
+	idx := strings.Index(content, migrationDownMarker)
+	if idx < 0 {
+		return strings.Replace(content, migrationUpMarker, "", 1), ""
+	}
+	up := strings.Replace(content[:idx], migrationUpMarker, "", 1)
+	return up, content[idx+len(migrationDownMarker):]
+}
+
+// SelectMigrations returns one migration per version for the given dialect,
+// preferring a dialect-specific file over a generic one.
+func SelectMigrations(migrations []Migration, dialect string) []Migration {
+	dialect = NormalizeDialect(dialect)
+	chosen := make(map[int64]Migration)
+	for _, m := range migrations {
+		if m.Dialect != "" && m.Dialect != dialect {
+			continue
+		}
+		if cur, ok := chosen[m.Version]; ok && cur.Dialect != "" {
+			continue
+		}
+		chosen[m.Version] = m
+	}
+	out := make([]Migration, 0, len(chosen))
+	for _, m := range chosen {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+// Migrator applies versioned migrations and records them in the migrations table.
+type Migrator struct {
+	db          *sql.DB
+	dialect     string
+	table       string
+	lockTimeout time.Duration
+	// conn is the connection holding the migration lock during a run
+	conn *sql.Conn
+}
+
+// NewMigrator creates a migrator for an open connection of the given dialect.
+func NewMigrator(db *sql.DB, dialect string) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("migrator: db is nil")
+	}
+	dialect = NormalizeDialect(dialect)
+	switch dialect {
+	case DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer:
+	default:
+		return nil, fmt.Errorf("migrator: unsupported dialect %q", dialect)
+	}
+	return &Migrator{db: db, dialect: dialect, table: DefaultMigrationsTable, lockTimeout: DefaultMigrationLockTimeout}, nil
+}
+
+// WithTable changes the bookkeeping table name.
+func (m *Migrator) WithTable(table string) *Migrator {
+	if table != "" {
+		m.table = table
+	}
+	return m
+}
+
+// WithLockTimeout changes how long Up, Down and Baseline wait for the migration lock.
+func (m *Migrator) WithLockTimeout(timeout time.Duration) *Migrator {
+	if timeout > 0 {
+		m.lockTimeout = timeout
+	}
+	return m
+}
+
+// Dialect returns the normalized dialect of the migrator.
+func (m *Migrator) Dialect() string { return m.dialect }
+
+// transactional reports whether DDL can be rolled back on this dialect.
+// MySQL implicitly commits DDL, so a transaction there gives no guarantee.
+func (m *Migrator) transactional() bool {
+	return m.dialect != DialectMySQL
+}
+
+// placeholder returns the bind parameter for position n (1-based).
+func (m *Migrator) placeholder(n int) string {
+	switch m.dialect {
+	case DialectPostgres:
+		return fmt.Sprintf("$%d", n)
+	case DialectSQLServer:
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	var ddl string
+	switch m.dialect {
+	case DialectSQLServer:
+		ddl = fmt.Sprintf(`IF OBJECT_ID(N'%[1]s', N'U') IS NULL
+CREATE TABLE %[1]s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name NVARCHAR(255) NOT NULL,
+	checksum VARCHAR(64) NOT NULL,
+	applied_at DATETIME2 NOT NULL,
+	execution_ms BIGINT NOT NULL DEFAULT 0
+)`, m.table)
+	case DialectMySQL:
+		ddl = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum VARCHAR(64) NOT NULL,
+	applied_at DATETIME(6) NOT NULL,
+	execution_ms BIGINT NOT NULL DEFAULT 0
+)`, m.table)
+	default:
+		ddl = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum VARCHAR(64) NOT NULL,
+	applied_at TIMESTAMP NOT NULL,
+	execution_ms BIGINT NOT NULL DEFAULT 0
+)`, m.table)
+	}
+	if _, err := m.execer(ctx, m.session(), m.table).ExecContext(ctx, ddl); err != nil {
+		return fmt.Errorf("failed to create %s: %w", m.table, err)
+	}
+	return nil
+}
+
+// Applied returns the rows of the migrations table ordered by version.
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.session().QueryContext(ctx, fmt.Sprintf(
+		"SELECT version, name, checksum, applied_at, execution_ms FROM %s ORDER BY version", m.table))
+	if err != nil {
+		if c, ok := SQLCaptureFromContext(ctx); ok && c.DryRun() {
+			// the table was only captured, not created: nothing is applied yet
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", m.table, err)
+	}
+	defer rows.Close()
+
+	var out []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt, &a.ExecutionMs); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", m.table, err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// Version returns the highest applied version, or 0 when nothing was applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(applied) == 0 {
+		return 0, nil
+	}
+	return applied[len(applied)-1].Version, nil
+}
+
+// Status compares the known migrations with the migrations table.
+func (m *Migrator) Status(ctx context.Context, migrations []Migration) ([]MigrationStatus, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]AppliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+	selected := SelectMigrations(migrations, m.dialect)
+	out := make([]MigrationStatus, 0, len(selected))
+	for _, mig := range selected {
+		st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := byVersion[mig.Version]; ok {
+			appliedAt := a.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &appliedAt
+			st.Modified = a.Checksum != mig.Checksum()
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Up applies every pending migration in version order. It returns how many
+// migrations were applied and how many were skipped because they were already applied.
+// Edited migrations (checksum differs from the recorded one) abort the run before anything is applied.
+func (m *Migrator) Up(ctx context.Context, migrations []Migration) (int, int, error) {
+	m, release, err := m.locked(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer release()
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	byVersion := make(map[int64]AppliedMigration, len(applied))
+	var maxApplied int64
+	for _, a := range applied {
+		byVersion[a.Version] = a
+		if a.Version > maxApplied {
+			maxApplied = a.Version
+		}
+	}
+
+	selected := SelectMigrations(migrations, m.dialect)
+	var pending []Migration
+	var mismatched []string
+	skipped := 0
+	for _, mig := range selected {
+		a, ok := byVersion[mig.Version]
+		if !ok {
+			pending = append(pending, mig)
+			continue
+		}
+		if a.Checksum != mig.Checksum() {
+			mismatched = append(mismatched, fmt.Sprintf("%d_%s", mig.Version, mig.Name))
+		}
+		skipped++
+	}
+	if len(mismatched) > 0 {
+		return 0, skipped, fmt.Errorf("%w: %s", ErrMigrationChecksumMismatch, strings.Join(mismatched, ", "))
+	}
+
+	count := 0
+	for _, mig := range pending {
+		if mig.Version < maxApplied {
+			gl.Log("warn", fmt.Sprintf("Applying out-of-order migration %d_%s (latest applied: %d)", mig.Version, mig.Name, maxApplied))
+		}
+		if err := m.apply(ctx, mig, mig.Up, true); err != nil {
+			return count, skipped, err
+		}
+		count++
+	}
+	return count, skipped, nil
+}
+
+// Down rolls back the last `steps` applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, migrations []Migration, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, nil
+	}
+	m, release, err := m.locked(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	known := make(map[int64]Migration)
+	for _, mig := range SelectMigrations(migrations, m.dialect) {
+		known[mig.Version] = mig
+	}
+
+	count := 0
+	for i := len(applied) - 1; i >= 0 && count < steps; i-- {
+		a := applied[i]
+		mig, ok := known[a.Version]
+		if !ok {
+			return count, fmt.Errorf("migration %d_%s is applied but missing from the source", a.Version, a.Name)
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			return count, fmt.Errorf("%w: %d_%s", ErrMigrationNoDown, mig.Version, mig.Name)
+		}
+		if err := m.apply(ctx, mig, mig.Down, false); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Baseline records migrations up to (and including) version as applied without
+// running them. Useful for databases initialized by other means (e.g. dockerstack init scripts).
+func (m *Migrator) Baseline(ctx context.Context, migrations []Migration, version int64) (int, error) {
+	m, release, err := m.locked(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	count := 0
+	for _, mig := range SelectMigrations(migrations, m.dialect) {
+		if mig.Version > version || done[mig.Version] {
+			continue
+		}
+		ex := m.execer(ctx, m.session(), fmt.Sprintf("%d_%s", mig.Version, mig.Name))
+		if _, err := ex.ExecContext(ctx, m.insertSQL(), mig.Version, mig.Name, mig.Checksum(), time.Now().UTC(), int64(0)); err != nil {
+			return count, fmt.Errorf("failed to baseline migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// sqlSession is satisfied by *sql.DB and *sql.Conn.
+type sqlSession interface {
+	execer
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// session returns the connection holding the migration lock, or the pool
+// outside a locked run.
+func (m *Migrator) session() sqlSession {
+	if m.conn != nil {
+		return m.conn
+	}
+	return m.db
+}
+
+// locked takes the migration lock on a dedicated connection and returns a
+// copy of the migrator running on it, so that concurrent processes apply the
+// migrations one at a time. The lock is a session advisory lock on Postgres,
+// GET_LOCK on MySQL, sp_getapplock on SQL Server and a row of the
+// <table>_lock table on SQLite (left behind by a crashed process, it must be
+// deleted by hand). Dry runs take no lock.
+func (m *Migrator) locked(ctx context.Context) (*Migrator, func(), error) {
+	if c, ok := SQLCaptureFromContext(ctx); ok && c.DryRun() {
+		return m, func() {}, nil
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open the migration lock connection: %w", err)
+	}
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		ok, err := m.tryLock(ctx, conn)
+		if err != nil {
+			_ = conn.Close()
+			return nil, nil, fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		if ok {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			_ = conn.Close()
+			return nil, nil, fmt.Errorf("%w: %s not released after %s", ErrMigrationLocked, m.lockName(), m.lockTimeout)
+		}
+		gl.Log("info", fmt.Sprintf("Waiting for the migration lock %s", m.lockName()))
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+			return nil, nil, ctx.Err()
+		case <-time.After(migrationLockPoll):
+		}
+	}
+
+	run := *m
+	run.conn = conn
+	release := func() {
+		if err := m.unlock(context.WithoutCancel(ctx), conn); err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to release the migration lock %s: %v", m.lockName(), err))
+		}
+		_ = conn.Close()
+	}
+	return &run, release, nil
+}
+
+// lockName names the migration lock of the migrations table.
+func (m *Migrator) lockName() string {
+	if m.dialect == DialectSQLite {
+		return m.table + "_lock"
+	}
+	return "gdbase:" + m.table
+}
+
+// tryLock takes the migration lock on conn without waiting.
+func (m *Migrator) tryLock(ctx context.Context, conn *sql.Conn) (bool, error) {
+	switch m.dialect {
+	case DialectPostgres:
+		var ok bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", m.lockKey()).Scan(&ok)
+		return ok, err
+	case DialectMySQL:
+		var ok sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", m.lockName()).Scan(&ok)
+		return ok.Valid && ok.Int64 == 1, err
+	case DialectSQLServer:
+		var status int
+		err := conn.QueryRowContext(ctx, `DECLARE @r int;
+EXEC @r = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0;
+SELECT @r`, m.lockName()).Scan(&status)
+		return status >= 0, err
+	default:
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1), locked_at TIMESTAMP NOT NULL)", m.lockName())); err != nil {
+			return false, err
+		}
+		res, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT OR IGNORE INTO %s (id, locked_at) VALUES (1, ?)", m.lockName()), time.Now().UTC())
+		if err != nil {
+			return false, err
+		}
+		n, err := res.RowsAffected()
+		return n == 1, err
+	}
+}
+
+// unlock releases the migration lock taken by tryLock.
+func (m *Migrator) unlock(ctx context.Context, conn *sql.Conn) error {
+	var err error
+	switch m.dialect {
+	case DialectPostgres:
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", m.lockKey())
+	case DialectMySQL:
+		_, err = conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", m.lockName())
+	case DialectSQLServer:
+		_, err = conn.ExecContext(ctx, "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", m.lockName())
+	default:
+		_, err = conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = 1", m.lockName()))
+	}
+	return err
+}
+
+// lockKey is the Postgres advisory lock key of the migrations table.
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(m.lockName()))
+	return int64(h.Sum64())
+}
+
+func (m *Migrator) insertSQL() string {
+	return fmt.Sprintf("INSERT INTO %s (version, name, checksum, applied_at, execution_ms) VALUES (%s, %s, %s, %s, %s)",
+		m.table, m.placeholder(1), m.placeholder(2), m.placeholder(3), m.placeholder(4), m.placeholder(5))
+}
+
+func (m *Migrator) deleteSQL() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.table, m.placeholder(1))
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// execer returns ex, recording its statements when ctx carries a SQLCapture
+// (see WithSQLCapture); caller names the migration in the capture.
+func (m *Migrator) execer(ctx context.Context, ex execer, caller string) execer {
+	if c, ok := SQLCaptureFromContext(ctx); ok {
+		return captureExecer{ex: ex, capture: c, dialect: m.dialect, caller: caller}
+	}
+	return ex
+}
+
+// apply runs a script and records (up) or removes (down) its bookkeeping row,
+// inside a single transaction when the dialect and the migration allow it.
+func (m *Migrator) apply(ctx context.Context, mig Migration, script string, up bool) error {
+	start := time.Now()
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	gl.Log("info", fmt.Sprintf("Applying migration %d_%s (%s)", mig.Version, mig.Name, direction))
+
+	record := func(ex execer) error {
+		var err error
+		if up {
+			_, err = ex.ExecContext(ctx, m.insertSQL(), mig.Version, mig.Name, mig.Checksum(), time.Now().UTC(), time.Since(start).Milliseconds())
+		} else {
+			_, err = ex.ExecContext(ctx, m.deleteSQL(), mig.Version)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		return nil
+	}
+
+	caller := fmt.Sprintf("%d_%s (%s)", mig.Version, mig.Name, direction)
+	c, capturing := SQLCaptureFromContext(ctx)
+	if mig.NoTx || !m.transactional() || (capturing && c.DryRun()) {
+		ex := m.execer(ctx, m.session(), caller)
+		if err := m.execScript(ctx, ex, script); err != nil {
+			return fmt.Errorf("migration %d_%s (%s) failed: %w", mig.Version, mig.Name, direction, err)
+		}
+		return record(ex)
+	}
+
+	tx, err := m.session().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if err := m.execScript(ctx, m.execer(ctx, tx, caller), script); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("migration %d_%s (%s) failed: %w", mig.Version, mig.Name, direction, err)
+	}
+	if err := record(m.execer(ctx, tx, caller)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// execScript executes a script using the splitting strategy of the dialect:
+// SQLite runs the whole script at once (keeps trigger bodies intact), SQL Server
+// splits on GO batch separators and the others run statement by statement.
+func (m *Migrator) execScript(ctx context.Context, ex execer, script string) error {
+	switch m.dialect {
+	case DialectSQLite:
+		if strings.TrimSpace(script) == "" {
+			return nil
+		}
+		_, err := ex.ExecContext(ctx, script)
+		return err
+	case DialectSQLServer:
+		for _, batch := range splitSQLServerBatches(script) {
+			if _, err := ex.ExecContext(ctx, batch.SQL); err != nil {
+				return fmt.Errorf("line %d: %w", batch.Line, err)
+			}
+		}
+		return nil
+	default:
+		for _, stmt := range SplitSQLStatements(script) {
+			if isCommentOnly(stmt.SQL) {
+				continue
+			}
+			if _, err := ex.ExecContext(ctx, stmt.SQL); err != nil {
+				return fmt.Errorf("line %d: %w", stmt.Line, err)
+			}
+		}
+		return nil
+	}
+}
+
+// splitSQLServerBatches splits a T-SQL script on lines containing only GO.
+func splitSQLServerBatches(script string) []SQLStatement {
+	var out []SQLStatement
+	var b strings.Builder
+	start := 1
+	for i, line := range strings.Split(script, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), "GO") {
+			if s := strings.TrimSpace(b.String()); s != "" && !isCommentOnly(s) {
+				out = append(out, SQLStatement{SQL: s, Line: start})
+			}
+			b.Reset()
+			start = i + 2
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	if s := strings.TrimSpace(b.String()); s != "" && !isCommentOnly(s) {
+		out = append(out, SQLStatement{SQL: s, Line: start})
+	}
+	return out
+}
+
+// isCommentOnly reports whether a statement has nothing besides comments and a terminator.
+func isCommentOnly(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == ";" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		if strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/") {
+			continue
+		}
+		return false
+	}
+	return true
+}
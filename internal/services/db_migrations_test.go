@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrations.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestParseMigrationFiles(t *testing.T) {
+	migs, err := ParseMigrationFiles(map[string]string{
+		"002_tags.up.sql":     "CREATE TABLE tags (id INTEGER);",
+		"002_tags.down.sql":   "DROP TABLE tags;",
+		"001_init.sql":        "CREATE TABLE a (id INTEGER);\n-- migrate:down\nDROP TABLE a;",
+		"001_init.sqlite.sql": "CREATE TABLE a (id INTEGER PRIMARY KEY);",
+		"README.md":           "ignored",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migs) != 3 {
+		t.Fatalf("expected 3 migrations, got %d", len(migs))
+	}
+
+	sqlite := SelectMigrations(migs, "sqlite3")
+	if len(sqlite) != 2 || sqlite[0].Dialect != DialectSQLite || sqlite[1].Down == "" {
+		t.Fatalf("unexpected sqlite selection: %+v", sqlite)
+	}
+	pg := SelectMigrations(migs, "postgresql")
+	if pg[0].Dialect != "" || pg[0].Down == "" {
+		t.Fatalf("expected generic 001 with down script for postgres, got %+v", pg[0])
+	}
+}
+
+func TestMigratorUpDownAndChecksum(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	m, err := NewMigrator(db, "sqlite")
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	files := map[string]string{
+		"001_users.sql":  "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);\n-- migrate:down\nDROP TABLE users;",
+		"002_orders.sql": "CREATE TABLE orders (id INTEGER PRIMARY KEY);\n-- migrate:down\nDROP TABLE orders;",
+	}
+	migs, err := ParseMigrationFiles(files)
+	if err != nil {
+		t.Fatalf("ParseMigrationFiles: %v", err)
+	}
+
+	applied, skipped, err := m.Up(ctx, migs)
+	if err != nil || applied != 2 || skipped != 0 {
+		t.Fatalf("first Up: applied=%d skipped=%d err=%v", applied, skipped, err)
+	}
+	applied, skipped, err = m.Up(ctx, migs)
+	if err != nil || applied != 0 || skipped != 2 {
+		t.Fatalf("second Up: applied=%d skipped=%d err=%v", applied, skipped, err)
+	}
+	if v, _ := m.Version(ctx); v != 2 {
+		t.Fatalf("expected version 2, got %d", v)
+	}
+
+	reverted, err := m.Down(ctx, migs, 1)
+	if err != nil || reverted != 1 {
+		t.Fatalf("Down: reverted=%d err=%v", reverted, err)
+	}
+	if _, err := db.Exec("SELECT 1 FROM orders"); err == nil {
+		t.Fatalf("expected orders table to be dropped")
+	}
+
+	files["001_users.sql"] = "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT);"
+	edited, _ := ParseMigrationFiles(files)
+	if _, _, err := m.Up(ctx, edited); !errors.Is(err, ErrMigrationChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestMigratorRollsBackFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	m, _ := NewMigrator(db, "sqlite")
+
+	migs, _ := ParseMigrationFiles(map[string]string{
+		"001_broken.sql": "CREATE TABLE broken (id INTEGER);\nINSERT INTO missing_table VALUES (1);",
+	})
+	if _, _, err := m.Up(ctx, migs); err == nil {
+		t.Fatalf("expected failure")
+	}
+	if _, err := db.Exec("SELECT 1 FROM broken"); err == nil {
+		t.Fatalf("expected partial migration to be rolled back")
+	}
+	if v, _ := m.Version(ctx); v != 0 {
+		t.Fatalf("failed migration must not be recorded, version=%d", v)
+	}
+}
+
+func TestMigratorWaitsForLock(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	m, _ := NewMigrator(db, "sqlite")
+	m.WithLockTimeout(600 * time.Millisecond)
+	migs, _ := ParseMigrationFiles(map[string]string{
+		"001_users.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY);",
+	})
+
+	// another process holds the lock
+	if _, err := db.Exec(`CREATE TABLE schema_migrations_lock (id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1), locked_at TIMESTAMP NOT NULL);
+INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP);`); err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	if _, _, err := m.Up(ctx, migs); !errors.Is(err, ErrMigrationLocked) {
+		t.Fatalf("expected ErrMigrationLocked, got %v", err)
+	}
+	if _, err := db.Exec("SELECT 1 FROM users"); err == nil {
+		t.Fatalf("expected no migration applied while locked")
+	}
+
+	if _, err := db.Exec("DELETE FROM schema_migrations_lock"); err != nil {
+		t.Fatalf("failed to release the lock: %v", err)
+	}
+	if applied, _, err := m.Up(ctx, migs); err != nil || applied != 1 {
+		t.Fatalf("Up: applied=%d err=%v", applied, err)
+	}
+	var held int
+	db.QueryRow("SELECT count(*) FROM schema_migrations_lock").Scan(&held)
+	if held != 0 {
+		t.Fatalf("expected the lock released after Up, %d rows held", held)
+	}
+}
+
+func TestEmbeddedMigrationSource(t *testing.T) {
+	migs, err := EmbeddedMigrationSource().Load()
+	if err != nil {
+		t.Fatalf("failed to load embedded migrations: %v", err)
+	}
+	if len(migs) < 2 || migs[0].Version != 1 || migs[0].Name != "init" {
+		t.Fatalf("unexpected embedded migrations: %+v", migs)
+	}
+}
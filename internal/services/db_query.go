@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+
+	"gorm.io/gorm"
+)
+
+// The helpers below run raw SQL on the connection selected by ctx (see GetDB),
+// joining the transaction of a WithTx. Columns are mapped to struct fields with
+// the GORM naming rules and `gorm:"column:..."` tags; T may also be a scalar
+// for single column results or map[string]any.
+//
+// Named parameters work on every dialect: write @name in the query and pass
+// sql.Named("name", v), a map[string]any or a struct with the matching fields.
+
+func rawDB(ctx context.Context, d *DBServiceImpl) (*gorm.DB, error) {
+	if d == nil {
+		return nil, fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	db, err := GetDB(ctx, d)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao obter banco de dados: %v", err)
+	}
+	if db == nil {
+		return nil, fmt.Errorf("❌ Banco de dados não inicializado")
+	}
+	if ctx != nil {
+		db = db.WithContext(ctx)
+	}
+	return db, nil
+}
+
+// QueryInto runs query and scans every row into a T.
+func QueryInto[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) ([]T, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0)
+	if err := db.Raw(query, args...).Scan(&out).Error; err != nil {
+		return nil, fmt.Errorf("❌ Erro ao executar consulta: %w", err)
+	}
+	return out, nil
+}
+
+// QueryOne runs query and scans the first row into a T. It returns
+// sql.ErrNoRows (wrapped) when the query yields no row.
+func QueryOne[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) (T, error) {
+	var out T
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return out, err
+	}
+	res := db.Raw(query, args...).Scan(&out)
+	if res.Error != nil {
+		return out, fmt.Errorf("❌ Erro ao executar consulta: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return out, fmt.Errorf("❌ Nenhum registro encontrado: %w", sql.ErrNoRows)
+	}
+	return out, nil
+}
+
+// QueryIter streams the rows of query one by one, for result sets too big to
+// load at once. The rows are closed when the loop ends or breaks; an error
+// stops the iteration after being yielded.
+func QueryIter[T any](ctx context.Context, d *DBServiceImpl, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		db, err := rawDB(ctx, d)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		rows, err := db.Raw(query, args...).Rows()
+		if err != nil {
+			yield(zero, fmt.Errorf("❌ Erro ao executar consulta: %w", err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var item T
+			if err := db.ScanRows(rows, &item); err != nil {
+				yield(zero, fmt.Errorf("❌ Erro ao ler registro: %w", err))
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, fmt.Errorf("❌ Erro ao ler registros: %w", err))
+		}
+	}
+}
+
+// Exec runs a statement that returns no rows and reports the rows affected.
+func (d *DBServiceImpl) Exec(ctx context.Context, query string, args ...any) (int64, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return 0, err
+	}
+	res := db.Exec(query, args...)
+	if res.Error != nil {
+		return 0, fmt.Errorf("❌ Erro ao executar comando: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
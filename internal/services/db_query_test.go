@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+type queryItem struct {
+	ID    uint
+	Label string `gorm:"column:name"`
+	Qty   int
+}
+
+func TestQueryHelpers(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+
+	if _, err := d.Exec(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, qty INTEGER)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	n, err := d.Exec(ctx, "INSERT INTO items (name, qty) VALUES (@name, @qty), ('b', 2), ('c', 3)",
+		sql.Named("name", "a"), sql.Named("qty", 1))
+	if err != nil || n != 3 {
+		t.Fatalf("Exec = %d, %v", n, err)
+	}
+
+	items, err := QueryInto[queryItem](ctx, d, "SELECT id, name, qty FROM items WHERE qty >= @min ORDER BY id",
+		map[string]any{"min": 2})
+	if err != nil || len(items) != 2 || items[0].Label != "b" || items[1].Qty != 3 {
+		t.Fatalf("QueryInto = %+v, %v", items, err)
+	}
+
+	total, err := QueryOne[int](ctx, d, "SELECT SUM(qty) FROM items")
+	if err != nil || total != 6 {
+		t.Fatalf("QueryOne[int] = %d, %v", total, err)
+	}
+	if _, err := QueryOne[queryItem](ctx, d, "SELECT * FROM items WHERE id = ?", 42); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	var seen []string
+	for item, err := range QueryIter[queryItem](ctx, d, "SELECT * FROM items ORDER BY id") {
+		if err != nil {
+			t.Fatalf("QueryIter: %v", err)
+		}
+		seen = append(seen, item.Label)
+		if len(seen) == 2 {
+			break
+		}
+	}
+	if len(seen) != 2 || seen[1] != "b" {
+		t.Fatalf("QueryIter yielded %v", seen)
+	}
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	evs "github.com/kubex-ecosystem/gdbase/internal/events"
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+)
+
+// DatabaseEvents is the EventBus name under which the service emits
+// connection changes; the event is one of the ConfigChange kinds.
+const DatabaseEvents = "database"
+
+// Kinds of ConfigChange, also used as EventBus event names.
+const (
+	ConfigChangeAdded       = "added"
+	ConfigChangeRemoved     = "removed"
+	ConfigChangeReconnected = "reconnected"
+	ConfigChangeFailed      = "failed"
+	ConfigReloaded          = "reloaded"
+)
+
+const (
+	configReloadDebounce = 500 * time.Millisecond
+	// connectionDrainTimeout bounds how long a replaced connection pool may
+	// keep serving in-flight queries before it is closed.
+	connectionDrainTimeout = 30 * time.Second
+)
+
+// ConfigChange describes what a reload did to one database.
+type ConfigChange struct {
+	Kind string
+	Name string
+	Err  error
+}
+
+// GetEventBus returns the bus on which connection changes are emitted.
+func (d *DBServiceImpl) GetEventBus() *evs.EventBus {
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	if d.eventBus == nil {
+		d.eventBus = evs.NewEventBus()
+	}
+	return d.eventBus
+}
+
+func (d *DBServiceImpl) emit(change ConfigChange) {
+	d.GetEventBus().Emit(DatabaseEvents, change.Kind, change)
+}
+
+// ReloadConfig reconciles the running connections with next: databases that
+// appear (or get enabled) are connected, the ones that disappear (or get
+// disabled) are drained and closed, and the ones whose DSN changed are
+// reconnected. The config held by the service is updated in place.
+func (d *DBServiceImpl) ReloadConfig(ctx context.Context, next *DBConfig) ([]ConfigChange, error) {
+	if d == nil {
+		return nil, fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	if next == nil {
+		return nil, fmt.Errorf("❌ Configuração do banco de dados não pode ser nula")
+	}
+
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
+	d.mutexes.MuLock()
+	if d.config == nil {
+		d.config = next
+	}
+	current := d.config
+	if d.db == nil {
+		d.db = make(map[string]*gorm.DB)
+	}
+	d.mutexes.MuUnlock()
+
+	before := enabledDatabases(current)
+	after := enabledDatabases(next)
+
+	var changes []ConfigChange
+	for _, name := range sortedDatabaseNames(before) {
+		if _, ok := after[name]; !ok {
+			d.dropConnection(name)
+			changes = append(changes, ConfigChange{Kind: ConfigChangeRemoved, Name: name})
+		}
+	}
+	for _, name := range sortedDatabaseNames(after) {
+		dbConf := after[name]
+		old, existed := before[name]
+		d.mutexes.MuLock()
+		_, connected := d.db[name]
+		d.mutexes.MuUnlock()
+
+		switch {
+		case !existed:
+			if err := d.openConnection(ctx, dbConf); err != nil {
+				changes = append(changes, ConfigChange{Kind: ConfigChangeFailed, Name: name, Err: err})
+				continue
+			}
+			changes = append(changes, ConfigChange{Kind: ConfigChangeAdded, Name: name})
+		case connectionFingerprint(old) != connectionFingerprint(dbConf):
+			if !connected {
+				// never connected: GetDBByName picks the new settings up lazily
+				continue
+			}
+			if err := d.openConnection(ctx, dbConf); err != nil {
+				changes = append(changes, ConfigChange{Kind: ConfigChangeFailed, Name: name, Err: err})
+				continue
+			}
+			changes = append(changes, ConfigChange{Kind: ConfigChangeReconnected, Name: name})
+		}
+	}
+
+	d.mutexes.MuLock()
+	current.Databases = next.Databases
+	current.Bindings = next.Bindings
+	d.mutexes.MuUnlock()
+	d.pruneReplicas(after)
+
+	var errs []error
+	for _, change := range changes {
+		if change.Err != nil {
+			gl.Log("error", fmt.Sprintf("❌ Erro ao reconectar '%s': %v", change.Name, change.Err))
+			errs = append(errs, fmt.Errorf("%s: %w", change.Name, change.Err))
+		} else {
+			gl.Log("info", fmt.Sprintf("Database '%s' %s", change.Name, change.Kind))
+		}
+		d.emit(change)
+	}
+	d.GetEventBus().Emit(DatabaseEvents, ConfigReloaded, changes)
+	return changes, errors.Join(errs...)
+}
+
+// WatchConfig reloads the config file at path whenever it changes and applies
+// it with ReloadConfig, until ctx is done. The directory is watched so editors
+// that replace the file on save are handled.
+func (d *DBServiceImpl) WatchConfig(ctx context.Context, path string) error {
+	if d == nil {
+		return fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao observar configuração: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("❌ Erro ao observar configuração: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) == path && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce = time.After(configReloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				gl.Log("warn", fmt.Sprintf("Config watcher: %v", err))
+			case <-debounce:
+				debounce = nil
+				next, err := loadDBConfigFile(path)
+				if err != nil {
+					// keep running with the current config until the file is valid again
+					gl.Log("error", fmt.Sprintf("❌ Erro ao recarregar configuração: %v", err))
+					d.emit(ConfigChange{Kind: ConfigChangeFailed, Err: err})
+					continue
+				}
+				if _, err := d.ReloadConfig(ctx, next); err != nil {
+					gl.Log("error", fmt.Sprintf("❌ Erro ao aplicar configuração: %v", err))
+				}
+			}
+		}
+	}()
+	gl.Log("info", fmt.Sprintf("Watching database config %s", path))
+	return nil
+}
+
+// watchConfigFile starts WatchConfig on path for the lifetime of the service
+// (until CloseDBConnection); it is a no-op when the file is already watched.
+func (d *DBServiceImpl) watchConfigFile(path string) {
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	if d.stopWatch != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := d.WatchConfig(ctx, path); err != nil {
+		cancel()
+		gl.Log("error", fmt.Sprintf("❌ Erro ao observar configuração %s: %v", path, err))
+		return
+	}
+	d.stopWatch = cancel
+}
+
+// openConnection connects dbConf. A connected database keeps its *gorm.DB:
+// the pools behind it are swapped (see reopen), so the repositories built
+// before the reload keep working. Only a change of engine replaces the
+// handle, draining the one it replaces.
+func (d *DBServiceImpl) openConnection(ctx context.Context, dbConf *ti.Database) error {
+	d.mutexes.MuLock()
+	old := d.db[dbConf.Name]
+	d.mutexes.MuUnlock()
+	if live, ok := livePoolOf(old); ok && NormalizeDialect(old.Dialector.Name()) == NormalizeDialect(dbConf.Type) {
+		return d.reopen(ctx, dbConf, live)
+	}
+
+	db, err := d.connect(ctx, dbConf)
+	if err != nil {
+		return err
+	}
+	d.mutexes.MuLock()
+	d.db[dbConf.Name] = db
+	d.mutexes.MuUnlock()
+	if old != nil {
+		go drainConnection(dbConf.Name, old)
+	}
+	return nil
+}
+
+// reopen connects the primary and the replicas of dbConf and swaps them in
+// behind the pools of a connected database.
+func (d *DBServiceImpl) reopen(ctx context.Context, dbConf *ti.Database, live *livePool) error {
+	_, sqlDB, _, err := openDialector(dbConf)
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return fmt.Errorf("❌ Erro ao pingar o banco de dados: %v", err)
+	}
+	go drainPool(dbConf.Name, live.swap(sqlDB))
+	if live.reads != nil {
+		d.useReplicas(ctx, dbConf, live.reads)
+	}
+	return nil
+}
+
+// pruneReplicas retires the replica sets of the databases missing from live.
+func (d *DBServiceImpl) pruneReplicas(live map[string]*ti.Database) {
+	d.mutexes.MuLock()
+	var stale []*replicaSet
+	for name, rs := range d.replicas {
+		if _, ok := live[name]; !ok {
+			stale = append(stale, rs)
+			delete(d.replicas, name)
+		}
+	}
+	d.mutexes.MuUnlock()
+	for _, rs := range stale {
+		rs.retire()
+	}
+}
+
+// dropConnection removes a database and its replicas, draining its pool.
+func (d *DBServiceImpl) dropConnection(name string) {
+	d.mutexes.MuLock()
+	old := d.db[name]
+	delete(d.db, name)
+	rs := d.replicas[name]
+	delete(d.replicas, name)
+	d.mutexes.MuUnlock()
+	if rs != nil {
+		rs.retire()
+	}
+	if old != nil {
+		go drainConnection(name, old)
+	}
+}
+
+// drainConnection closes db once its in-flight queries are done, or after
+// connectionDrainTimeout.
+func drainConnection(name string, db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		drainPool(name, sqlDB)
+	}
+}
+
+// drainPool closes pool once its in-flight queries are done, or after
+// connectionDrainTimeout.
+func drainPool(name string, pool *sql.DB) {
+	deadline := time.Now().Add(connectionDrainTimeout)
+	for pool.Stats().InUse > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := pool.Close(); err != nil {
+		gl.Log("warn", fmt.Sprintf("Error closing connection '%s': %v", name, err))
+	}
+}
+
+// livePool is the primary pool of the connections opened by the service. A
+// reload swaps the *sql.DB behind it, keeping the *gorm.DB (and the sessions
+// and repositories derived from it) valid.
+type livePool struct {
+	db atomic.Pointer[sql.DB]
+	// reads is the pool of the replicas registered on the connection
+	reads *readPool
+}
+
+func newLivePool(db *sql.DB) *livePool {
+	p := &livePool{}
+	p.db.Store(db)
+	return p
+}
+
+// livePoolOf returns the livePool of a connection opened by the service.
+func livePoolOf(db *gorm.DB) (*livePool, bool) {
+	if db == nil || db.Config == nil {
+		return nil, false
+	}
+	live, ok := db.ConnPool.(*livePool)
+	return live, ok
+}
+
+// swap points the pool at db and returns the *sql.DB it replaces.
+func (p *livePool) swap(db *sql.DB) *sql.DB { return p.db.Swap(db) }
+
+// GetDBConn returns the current *sql.DB (gorm.GetDBConnector, used by DB()).
+func (p *livePool) GetDBConn() (*sql.DB, error) { return p.db.Load(), nil }
+
+func (p *livePool) Ping() error { return p.db.Load().Ping() }
+
+func (p *livePool) PingContext(ctx context.Context) error { return p.db.Load().PingContext(ctx) }
+
+func (p *livePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return p.db.Load().BeginTx(ctx, opts)
+}
+
+func (p *livePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.db.Load().PrepareContext(ctx, query)
+}
+
+func (p *livePool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.db.Load().ExecContext(ctx, query, args...)
+}
+
+func (p *livePool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.db.Load().QueryContext(ctx, query, args...)
+}
+
+func (p *livePool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.db.Load().QueryRowContext(ctx, query, args...)
+}
+
+// enabledDatabases indexes the enabled databases of cfg by Database.Name, the
+// key of DBServiceImpl.db.
+func enabledDatabases(cfg *DBConfig) map[string]*ti.Database {
+	out := make(map[string]*ti.Database)
+	if cfg == nil {
+		return out
+	}
+	for _, dbConf := range cfg.Databases {
+		if dbConf != nil && dbConf.Enabled {
+			out[dbConf.Name] = dbConf
+		}
+	}
+	return out
+}
+
+func sortedDatabaseNames(m map[string]*ti.Database) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// connectionFingerprint identifies the settings that require a reconnect: the
+// connection fields of the primary and of its replicas. It only reads the
+// config, so secrets are not resolved and no TLS config is registered.
+func connectionFingerprint(dbConf *ti.Database) string {
+	parts := []string{connectionSettings(dbConf), dbConf.ReplicaPolicy, dbConf.ReplicaHealthInterval}
+	for _, replica := range dbConf.Replicas {
+		if replica != nil {
+			parts = append(parts, connectionSettings(replicaConfig(dbConf, replica)))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func connectionSettings(dbConf *ti.Database) string {
+	return fmt.Sprintf("%#v", []any{
+		NormalizeDialect(dbConf.Type), dbConf.Driver, dbConf.ConnectionString, dbConf.Dsn,
+		dbConf.Path, dbConf.Host, dbConf.Port, dbConf.Username, dbConf.Password, dbConf.Name,
+		DatabaseKeyringEntry(dbConf), dbConf.SSLMode, dbConf.SSLCert, dbConf.SSLKey, dbConf.SSLRootCert,
+		dbConf.TimeZone, dbConf.ConnectTimeout, dbConf.ApplicationName, dbConf.Params,
+	})
+}
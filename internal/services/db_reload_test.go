@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestReloadConfig(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sqliteDB := func(name, file string) *ti.Database {
+		return &ti.Database{Name: name, Type: "sqlite", Path: filepath.Join(dir, file), Enabled: true}
+	}
+	cfg := &DBConfig{Databases: map[string]*ti.Database{
+		"main":    sqliteDB("main", "main.db"),
+		"reports": sqliteDB("reports", "reports.db"),
+	}}
+	d, err := NewDatabaseServiceImpl(ctx, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceImpl: %v", err)
+	}
+	if err := d.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	oldMain := d.db["main"]
+
+	var mu sync.Mutex
+	events := map[string]string{}
+	for _, kind := range []string{ConfigChangeAdded, ConfigChangeRemoved, ConfigChangeReconnected} {
+		d.GetEventBus().On(DatabaseEvents, kind, func(args ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			change := args[0].(ConfigChange)
+			events[change.Name] = change.Kind
+		})
+	}
+
+	next := &DBConfig{Databases: map[string]*ti.Database{
+		"main":    sqliteDB("main", "main-moved.db"),
+		"archive": sqliteDB("archive", "archive.db"),
+	}}
+	changes, err := d.ReloadConfig(ctx, next)
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	want := map[string]string{"reports": ConfigChangeRemoved, "archive": ConfigChangeAdded, "main": ConfigChangeReconnected}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for _, c := range changes {
+		if want[c.Name] != c.Kind {
+			t.Fatalf("unexpected change %+v", c)
+		}
+	}
+	if _, ok := d.db["reports"]; ok {
+		t.Fatalf("removed database still connected")
+	}
+	if d.db["main"] != oldMain || d.db["archive"] == nil {
+		t.Fatalf("connections not reconciled")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main-moved.db")); err != nil {
+		t.Fatalf("main was not reconnected to the new path: %v", err)
+	}
+	if _, ok := d.lookupDatabase("archive"); !ok {
+		t.Fatalf("service config not updated")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n == len(want) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for name, kind := range want {
+		if events[name] != kind {
+			t.Fatalf("event for %s = %q, want %q", name, events[name], kind)
+		}
+	}
+
+	if changes, err := d.ReloadConfig(ctx, next); err != nil || len(changes) != 0 {
+		t.Fatalf("reloading the same config must be a no-op: %+v, %v", changes, err)
+	}
+}
+
+// TestReloadConfigKeepsRepositories uses a repository built before a reload
+// that moves its database: the *gorm.DB it holds follows the new settings.
+func TestReloadConfigKeepsRepositories(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, file := range []string{"old.db", "new.db"} {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, file)), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open %s: %v", file, err)
+		}
+		if err := db.AutoMigrate(&replicaItem{}); err != nil {
+			t.Fatalf("AutoMigrate: %v", err)
+		}
+		db.Create(&replicaItem{Name: file})
+		closeGormDB(db)
+	}
+	main := func(file string, replicas ...string) *DBConfig {
+		dbConf := &ti.Database{Name: "main", Type: "sqlite", Path: filepath.Join(dir, file), Enabled: true}
+		for _, r := range replicas {
+			dbConf.Replicas = append(dbConf.Replicas, &ti.Database{Path: filepath.Join(dir, r)})
+		}
+		return &DBConfig{Databases: map[string]*ti.Database{"main": dbConf}}
+	}
+	d, err := NewDatabaseServiceImpl(ctx, main("old.db"), nil)
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceImpl: %v", err)
+	}
+	if err := d.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	t.Cleanup(d.closeReplicas)
+	repo, err := NewRepositoryFor[replicaItem](ctx, d, "items")
+	if err != nil {
+		t.Fatalf("NewRepositoryFor: %v", err)
+	}
+	session := d.db["main"].Session(&gorm.Session{})
+	first := func(db *gorm.DB) string {
+		var item replicaItem
+		if err := db.Order("id").First(&item).Error; err != nil {
+			t.Fatalf("First: %v", err)
+		}
+		return item.Name
+	}
+	if got := first(repo.DB(ctx)); got != "old.db" {
+		t.Fatalf("before reload read %q", got)
+	}
+
+	// new.db as primary and old.db as replica: writes go to new.db, reads to old.db
+	if _, err := d.ReloadConfig(ctx, main("new.db", "old.db")); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if _, err := repo.Create(ctx, &replicaItem{Name: "after"}); err != nil {
+		t.Fatalf("Create after reload: %v", err)
+	}
+	if got := first(repo.DB(ctx)); got != "old.db" {
+		t.Fatalf("expected the read from the new replica, got %q", got)
+	}
+	if got := first(ForcePrimary(session)); got != "new.db" {
+		t.Fatalf("expected the read from the new primary, got %q", got)
+	}
+	var n int64
+	if err := ForcePrimary(session).Model(&replicaItem{}).Count(&n).Error; err != nil || n != 2 {
+		t.Fatalf("primary count = %d, %v", n, err)
+	}
+
+	// dropping the replica sends the reads back to the primary
+	if _, err := d.ReloadConfig(ctx, main("new.db")); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if got := first(repo.DB(ctx)); got != "new.db" {
+		t.Fatalf("expected the read from the primary, got %q", got)
+	}
+}
+
+func TestReloadConfigClosesStaleReplicas(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	main := &ti.Database{Name: "main", Type: "sqlite", Path: filepath.Join(dir, "main.db"), Enabled: true,
+		Replicas: []*ti.Database{{Path: filepath.Join(dir, "replica.db")}}}
+	d, err := NewDatabaseServiceImpl(ctx, &DBConfig{Databases: map[string]*ti.Database{"main": main}}, nil)
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceImpl: %v", err)
+	}
+	if err := d.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	t.Cleanup(d.closeReplicas)
+	if len(d.ReplicaStatus("main")) != 1 {
+		t.Fatalf("replica not registered")
+	}
+
+	withoutReplicas := *main
+	withoutReplicas.Replicas = nil
+	next := &DBConfig{Databases: map[string]*ti.Database{"main": &withoutReplicas}}
+	if changes, err := d.ReloadConfig(ctx, next); err != nil || len(changes) != 1 || changes[0].Kind != ConfigChangeReconnected {
+		t.Fatalf("unexpected reload: %+v, %v", changes, err)
+	}
+	if st := d.ReplicaStatus("main"); st != nil {
+		t.Fatalf("stale replicas kept after reconnect: %+v", st)
+	}
+
+	if _, err := d.ReloadConfig(ctx, &DBConfig{Databases: map[string]*ti.Database{}}); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if len(d.replicas) != 0 {
+		t.Fatalf("replica sets of removed databases kept: %v", d.replicas)
+	}
+}
+
+func TestConnectionFingerprintReadsConfigOnly(t *testing.T) {
+	base := &ti.Database{Name: "main", Type: "postgres", Host: "db", Port: 5432, Password: "env:GDBASE_FINGERPRINT_UNSET"}
+	tls := *base
+	tls.SSLMode = "verify-full"
+	tls.SSLRootCert = "/missing/ca.pem"
+	// an unresolvable secret and a missing root certificate don't matter:
+	// the fields are compared, no DSN is built
+	if connectionFingerprint(base) == connectionFingerprint(&tls) {
+		t.Fatalf("a TLS change must change the fingerprint")
+	}
+	if strings.Contains(connectionFingerprint(&tls), "invalid") {
+		t.Fatalf("fingerprint built a DSN: %s", connectionFingerprint(&tls))
+	}
+}
+
+func TestInitializeWatchesConfigFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	write := func(names ...string) {
+		dbs := map[string]any{}
+		for _, name := range names {
+			dbs[name] = map[string]any{"name": name, "type": "sqlite", "path": filepath.Join(dir, name+".db"), "enabled": true}
+		}
+		data, _ := json.Marshal(map[string]any{"watch_file": true, "databases": dbs})
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write("main")
+	cfg, err := NewDBConfigFromFile(ctx, path, false, nil, false)
+	if err != nil {
+		t.Fatalf("NewDBConfigFromFile: %v", err)
+	}
+	d, err := NewDatabaseServiceImpl(ctx, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceImpl: %v", err)
+	}
+	if err := d.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	t.Cleanup(func() { _ = d.CloseDBConnection(ctx) })
+
+	write("main", "archive")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := d.lookupDatabase("archive"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the config file change was not applied")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
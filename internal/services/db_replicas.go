@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Replica routing policies accepted in Database.ReplicaPolicy.
+const (
+	ReplicaPolicyRoundRobin   = "round_robin"
+	ReplicaPolicyLeastLatency = "least_latency"
+)
+
+const (
+	defaultReplicaHealthInterval = 10 * time.Second
+	replicaPingTimeout           = 2 * time.Second
+	// replicaFailureThreshold is how many consecutive failed pings take a replica out of rotation.
+	replicaFailureThreshold = 2
+)
+
+// ReplicaHealth is a snapshot of a replica state.
+type ReplicaHealth struct {
+	Name      string
+	Healthy   bool
+	Latency   time.Duration
+	LastError string
+	CheckedAt time.Time
+}
+
+type replicaNode struct {
+	name     string
+	pool     *sql.DB
+	healthy  atomic.Bool
+	latency  atomic.Int64 // EWMA in nanoseconds
+	failures atomic.Int32
+
+	mu        sync.Mutex
+	lastErr   string
+	checkedAt time.Time
+}
+
+// observe records the outcome of a health probe.
+func (n *replicaNode) observe(latency time.Duration, err error) {
+	n.mu.Lock()
+	n.checkedAt = time.Now()
+	if err != nil {
+		n.lastErr = err.Error()
+	} else {
+		n.lastErr = ""
+	}
+	n.mu.Unlock()
+
+	if err != nil {
+		if n.failures.Add(1) >= replicaFailureThreshold && n.healthy.Swap(false) {
+			gl.Log("warn", fmt.Sprintf("Replica '%s' out of rotation: %v", n.name, err))
+		}
+		return
+	}
+	n.failures.Store(0)
+	prev := n.latency.Load()
+	if prev == 0 {
+		n.latency.Store(int64(latency))
+	} else {
+		n.latency.Store((prev*7 + int64(latency)*3) / 10)
+	}
+	if !n.healthy.Swap(true) {
+		gl.Log("info", fmt.Sprintf("Replica '%s' back in rotation", n.name))
+	}
+}
+
+func (n *replicaNode) snapshot() ReplicaHealth {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return ReplicaHealth{
+		Name:      n.name,
+		Healthy:   n.healthy.Load(),
+		Latency:   time.Duration(n.latency.Load()),
+		LastError: n.lastErr,
+		CheckedAt: n.checkedAt,
+	}
+}
+
+// replicaSet picks the replica of one database serving a read, skipping the
+// unhealthy ones.
+type replicaSet struct {
+	name   string
+	policy string
+	nodes  []*replicaNode
+	next   atomic.Uint64
+	cancel context.CancelFunc
+}
+
+func newReplicaSet(name, policy string, nodes []*replicaNode) *replicaSet {
+	if policy != ReplicaPolicyLeastLatency {
+		policy = ReplicaPolicyRoundRobin
+	}
+	return &replicaSet{name: name, policy: policy, nodes: nodes}
+}
+
+// pick returns the pool of the replica used for a read, or nil when no
+// replica is healthy.
+func (rs *replicaSet) pick() gorm.ConnPool {
+	healthy := make([]*replicaNode, 0, len(rs.nodes))
+	for _, n := range rs.nodes {
+		if n.healthy.Load() {
+			healthy = append(healthy, n)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	if rs.policy == ReplicaPolicyLeastLatency {
+		best := healthy[0]
+		for _, n := range healthy[1:] {
+			if n.latency.Load() < best.latency.Load() {
+				best = n
+			}
+		}
+		return best.pool
+	}
+	return healthy[int(rs.next.Add(1)-1)%len(healthy)].pool
+}
+
+// readPool is the pool dbresolver sends the reads of a connection to: a
+// healthy replica of the current set, or the primary. A reload swaps the set
+// without touching the *gorm.DB.
+type readPool struct {
+	primary gorm.ConnPool
+	set     atomic.Pointer[replicaSet]
+}
+
+func (p *readPool) pool() gorm.ConnPool {
+	if rs := p.set.Load(); rs != nil {
+		if pool := rs.pick(); pool != nil {
+			return pool
+		}
+	}
+	return p.primary
+}
+
+func (p *readPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.pool().PrepareContext(ctx, query)
+}
+
+func (p *readPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.pool().ExecContext(ctx, query, args...)
+}
+
+func (p *readPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.pool().QueryContext(ctx, query, args...)
+}
+
+func (p *readPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.pool().QueryRowContext(ctx, query, args...)
+}
+
+func (rs *replicaSet) probe(ctx context.Context) {
+	for _, n := range rs.nodes {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		start := time.Now()
+		err := n.pool.PingContext(pingCtx)
+		cancel()
+		n.observe(time.Since(start), err)
+	}
+}
+
+func (rs *replicaSet) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.probe(ctx)
+		}
+	}
+}
+
+func (rs *replicaSet) close() {
+	if rs.cancel != nil {
+		rs.cancel()
+	}
+	for _, n := range rs.nodes {
+		_ = n.pool.Close()
+	}
+}
+
+// retire stops probing the replicas and closes their pools once the reads in
+// flight are done (see drainPool).
+func (rs *replicaSet) retire() {
+	if rs.cancel != nil {
+		rs.cancel()
+	}
+	for _, n := range rs.nodes {
+		go drainPool(n.name, n.pool)
+	}
+}
+
+// replicaConfig fills the blanks of a replica entry with the primary settings
+// (credentials, TLS, session and driver params), so a replica usually only
+// needs a host (and port).
+func replicaConfig(primary, replica *ti.Database) *ti.Database {
+	cfg := *replica
+	cfg.Replicas = nil
+	inherit := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	inherit(&cfg.Type, primary.Type)
+	inherit(&cfg.Driver, primary.Driver)
+	inherit(&cfg.Username, primary.Username)
+	inherit(&cfg.Password, primary.Password)
+	inherit(&cfg.Name, primary.Name)
+	inherit(&cfg.SSLMode, primary.SSLMode)
+	inherit(&cfg.SSLCert, primary.SSLCert)
+	inherit(&cfg.SSLKey, primary.SSLKey)
+	inherit(&cfg.SSLRootCert, primary.SSLRootCert)
+	inherit(&cfg.TimeZone, primary.TimeZone)
+	inherit(&cfg.ApplicationName, primary.ApplicationName)
+	// the replica shares the credentials of the primary, not the ones of
+	// "<replica name>_<Engine>"
+	inherit(&cfg.KeyringEntry, DatabaseKeyringEntry(primary))
+	if cfg.Port == nil {
+		cfg.Port = primary.Port
+	}
+	if cfg.ConnectTimeout == 0 {
+		cfg.ConnectTimeout = primary.ConnectTimeout
+	}
+	if len(primary.Params) > 0 {
+		params := make(map[string]string, len(primary.Params)+len(replica.Params))
+		for k, v := range primary.Params {
+			params[k] = v
+		}
+		for k, v := range replica.Params {
+			params[k] = v
+		}
+		cfg.Params = params
+	}
+	return &cfg
+}
+
+// attachReplicas registers the read/write splitting resolver on db: reads go
+// to the replicas declared on dbConfig (the primary when there are none),
+// writes and transactions to the primary.
+func (d *DBServiceImpl) attachReplicas(ctx context.Context, dbConfig *ti.Database, db *gorm.DB) error {
+	reads, err := registerReadPool(db)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao registrar réplicas de '%s': %w", dbConfig.Name, err)
+	}
+	d.useReplicas(ctx, dbConfig, reads)
+	return nil
+}
+
+// registerReadPool installs dbresolver on db with a readPool as its only
+// replica, linking the pool to the livePool of db.
+func registerReadPool(db *gorm.DB) (*readPool, error) {
+	reads := &readPool{primary: db.ConnPool}
+	dialector, err := dialectorFor(db.Dialector.Name(), reads)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{Replicas: []gorm.Dialector{dialector}})); err != nil {
+		return nil, err
+	}
+	if live, ok := db.ConnPool.(*livePool); ok {
+		live.reads = reads
+	}
+	return reads, nil
+}
+
+// openReplicas opens the replicas declared on dbConfig, returning nil when
+// there are none (or none could be opened).
+func openReplicas(dbConfig *ti.Database) []*replicaNode {
+	nodes := make([]*replicaNode, 0, len(dbConfig.Replicas))
+	for i, replica := range dbConfig.Replicas {
+		if replica == nil {
+			continue
+		}
+		cfg := replicaConfig(dbConfig, replica)
+		_, pool, _, err := openDialector(cfg)
+		if err != nil {
+			gl.Log("error", fmt.Sprintf("❌ Erro ao abrir réplica %d de '%s': %v", i, dbConfig.Name, err))
+			continue
+		}
+		name := fmt.Sprintf("%s#%d", dbConfig.Name, i)
+		if cfg.Host != "" {
+			name = fmt.Sprintf("%s@%s", dbConfig.Name, cfg.Host)
+		}
+		nodes = append(nodes, &replicaNode{name: name, pool: pool})
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
+
+// useReplicas points reads at the replicas of dbConfig and retires the set it
+// replaces.
+func (d *DBServiceImpl) useReplicas(ctx context.Context, dbConfig *ti.Database, reads *readPool) {
+	interval := defaultReplicaHealthInterval
+	if dbConfig.ReplicaHealthInterval != "" {
+		if parsed, err := time.ParseDuration(dbConfig.ReplicaHealthInterval); err == nil && parsed > 0 {
+			interval = parsed
+		}
+	}
+	var rs *replicaSet
+	if nodes := openReplicas(dbConfig); nodes != nil {
+		rs = d.startReplicas(ctx, dbConfig.Name, nodes, dbConfig.ReplicaPolicy, interval)
+	}
+	d.swapReplicas(dbConfig.Name, reads, rs)
+}
+
+// startReplicas probes the replicas and keeps probing them every interval.
+func (d *DBServiceImpl) startReplicas(ctx context.Context, name string, nodes []*replicaNode, policy string, interval time.Duration) *replicaSet {
+	rs := newReplicaSet(name, policy, nodes)
+	rs.probe(ctx)
+	watchCtx, cancel := context.WithCancel(context.Background())
+	rs.cancel = cancel
+	go rs.watch(watchCtx, interval)
+	gl.Log("info", fmt.Sprintf("Database '%s': %d réplica(s) registrada(s) (%s)", name, len(nodes), rs.policy))
+	return rs
+}
+
+// swapReplicas installs rs (none when nil) as the replica set of name.
+func (d *DBServiceImpl) swapReplicas(name string, reads *readPool, rs *replicaSet) {
+	reads.set.Store(rs)
+	d.mutexes.MuLock()
+	if d.replicas == nil {
+		d.replicas = make(map[string]*replicaSet)
+	}
+	old := d.replicas[name]
+	if rs != nil {
+		d.replicas[name] = rs
+	} else {
+		delete(d.replicas, name)
+	}
+	d.mutexes.MuUnlock()
+	if old != nil && old != rs {
+		old.retire()
+	}
+}
+
+// dialectorFor wraps an open pool in a GORM dialector of the given dialect.
+func dialectorFor(dialect string, conn gorm.ConnPool) (gorm.Dialector, error) {
+	switch NormalizeDialect(dialect) {
+	case DialectPostgres:
+		return postgres.New(postgres.Config{Conn: conn, PreferSimpleProtocol: true}), nil
+	case DialectMySQL:
+		return mysql.New(mysql.Config{Conn: conn}), nil
+	case DialectSQLite:
+		return sqlite.New(sqlite.Config{Conn: conn}), nil
+	case DialectSQLServer:
+		return sqlserver.New(sqlserver.Config{Conn: conn}), nil
+	default:
+		return nil, fmt.Errorf("banco de dados não suportado: %s", dialect)
+	}
+}
+
+// connect opens a database and its read replicas.
+func (d *DBServiceImpl) connect(ctx context.Context, dbConfig *ti.Database) (*gorm.DB, error) {
+	db, _, err := connectDatabase(ctx, dbConfig, d.gormLogger(dbConfig))
+	if err != nil {
+		return nil, err
+	}
+	if err := d.registerTenancy(db, dbConfig); err != nil {
+		closeGormDB(db)
+		return nil, err
+	}
+	if err := d.attachReplicas(ctx, dbConfig, db); err != nil {
+		gl.Log("error", err.Error())
+	}
+	return db, nil
+}
+
+// ReplicaStatus returns the health of the replicas of a database.
+func (d *DBServiceImpl) ReplicaStatus(name string) []ReplicaHealth {
+	if d == nil {
+		return nil
+	}
+	if dbConf, ok := d.lookupDatabase(name); ok {
+		name = dbConf.Name
+	}
+	d.mutexes.MuLock()
+	rs, ok := d.replicas[name]
+	d.mutexes.MuUnlock()
+	if !ok {
+		return nil
+	}
+	out := make([]ReplicaHealth, 0, len(rs.nodes))
+	for _, n := range rs.nodes {
+		out = append(out, n.snapshot())
+	}
+	return out
+}
+
+func (d *DBServiceImpl) closeReplicas() {
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	for name, rs := range d.replicas {
+		rs.close()
+		delete(d.replicas, name)
+	}
+}
+
+type primaryCtxKey struct{}
+
+// UsePrimary returns a copy of ctx whose connections send reads to the primary,
+// for read-after-write consistency.
+func UsePrimary(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, primaryCtxKey{}, true)
+}
+
+// ForcePrimary returns a session of db whose reads go to the primary.
+func ForcePrimary(db *gorm.DB) *gorm.DB {
+	if db == nil {
+		return nil
+	}
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
+
+// withReadPreference applies UsePrimary from the context to db.
+func withReadPreference(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if ctx == nil || db == nil {
+		return db
+	}
+	if forced, _ := ctx.Value(primaryCtxKey{}).(bool); forced {
+		return ForcePrimary(db)
+	}
+	return db
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type replicaItem struct {
+	ID   uint
+	Name string
+}
+
+func TestReplicaReadWriteSplitting(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	primary, err := gorm.Open(sqlite.Open(filepath.Join(dir, "primary.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open primary: %v", err)
+	}
+	replicaPool, err := sql.Open("sqlite3", filepath.Join(dir, "replica.db"))
+	if err != nil {
+		t.Fatalf("failed to open replica: %v", err)
+	}
+	replica, _ := gorm.Open(sqlite.New(sqlite.Config{Conn: replicaPool}), &gorm.Config{})
+	for _, db := range []*gorm.DB{primary, replica} {
+		if err := db.AutoMigrate(&replicaItem{}); err != nil {
+			t.Fatalf("AutoMigrate: %v", err)
+		}
+	}
+	replica.Create(&replicaItem{Name: "from-replica"})
+
+	d := &DBServiceImpl{mutexes: newRoutingTestService(t).mutexes}
+	node := &replicaNode{name: "replica", pool: replicaPool}
+	reads, err := registerReadPool(primary)
+	if err != nil {
+		t.Fatalf("registerReadPool: %v", err)
+	}
+	d.swapReplicas("main", reads, d.startReplicas(ctx, "main", []*replicaNode{node}, ReplicaPolicyRoundRobin, time.Hour))
+	t.Cleanup(d.closeReplicas)
+
+	if err := primary.Create(&replicaItem{Name: "from-primary"}).Error; err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	var got replicaItem
+	primary.First(&got)
+	if got.Name != "from-replica" {
+		t.Fatalf("expected read from replica, got %q", got.Name)
+	}
+
+	got = replicaItem{}
+	ForcePrimary(primary).First(&got)
+	if got.Name != "from-primary" {
+		t.Fatalf("expected forced read from primary, got %q", got.Name)
+	}
+
+	// named lookups follow UsePrimary as GetDB does
+	d.config = &DBConfig{Databases: map[string]*ti.Database{"main": {Name: "main", Type: "sqlite", Enabled: true}}}
+	d.db = map[string]*gorm.DB{"main": primary}
+	named, err := GetDBByName(UsePrimary(ctx), d, "main")
+	got = replicaItem{}
+	if err != nil || named.First(&got).Error != nil || got.Name != "from-primary" {
+		t.Fatalf("expected GetDBByName to read from primary, got %q: %v", got.Name, err)
+	}
+
+	for i := 0; i < replicaFailureThreshold; i++ {
+		node.observe(0, errors.New("down"))
+	}
+	got = replicaItem{}
+	primary.First(&got)
+	if got.Name != "from-primary" {
+		t.Fatalf("unhealthy replica must be skipped, got %q", got.Name)
+	}
+	if st := d.ReplicaStatus("main"); len(st) != 1 || st[0].Healthy {
+		t.Fatalf("unexpected replica status: %+v", st)
+	}
+}
+
+func TestReplicaSetLeastLatency(t *testing.T) {
+	fast, _ := sql.Open("sqlite3", ":memory:")
+	slow, _ := sql.Open("sqlite3", ":memory:")
+	defer fast.Close()
+	defer slow.Close()
+
+	nFast := &replicaNode{name: "fast", pool: fast}
+	nSlow := &replicaNode{name: "slow", pool: slow}
+	nFast.observe(time.Millisecond, nil)
+	nSlow.observe(50*time.Millisecond, nil)
+
+	rs := newReplicaSet("main", ReplicaPolicyLeastLatency, []*replicaNode{nSlow, nFast})
+	if got := rs.pick(); got != gorm.ConnPool(fast) {
+		t.Fatalf("expected the fastest replica")
+	}
+}
+
+func TestReplicaConfigInheritsPrimary(t *testing.T) {
+	primary := &ti.Database{
+		Type: "postgresql", Name: "kubex_db", Host: "primary", Port: 5432, Username: "kubex",
+		SSLMode: "verify-full", SSLRootCert: "/etc/ca.pem", TimeZone: "UTC", ConnectTimeout: 5,
+		ApplicationName: "gdbase", Params: map[string]string{"search_path": "app", "statement_timeout": "5000"},
+	}
+	replica := &ti.Database{Host: "replica", Name: "kubex_ro", Params: map[string]string{"statement_timeout": "1000"}}
+
+	cfg := replicaConfig(primary, replica)
+	if cfg.Host != "replica" || cfg.Name != "kubex_ro" || cfg.Port != 5432 || cfg.Username != "kubex" {
+		t.Fatalf("unexpected connection settings: %+v", cfg)
+	}
+	if cfg.SSLMode != "verify-full" || cfg.SSLRootCert != "/etc/ca.pem" || cfg.TimeZone != "UTC" || cfg.ConnectTimeout != 5 || cfg.ApplicationName != "gdbase" {
+		t.Fatalf("TLS and session settings not inherited: %+v", cfg)
+	}
+	if cfg.Params["search_path"] != "app" || cfg.Params["statement_timeout"] != "1000" {
+		t.Fatalf("unexpected params: %v", cfg.Params)
+	}
+	if primary.Params["statement_timeout"] != "5000" {
+		t.Fatalf("the primary params were changed: %v", primary.Params)
+	}
+	if cfg.KeyringEntry != "kubex_db_Postgres" {
+		t.Fatalf("expected the keyring entry of the primary, got %q", cfg.KeyringEntry)
+	}
+}
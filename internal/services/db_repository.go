@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Page is a page of a Repository.List.
+type Page[T any] struct {
+	Data       []*T `json:"data"`
+	Total      int  `json:"total"`
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	TotalPages int  `json:"totalPages"`
+	// NextCursor selects the next keyset page; empty on the last one
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Repository is the generic CRUD and query repository of the model T. Every
+// method runs on the connection bound to ctx (see DBFromContext), joining the
+// transaction, tenant and capture it carries. Model repositories embed it and
+// keep only their specific methods.
+type Repository[T any] struct {
+	db *gorm.DB
+
+	schemaOnce sync.Once
+	schema     *schema.Schema
+	schemaErr  error
+}
+
+// NewRepository returns the repository of T on db.
+func NewRepository[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// NewRepositoryFor returns the repository of T on the database bound to
+// repoName (see GetDBForRepo).
+func NewRepositoryFor[T any](ctx context.Context, d *DBServiceImpl, repoName string) (*Repository[T], error) {
+	db, err := GetDBForRepo(ctx, d, repoName)
+	if err != nil {
+		return nil, err
+	}
+	return NewRepository[T](db), nil
+}
+
+// DB returns the connection of the repository bound to ctx.
+func (r *Repository[T]) DB(ctx context.Context) *gorm.DB {
+	if ctx == nil {
+		return r.db
+	}
+	return DBFromContext(ctx, r.db).WithContext(ctx)
+}
+
+func (r *Repository[T]) modelSchema() (*schema.Schema, error) {
+	r.schemaOnce.Do(func() {
+		stmt := &gorm.Statement{DB: r.db}
+		r.schemaErr = stmt.Parse(new(T))
+		r.schema = stmt.Schema
+	})
+	return r.schema, r.schemaErr
+}
+
+// byKey restricts db to the row whose primary key is id.
+func (r *Repository[T]) byKey(db *gorm.DB, id any) (*gorm.DB, error) {
+	s, err := r.modelSchema()
+	if err != nil {
+		return nil, err
+	}
+	pk := s.PrioritizedPrimaryField
+	if pk == nil && len(s.PrimaryFields) > 0 {
+		pk = s.PrimaryFields[0]
+	}
+	if pk == nil {
+		return nil, fmt.Errorf("❌ Tabela '%s' sem chave primária", s.Table)
+	}
+	return db.Where(clause.Eq{Column: specColumn(pk), Value: id}), nil
+}
+
+// Create inserts entity.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) (*T, error) {
+	if entity == nil {
+		return nil, fmt.Errorf("❌ Entidade nula")
+	}
+	if err := r.DB(ctx).Create(entity).Error; err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Get returns the row whose primary key is id (gorm.ErrRecordNotFound when missing).
+func (r *Repository[T]) Get(ctx context.Context, id any, preload ...string) (*T, error) {
+	db, err := r.byKey(r.DB(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	for _, association := range preload {
+		db = db.Preload(association)
+	}
+	var entity T
+	if err := db.First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// Update saves entity, checking its lock version when the model has one
+// (see SaveOptimistic and ErrStaleObject).
+func (r *Repository[T]) Update(ctx context.Context, entity *T) (*T, error) {
+	if entity == nil {
+		return nil, fmt.Errorf("❌ Entidade nula")
+	}
+	if err := SaveOptimistic(r.DB(ctx), entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Upsert inserts entity or, when it conflicts on conflictColumns (the primary
+// key when none is given), updates the existing row with it.
+func (r *Repository[T]) Upsert(ctx context.Context, entity *T, conflictColumns ...string) (*T, error) {
+	if entity == nil {
+		return nil, fmt.Errorf("❌ Entidade nula")
+	}
+	onConflict := clause.OnConflict{UpdateAll: true}
+	if len(conflictColumns) > 0 {
+		s, err := r.modelSchema()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range conflictColumns {
+			field, err := specField(s, name)
+			if err != nil {
+				return nil, err
+			}
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+		}
+	}
+	if err := r.DB(ctx).Clauses(onConflict).Create(entity).Error; err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// Delete removes the row whose primary key is id (gorm.ErrRecordNotFound when missing).
+func (r *Repository[T]) Delete(ctx context.Context, id any) error {
+	db, err := r.byKey(r.DB(ctx), id)
+	if err != nil {
+		return err
+	}
+	res := db.Delete(new(T))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// query applies spec to a query of T.
+func (r *Repository[T]) query(ctx context.Context, spec *QuerySpec) (*gorm.DB, *schema.Schema, error) {
+	s, err := r.modelSchema()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := spec.filtered(r.DB(ctx).Model(new(T)), s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, s, nil
+}
+
+// Find returns the rows matching spec, sorted and limited by it (without its pagination).
+func (r *Repository[T]) Find(ctx context.Context, spec *QuerySpec) ([]*T, error) {
+	db, s, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if db, err = spec.ordered(db, s); err != nil {
+		return nil, err
+	}
+	if spec != nil && spec.Limit > 0 {
+		db = db.Limit(spec.Limit)
+	}
+	out := make([]*T, 0)
+	if err := db.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindOne returns the first row matching spec (gorm.ErrRecordNotFound when none).
+func (r *Repository[T]) FindOne(ctx context.Context, spec *QuerySpec) (*T, error) {
+	db, s, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if db, err = spec.ordered(db, s); err != nil {
+		return nil, err
+	}
+	var entity T
+	if err := db.Take(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// Count returns the number of rows matching the filters of spec.
+func (r *Repository[T]) Count(ctx context.Context, spec *QuerySpec) (int64, error) {
+	var filters *QuerySpec
+	if spec != nil {
+		filters = &QuerySpec{Filters: spec.Filters}
+	}
+	db, _, err := r.query(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// List returns a page of the rows matching spec: the page Page (1-based) of
+// Limit rows (DefaultPageLimit when unset), or with spec.Keyset the Limit rows
+// following spec.Cursor, with the cursor of the next page.
+func (r *Repository[T]) List(ctx context.Context, spec *QuerySpec) (*Page[T], error) {
+	if spec == nil {
+		spec = &QuerySpec{}
+	}
+	limit := spec.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	page := max(spec.Page, 1)
+
+	total, err := r.Count(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	db, s, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if db, err = spec.ordered(db, s); err != nil {
+		return nil, err
+	}
+
+	out := &Page[T]{
+		Data:       make([]*T, 0),
+		Total:      int(total),
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (int(total) + limit - 1) / limit,
+	}
+	if !spec.Keyset {
+		if err := db.Offset((page - 1) * limit).Limit(limit).Find(&out.Data).Error; err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	// one more row tells whether there is a next page
+	out.Page = 0
+	if err := db.Limit(limit + 1).Find(&out.Data).Error; err != nil {
+		return nil, err
+	}
+	if len(out.Data) > limit {
+		out.Data = out.Data[:limit]
+		fields, _, err := spec.sortFields(s)
+		if err != nil {
+			return nil, err
+		}
+		if out.NextCursor, err = encodeCursor(ctx, reflect.ValueOf(out.Data[limit-1]).Elem(), fields); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
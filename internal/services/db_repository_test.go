@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type repoOwner struct {
+	ID    uint
+	Name  string
+	Tasks []repoTask `gorm:"foreignKey:OwnerID"`
+}
+
+type repoTask struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"ownerId"`
+	Title       string    `json:"title" gorm:"uniqueIndex"`
+	Status      string    `json:"status"`
+	Priority    int       `json:"priority"`
+	DueAt       time.Time `json:"dueAt"`
+	Notes       *string   `json:"notes"`
+	LockVersion int       `json:"lockVersion"`
+}
+
+func newTaskRepository(t *testing.T) *Repository[repoTask] {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repo.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&repoOwner{}, &repoTask{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	owner := repoOwner{Name: "ops"}
+	db.Create(&owner)
+	repo := NewRepository[repoTask](db)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 7; i++ {
+		status := "open"
+		if i%2 == 0 {
+			status = "done"
+		}
+		task := &repoTask{OwnerID: owner.ID, Title: fmt.Sprintf("task %d", i), Status: status, Priority: i % 3, DueAt: base.Add(time.Duration(i) * time.Hour)}
+		if _, err := repo.Create(context.Background(), task); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	return repo
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newTaskRepository(t)
+
+	task, err := repo.Get(ctx, 3)
+	if err != nil || task.Title != "task 3" {
+		t.Fatalf("Get = %+v, %v", task, err)
+	}
+	task.Status = "done"
+	if _, err := repo.Update(ctx, task); err != nil || task.LockVersion != 1 {
+		t.Fatalf("Update = version %d, %v", task.LockVersion, err)
+	}
+	stale, _ := repo.Get(ctx, 3)
+	stale.LockVersion = 0
+	if _, err := repo.Update(ctx, stale); !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+
+	if _, err := repo.Upsert(ctx, &repoTask{Title: "task 3", Status: "archived", OwnerID: 1}, "title"); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if n, _ := repo.Count(ctx, NewQuerySpec().Eq("title", "task 3").Eq("status", "archived")); n != 1 {
+		t.Fatalf("upsert did not update the existing row: %d", n)
+	}
+
+	if err := repo.Delete(ctx, 7); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, 7); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+	if _, err := repo.Get(ctx, 7); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestRepositoryQuerySpec(t *testing.T) {
+	ctx := context.Background()
+	repo := newTaskRepository(t)
+
+	open, err := repo.Find(ctx, NewQuerySpec().Eq("status", "open").Where("priority", OpGte, 1).OrderByDesc("dueAt"))
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(open) != 3 || open[0].Title != "task 7" || open[2].Title != "task 1" {
+		t.Fatalf("unexpected rows: %+v", open)
+	}
+	if n, _ := repo.Count(ctx, NewQuerySpec().In("title", "task 1", "task 2").Where("notes", OpIsNull, nil)); n != 2 {
+		t.Fatalf("Count = %d", n)
+	}
+	picked, err := repo.FindOne(ctx, NewQuerySpec().Select("title").Where("title", OpLike, "task 5%"))
+	if err != nil || picked.Title != "task 5" || picked.Status != "" {
+		t.Fatalf("FindOne = %+v, %v", picked, err)
+	}
+	if _, err := repo.Find(ctx, NewQuerySpec().Eq("password; DROP TABLE repo_tasks", 1)); err == nil {
+		t.Fatalf("expected an unknown field error")
+	}
+
+	page, err := repo.List(ctx, NewQuerySpec().OrderBy("id").Paginate(2, 3))
+	if err != nil || page.Total != 7 || page.TotalPages != 3 || len(page.Data) != 3 || page.Data[0].Title != "task 4" {
+		t.Fatalf("offset page = %+v, %v", page, err)
+	}
+
+	// keyset pages by priority, ties broken by the primary key
+	var titles []string
+	spec := NewQuerySpec().OrderBy("priority").After("", 3)
+	for range 4 {
+		page, err := repo.List(ctx, spec)
+		if err != nil {
+			t.Fatalf("keyset page: %v", err)
+		}
+		for _, task := range page.Data {
+			titles = append(titles, task.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		spec.Cursor = page.NextCursor
+	}
+	want := "[task 3 task 6 task 1 task 4 task 7 task 2 task 5]"
+	if fmt.Sprint(titles) != want {
+		t.Fatalf("keyset order = %v, want %s", titles, want)
+	}
+	if _, err := repo.List(ctx, NewQuerySpec().After("bogus", 3)); err == nil {
+		t.Fatalf("expected an invalid cursor error")
+	}
+
+	owners := NewRepository[repoOwner](repo.db)
+	owner, err := owners.FindOne(ctx, NewQuerySpec().With("Tasks"))
+	if err != nil || len(owner.Tasks) != 7 {
+		t.Fatalf("preload = %+v, %v", owner, err)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+)
+
+type connectionCtxKey struct{}
+
+// Using returns a copy of ctx that routes GetDB/GetDBForRepo to the named
+// connection (a key of DBConfig.Databases or a Database.Name).
+func Using(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, connectionCtxKey{}, name)
+}
+
+// ConnectionFromContext returns the connection name set with Using, if any.
+func ConnectionFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	name, ok := ctx.Value(connectionCtxKey{}).(string)
+	return name, ok && name != ""
+}
+
+// routes returns the databases and the bindings of the config. ReloadConfig
+// replaces both maps under the service lock and never writes into them, so
+// only the references are read under the lock.
+func (d *DBServiceImpl) routes() (map[string]*ti.Database, map[string]string) {
+	if d == nil {
+		return nil, nil
+	}
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	if d.config == nil {
+		return nil, nil
+	}
+	return d.config.Databases, d.config.Bindings
+}
+
+// connections returns a copy of the open connections, keyed by Database.Name.
+func (d *DBServiceImpl) connections() map[string]*gorm.DB {
+	d.mutexes.MuLock()
+	defer d.mutexes.MuUnlock()
+	out := make(map[string]*gorm.DB, len(d.db))
+	for name, db := range d.db {
+		out[name] = db
+	}
+	return out
+}
+
+// lookupDatabase finds a database entry by config key or by Database.Name.
+func (d *DBServiceImpl) lookupDatabase(name string) (*ti.Database, bool) {
+	databases, _ := d.routes()
+	if dbConf, ok := databases[name]; ok && dbConf != nil {
+		return dbConf, true
+	}
+	for _, dbConf := range databases {
+		if dbConf != nil && dbConf.Name == name {
+			return dbConf, true
+		}
+	}
+	return nil, false
+}
+
+// ConnectionNames returns the names of the enabled databases in the config, sorted.
+func (d *DBServiceImpl) ConnectionNames() []string {
+	databases, _ := d.routes()
+	if databases == nil {
+		return nil
+	}
+	names := make([]string, 0, len(databases))
+	for key, dbConf := range databases {
+		if dbConf != nil && dbConf.Enabled {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetDBByName returns the connection for a named database, connecting lazily
+// when the database is configured and enabled but not connected yet. Inside
+// WithTx on that database it returns the transaction; reads go to the primary
+// when the context was marked with UsePrimary, as with GetDB.
+func GetDBByName(ctx context.Context, d *DBServiceImpl, name string) (*gorm.DB, error) {
+	ctx = d.captureContext(ctx)
+	db, err := d.connection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if tx, ok := txFor(ctx, db); ok {
+		return bindDB(ctx, tx), nil
+	}
+	return bindDB(ctx, withReadPreference(ctx, db)), nil
+}
+
+func (d *DBServiceImpl) connection(ctx context.Context, name string) (*gorm.DB, error) {
+	if d == nil {
+		return nil, fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	if d.config == nil {
+		return nil, fmt.Errorf("❌ Database Service não configurado")
+	}
+	dbConf, ok := d.lookupDatabase(name)
+	if !ok {
+		return nil, fmt.Errorf("❌ Banco de dados '%s' não encontrado na configuração", name)
+	}
+	if !dbConf.Enabled {
+		return nil, fmt.Errorf("❌ Banco de dados '%s' está desabilitado", name)
+	}
+
+	// connMu serializes on-demand connections so a database is opened only once
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
+	d.mutexes.MuLock()
+	if d.db == nil {
+		d.db = make(map[string]*gorm.DB)
+	}
+	db, ok := d.db[dbConf.Name]
+	d.mutexes.MuUnlock()
+	if ok && db != nil {
+		return db, nil
+	}
+
+	gl.Log("notice", fmt.Sprintf("Connecting to DB '%s' on demand", dbConf.Name))
+	db, err := d.connect(ctx, dbConf)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao conectar ao banco de dados '%s': %w", name, err)
+	}
+
+	d.mutexes.MuLock()
+	d.db[dbConf.Name] = db
+	d.mutexes.MuUnlock()
+	return db, nil
+}
+
+// GetDBForRepo resolves the connection used by a repository. The connection set
+// on the context with Using wins, then the DBConfig.Bindings entry for repo,
+// then the default database (same rules as GetDB). A transaction started with
+// WithTx on the resolved database is returned instead of the connection.
+func GetDBForRepo(ctx context.Context, d *DBServiceImpl, repo string) (*gorm.DB, error) {
+	ctx = d.captureContext(ctx)
+	if _, ok := ConnectionFromContext(ctx); !ok {
+		_, bindings := d.routes()
+		if name, ok := bindings[repo]; ok && name != "" {
+			db, err := d.connection(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if tx, ok := txFor(ctx, db); ok {
+				return bindDB(ctx, tx), nil
+			}
+			return bindDB(ctx, withReadPreference(ctx, db)), nil
+		}
+	}
+	return GetDB(ctx, d)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newRoutingTestService(t *testing.T) *DBServiceImpl {
+	t.Helper()
+	cfg := &DBConfig{
+		Databases: map[string]*ti.Database{
+			"transactional": {Name: "transactional", Type: "sqlite", Enabled: true},
+			"analytics":     {Name: "analytics", Type: "sqlite", Enabled: true},
+		},
+		Bindings: map[string]string{"job_queue": "analytics"},
+	}
+	d, err := NewDatabaseServiceImpl(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceImpl: %v", err)
+	}
+	for name := range cfg.Databases {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		d.db[name] = db
+	}
+	return d
+}
+
+func TestGetDBRouting(t *testing.T) {
+	ctx := context.Background()
+	d := newRoutingTestService(t)
+
+	if _, err := GetDB(ctx, d); err == nil {
+		t.Fatalf("expected ambiguity error without a default database")
+	}
+
+	analytics, err := GetDBByName(ctx, d, "analytics")
+	if err != nil || analytics != d.db["analytics"] {
+		t.Fatalf("GetDBByName(analytics) = %v, %v", analytics, err)
+	}
+	if _, err := GetDBByName(ctx, d, "missing"); err == nil {
+		t.Fatalf("expected error for unknown database")
+	}
+
+	db, err := GetDB(Using(ctx, "transactional"), d)
+	if err != nil || db != d.db["transactional"] {
+		t.Fatalf("GetDB with Using(transactional) = %v, %v", db, err)
+	}
+
+	db, err = GetDBForRepo(ctx, d, "job_queue")
+	if err != nil || db != d.db["analytics"] {
+		t.Fatalf("binding for job_queue not honored: %v, %v", db, err)
+	}
+	db, err = GetDBForRepo(Using(ctx, "transactional"), d, "job_queue")
+	if err != nil || db != d.db["transactional"] {
+		t.Fatalf("Using must take precedence over bindings: %v, %v", db, err)
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Schema is the live structure of a database, as returned by DescribeSchema.
+type Schema struct {
+	Dialect string        `json:"dialect" yaml:"dialect"`
+	Tables  []TableSchema `json:"tables" yaml:"tables"`
+	Views   []ViewSchema  `json:"views,omitempty" yaml:"views,omitempty"`
+	Enums   []EnumSchema  `json:"enums,omitempty" yaml:"enums,omitempty"`
+}
+
+// TableSchema describes one table.
+type TableSchema struct {
+	Name        string             `json:"name" yaml:"name"`
+	Columns     []ColumnSchema     `json:"columns" yaml:"columns"`
+	PrimaryKey  []string           `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	Indexes     []IndexSchema      `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+	ForeignKeys []ForeignKeySchema `json:"foreign_keys,omitempty" yaml:"foreign_keys,omitempty"`
+}
+
+// ColumnSchema describes one column. Type is the full database type (e.g.
+// varchar(255)); Default is the default expression as the database reports it
+// (e.g. 'active' or now()), nil when the column has no default.
+type ColumnSchema struct {
+	Name          string  `json:"name" yaml:"name"`
+	Type          string  `json:"type" yaml:"type"`
+	Nullable      bool    `json:"nullable" yaml:"nullable"`
+	Default       *string `json:"default,omitempty" yaml:"default,omitempty"`
+	PrimaryKey    bool    `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	AutoIncrement bool    `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	Unique        bool    `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Comment       string  `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// IndexSchema describes one index.
+type IndexSchema struct {
+	Name    string   `json:"name" yaml:"name"`
+	Columns []string `json:"columns" yaml:"columns"`
+	Unique  bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Primary bool     `json:"primary,omitempty" yaml:"primary,omitempty"`
+}
+
+// ForeignKeySchema describes one foreign key.
+type ForeignKeySchema struct {
+	Name       string   `json:"name" yaml:"name"`
+	Columns    []string `json:"columns" yaml:"columns"`
+	RefTable   string   `json:"ref_table" yaml:"ref_table"`
+	RefColumns []string `json:"ref_columns" yaml:"ref_columns"`
+	OnDelete   string   `json:"on_delete,omitempty" yaml:"on_delete,omitempty"`
+	OnUpdate   string   `json:"on_update,omitempty" yaml:"on_update,omitempty"`
+}
+
+// ViewSchema describes one view.
+type ViewSchema struct {
+	Name       string `json:"name" yaml:"name"`
+	Definition string `json:"definition,omitempty" yaml:"definition,omitempty"`
+}
+
+// EnumSchema describes an enumerated type. MySQL enums are inline column
+// types, so they are named "<table>.<column>".
+type EnumSchema struct {
+	Name   string   `json:"name" yaml:"name"`
+	Values []string `json:"values" yaml:"values"`
+}
+
+// Table returns the table with the given name.
+func (s *Schema) Table(name string) (*TableSchema, bool) {
+	for i := range s.Tables {
+		if strings.EqualFold(s.Tables[i].Name, name) {
+			return &s.Tables[i], true
+		}
+	}
+	return nil, false
+}
+
+// Column returns the column with the given name.
+func (t *TableSchema) Column(name string) (*ColumnSchema, bool) {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+// DescribeSchema introspects the database selected by ctx (see GetDB). When
+// tables are given, only those tables are described.
+func (d *DBServiceImpl) DescribeSchema(ctx context.Context, tables ...string) (*Schema, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return DescribeSchema(db, tables...)
+}
+
+// DescribeSchema introspects db: tables, columns, indexes and foreign keys,
+// plus views and enums. Postgres, MySQL, SQLite and SQL Server are supported.
+func DescribeSchema(db *gorm.DB, tables ...string) (*Schema, error) {
+	dialect := NormalizeDialect(db.Dialector.Name())
+	switch dialect {
+	case DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer:
+	default:
+		return nil, fmt.Errorf("banco de dados não suportado: %s", dialect)
+	}
+	schema := &Schema{Dialect: dialect}
+
+	names, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao listar tabelas: %w", err)
+	}
+	wanted := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		wanted[strings.ToLower(t)] = true
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if dialect == DialectSQLite && strings.HasPrefix(name, "sqlite_") {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[strings.ToLower(name)] {
+			continue
+		}
+		table, err := describeTable(db, name)
+		if err != nil {
+			return nil, err
+		}
+		schema.Tables = append(schema.Tables, *table)
+	}
+	if len(wanted) > 0 && len(schema.Tables) < len(wanted) {
+		for t := range wanted {
+			if _, ok := schema.Table(t); !ok {
+				return nil, fmt.Errorf("❌ Tabela '%s' não encontrada", t)
+			}
+		}
+	}
+
+	if err := describeForeignKeys(db, dialect, schema); err != nil {
+		return nil, err
+	}
+	if len(wanted) == 0 {
+		if schema.Views, err = describeViews(db, dialect); err != nil {
+			return nil, err
+		}
+	}
+	if schema.Enums, err = describeEnums(db, dialect, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func describeTable(db *gorm.DB, name string) (*TableSchema, error) {
+	table := &TableSchema{Name: name}
+	columnTypes, err := db.Migrator().ColumnTypes(name)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao ler colunas de '%s': %w", name, err)
+	}
+	for _, ct := range columnTypes {
+		col := ColumnSchema{Name: ct.Name(), Type: strings.ToLower(ct.DatabaseTypeName())}
+		if full, ok := ct.ColumnType(); ok && full != "" {
+			col.Type = full
+		}
+		if nullable, ok := ct.Nullable(); ok {
+			col.Nullable = nullable
+		}
+		if def, ok := ct.DefaultValue(); ok {
+			col.Default = &def
+		}
+		if pk, ok := ct.PrimaryKey(); ok && pk {
+			col.PrimaryKey = true
+			table.PrimaryKey = append(table.PrimaryKey, col.Name)
+		}
+		if ai, ok := ct.AutoIncrement(); ok {
+			col.AutoIncrement = ai
+		}
+		if unique, ok := ct.Unique(); ok {
+			col.Unique = unique
+		}
+		if comment, ok := ct.Comment(); ok {
+			col.Comment = comment
+		}
+		table.Columns = append(table.Columns, col)
+	}
+
+	indexes, err := db.Migrator().GetIndexes(name)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao ler índices de '%s': %w", name, err)
+	}
+	for _, idx := range indexes {
+		is := IndexSchema{Name: idx.Name(), Columns: idx.Columns()}
+		if unique, ok := idx.Unique(); ok {
+			is.Unique = unique
+		}
+		if primary, ok := idx.PrimaryKey(); ok {
+			is.Primary = primary
+		}
+		table.Indexes = append(table.Indexes, is)
+	}
+	sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
+	return table, nil
+}
+
+type fkRow struct {
+	Name       string
+	TableName  string
+	ColumnName string
+	RefTable   string
+	RefColumn  string
+	OnDelete   string
+	OnUpdate   string
+}
+
+const postgresForeignKeys = `
+SELECT rc.constraint_name AS name, kcu.table_name AS table_name, kcu.column_name AS column_name,
+       ref.table_name AS ref_table, ref.column_name AS ref_column,
+       rc.delete_rule AS on_delete, rc.update_rule AS on_update
+FROM information_schema.referential_constraints rc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name
+JOIN information_schema.key_column_usage ref
+  ON ref.constraint_schema = rc.unique_constraint_schema AND ref.constraint_name = rc.unique_constraint_name
+ AND ref.ordinal_position = kcu.position_in_unique_constraint
+WHERE kcu.table_schema = current_schema()
+ORDER BY kcu.table_name, rc.constraint_name, kcu.ordinal_position`
+
+// sqlServerForeignKeys reads the catalog views: the INFORMATION_SCHEMA of SQL
+// Server has no position_in_unique_constraint to pair the columns.
+const sqlServerForeignKeys = `
+SELECT fk.name AS name, OBJECT_NAME(fk.parent_object_id) AS table_name, pc.name AS column_name,
+       OBJECT_NAME(fk.referenced_object_id) AS ref_table, rc.name AS ref_column,
+       REPLACE(fk.delete_referential_action_desc, '_', ' ') AS on_delete,
+       REPLACE(fk.update_referential_action_desc, '_', ' ') AS on_update
+FROM sys.foreign_keys fk
+JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE OBJECT_SCHEMA_NAME(fk.parent_object_id) = SCHEMA_NAME()
+ORDER BY OBJECT_NAME(fk.parent_object_id), fk.name, fkc.constraint_column_id`
+
+func describeForeignKeys(db *gorm.DB, dialect string, schema *Schema) error {
+	var rows []fkRow
+	var err error
+	switch dialect {
+	case DialectPostgres:
+		err = db.Raw(postgresForeignKeys).Scan(&rows).Error
+	case DialectSQLServer:
+		err = db.Raw(sqlServerForeignKeys).Scan(&rows).Error
+	case DialectMySQL:
+		err = db.Raw(`
+SELECT kcu.CONSTRAINT_NAME AS name, kcu.TABLE_NAME AS table_name, kcu.COLUMN_NAME AS column_name,
+       kcu.REFERENCED_TABLE_NAME AS ref_table, kcu.REFERENCED_COLUMN_NAME AS ref_column,
+       rc.DELETE_RULE AS on_delete, rc.UPDATE_RULE AS on_update
+FROM information_schema.KEY_COLUMN_USAGE kcu
+JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+  ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+WHERE kcu.TABLE_SCHEMA = DATABASE() AND kcu.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY kcu.TABLE_NAME, kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION`).Scan(&rows).Error
+	case DialectSQLite:
+		for _, t := range schema.Tables {
+			var list []struct {
+				ID       int
+				Seq      int
+				Table    string
+				From     string
+				To       string
+				OnUpdate string
+				OnDelete string
+			}
+			if err := db.Raw(fmt.Sprintf("PRAGMA foreign_key_list(%q)", t.Name)).Scan(&list).Error; err != nil {
+				return fmt.Errorf("❌ Erro ao ler chaves estrangeiras de '%s': %w", t.Name, err)
+			}
+			for _, fk := range list {
+				rows = append(rows, fkRow{
+					Name: fmt.Sprintf("fk_%s_%d", t.Name, fk.ID), TableName: t.Name, ColumnName: fk.From,
+					RefTable: fk.Table, RefColumn: fk.To, OnDelete: fk.OnDelete, OnUpdate: fk.OnUpdate,
+				})
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler chaves estrangeiras: %w", err)
+	}
+
+	for _, r := range rows {
+		table, ok := schema.Table(r.TableName)
+		if !ok {
+			continue
+		}
+		n := len(table.ForeignKeys)
+		if n == 0 || table.ForeignKeys[n-1].Name != r.Name {
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKeySchema{
+				Name: r.Name, RefTable: r.RefTable, OnDelete: r.OnDelete, OnUpdate: r.OnUpdate,
+			})
+			n++
+		}
+		fk := &table.ForeignKeys[n-1]
+		fk.Columns = append(fk.Columns, r.ColumnName)
+		fk.RefColumns = append(fk.RefColumns, r.RefColumn)
+	}
+	return nil
+}
+
+func describeViews(db *gorm.DB, dialect string) ([]ViewSchema, error) {
+	var query string
+	switch dialect {
+	case DialectPostgres:
+		query = `SELECT table_name AS name, view_definition AS definition FROM information_schema.views WHERE table_schema = current_schema() ORDER BY table_name`
+	case DialectMySQL:
+		query = `SELECT TABLE_NAME AS name, VIEW_DEFINITION AS definition FROM information_schema.VIEWS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME`
+	case DialectSQLServer:
+		query = `SELECT table_name AS name, view_definition AS definition FROM information_schema.views WHERE table_schema = SCHEMA_NAME() ORDER BY table_name`
+	case DialectSQLite:
+		query = `SELECT name, sql AS definition FROM sqlite_master WHERE type = 'view' ORDER BY name`
+	}
+	var views []ViewSchema
+	if err := db.Raw(query).Scan(&views).Error; err != nil {
+		return nil, fmt.Errorf("❌ Erro ao ler views: %w", err)
+	}
+	return views, nil
+}
+
+var mysqlEnumRe = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+func describeEnums(db *gorm.DB, dialect string, schema *Schema) ([]EnumSchema, error) {
+	switch dialect {
+	case DialectPostgres:
+		var rows []struct {
+			Name  string
+			Value string
+		}
+		err := db.Raw(`
+SELECT t.typname AS name, e.enumlabel AS value
+FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid
+JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = current_schema()
+ORDER BY t.typname, e.enumsortorder`).Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("❌ Erro ao ler enums: %w", err)
+		}
+		var enums []EnumSchema
+		for _, r := range rows {
+			if n := len(enums); n == 0 || enums[n-1].Name != r.Name {
+				enums = append(enums, EnumSchema{Name: r.Name})
+			}
+			enums[len(enums)-1].Values = append(enums[len(enums)-1].Values, r.Value)
+		}
+		return enums, nil
+	case DialectMySQL:
+		var enums []EnumSchema
+		for _, t := range schema.Tables {
+			for _, c := range t.Columns {
+				if !strings.HasPrefix(strings.ToLower(c.Type), "enum(") {
+					continue
+				}
+				enum := EnumSchema{Name: t.Name + "." + c.Name}
+				for _, m := range mysqlEnumRe.FindAllStringSubmatch(c.Type, -1) {
+					enum.Values = append(enum.Values, strings.ReplaceAll(m[1], "''", "'"))
+				}
+				enums = append(enums, enum)
+			}
+		}
+		return enums, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDescribeSchemaSQLite(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+	for _, stmt := range []string{
+		`CREATE TABLE clients (id TEXT PRIMARY KEY, name VARCHAR(120) NOT NULL, status TEXT DEFAULT 'active')`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, client_id TEXT REFERENCES clients(id) ON DELETE CASCADE, total NUMERIC)`,
+		`CREATE UNIQUE INDEX idx_clients_name ON clients(name)`,
+		`CREATE VIEW client_totals AS SELECT client_id, SUM(total) AS total FROM orders GROUP BY client_id`,
+	} {
+		if _, err := d.Exec(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	schema, err := d.DescribeSchema(ctx)
+	if err != nil {
+		t.Fatalf("DescribeSchema: %v", err)
+	}
+	if schema.Dialect != DialectSQLite || len(schema.Tables) != 2 || len(schema.Views) != 1 {
+		t.Fatalf("unexpected schema: %+v", schema)
+	}
+
+	clients, ok := schema.Table("clients")
+	if !ok {
+		t.Fatalf("clients table missing")
+	}
+	name, _ := clients.Column("name")
+	status, _ := clients.Column("status")
+	if name == nil || name.Nullable || name.Type != "VARCHAR(120)" {
+		t.Fatalf("unexpected name column: %+v", name)
+	}
+	if status == nil || status.Default == nil || *status.Default != "'active'" {
+		t.Fatalf("unexpected status column: %+v", status)
+	}
+	if len(clients.PrimaryKey) != 1 || clients.PrimaryKey[0] != "id" {
+		t.Fatalf("unexpected primary key: %v", clients.PrimaryKey)
+	}
+	var unique bool
+	for _, idx := range clients.Indexes {
+		unique = unique || (idx.Name == "idx_clients_name" && idx.Unique)
+	}
+	if !unique {
+		t.Fatalf("unique index missing: %+v", clients.Indexes)
+	}
+
+	orders, _ := schema.Table("orders")
+	if len(orders.ForeignKeys) != 1 {
+		t.Fatalf("foreign key missing: %+v", orders)
+	}
+	fk := orders.ForeignKeys[0]
+	if fk.RefTable != "clients" || fk.Columns[0] != "client_id" || fk.RefColumns[0] != "id" || fk.OnDelete != "CASCADE" {
+		t.Fatalf("unexpected foreign key: %+v", fk)
+	}
+
+	only, err := d.DescribeSchema(ctx, "orders")
+	if err != nil || len(only.Tables) != 1 || only.Tables[0].Name != "orders" {
+		t.Fatalf("table filter: %+v, %v", only, err)
+	}
+	if _, err := d.DescribeSchema(ctx, "missing"); err == nil {
+		t.Fatalf("expected error for unknown table")
+	}
+}
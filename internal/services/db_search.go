@@ -0,0 +1,618 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Full text and fuzzy search of the Searchable models (Repository.Search):
+//
+//   - Postgres ranks in SQL by pg_trgm word similarity (typos and partial
+//     words) plus a 'simple' tsvector rank, served by the trigram and tsvector
+//     indexes of EnsureSearchIndex (and of the 005_search migration);
+//   - SQLite reads the candidates from the FTS5 trigram index of
+//     EnsureSearchIndex (<table>_search), which needs a driver built with FTS5
+//     (go build -tags sqlite_fts5); without it, and on the other dialects, the
+//     candidates come from LIKE on the trigrams of the query.
+//
+// Outside Postgres the candidates are ranked in Go with the same trigram
+// similarity, so the scores are comparable across dialects. The highlights
+// are always built in Go.
+
+// SearchField is a text field of a Searchable model and its weight in the rank.
+type SearchField struct {
+	// Name is the column, Go or json name of the field
+	Name string
+	// Weight multiplies the field score (1 when zero)
+	Weight float64
+}
+
+// Searchable is implemented by the models exposed to Repository.Search.
+type Searchable interface {
+	SearchFields() []SearchField
+}
+
+// SearchOptions configures Repository.Search.
+type SearchOptions struct {
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+	// MinScore drops the weaker matches (DefaultSearchMinScore when zero)
+	MinScore float64 `json:"minScore,omitempty"`
+	// Fields restricts the search to some of the SearchFields
+	Fields []string `json:"fields,omitempty"`
+	// Spec adds filters (and preloads) to the search
+	Spec *QuerySpec `json:"-"`
+	// PreTag and PostTag wrap the matches in the highlights (<mark></mark> by default)
+	PreTag  string `json:"preTag,omitempty"`
+	PostTag string `json:"postTag,omitempty"`
+}
+
+const (
+	// DefaultSearchMinScore is the pg_trgm default similarity threshold.
+	DefaultSearchMinScore = 0.3
+	// SearchCandidateLimit caps the rows ranked in Go (outside Postgres), read
+	// in the order of the patterns they match.
+	SearchCandidateLimit = 2000
+	// searchMaxPatterns caps the trigrams sent as LIKE/MATCH patterns.
+	searchMaxPatterns = 32
+	// highlightMinSimilarity keeps the short words sharing a prefix with a
+	// term ("para" for "parafuso") out of the highlights.
+	highlightMinSimilarity = 0.5
+)
+
+// SearchMethod tells how a search ran.
+type SearchMethod string
+
+const (
+	SearchPostgres SearchMethod = "postgres"
+	SearchFTS5     SearchMethod = "fts5"
+	SearchLike     SearchMethod = "like"
+)
+
+// SearchHit is a row found by Repository.Search.
+type SearchHit[T any] struct {
+	Item  *T      `json:"item"`
+	Score float64 `json:"score"`
+	// Highlights maps the matched fields (SearchField.Name) to their text,
+	// HTML escaped, with the matches tagged
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// SearchResult is a ranked page of Repository.Search. Approximate is set when
+// more than SearchCandidateLimit rows matched outside Postgres: only the
+// candidates read were ranked and counted in Total.
+type SearchResult[T any] struct {
+	Hits        []SearchHit[T] `json:"hits"`
+	Total       int            `json:"total"`
+	Approximate bool           `json:"approximate,omitempty"`
+	Method      SearchMethod   `json:"method"`
+}
+
+// searchColumn is a resolved SearchField.
+type searchColumn struct {
+	name   string
+	field  *schema.Field
+	weight float64
+}
+
+// searchColumns resolves the search fields of model, restricted to only.
+func searchColumns(s *schema.Schema, model any, only []string) ([]searchColumn, error) {
+	searchable, ok := model.(Searchable)
+	if !ok {
+		return nil, fmt.Errorf("❌ Modelo '%s' não implementa Searchable", s.Table)
+	}
+	var cols []searchColumn
+	for _, sf := range searchable.SearchFields() {
+		if len(only) > 0 && !slices.Contains(only, sf.Name) {
+			continue
+		}
+		field, err := specField(s, sf.Name)
+		if err != nil {
+			return nil, err
+		}
+		if field.DataType != schema.String {
+			return nil, fmt.Errorf("❌ Campo '%s' de '%s' não é texto", sf.Name, s.Table)
+		}
+		weight := sf.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		cols = append(cols, searchColumn{name: sf.Name, field: field, weight: weight})
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("❌ Nenhum campo de busca em '%s' (campos: %v)", s.Table, only)
+	}
+	return cols, nil
+}
+
+// Search returns the rows of T matching query, best first. T must implement
+// Searchable; see SearchOptions for the paging, filters and highlights.
+func (r *Repository[T]) Search(ctx context.Context, query string, opts SearchOptions) (*SearchResult[T], error) {
+	s, err := r.modelSchema()
+	if err != nil {
+		return nil, err
+	}
+	cols, err := searchColumns(s, new(T), opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("❌ Busca vazia")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageLimit
+	}
+	if opts.MinScore <= 0 {
+		opts.MinScore = DefaultSearchMinScore
+	}
+	if opts.PreTag == "" && opts.PostTag == "" {
+		opts.PreTag, opts.PostTag = "<mark>", "</mark>"
+	}
+	var filters *QuerySpec
+	if opts.Spec != nil {
+		filters = &QuerySpec{Filters: opts.Spec.Filters, Preload: opts.Spec.Preload}
+	}
+	base := func(db *gorm.DB) (*gorm.DB, error) { return filters.filtered(db.Model(new(T)), s) }
+
+	var result *SearchResult[T]
+	db := r.DB(ctx)
+	if NormalizeDialect(db.Dialector.Name()) == DialectPostgres {
+		result, err = r.searchPostgres(db, s, base, cols, query, opts)
+	} else {
+		result, err = r.searchRanked(ctx, db, s, base, cols, terms, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Hits {
+		result.Hits[i].Highlights = searchHighlights(ctx, reflect.ValueOf(result.Hits[i].Item).Elem(), cols, terms, opts)
+	}
+	return result, nil
+}
+
+// searchPostgres ranks and pages in SQL: the keys and scores first, the rows after.
+func (r *Repository[T]) searchPostgres(db *gorm.DB, s *schema.Schema, base func(*gorm.DB) (*gorm.DB, error), cols []searchColumn, query string, opts SearchOptions) (*SearchResult[T], error) {
+	pk := s.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("❌ Tabela '%s' sem chave primária", s.Table)
+	}
+	columns := make([]any, len(cols))
+	for i, c := range cols {
+		columns[i] = specColumn(c.field)
+	}
+	document := searchDocumentSQL(len(cols))
+	tsquery := "to_tsvector('simple', " + document + ") @@ plainto_tsquery('simple', ?)"
+
+	var match, scores []string
+	var matchVars, scoreVars []any
+	for i, c := range cols {
+		match = append(match, "? <% ?", "? ILIKE ?")
+		matchVars = append(matchVars, query, columns[i], columns[i], "%"+escapeLike(query)+"%")
+		scores = append(scores, "? * word_similarity(?, ?)")
+		scoreVars = append(scoreVars, c.weight, query, columns[i])
+	}
+	where := clause.Expr{
+		SQL:  "(" + strings.Join(match, " OR ") + " OR " + tsquery + ")",
+		Vars: append(append(matchVars, columns...), query),
+	}
+	score := clause.Expr{
+		SQL:  "COALESCE(GREATEST(" + strings.Join(scores, ", ") + "), 0) + ts_rank(to_tsvector('simple', " + document + "), plainto_tsquery('simple', ?))",
+		Vars: append(append(scoreVars, columns...), query),
+	}
+
+	result := &SearchResult[T]{Hits: make([]SearchHit[T], 0), Method: SearchPostgres}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// <% (the trigram index operator) matches from this threshold on
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", strconv.FormatFloat(opts.MinScore, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+		counted, err := base(tx)
+		if err != nil {
+			return err
+		}
+		var total int64
+		if err := counted.Where(where).Count(&total).Error; err != nil {
+			return err
+		}
+		result.Total = int(total)
+
+		ranked, err := base(tx)
+		if err != nil {
+			return err
+		}
+		var keys []map[string]any
+		err = ranked.Select("? AS search_key, ? AS search_score", specColumn(pk), score).Where(where).
+			Order("search_score DESC").Order(clause.OrderByColumn{Column: specColumn(pk)}).
+			Offset(opts.Offset).Limit(opts.Limit).Find(&keys).Error
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+
+		values := make([]any, len(keys))
+		for i, k := range keys {
+			values[i] = k["search_key"]
+		}
+		rows, err := base(tx)
+		if err != nil {
+			return err
+		}
+		var items []*T
+		if err := rows.Where(clause.IN{Column: specColumn(pk), Values: values}).Find(&items).Error; err != nil {
+			return err
+		}
+		byKey := make(map[string]*T, len(items))
+		for _, item := range items {
+			key, _ := pk.ValueOf(tx.Statement.Context, reflect.ValueOf(item).Elem())
+			byKey[fmt.Sprint(key)] = item
+		}
+		for _, k := range keys {
+			if item, ok := byKey[fmt.Sprint(k["search_key"])]; ok {
+				result.Hits = append(result.Hits, SearchHit[T]{Item: item, Score: searchFloat(k["search_score"])})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// searchDocumentSQL is the text indexed by the tsvector: the n search columns
+// (the ? of the Vars) joined by spaces. EnsureSearchIndex indexes the same
+// expression, so that the planner can use it.
+func searchDocumentSQL(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = "COALESCE(?, '')"
+	}
+	return strings.Join(parts, " || ' ' || ")
+}
+
+// searchRanked reads the candidates from the FTS5 index or LIKE, and ranks them in Go.
+func (r *Repository[T]) searchRanked(ctx context.Context, db *gorm.DB, s *schema.Schema, base func(*gorm.DB) (*gorm.DB, error), cols []searchColumn, terms []string, opts SearchOptions) (*SearchResult[T], error) {
+	patterns := searchPatterns(terms)
+	candidates, err := base(db)
+	if err != nil {
+		return nil, err
+	}
+
+	method := SearchLike
+	if NormalizeDialect(db.Dialector.Name()) == DialectSQLite && !slices.ContainsFunc(patterns, func(p string) bool { return utf8.RuneCountInString(p) < 3 }) {
+		var found int64
+		fts := s.Table + "_search"
+		if err := db.Session(&gorm.Session{NewDB: true}).Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", fts).Scan(&found).Error; err == nil && found > 0 {
+			quoted := make([]string, len(patterns))
+			for i, p := range patterns {
+				quoted[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+			}
+			candidates = candidates.Where("? IN (SELECT rowid FROM ? WHERE ? MATCH ?)",
+				clause.Column{Table: clause.CurrentTable, Name: "rowid"}, clause.Table{Name: fts}, clause.Table{Name: fts}, strings.Join(quoted, " OR "))
+			method = SearchFTS5
+		}
+	}
+	var exprs []clause.Expression
+	var ranks []string
+	var rankVars []any
+	for _, c := range cols {
+		for _, p := range patterns {
+			// the patterns are letters and digits only, nothing to escape
+			exprs = append(exprs, clause.Expr{SQL: "LOWER(?) LIKE ?", Vars: []any{specColumn(c.field), "%" + p + "%"}})
+			ranks = append(ranks, "CASE WHEN LOWER(?) LIKE ? THEN ? ELSE 0 END")
+			rankVars = append(rankVars, specColumn(c.field), "%"+p+"%", c.weight)
+		}
+	}
+	if method == SearchLike {
+		candidates = candidates.Where(clause.Or(exprs...))
+	}
+	// the weighted count of the patterns matched picks the candidates worth
+	// ranking when there are more than SearchCandidateLimit
+	order := clause.Expr{SQL: "(" + strings.Join(ranks, " + ") + ") DESC", Vars: rankVars, WithoutParentheses: true}
+	if pk := s.PrioritizedPrimaryField; pk != nil {
+		order.SQL, order.Vars = order.SQL+", ?", append(order.Vars, specColumn(pk))
+	}
+	candidates = candidates.Order(clause.OrderBy{Expression: order})
+
+	var items []*T
+	if err := candidates.Limit(SearchCandidateLimit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	approximate := len(items) > SearchCandidateLimit
+	if approximate {
+		items = items[:SearchCandidateLimit]
+	}
+	hits := make([]SearchHit[T], 0, len(items))
+	for _, item := range items {
+		row := reflect.ValueOf(item).Elem()
+		var score float64
+		for _, c := range cols {
+			score = max(score, c.weight*textScore(terms, searchText(ctx, c.field, row)))
+		}
+		if score >= opts.MinScore {
+			hits = append(hits, SearchHit[T]{Item: item, Score: score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b SearchHit[T]) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	result := &SearchResult[T]{Total: len(hits), Approximate: approximate, Method: method}
+	from := min(opts.Offset, len(hits))
+	result.Hits = hits[from:min(from+opts.Limit, len(hits))]
+	return result, nil
+}
+
+// EnsureSearchIndex creates the search indexes of model: the pg_trgm and
+// tsvector GIN indexes on Postgres, the FTS5 trigram table (kept in sync by
+// triggers) on SQLite. Other dialects search with LIKE and need nothing.
+func EnsureSearchIndex(ctx context.Context, db *gorm.DB, model Searchable) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	cols, err := searchColumns(stmt.Schema, model, nil)
+	if err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	quote := func(name string) string { return stmt.Quote(name) }
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = quote(c.field.DBName)
+	}
+
+	var statements []string
+	switch NormalizeDialect(db.Dialector.Name()) {
+	case DialectPostgres:
+		statements = append(statements, `CREATE EXTENSION IF NOT EXISTS pg_trgm`)
+		document := make([]string, len(cols))
+		for i, c := range cols {
+			statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s gin_trgm_ops)",
+				quote("idx_"+table+"_"+c.field.DBName+"_trgm"), quote(table), names[i]))
+			document[i] = "COALESCE(" + names[i] + ", '')"
+		}
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (to_tsvector('simple', %s))",
+			quote("idx_"+table+"_search_tsv"), quote(table), strings.Join(document, " || ' ' || ")))
+	case DialectSQLite:
+		fts := table + "_search"
+		list := strings.Join(names, ", ")
+		values := func(prefix string) string {
+			out := make([]string, len(names))
+			for i, n := range names {
+				out[i] = prefix + "." + n
+			}
+			return strings.Join(out, ", ")
+		}
+		statements = append(statements,
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content=%s, content_rowid='rowid', tokenize='trigram')", quote(fts), list, quote(table)),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER INSERT ON %s BEGIN INSERT INTO %s(rowid, %s) VALUES (new.rowid, %s); END",
+				quote(fts+"_ai"), quote(table), quote(fts), list, values("new")),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER DELETE ON %s BEGIN INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.rowid, %s); END",
+				quote(fts+"_ad"), quote(table), quote(fts), quote(fts), list, values("old")),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER UPDATE ON %s BEGIN INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.rowid, %s); INSERT INTO %s(rowid, %s) VALUES (new.rowid, %s); END",
+				quote(fts+"_au"), quote(table), quote(fts), quote(fts), list, values("old"), quote(fts), list, values("new")),
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", quote(fts), quote(fts)),
+		)
+	default:
+		return nil
+	}
+
+	for _, statement := range statements {
+		if err := db.WithContext(ctx).Exec(statement).Error; err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				return fmt.Errorf("%w (compile com -tags sqlite_fts5; a busca usa LIKE)", ErrSearchFTS5Unavailable)
+			}
+			return fmt.Errorf("❌ Erro ao criar o índice de busca de '%s': %w", table, err)
+		}
+	}
+	return nil
+}
+
+// ErrSearchFTS5Unavailable is returned by EnsureSearchIndex when the SQLite
+// driver was built without FTS5.
+var ErrSearchFTS5Unavailable = errors.New("❌ SQLite sem FTS5")
+
+// searchTerms splits a query into its lower case words.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), notWordRune) {
+		if !slices.Contains(terms, w) {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+func notWordRune(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
+// searchPatterns returns the trigrams of the terms (the terms themselves when
+// shorter), the substrings a fuzzy match shares with the query.
+func searchPatterns(terms []string) []string {
+	var patterns []string
+	for _, term := range terms {
+		runes := []rune(term)
+		if len(runes) < 3 {
+			patterns = append(patterns, term)
+			continue
+		}
+		for i := 0; i+3 <= len(runes); i++ {
+			if p := string(runes[i : i+3]); !slices.Contains(patterns, p) {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	return patterns[:min(len(patterns), searchMaxPatterns)]
+}
+
+// trigrams returns the pg_trgm trigrams of a word ("  w", " wo", "wor", "ord", "rd ").
+func trigrams(word string) map[string]struct{} {
+	runes := []rune("  " + word + " ")
+	set := make(map[string]struct{}, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// trigramSimilarity is the pg_trgm similarity of two words: the shared
+// trigrams over all the trigrams of both.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	if union := len(ta) + len(tb) - shared; union > 0 {
+		return float64(shared) / float64(union)
+	}
+	return 0
+}
+
+// termScore is how well term matches the best word of words: 1 when equal,
+// high when the word contains it (partial names, codes), the trigram
+// similarity otherwise (typos).
+func termScore(term string, words []string) float64 {
+	var best float64
+	for _, w := range words {
+		if w == term {
+			return 1
+		}
+		score := trigramSimilarity(term, w)
+		if strings.Contains(w, term) {
+			score = max(score, 0.5+0.5*float64(utf8.RuneCountInString(term))/float64(utf8.RuneCountInString(w)))
+		}
+		best = max(best, score)
+	}
+	return best
+}
+
+// textScore is the mean score of the terms in text.
+func textScore(terms []string, text string) float64 {
+	if text == "" || len(terms) == 0 {
+		return 0
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), notWordRune)
+	var total float64
+	for _, term := range terms {
+		total += termScore(term, words)
+	}
+	return total / float64(len(terms))
+}
+
+// searchText reads the text of a search field from a row.
+func searchText(ctx context.Context, field *schema.Field, row reflect.Value) string {
+	value, zero := field.ValueOf(ctx, row)
+	if zero || value == nil {
+		return ""
+	}
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.String {
+		return ""
+	}
+	return v.String()
+}
+
+// searchHighlights tags the matches of terms in the search fields of row.
+func searchHighlights(ctx context.Context, row reflect.Value, cols []searchColumn, terms []string, opts SearchOptions) map[string]string {
+	out := make(map[string]string)
+	for _, c := range cols {
+		if text, ok := highlight(searchText(ctx, c.field, row), terms, opts); ok {
+			out[c.name] = text
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// highlight wraps in the tags of opts the part of each word containing a term,
+// or the whole word when it is similar to one. The text is HTML escaped, the
+// tags are not.
+func highlight(text string, terms []string, opts SearchOptions) (string, bool) {
+	var b strings.Builder
+	found := false
+	last := 0
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		if notWordRune(r) {
+			start += size
+			continue
+		}
+		end := start
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if notWordRune(r) {
+				break
+			}
+			end += size
+		}
+		word := text[start:end]
+		lower := strings.ToLower(word)
+		from, to := -1, -1
+		for _, term := range terms {
+			if i := strings.Index(lower, term); i >= 0 && len(lower) == len(word) {
+				from, to = start+i, start+i+len(term)
+				break
+			}
+			if trigramSimilarity(term, lower) >= max(opts.MinScore, highlightMinSimilarity) {
+				from, to = start, end
+				break
+			}
+		}
+		if from >= 0 {
+			b.WriteString(html.EscapeString(text[last:from]))
+			b.WriteString(opts.PreTag)
+			b.WriteString(html.EscapeString(text[from:to]))
+			b.WriteString(opts.PostTag)
+			last, found = to, true
+		}
+		start = end
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String(), found
+}
+
+// escapeLike escapes the LIKE wildcards of s with backslash, the default
+// escape of Postgres.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func searchFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	case []byte:
+		f, _ := strconv.ParseFloat(string(n), 64)
+		return f
+	}
+	return 0
+}
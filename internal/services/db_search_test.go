@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type searchProduct struct {
+	ID          uint
+	Name        string  `json:"name"`
+	SKU         string  `json:"sku"`
+	EAN         *string `json:"ean"`
+	Description string  `json:"description"`
+	Active      bool    `json:"active"`
+}
+
+func (searchProduct) SearchFields() []SearchField {
+	return []SearchField{{Name: "name"}, {Name: "sku"}, {Name: "ean"}, {Name: "description", Weight: 0.5}}
+}
+
+func newSearchRepository(t *testing.T) *Repository[searchProduct] {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "search.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&searchProduct{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	// without -tags sqlite_fts5 the search falls back to LIKE
+	if err := EnsureSearchIndex(context.Background(), db, searchProduct{}); err != nil && !errors.Is(err, ErrSearchFTS5Unavailable) {
+		t.Fatalf("EnsureSearchIndex: %v", err)
+	}
+	ean := "7891234567890"
+	for _, p := range []searchProduct{
+		{Name: "Parafuso sextavado inox", SKU: "PAR-001", EAN: &ean, Description: "Rosca inteira", Active: true},
+		{Name: "Porca sextavada", SKU: "POR-002", Description: "Para parafuso M8", Active: true},
+		{Name: "Arruela lisa", SKU: "ARR-003", Description: "Zincada", Active: false},
+		{Name: "Parafuso francês", SKU: "PAR-004", Description: "Cabeça abaulada", Active: false},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	return NewRepository[searchProduct](db)
+}
+
+func TestRepositorySearch(t *testing.T) {
+	ctx := context.Background()
+	repo := newSearchRepository(t)
+
+	// typo, highlighted
+	res, err := repo.Search(ctx, "parafuzo", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if res.Total != 2 || res.Hits[0].Item.Name != "Parafuso sextavado inox" {
+		t.Fatalf("unexpected hits (%s): %+v", res.Method, res.Hits)
+	}
+	if got := res.Hits[0].Highlights["name"]; got != "<mark>Parafuso</mark> sextavado inox" {
+		t.Fatalf("highlight = %q", got)
+	}
+
+	// the description weighs less than the name
+	res, err = repo.Search(ctx, "parafuso", SearchOptions{})
+	if err != nil || res.Total != 3 || res.Hits[2].Item.Name != "Porca sextavada" || res.Hits[2].Score != 0.5 {
+		t.Fatalf("weighted search = %+v, %v", res, err)
+	}
+	if got := res.Hits[2].Highlights["description"]; got != "Para <mark>parafuso</mark> M8" {
+		t.Fatalf("highlight = %q", got)
+	}
+
+	// partial words and codes
+	res, err = repo.Search(ctx, "sexta", SearchOptions{PreTag: "[", PostTag: "]"})
+	if err != nil || res.Total != 2 || res.Hits[0].Highlights["name"] != "Parafuso [sexta]vado inox" {
+		t.Fatalf("partial search = %+v, %v", res, err)
+	}
+	res, err = repo.Search(ctx, "78912", SearchOptions{})
+	if err != nil || res.Total != 1 || res.Hits[0].Highlights["ean"] != "<mark>78912</mark>34567890" {
+		t.Fatalf("EAN search = %+v, %v", res, err)
+	}
+
+	// filters, fields and paging
+	res, err = repo.Search(ctx, "parafuso", SearchOptions{Spec: NewQuerySpec().Eq("active", false)})
+	if err != nil || res.Total != 1 || res.Hits[0].Item.SKU != "PAR-004" {
+		t.Fatalf("filtered search = %+v, %v", res, err)
+	}
+	res, err = repo.Search(ctx, "parafuso", SearchOptions{Fields: []string{"name"}, Limit: 1, Offset: 1})
+	if err != nil || res.Total != 2 || len(res.Hits) != 1 || res.Hits[0].Item.SKU != "PAR-004" {
+		t.Fatalf("paged search = %+v, %v", res, err)
+	}
+
+	if _, err := repo.Search(ctx, " - ", SearchOptions{}); err == nil {
+		t.Fatalf("expected an empty search error")
+	}
+	if _, err := NewRepository[repoTask](repo.db).Search(ctx, "x", SearchOptions{}); err == nil {
+		t.Fatalf("expected a not Searchable error")
+	}
+}
+
+func TestRepositorySearchCandidates(t *testing.T) {
+	ctx := context.Background()
+	repo := newSearchRepository(t)
+
+	// the highlighted text is escaped, the tags are not
+	script := searchProduct{Name: `Parafuso <script>alert("x")</script>`, SKU: "PAR-005"}
+	if err := repo.db.Create(&script).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res, err := repo.Search(ctx, "parafuso", SearchOptions{Spec: NewQuerySpec().Eq("sku", "PAR-005")})
+	if err != nil || len(res.Hits) != 1 {
+		t.Fatalf("Search = %+v, %v", res, err)
+	}
+	if got := res.Hits[0].Highlights["name"]; got != "<mark>Parafuso</mark> &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;" {
+		t.Fatalf("highlight = %q", got)
+	}
+
+	// past the candidate limit the rows matching more patterns are read first
+	weak := make([]searchProduct, SearchCandidateLimit)
+	for i := range weak {
+		weak[i] = searchProduct{Name: "Parede", SKU: "PAD"}
+	}
+	if err := repo.db.CreateInBatches(weak, 500).Error; err != nil {
+		t.Fatalf("CreateInBatches: %v", err)
+	}
+	late := searchProduct{Name: "Parafuso allen", SKU: "PAR-006"}
+	if err := repo.db.Create(&late).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res, err = repo.Search(ctx, "parafuso", SearchOptions{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !res.Approximate || res.Total != 4 || res.Hits[3].Item.SKU != "PAR-006" {
+		t.Fatalf("capped search = %+v", res)
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	if got := trigramSimilarity("parafuzo", "parafuso"); got != 0.5 {
+		t.Fatalf("similarity = %v", got)
+	}
+	if got := trigramSimilarity("abc", "xyz"); got != 0 {
+		t.Fatalf("similarity = %v", got)
+	}
+}
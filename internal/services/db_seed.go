@@ -0,0 +1,688 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// A seed set is a directory of fixtures (YAML, JSON or CSV), plus the
+// fixtures of the environment subdirectory (seeds/dev, seeds/test...), whose
+// rows override the rows of the same table and key:
+//
+//	# seeds/users.yaml
+//	table: users        # defaults to the file name
+//	key: [email]        # upsert key, defaults to the primary key
+//	rows:
+//	  admin:            # symbolic key
+//	    name: Admin
+//	    email: admin@example.com
+//	    password: admin # models implementing SeedPreparer (users) hash it
+//	# seeds/orders.yaml
+//	rows:
+//	  first:
+//	    client_id: "@clients.acme"      # primary key of clients.acme
+//	    notes: "@clients.acme.email"    # any field of clients.acme
+//
+// A CSV fixture has a header of fields and an optional _key column. A value
+// starting with "@@" is a literal "@". Rows without a primary key get a UUID
+// derived from table and key, so seeding again updates the same rows.
+
+// SeedPreparer is implemented by models that adjust a fixture (or imported,
+// see ImportData) row before it is written, e.g. hashing a password. fields
+// is the row read and existing the stored row (the same model type), nil
+// when the row is new.
+type SeedPreparer interface {
+	PrepareSeed(fields map[string]any, existing any) error
+}
+
+// SeedFixture is the content of a fixture file.
+type SeedFixture struct {
+	Table string                    `json:"table,omitempty" yaml:"table,omitempty"`
+	Key   []string                  `json:"key,omitempty" yaml:"key,omitempty"`
+	Rows  map[string]map[string]any `json:"rows" yaml:"rows"`
+
+	// some rows were read from CSV, with string values
+	csv bool
+}
+
+// SeedOptions configures Seed.
+type SeedOptions struct {
+	// Dir defaults to DefaultSeedDir
+	Dir string
+	// Env selects the environment subdirectory of Dir
+	Env string
+	// Truncate deletes every row of the seeded tables first
+	Truncate bool
+	// Models defaults to the models registered with RegisterSchemaModels
+	Models []any
+}
+
+// SeedTableResult counts the rows written to a table.
+type SeedTableResult struct {
+	Table     string `json:"table" yaml:"table"`
+	Inserted  int    `json:"inserted" yaml:"inserted"`
+	Updated   int    `json:"updated" yaml:"updated"`
+	Unchanged int    `json:"unchanged" yaml:"unchanged"`
+	Deleted   int64  `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+}
+
+// DefaultSeedDir is the seed directory used when none is given.
+const DefaultSeedDir = "seeds"
+
+// seedNamespace derives the UUIDs of the rows without a primary key.
+var seedNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("gdbase:seed"))
+
+// Seed loads the seed set of opts and upserts it into the selected database,
+// in a single transaction.
+func (d *DBServiceImpl) Seed(ctx context.Context, opts SeedOptions) ([]SeedTableResult, error) {
+	db, err := rawDB(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := LoadSeedSet(opts.Dir, opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	return SeedDB(ctx, db, fixtures, opts)
+}
+
+// LoadSeedSet reads the fixtures of dir and of its env subdirectory, merged
+// by table and key.
+func LoadSeedSet(dir, env string) ([]*SeedFixture, error) {
+	if dir == "" {
+		dir = DefaultSeedDir
+	}
+	dirs := []string{dir}
+	if env != "" {
+		dirs = append(dirs, filepath.Join(dir, env))
+	}
+	var fixtures []*SeedFixture
+	byTable := map[string]*SeedFixture{}
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Erro ao ler diretório de seeds '%s': %v", d, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(d, entry.Name())
+			fx, err := readSeedFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if fx == nil {
+				continue
+			}
+			base, ok := byTable[fx.Table]
+			if !ok {
+				byTable[fx.Table] = fx
+				fixtures = append(fixtures, fx)
+				continue
+			}
+			if len(fx.Key) > 0 {
+				base.Key = fx.Key
+			}
+			base.csv = base.csv || fx.csv
+			for key, row := range fx.Rows {
+				if prev, ok := base.Rows[key]; ok {
+					for field, v := range row {
+						prev[field] = v
+					}
+				} else {
+					base.Rows[key] = row
+				}
+			}
+		}
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("❌ Nenhuma fixture encontrada em '%s'", strings.Join(dirs, "', '"))
+	}
+	return fixtures, nil
+}
+
+func readSeedFile(path string) (*SeedFixture, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	table := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Erro ao ler fixture '%s': %v", path, err)
+	}
+
+	var fx *SeedFixture
+	switch ext {
+	case "yaml", "yml", "json":
+		fx = &SeedFixture{}
+		if _, err := ti.NewMapperPtr(fx, path).Deserialize(data, ext); err != nil {
+			return nil, fmt.Errorf("❌ Fixture inválida '%s': %v", path, err)
+		}
+	case "csv":
+		if fx, err = readSeedCSV(data); err != nil {
+			return nil, fmt.Errorf("❌ Fixture inválida '%s': %v", path, err)
+		}
+	default:
+		return nil, nil
+	}
+	if fx.Table == "" {
+		fx.Table = table
+	}
+	if fx.Rows == nil {
+		fx.Rows = map[string]map[string]any{}
+	}
+	return fx, nil
+}
+
+func readSeedCSV(data []byte) (*SeedFixture, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	fx := &SeedFixture{Rows: map[string]map[string]any{}, csv: true}
+	if len(records) == 0 {
+		return fx, nil
+	}
+	header := records[0]
+	for i, record := range records[1:] {
+		key := fmt.Sprintf("%d", i+1)
+		row := map[string]any{}
+		for c, value := range record {
+			switch {
+			case c >= len(header):
+			case header[c] == "_key":
+				key = value
+			case value != "":
+				// empty cells are left unset
+				row[strings.TrimSpace(header[c])] = value
+			}
+		}
+		fx.Rows[key] = row
+	}
+	return fx, nil
+}
+
+// seedRow is one row of a seed set, with what is known after it is written.
+type seedRow struct {
+	table *seedTable
+	key   string
+	refs  []seedRef
+	model any
+}
+
+type seedTable struct {
+	fx     *SeedFixture
+	sch    *schema.Schema
+	fields map[string]*schema.Field
+	result SeedTableResult
+}
+
+type seedRef struct{ table, key, field string }
+
+func (r seedRef) String() string {
+	if r.field != "" {
+		return fmt.Sprintf("@%s.%s.%s", r.table, r.key, r.field)
+	}
+	return fmt.Sprintf("@%s.%s", r.table, r.key)
+}
+
+// SeedDB upserts fixtures into db in dependency order, in a single
+// transaction.
+func SeedDB(ctx context.Context, db *gorm.DB, fixtures []*SeedFixture, opts SeedOptions) ([]SeedTableResult, error) {
+	models := opts.Models
+	if len(models) == 0 {
+		models = SchemaModels()
+	}
+	// only the models of the seeded tables are parsed
+	wanted := map[string]bool{}
+	for _, fx := range fixtures {
+		wanted[fx.Table] = true
+	}
+	schemas := map[string]*schema.Schema{}
+	for _, model := range models {
+		if !wanted[seedModelTable(db, model)] {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("❌ Modelo inválido %s: %v", modelName(model), err)
+		}
+		schemas[stmt.Schema.Table] = stmt.Schema
+	}
+
+	tables := map[string]*seedTable{}
+	var order []*seedTable
+	for _, fx := range fixtures {
+		sch, ok := schemas[fx.Table]
+		if !ok {
+			return nil, fmt.Errorf("❌ Nenhum modelo registrado para a tabela '%s'", fx.Table)
+		}
+		t := &seedTable{fx: fx, sch: sch, fields: seedFields(sch), result: SeedTableResult{Table: fx.Table}}
+		tables[fx.Table] = t
+		order = append(order, t)
+	}
+
+	rows, err := sortSeedRows(order, tables)
+	if err != nil {
+		return nil, err
+	}
+	byRef := map[string]*seedRow{}
+	for _, row := range rows {
+		byRef[row.table.fx.Table+"."+row.key] = row
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if opts.Truncate {
+			for _, t := range slices.Backward(seedTableOrder(order, rows)) {
+				res := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(reflect.New(t.sch.ModelType).Interface())
+				if res.Error != nil {
+					return fmt.Errorf("❌ Erro ao limpar a tabela '%s': %v", t.fx.Table, res.Error)
+				}
+				t.result.Deleted = res.RowsAffected
+			}
+		}
+		for _, row := range rows {
+			if err := writeSeedRow(ctx, tx, row, byRef); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SeedTableResult, 0, len(order))
+	for _, t := range order {
+		results = append(results, t.result)
+		gl.Log("info", fmt.Sprintf("🌱 %s: %d inseridos, %d atualizados, %d inalterados", t.fx.Table, t.result.Inserted, t.result.Updated, t.result.Unchanged))
+	}
+	return results, nil
+}
+
+// sortSeedRows orders the rows so that every row comes after the rows it
+// references.
+func sortSeedRows(order []*seedTable, tables map[string]*seedTable) ([]*seedRow, error) {
+	var all []*seedRow
+	index := map[string]int{}
+	for _, t := range order {
+		for _, key := range slices.Sorted(maps.Keys(t.fx.Rows)) {
+			row := &seedRow{table: t, key: key}
+			for field, v := range t.fx.Rows[key] {
+				if _, ok := t.fields[field]; !ok {
+					return nil, fmt.Errorf("❌ Campo '%s' desconhecido em %s.%s", field, t.fx.Table, key)
+				}
+				refs, err := collectSeedRefs(v)
+				if err != nil {
+					return nil, fmt.Errorf("❌ %s.%s: %v", t.fx.Table, key, err)
+				}
+				row.refs = append(row.refs, refs...)
+			}
+			index[t.fx.Table+"."+key] = len(all)
+			all = append(all, row)
+		}
+	}
+
+	deps := make([][]int, len(all))
+	pending := make([]int, len(all))
+	for i, row := range all {
+		for _, ref := range row.refs {
+			j, ok := index[ref.table+"."+ref.key]
+			if !ok {
+				return nil, fmt.Errorf("❌ %s.%s: referência desconhecida '%s'", row.table.fx.Table, row.key, ref)
+			}
+			if ref.field != "" {
+				if _, ok := tables[ref.table].fields[ref.field]; !ok {
+					return nil, fmt.Errorf("❌ %s.%s: campo desconhecido em '%s'", row.table.fx.Table, row.key, ref)
+				}
+			}
+			if j == i {
+				return nil, fmt.Errorf("❌ %s.%s referencia a si mesma", row.table.fx.Table, row.key)
+			}
+			deps[j] = append(deps[j], i)
+			pending[i]++
+		}
+	}
+	sorted := make([]*seedRow, 0, len(all))
+	for len(sorted) < len(all) {
+		next := -1
+		for i, n := range pending {
+			if n == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, n := range pending {
+				if n > 0 {
+					cycle = append(cycle, all[i].table.fx.Table+"."+all[i].key)
+				}
+			}
+			return nil, fmt.Errorf("❌ Referências circulares entre %s", strings.Join(cycle, ", "))
+		}
+		pending[next] = -1
+		for _, i := range deps[next] {
+			pending[i]--
+		}
+		sorted = append(sorted, all[next])
+	}
+	return sorted, nil
+}
+
+// seedTableOrder orders the tables so that every table comes after the
+// tables it references (in load order when they reference each other).
+func seedTableOrder(order []*seedTable, rows []*seedRow) []*seedTable {
+	deps := map[*seedTable]map[string]bool{}
+	for _, row := range rows {
+		for _, ref := range row.refs {
+			if ref.table != row.table.fx.Table {
+				if deps[row.table] == nil {
+					deps[row.table] = map[string]bool{}
+				}
+				deps[row.table][ref.table] = true
+			}
+		}
+	}
+	var out []*seedTable
+	placed := map[string]bool{}
+	for len(out) < len(order) {
+		progress := false
+		for _, t := range order {
+			if placed[t.fx.Table] {
+				continue
+			}
+			ready := true
+			for dep := range deps[t] {
+				ready = ready && placed[dep]
+			}
+			if ready {
+				out, placed[t.fx.Table], progress = append(out, t), true, true
+			}
+		}
+		if !progress {
+			for _, t := range order {
+				if !placed[t.fx.Table] {
+					out, placed[t.fx.Table] = append(out, t), true
+				}
+			}
+		}
+	}
+	return out
+}
+
+func collectSeedRefs(v any) ([]seedRef, error) {
+	switch v := v.(type) {
+	case string:
+		ref, ok, err := parseSeedRef(v)
+		if !ok || err != nil {
+			return nil, err
+		}
+		return []seedRef{ref}, nil
+	case map[string]any:
+		var refs []seedRef
+		for _, item := range v {
+			r, err := collectSeedRefs(item)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, r...)
+		}
+		return refs, nil
+	case []any:
+		var refs []seedRef
+		for _, item := range v {
+			r, err := collectSeedRefs(item)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, r...)
+		}
+		return refs, nil
+	}
+	return nil, nil
+}
+
+func parseSeedRef(s string) (seedRef, bool, error) {
+	if !strings.HasPrefix(s, "@") || strings.HasPrefix(s, "@@") {
+		return seedRef{}, false, nil
+	}
+	parts := strings.SplitN(s[1:], ".", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return seedRef{}, false, fmt.Errorf("referência inválida '%s' (use @tabela.chave ou @tabela.chave.campo)", s)
+	}
+	ref := seedRef{table: parts[0], key: parts[1]}
+	if len(parts) == 3 {
+		ref.field = parts[2]
+	}
+	return ref, true, nil
+}
+
+// resolveSeedValue replaces the references in v with their values.
+func resolveSeedValue(ctx context.Context, v any, byRef map[string]*seedRow) any {
+	switch v := v.(type) {
+	case string:
+		if strings.HasPrefix(v, "@@") {
+			return v[1:]
+		}
+		ref, ok, _ := parseSeedRef(v)
+		if !ok {
+			return v
+		}
+		target := byRef[ref.table+"."+ref.key]
+		rv := reflect.ValueOf(target.model)
+		field := target.table.sch.PrioritizedPrimaryField
+		if ref.field != "" {
+			field = target.table.fields[ref.field]
+		}
+		if field == nil {
+			return nil
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		return value
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = resolveSeedValue(ctx, item, byRef)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = resolveSeedValue(ctx, item, byRef)
+		}
+		return out
+	}
+	return v
+}
+
+func writeSeedRow(ctx context.Context, tx *gorm.DB, row *seedRow, byRef map[string]*seedRow) error {
+	t := row.table
+	name := t.fx.Table + "." + row.key
+	model := reflect.New(t.sch.ModelType)
+	fields := make(map[string]any, len(t.fx.Rows[row.key]))
+	for fieldName, raw := range t.fx.Rows[row.key] {
+		v := resolveSeedValue(ctx, raw, byRef)
+		fields[fieldName] = v
+		if err := setSeedField(ctx, t.fields[fieldName], model, v, t.fx.csv); err != nil {
+			return fmt.Errorf("❌ %s: campo '%s': %v", name, fieldName, err)
+		}
+	}
+
+	// rows without a primary key get a stable one
+	pk := t.sch.PrioritizedPrimaryField
+	if pk != nil {
+		if _, zero := pk.ValueOf(ctx, model); zero {
+			id := uuid.NewSHA1(seedNamespace, []byte(name))
+			switch {
+			case pk.FieldType == reflect.TypeOf(uuid.UUID{}):
+				_ = pk.Set(ctx, model, id)
+			case pk.FieldType.Kind() == reflect.String:
+				_ = pk.Set(ctx, model, id.String())
+			}
+		}
+	}
+
+	// the stored row, by the upsert key
+	where, err := seedKey(ctx, t, model)
+	if err != nil {
+		return err
+	}
+	var existing any
+	if len(where) > 0 {
+		found := reflect.New(t.sch.ModelType).Interface()
+		res := tx.Where(where).Limit(1).Find(found)
+		if res.Error != nil {
+			return fmt.Errorf("❌ %s: %v", name, res.Error)
+		}
+		if res.RowsAffected > 0 {
+			existing = found
+		}
+	}
+
+	if p, ok := model.Interface().(SeedPreparer); ok {
+		if err := p.PrepareSeed(fields, existing); err != nil {
+			return fmt.Errorf("❌ %s: %v", name, err)
+		}
+	}
+
+	if existing == nil {
+		if err := tx.Create(model.Interface()).Error; err != nil {
+			return fmt.Errorf("❌ Erro ao inserir %s: %v", name, err)
+		}
+		t.result.Inserted++
+		row.model = model.Interface()
+		return nil
+	}
+
+	current := reflect.ValueOf(existing)
+	updates := map[string]any{}
+	for fieldName := range fields {
+		field := t.fields[fieldName]
+		if field.PrimaryKey {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, model)
+		if old, _ := field.ValueOf(ctx, current); !reflect.DeepEqual(old, value) {
+			updates[field.DBName] = value
+		}
+	}
+	if len(updates) == 0 {
+		t.result.Unchanged++
+	} else {
+		if err := tx.Model(existing).Updates(updates).Error; err != nil {
+			return fmt.Errorf("❌ Erro ao atualizar %s: %v", name, err)
+		}
+		t.result.Updated++
+	}
+	row.model = existing
+	return nil
+}
+
+// seedKey returns the upsert key of a row: the key of the fixture, or else
+// the primary key or the first unique column or index the row sets.
+func seedKey(ctx context.Context, t *seedTable, model reflect.Value) (map[string]any, error) {
+	var candidates [][]*schema.Field
+	if len(t.fx.Key) > 0 {
+		var fields []*schema.Field
+		for _, k := range t.fx.Key {
+			field, ok := t.fields[k]
+			if !ok {
+				return nil, fmt.Errorf("❌ Chave '%s' desconhecida na tabela '%s'", k, t.fx.Table)
+			}
+			fields = append(fields, field)
+		}
+		candidates = append(candidates, fields)
+	} else {
+		candidates = append(candidates, t.sch.PrimaryFields)
+		for _, field := range t.sch.Fields {
+			if field.Unique && !field.PrimaryKey {
+				candidates = append(candidates, []*schema.Field{field})
+			}
+		}
+		indexes := t.sch.ParseIndexes()
+		slices.SortFunc(indexes, func(a, b *schema.Index) int { return strings.Compare(a.Name, b.Name) })
+		for _, idx := range indexes {
+			if idx.Class == "UNIQUE" && idx.Where == "" {
+				var fields []*schema.Field
+				for _, opt := range idx.Fields {
+					fields = append(fields, opt.Field)
+				}
+				candidates = append(candidates, fields)
+			}
+		}
+	}
+candidates:
+	for _, fields := range candidates {
+		if len(fields) == 0 {
+			continue
+		}
+		where := map[string]any{}
+		for _, field := range fields {
+			value, zero := field.ValueOf(ctx, model)
+			if zero {
+				continue candidates
+			}
+			where[field.DBName] = value
+		}
+		return where, nil
+	}
+	return nil, nil
+}
+
+// setSeedField converts a fixture value to the type of field and sets it.
+// Strings from CSV are read as JSON first (numbers, booleans, objects).
+func setSeedField(ctx context.Context, field *schema.Field, model reflect.Value, v any, fromCSV bool) error {
+	if v == nil {
+		return field.Set(ctx, model, reflect.Zero(field.FieldType).Interface())
+	}
+	ptr := reflect.New(field.FieldType)
+	s, isString := v.(string)
+	if isString && fromCSV && json.Unmarshal([]byte(s), ptr.Interface()) == nil {
+		return field.Set(ctx, model, ptr.Elem().Interface())
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return err
+	}
+	return field.Set(ctx, model, ptr.Elem().Interface())
+}
+
+func seedModelTable(db *gorm.DB, model any) string {
+	if t, ok := model.(schema.Tabler); ok {
+		return t.TableName()
+	}
+	return db.NamingStrategy.TableName(reflect.Indirect(reflect.ValueOf(model)).Type().Name())
+}
+
+// seedFields indexes the fields of sch by column, Go and json name.
+func seedFields(sch *schema.Schema) map[string]*schema.Field {
+	out := map[string]*schema.Field{}
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			out[name] = field
+		}
+		out[field.Name] = field
+		out[field.DBName] = field
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type seedAccount struct {
+	ID       string `gorm:"primaryKey" json:"id"`
+	Email    string `gorm:"uniqueIndex" json:"email"`
+	Password string `json:"password"`
+	Active   bool   `json:"active"`
+}
+
+func (seedAccount) TableName() string { return "accounts" }
+
+// PrepareSeed stands in for a password hash.
+func (a *seedAccount) PrepareSeed(fields map[string]any, existing any) error {
+	if prev, ok := existing.(*seedAccount); ok && prev.Password == "hashed:"+a.Password {
+		a.Password = prev.Password
+		return nil
+	}
+	a.Password = "hashed:" + a.Password
+	return nil
+}
+
+type seedItem struct {
+	ID    uint    `gorm:"primaryKey" json:"id"`
+	SKU   string  `gorm:"uniqueIndex" json:"sku"`
+	Price float64 `json:"price"`
+}
+
+func (seedItem) TableName() string { return "items" }
+
+type seedPurchase struct {
+	ID        string `gorm:"primaryKey" json:"id"`
+	AccountID string `json:"accountId"`
+	ItemID    uint   `json:"itemId"`
+	Contact   string `json:"contact"`
+	Note      string `json:"note"`
+}
+
+func (seedPurchase) TableName() string { return "purchases" }
+
+func writeSeedFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+}
+
+func TestSeedDB(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	models := []any{&seedAccount{}, &seedItem{}, &seedPurchase{}}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeSeedFiles(t, dir, map[string]string{
+		"purchases.yaml": `
+rows:
+  first:
+    accountId: "@accounts.admin"
+    itemId: "@items.bolt"
+    contact: "@accounts.admin.email"
+    note: "@@literal"
+`,
+		"accounts.json": `{"key": ["email"], "rows": {"admin": {"email": "admin@example.com", "password": "secret", "active": true}}}`,
+		"items.csv":     "_key,sku,price\nbolt,B-1,0.25\nnut,N-1,\n",
+		"dev/items.csv": "_key,price\nbolt,0.30\n",
+	})
+
+	fixtures, err := LoadSeedSet(dir, "dev")
+	if err != nil {
+		t.Fatalf("LoadSeedSet: %v", err)
+	}
+	results, err := SeedDB(ctx, db, fixtures, SeedOptions{Models: models})
+	if err != nil {
+		t.Fatalf("SeedDB: %v", err)
+	}
+	for _, r := range results {
+		if r.Inserted == 0 || r.Updated+r.Unchanged != 0 {
+			t.Fatalf("first run: %+v", results)
+		}
+	}
+
+	var p seedPurchase
+	var a seedAccount
+	var bolt seedItem
+	db.First(&p)
+	db.First(&a)
+	db.Where("sku = ?", "B-1").First(&bolt)
+	if a.Password != "hashed:secret" || !a.Active || bolt.Price != 0.30 {
+		t.Fatalf("unexpected rows: %+v %+v", a, bolt)
+	}
+	if p.AccountID != a.ID || p.ItemID != bolt.ID || p.Contact != "admin@example.com" || p.Note != "@literal" {
+		t.Fatalf("references not resolved: %+v", p)
+	}
+
+	// seeding again changes nothing
+	fixtures, _ = LoadSeedSet(dir, "dev")
+	results, err = SeedDB(ctx, db, fixtures, SeedOptions{Models: models})
+	if err != nil {
+		t.Fatalf("second SeedDB: %v", err)
+	}
+	for _, r := range results {
+		if r.Inserted+r.Updated != 0 {
+			t.Fatalf("second run: %+v", results)
+		}
+	}
+	var n int64
+	db.Model(&seedItem{}).Count(&n)
+	if n != 2 {
+		t.Fatalf("%d items after the second run", n)
+	}
+
+	// truncate removes the rows out of the fixtures
+	db.Create(&seedItem{SKU: "extra"})
+	fixtures, _ = LoadSeedSet(dir, "")
+	results, err = SeedDB(ctx, db, fixtures, SeedOptions{Models: models, Truncate: true})
+	if err != nil {
+		t.Fatalf("truncate SeedDB: %v", err)
+	}
+	db.Model(&seedItem{}).Count(&n)
+	if n != 2 || results[1].Table != "items" || results[1].Deleted != 3 || results[1].Inserted != 2 {
+		t.Fatalf("truncate: %d items, %+v", n, results)
+	}
+}
+
+func TestSeedDBErrors(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	models := []any{&seedAccount{}, &seedPurchase{}}
+	for fixture, msg := range map[string]string{
+		"rows: {a: {accountId: '@accounts.missing'}}":                  "referência desconhecida",
+		"rows: {a: {unknown: 1}}":                                      "desconhecido",
+		"rows: {a: {note: '@purchases.b'}, b: {note: '@purchases.a'}}": "circulares",
+		"rows: {a: {note: '@purchases'}}":                              "referência inválida",
+		"table: orders\nrows: {a: {note: x}}":                          "Nenhum modelo",
+		"rows: {a: {accountId: '@accounts.admin.nope'}}":               "campo desconhecido",
+	} {
+		dir := t.TempDir()
+		writeSeedFiles(t, dir, map[string]string{
+			"purchases.yaml": fixture,
+			"accounts.yaml":  "rows: {admin: {email: a@b.c}}",
+		})
+		fixtures, err := LoadSeedSet(dir, "")
+		if err == nil {
+			_, err = SeedDB(ctx, db, fixtures, SeedOptions{Models: models})
+		}
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q: expected %q, got %v", fixture, msg, err)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	crp "github.com/kubex-ecosystem/gdbase/internal/security/crypto"
 	krs "github.com/kubex-ecosystem/gdbase/internal/security/external"
 
+	evs "github.com/kubex-ecosystem/gdbase/internal/events"
 	ci "github.com/kubex-ecosystem/gdbase/internal/interfaces"
 	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
 	ti "github.com/kubex-ecosystem/gdbase/internal/types"
@@ -23,6 +24,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 type DirectDatabase interface {
@@ -48,6 +50,10 @@ type DBService interface {
 	GetHost(ctx context.Context) (string, error)
 	GetConfig(ctx context.Context) IDBConfig
 	RunMigrations(ctx context.Context, files map[string]string) (int, int, error)
+	RollbackMigrations(ctx context.Context, files map[string]string, steps int) (int, error)
+	MigrationStatus(ctx context.Context, files map[string]string) ([]MigrationStatus, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+	DescribeSchema(ctx context.Context, tables ...string) (*Schema, error)
 }
 type DBServiceImpl struct {
 	Logger    l.Logger
@@ -57,6 +63,18 @@ type DBServiceImpl struct {
 	db   map[string]*gorm.DB
 	pool *sync.Pool
 
+	// replicas tracks the read replicas registered per database
+	replicas map[string]*replicaSet
+	connMu   sync.Mutex
+
+	// eventBus receives the connection changes made by ReloadConfig
+	eventBus *evs.EventBus
+	// stopWatch stops the config file watcher started by Initialize
+	stopWatch context.CancelFunc
+
+	// capture records the statements of every connection (StartSQLCapture)
+	capture *SQLCapture
+
 	// config holds the database configuration
 	config *DBConfig
 
@@ -83,6 +101,7 @@ func NewDatabaseServiceImpl(_ context.Context, config *DBConfig, logger l.Logger
 		properties: make(map[string]any),
 		pool:       &sync.Pool{},
 		db:         make(map[string]*gorm.DB),
+		replicas:   make(map[string]*replicaSet),
 	}
 	dbService.config = config
 	dbService.properties["config"] = ti.NewProperty("config", &config, true, nil)
@@ -113,66 +132,32 @@ func (d *DBServiceImpl) Initialize(ctx context.Context) error {
 		}
 	}
 
-	//driver = db.Driver // Pro futuro.. rs
-	var dbHost, dbPort, dbUser, dbPass, dbName, dsn string
-	for _, dbConfig := range config.Databases {
-		if dsn == "" {
-			dsn = dbConfig.ConnectionString
-		}
-		if dsn == "" {
-			dbHost = dbConfig.Host
-			dbPort = dbConfig.Port.(string)
-			dbUser = dbConfig.Username
-			if dbConfig.Type != "postgresql" {
-				dbPass = dbConfig.Password
-				if dbPass == "" {
-					dbPassKey, dbPassErr := gl.GetOrGenPasswordKeyringPass("pgpass")
-					if dbPassErr != nil {
-						gl.Log("error", fmt.Sprintf("❌ Erro ao recuperar senha do banco de dados: %v", dbPassErr))
-						continue
-					}
-					dbConfig.Password = string(dbPassKey)
-					dbPass = dbConfig.Password
-				}
-
-				dbName = dbConfig.Name
-				dsn = fmt.Sprintf(
-					"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=America/Sao_Paulo",
-					dbHost, dbPort, dbUser, dbPass, dbName,
-				)
-			} else {
-				// dbPass = dbConfig.Password
-				dbName = dbConfig.Name
-				dsn = fmt.Sprintf(
-					"host=%s port=%s user=%s dbname=%s sslmode=disable TimeZone=America/Sao_Paulo",
-					dbHost, dbPort, dbUser, dbName,
-				)
-			}
-			dbConfig.ConnectionString = dsn
-			break
-		}
-	}
-
 	// Conecta (Databases habilitados)
 	for _, dbConfig := range config.Databases {
 		if dbConfig.Enabled {
-			db, _, err := connectDatabase(ctx, dbConfig)
+			db, err := d.connect(ctx, dbConfig)
 			if err != nil {
 				gl.Log("error", fmt.Sprintf("❌ Erro ao conectar ao banco de dados '%s': %v", dbConfig.Name, err))
 				continue
 			}
+			d.mutexes.MuLock()
 			d.db[dbConfig.Name] = db
+			d.mutexes.MuUnlock()
 			continue
 		}
 	}
 
+	if config.WatchFile && config.FilePath != "" {
+		d.watchConfigFile(os.ExpandEnv(config.FilePath))
+	}
+
 	// Conecta (Messagery habilitados)
 	if config.Messagery != nil {
-		if config.Messagery.RabbitMQ.Enabled {
+		if config.Messagery.RabbitMQ != nil && config.Messagery.RabbitMQ.Enabled {
 			// Implementar conexão com RabbitMQ se necessário
 			gl.Log("info", "RabbitMQ habilitado")
 		}
-		if config.Messagery.Redis.Enabled {
+		if config.Messagery.Redis != nil && config.Messagery.Redis.Enabled {
 			// Implementar conexão com Redis se necessário
 			gl.Log("info", "Redis habilitado")
 		}
@@ -194,19 +179,17 @@ func (d *DBServiceImpl) InitializeFromEnv(ctx context.Context, env ci.IEnvironme
 	dbUser := env.Getenv("DB_USER")
 	dbPass := env.Getenv("DB_PASS")
 	dbName := env.Getenv("DB_NAME")
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=America/Sao_Paulo",
-		dbHost, dbPort, dbUser, dbPass, dbName,
-	)
 	databaseConfig := &ti.Database{
-		Type:             dbType,
-		Host:             dbHost,
-		Port:             dbPort,
-		Username:         dbUser,
-		Password:         dbPass,
-		Name:             dbName,
-		ConnectionString: dsn,
-		Enabled:          true,
+		Type:            dbType,
+		Host:            dbHost,
+		Port:            dbPort,
+		Username:        dbUser,
+		Password:        dbPass,
+		Name:            dbName,
+		SSLMode:         env.Getenv("DB_SSLMODE"),
+		TimeZone:        env.Getenv("DB_TIMEZONE"),
+		ApplicationName: env.Getenv("DB_APPLICATION_NAME"),
+		Enabled:         true,
 	}
 	dbConfig := d.properties["config"].(*ti.Property[*DBConfig]).GetValue()
 
@@ -226,7 +209,7 @@ func (d *DBServiceImpl) InitializeFromEnv(ctx context.Context, env ci.IEnvironme
 	}
 	// Aguarda o banco de dados ficar pronto e conecta
 	// Timeout de 1 minuto para aguardar o banco de dados ficar pronto
-	db, conn, err := waitAndConnect(context.Background(), databaseConfig, 1*time.Minute)
+	db, conn, err := waitAndConnect(context.Background(), databaseConfig, d.gormLogger(databaseConfig), 1*time.Minute)
 	if err != nil {
 		return fmt.Errorf("❌ Erro ao conectar ao banco de dados: %v", err)
 	}
@@ -236,6 +219,13 @@ func (d *DBServiceImpl) InitializeFromEnv(ctx context.Context, env ci.IEnvironme
 }
 
 func (d *DBServiceImpl) CloseDBConnection(ctx context.Context) error {
+	d.mutexes.MuLock()
+	if d.stopWatch != nil {
+		d.stopWatch()
+		d.stopWatch = nil
+	}
+	d.mutexes.MuUnlock()
+	d.closeReplicas()
 	db, err := GetDB(ctx, d)
 	if err != nil {
 		return fmt.Errorf("❌ Erro ao obter banco de dados: %v", err)
@@ -291,7 +281,7 @@ func (d *DBServiceImpl) GetConnection(ctx context.Context, timeout time.Duration
 	if timeout <= 0 {
 		timeout = 1 * time.Minute
 	}
-	_, conn, err := waitAndConnect(context.Background(), dbConfig, timeout)
+	_, conn, err := waitAndConnect(context.Background(), dbConfig, d.gormLogger(dbConfig), timeout)
 	if err != nil {
 		return nil, fmt.Errorf("❌ Erro ao conectar ao banco de dados: %v", err)
 	}
@@ -303,11 +293,10 @@ func (d *DBServiceImpl) GetDefaultDBName() (string, bool) {
 		return "", false
 	}
 
-	if len(d.config.Databases) > 0 {
-		for name, dbConfig := range d.config.Databases {
-			if dbConfig.Enabled && dbConfig.IsDefault {
-				return name, true
-			}
+	databases, _ := d.routes()
+	for name, dbConfig := range databases {
+		if dbConfig.Enabled && dbConfig.IsDefault {
+			return name, true
 		}
 	}
 
@@ -470,45 +459,89 @@ func (d *DBServiceImpl) GetConfig(ctx context.Context) IDBConfig {
 	return d.config
 }
 
+// RunMigrations applies the pending migrations on the default database and
+// returns how many were applied and how many were already applied (skipped).
+// files maps file names (e.g. 003_orders.sql) to their content; when empty,
+// the migrations embedded in bootstrap.MigrationFiles are used.
 func (d *DBServiceImpl) RunMigrations(ctx context.Context, files map[string]string) (int, int, error) {
-	if d.db == nil {
-		return 0, 0, fmt.Errorf("❌ Banco de dados não inicializado")
+	migrator, migrations, err := d.migratorFor(ctx, files)
+	if err != nil {
+		return 0, 0, err
 	}
-	dbName, ok := d.GetDefaultDBName()
-	if !ok {
-		return 0, 0, fmt.Errorf("❌ Banco de dados padrão não encontrado")
+	applied, skipped, err := migrator.Up(ctx, migrations)
+	if err != nil {
+		return applied, skipped, fmt.Errorf("❌ Erro ao executar migrations: %w", err)
 	}
-	sqlDB, err := d.db[dbName].DB()
+	gl.Log("info", fmt.Sprintf("Migrations: %d aplicadas, %d já aplicadas", applied, skipped))
+	return applied, skipped, nil
+}
+
+// RollbackMigrations reverts the last `steps` applied migrations on the default database.
+func (d *DBServiceImpl) RollbackMigrations(ctx context.Context, files map[string]string, steps int) (int, error) {
+	migrator, migrations, err := d.migratorFor(ctx, files)
 	if err != nil {
-		return 0, 0, fmt.Errorf("❌ Erro ao obter conexão SQL: %v", err)
+		return 0, err
 	}
-	conn, err := sqlDB.Conn(ctx)
+	reverted, err := migrator.Down(ctx, migrations, steps)
 	if err != nil {
-		return 0, 0, fmt.Errorf("❌ Erro ao obter conexão SQL: %v", err)
+		return reverted, fmt.Errorf("❌ Erro ao reverter migrations: %w", err)
 	}
-	defer conn.Close()
+	return reverted, nil
+}
 
-	return 0, 0, nil
+// MigrationStatus lists the known migrations and whether they were applied on the default database.
+func (d *DBServiceImpl) MigrationStatus(ctx context.Context, files map[string]string) ([]MigrationStatus, error) {
+	migrator, migrations, err := d.migratorFor(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Status(ctx, migrations)
+}
 
+func (d *DBServiceImpl) migratorFor(ctx context.Context, files map[string]string) (*Migrator, []Migration, error) {
+	if d.db == nil {
+		return nil, nil, fmt.Errorf("❌ Banco de dados não inicializado")
+	}
+	db, err := GetDB(ctx, d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Banco de dados padrão não encontrado: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Erro ao obter conexão SQL: %v", err)
+	}
+	migrator, err := NewMigrator(sqlDB, db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source := EmbeddedMigrationSource()
+	if len(files) > 0 {
+		source = NewMapMigrationSource(files)
+	}
+	migrations, err := source.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Erro ao carregar migrations: %w", err)
+	}
+	migrations, err = dialectMigrations(migrations, migrator.Dialect())
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Erro ao traduzir migrations: %w", err)
+	}
+	return migrator, migrations, nil
 }
 
 func (d *DBServiceImpl) GetProperties(ctx context.Context) map[string]any {
 	return d.properties
 }
 
+// Query runs query and returns its *sql.Rows, which the caller must close.
+// Prefer QueryInto, QueryOne or QueryIter, which scan the rows into typed values.
 func (d *DBServiceImpl) Query(ctx context.Context, query string, args ...interface{}) (any, error) {
-	db, err := GetDB(ctx, d)
-	if err != nil {
-		return nil, fmt.Errorf("❌ Erro ao obter banco de dados: %v", err)
-	}
-	if db == nil {
-		return nil, fmt.Errorf("❌ Banco de dados não inicializado")
-	}
-	dbSQL, err := db.DB()
+	db, err := rawDB(ctx, d)
 	if err != nil {
-		return nil, fmt.Errorf("❌ Erro ao executar consulta: %v", err)
+		return nil, err
 	}
-	rows, err := dbSQL.QueryContext(ctx, query, args...)
+	rows, err := db.Raw(query, args...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf("❌ Erro ao executar consulta: %v", err)
 	}
@@ -517,41 +550,67 @@ func (d *DBServiceImpl) Query(ctx context.Context, query string, args ...interfa
 
 // connectDatabase conecta ao banco de dados e retorna a instância do GORM
 // Retorna também um booleano indicando se a conexão foi bem-sucedida
-// e um erro caso ocorra algum problema durante a conexão
+// e um erro caso ocorra algum problema durante a conexão. O lgr vai no
+// gorm.Config da conexão (nil usa o logger padrão do GORM)
 // Retorna:
 // - *gorm.DB: instância do GORM conectada ao banco de dados
 // - bool: Indica se é uma conexão válida
 // - error: erro caso ocorra algum problema durante a conexão
-func connectDatabase(_ context.Context, config *ti.Database) (*gorm.DB, bool, error) {
-	// var dialector *sql.DB
+func connectDatabase(_ context.Context, config *ti.Database, lgr logger.Interface) (*gorm.DB, bool, error) {
+	_, sqlDB, valid, err := openDialector(config)
+	if err != nil {
+		return nil, valid, err
+	}
+	// A conexão fica atrás de um livePool, trocado pelo ReloadConfig sem mudar o *gorm.DB
+	gormDialector, err := dialectorFor(config.Type, newLivePool(sqlDB))
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, valid, err
+	}
+
+	db, err := gorm.Open(gormDialector, &gorm.Config{Logger: lgr})
+	if err != nil {
+		return nil, true, fmt.Errorf("❌ Erro ao conectar ao banco de dados: %v", err)
+	}
+	// Callbacks de captura (WithSQLCapture) registrados antes do primeiro uso da conexão
+	registerCaptureCallbacks(db)
+
+	// Testa a conexão
+	if err := sqlDB.Ping(); err != nil {
+		return nil, true, fmt.Errorf("❌ Erro ao pingar o banco de dados: %v", err)
+	}
+
+	return db, true, nil
+}
+
+// openDialector abre a conexão SQL padrão e devolve o dialector do GORM sobre ela.
+// O booleano indica se o tipo de banco é suportado (conexão válida).
+func openDialector(config *ti.Database) (gorm.Dialector, *sql.DB, bool, error) {
 	var dialector *sql.DB
 	var err error
 	// Abre a conexão SQL padrão
 	switch config.Type {
-	case "mysql":
-		dialector, err = sql.Open("mysql", GetConnectionString(config))
-	case "postgres", "postgresql":
-		dialector, err = sql.Open("postgres", GetConnectionString(config))
-	case "sqlite":
-		dialector, err = sql.Open("sqlite", GetConnectionString(config))
-	case "mariadb":
-		dialector, err = sql.Open("mariadb", GetConnectionString(config))
-	case "sqlserver":
-		dialector, err = sql.Open("sqlserver", GetConnectionString(config))
+	case "mysql", "mariadb", "postgres", "postgresql", "sqlite", "sqlite3", "sqlserver":
+		dsn, dsnErr := BuildDSN(config)
+		if dsnErr != nil {
+			return nil, nil, true, fmt.Errorf("❌ Erro ao montar a string de conexão: %v", dsnErr)
+		}
+		gl.Log("debug", fmt.Sprintf("Abrindo conexão %s: %s", dsn.Dialect, dsn.Redacted))
+		dialector, err = sql.Open(dsn.Driver, dsn.Value)
 	case "oracle":
 		// dialector = oracle.Open(dsn) // Implementar quando necessário
-		return nil, false, fmt.Errorf("banco de dados Oracle não suportado no momento")
+		return nil, nil, false, fmt.Errorf("banco de dados Oracle não suportado no momento")
 	case "mongodb":
-		return nil, false, fmt.Errorf("banco de dados MongoDB não suportado pelo GORM")
+		return nil, nil, false, fmt.Errorf("banco de dados MongoDB não suportado pelo GORM")
 	case "redis":
-		return nil, false, fmt.Errorf("banco de dados Redis não suportado pelo GORM")
+		return nil, nil, false, fmt.Errorf("banco de dados Redis não suportado pelo GORM")
 	case "rabbitmq":
-		return nil, false, fmt.Errorf("RabbitMQ não é um banco de dados suportado pelo GORM")
+		return nil, nil, false, fmt.Errorf("RabbitMQ não é um banco de dados suportado pelo GORM")
 	default:
-		return nil, false, fmt.Errorf("banco de dados não suportado: %s", config.Type)
+		return nil, nil, false, fmt.Errorf("banco de dados não suportado: %s", config.Type)
 	}
 	if err != nil {
-		return nil, true, fmt.Errorf("❌ Erro ao abrir conexão SQL: %v", err)
+		return nil, nil, true, fmt.Errorf("❌ Erro ao abrir conexão SQL: %v", err)
 	}
 
 	var gormDialector gorm.Dialector
@@ -566,7 +625,7 @@ func connectDatabase(_ context.Context, config *ti.Database) (*gorm.DB, bool, er
 			Conn:                 dialector,
 			PreferSimpleProtocol: true, // Recomendado para evitar problemas com tipos complexos
 		})
-	case "sqlite":
+	case "sqlite", "sqlite3":
 		gormDialector = sqlite.New(sqlite.Config{
 			Conn: dialector,
 		})
@@ -580,29 +639,14 @@ func connectDatabase(_ context.Context, config *ti.Database) (*gorm.DB, bool, er
 			Conn: dialector,
 		})
 	default:
-		return nil, false, fmt.Errorf("banco de dados não suportado: %s", config.Type)
+		return nil, nil, false, fmt.Errorf("banco de dados não suportado: %s", config.Type)
 	}
 
-	db, err := gorm.Open(gormDialector, &gorm.Config{})
-	if err != nil {
-		return nil, true, fmt.Errorf("❌ Erro ao conectar ao banco de dados: %v", err)
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, true, fmt.Errorf("❌ Erro ao obter conexão SQL: %v", err)
-	}
-
-	// Testa a conexão
-	if err := sqlDB.Ping(); err != nil {
-		return nil, true, fmt.Errorf("❌ Erro ao pingar o banco de dados: %v", err)
-	}
-
-	return db, true, nil
+	return gormDialector, dialector, true, nil
 }
 
 // waitAndConnect aguarda PostgreSQL estar pronto e retorna conexão
-func waitAndConnect(ctx context.Context, cfg *ti.Database, maxWait time.Duration) (*gorm.DB, *sql.Conn, error) {
+func waitAndConnect(ctx context.Context, cfg *ti.Database, lgr logger.Interface, maxWait time.Duration) (*gorm.DB, *sql.Conn, error) {
 	// Configuração inteligente de retry
 	baseRetryInterval := 2 * time.Second // Base menor, mais responsivo
 	maxRetryInterval := 10 * time.Second // Limite máximo
@@ -622,7 +666,7 @@ func waitAndConnect(ctx context.Context, cfg *ti.Database, maxWait time.Duration
 	gl.Log("debug", fmt.Sprintf("⏳ Aguardando PostgreSQL ficar pronto (até %d tentativas)...", maxAttempts))
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		db, _, err := connectDatabase(ctx, cfg)
+		db, _, err := connectDatabase(ctx, cfg, lgr)
 		if err != nil {
 			// Exponential backoff com jitter
 			retryDelay := calculateBackoff(attempt, baseRetryInterval, maxRetryInterval)
@@ -781,32 +825,40 @@ func storeKeyringPassword(name string, pass []byte) (string, error) {
 	return encodedPass, nil
 }
 
+// GetConnectionString returns the DSN of a database for its dialect (see BuildDSN).
 func GetConnectionString(dbConfig *ti.Database) string {
-	if dbConfig.ConnectionString != "" {
-		return dbConfig.ConnectionString
-	}
-	if dbConfig.Host != "" && dbConfig.Port != nil && dbConfig.Username != "" && dbConfig.Name != "" {
-		dbPass := dbConfig.Password
-		if dbPass == "" {
-			dbPassKey, dbPassErr := getPasswordFromKeyring("pgpass")
-			if dbPassErr != nil {
-				gl.Log("error", fmt.Sprintf("❌ Erro ao recuperar senha do banco de dados: %v", dbPassErr))
-			} else {
-				dbConfig.Password = string(dbPassKey)
-				dbPass = dbConfig.Password
-			}
-		}
-		return fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=America/Sao_Paulo",
-			// "host=%s port=%s user=%s dbname=%s sslmode=disable TimeZone=America/Sao_Paulo",
-			dbConfig.Host, dbConfig.Port.(string), dbConfig.Username, dbPass, dbConfig.Name,
-			// dbConfig.Host, dbConfig.Port.(string), dbConfig.Username /* dbPass, */, dbConfig.Name,
-		)
+	dsn, err := BuildDSN(dbConfig)
+	if err != nil {
+		gl.Log("error", fmt.Sprintf("❌ Erro ao montar a string de conexão: %v", err))
+		return ""
 	}
-	return ""
+	return dsn.Value
 }
 
+// GetDB returns the connection selected with Using on the context or, when
+// none is set, the default database. Reads go to the primary when the context
+// was marked with UsePrimary.
 func GetDB(ctx context.Context, d *DBServiceImpl) (*gorm.DB, error) {
+	ctx = d.captureContext(ctx)
+	db, err := d.resolveDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tx, ok := txFor(ctx, db); ok {
+		return bindDB(ctx, tx), nil
+	}
+	return bindDB(ctx, withReadPreference(ctx, db)), nil
+}
+
+// resolveDB returns the connection selected by ctx, ignoring any transaction on it.
+func (d *DBServiceImpl) resolveDB(ctx context.Context) (*gorm.DB, error) {
+	if name, ok := ConnectionFromContext(ctx); ok {
+		return d.connection(ctx, name)
+	}
+	return getDefaultDB(ctx, d)
+}
+
+func getDefaultDB(ctx context.Context, d *DBServiceImpl) (*gorm.DB, error) {
 	if d == nil {
 		return nil, fmt.Errorf("❌ Serviço de banco de dados não inicializado")
 	}
@@ -817,45 +869,50 @@ func GetDB(ctx context.Context, d *DBServiceImpl) (*gorm.DB, error) {
 		return nil, fmt.Errorf("❌ Database Service não configurado")
 	}
 	dbName, hasDefault := d.GetDefaultDBName()
+	conns := d.connections()
 
 	// Check if the configured default actually exists in the map
 	if hasDefault {
-		if _, exists := d.db[dbName]; !exists {
+		if _, exists := conns[dbName]; !exists {
 			hasDefault = false
 		}
 	}
 
 	if !hasDefault {
 		// Fallback: if there's only one database, use it
-		dbLength := len(d.db)
+		dbLength := len(conns)
 		if dbLength == 0 {
 			d.Initialize(ctx)
-			dbLength = len(d.db)
+			conns = d.connections()
+			dbLength = len(conns)
 		}
 		// If there's exactly one DB, use it as default
 		if dbLength > 0 {
 			if dbLength == 1 {
 				gl.Log("notice", "No default DB configured, using the only available one")
-				for name := range d.db {
+				for name := range conns {
 					dbName = name
 					hasDefault = true
 					break
 				}
 			} else {
 				gl.Log("error", "No default DB configured and multiple DBs available, cannot decide which to use")
-				return nil, fmt.Errorf("❌ No default DB configured and multiple DBs available (%d), cannot decide which to use", dbLength)
+				return nil, fmt.Errorf("❌ No default DB configured and multiple DBs available (%d), set is_default or select one with Using/GetDBByName", dbLength)
 			}
 		} else {
-			if len(d.config.Databases) > 0 {
-				for _, dbConf := range d.config.Databases {
+			databases, _ := d.routes()
+			if len(databases) > 0 {
+				for _, dbConf := range databases {
 					if dbConf.Enabled {
 						gl.Log("notice", fmt.Sprintf("No DB connections available, attempting to connect to '%s'", dbConf.Name))
-						db, _, err := connectDatabase(ctx, dbConf)
+						db, err := d.connect(ctx, dbConf)
 						if err != nil {
 							gl.Log("error", fmt.Sprintf("Error connecting to DB '%s': %v", dbConf.Name, err))
 							continue
 						}
+						d.mutexes.MuLock()
 						d.db[dbConf.Name] = db
+						d.mutexes.MuUnlock()
 						dbName = dbConf.Name
 						hasDefault = true
 						break
@@ -869,10 +926,10 @@ func GetDB(ctx context.Context, d *DBServiceImpl) (*gorm.DB, error) {
 		}
 	}
 
-	db := d.db[dbName]
+	db := d.connections()[dbName]
 	if db == nil {
 		d.Reconnect(ctx)
-		db = d.db[dbName]
+		db = d.connections()[dbName]
 		if db == nil {
 			return nil, fmt.Errorf("❌ Default DB connection is down")
 		}
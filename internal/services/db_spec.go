@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FilterOp is the comparison of a Filter.
+type FilterOp string
+
+const (
+	OpEq      FilterOp = "eq"
+	OpNe      FilterOp = "ne"
+	OpGt      FilterOp = "gt"
+	OpGte     FilterOp = "gte"
+	OpLt      FilterOp = "lt"
+	OpLte     FilterOp = "lte"
+	OpIn      FilterOp = "in"
+	OpNotIn   FilterOp = "nin"
+	OpLike    FilterOp = "like"
+	OpILike   FilterOp = "ilike"
+	OpIsNull  FilterOp = "null"
+	OpNotNull FilterOp = "notnull"
+)
+
+// likeEscape is the escape character of the OpLike and OpILike patterns; it
+// is bound as a parameter, so it needs no quoting on any dialect.
+const likeEscape = `\`
+
+// Filter restricts a QuerySpec to the rows whose Field compares to Value.
+// Field is the column, the Go field or the json name of a model field; OpIn
+// and OpNotIn take a slice, OpIsNull and OpNotNull no value. The patterns of
+// OpLike and OpILike escape '%', '_' and '\' with a backslash.
+type Filter struct {
+	Field string   `json:"field"`
+	Op    FilterOp `json:"op"`
+	Value any      `json:"value,omitempty"`
+}
+
+// SortField orders a QuerySpec by Field.
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// DefaultPageLimit is the page size of Repository.List when QuerySpec.Limit is not set.
+const DefaultPageLimit = 50
+
+// QuerySpec describes a query of a Repository: filters (AND-ed), sort, offset
+// (Page) or keyset (Cursor) pagination, field selection and preloads. The
+// fields are resolved against the model, so a spec built from user input
+// cannot reach unknown columns.
+type QuerySpec struct {
+	Filters []Filter    `json:"filters,omitempty"`
+	Sort    []SortField `json:"sort,omitempty"`
+	// Page is the 1-based page of the offset pagination
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit,omitempty"`
+	// Keyset switches to keyset pagination: pages follow Cursor (the NextCursor of the previous page)
+	Keyset bool     `json:"keyset,omitempty"`
+	Cursor string   `json:"cursor,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+	// Preload are the associations loaded with the rows
+	Preload []string `json:"preload,omitempty"`
+}
+
+// NewQuerySpec returns an empty spec to be built with the chained methods.
+func NewQuerySpec() *QuerySpec { return &QuerySpec{} }
+
+// Where adds a filter.
+func (q *QuerySpec) Where(field string, op FilterOp, value any) *QuerySpec {
+	q.Filters = append(q.Filters, Filter{Field: field, Op: op, Value: value})
+	return q
+}
+
+// Eq adds an equality filter.
+func (q *QuerySpec) Eq(field string, value any) *QuerySpec { return q.Where(field, OpEq, value) }
+
+// In adds a filter matching any of values.
+func (q *QuerySpec) In(field string, values ...any) *QuerySpec { return q.Where(field, OpIn, values) }
+
+// OrderBy sorts by field, ascending.
+func (q *QuerySpec) OrderBy(field string) *QuerySpec {
+	q.Sort = append(q.Sort, SortField{Field: field})
+	return q
+}
+
+// OrderByDesc sorts by field, descending.
+func (q *QuerySpec) OrderByDesc(field string) *QuerySpec {
+	q.Sort = append(q.Sort, SortField{Field: field, Desc: true})
+	return q
+}
+
+// Paginate selects a page of the offset pagination.
+func (q *QuerySpec) Paginate(page, limit int) *QuerySpec {
+	q.Page, q.Limit = page, limit
+	return q
+}
+
+// After selects the keyset page following cursor ("" for the first one).
+func (q *QuerySpec) After(cursor string, limit int) *QuerySpec {
+	q.Keyset, q.Cursor, q.Limit = true, cursor, limit
+	return q
+}
+
+// Select restricts the loaded columns.
+func (q *QuerySpec) Select(fields ...string) *QuerySpec {
+	q.Fields = append(q.Fields, fields...)
+	return q
+}
+
+// With preloads associations.
+func (q *QuerySpec) With(associations ...string) *QuerySpec {
+	q.Preload = append(q.Preload, associations...)
+	return q
+}
+
+// specField resolves name to a field of s by column, Go name or json name.
+func specField(s *schema.Schema, name string) (*schema.Field, error) {
+	if f := s.LookUpField(name); f != nil && f.DBName != "" {
+		return f, nil
+	}
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		if jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ","); jsonName != "" && jsonName == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("❌ Campo desconhecido '%s' em '%s'", name, s.Table)
+}
+
+func specColumn(f *schema.Field) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: f.DBName}
+}
+
+// expression builds the condition of a filter.
+func (f Filter) expression(s *schema.Schema) (clause.Expression, error) {
+	field, err := specField(s, f.Field)
+	if err != nil {
+		return nil, err
+	}
+	col := specColumn(field)
+	value, err := filterValue(field, f.Value)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Op {
+	case OpEq, "":
+		return clause.Eq{Column: col, Value: value}, nil
+	case OpNe:
+		return clause.Neq{Column: col, Value: value}, nil
+	case OpGt:
+		return clause.Gt{Column: col, Value: value}, nil
+	case OpGte:
+		return clause.Gte{Column: col, Value: value}, nil
+	case OpLt:
+		return clause.Lt{Column: col, Value: value}, nil
+	case OpLte:
+		return clause.Lte{Column: col, Value: value}, nil
+	case OpIn, OpNotIn:
+		values := filterValues(value)
+		if f.Op == OpNotIn {
+			return clause.Not(clause.IN{Column: col, Values: values}), nil
+		}
+		return clause.IN{Column: col, Values: values}, nil
+	case OpLike:
+		return clause.Expr{SQL: "? LIKE ? ESCAPE ?", Vars: []any{col, value, likeEscape}}, nil
+	case OpILike:
+		// LOWER on both sides behaves the same on every dialect
+		return clause.Expr{SQL: "LOWER(?) LIKE LOWER(?) ESCAPE ?", Vars: []any{col, value, likeEscape}}, nil
+	case OpIsNull:
+		return clause.Eq{Column: col, Value: nil}, nil
+	case OpNotNull:
+		return clause.Neq{Column: col, Value: nil}, nil
+	default:
+		return nil, fmt.Errorf("❌ Operador de filtro inválido '%s'", f.Op)
+	}
+}
+
+// filterValue converts the string values of a filter (query strings) to the
+// type of the field, so that they compare right on every dialect.
+func filterValue(field *schema.Field, v any) (any, error) {
+	switch value := v.(type) {
+	case string:
+		return convertFilterString(field, value)
+	case []string:
+		out := make([]any, len(value))
+		for i, s := range value {
+			converted, err := convertFilterString(field, s)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+var filterTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+func convertFilterString(field *schema.Field, s string) (any, error) {
+	invalid := func() error {
+		return fmt.Errorf("❌ Valor inválido '%s' para o campo '%s' (%s)", s, field.DBName, field.DataType)
+	}
+	switch field.DataType {
+	case schema.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, invalid()
+		}
+		return b, nil
+	case schema.Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, invalid()
+		}
+		return n, nil
+	case schema.Uint:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, invalid()
+		}
+		return n, nil
+	case schema.Float:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, invalid()
+		}
+		return n, nil
+	case schema.Time:
+		for _, layout := range filterTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, invalid()
+	}
+	return s, nil
+}
+
+func filterValues(v any) []any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []any{v}
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// filtered applies the filters, field selection and preloads of the spec.
+func (q *QuerySpec) filtered(db *gorm.DB, s *schema.Schema) (*gorm.DB, error) {
+	if q == nil {
+		return db, nil
+	}
+	exprs := make([]clause.Expression, 0, len(q.Filters))
+	for _, f := range q.Filters {
+		expr, err := f.expression(s)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) > 0 {
+		db = db.Where(clause.And(exprs...))
+	}
+	if len(q.Fields) > 0 {
+		columns := make([]string, 0, len(q.Fields))
+		for _, name := range q.Fields {
+			field, err := specField(s, name)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, field.DBName)
+		}
+		if q.Keyset {
+			// the cursor is read from the sort columns
+			sortFields, _, err := q.sortFields(s)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range sortFields {
+				if !slices.Contains(columns, f.DBName) {
+					columns = append(columns, f.DBName)
+				}
+			}
+		}
+		db = db.Select(columns)
+	}
+	for _, association := range q.Preload {
+		if _, ok := s.Relationships.Relations[association]; !ok && !strings.Contains(association, ".") {
+			return nil, fmt.Errorf("❌ Associação desconhecida '%s' em '%s'", association, s.Table)
+		}
+		db = db.Preload(association)
+	}
+	return db, nil
+}
+
+// sortFields resolves the sort of the spec; the keyset pagination needs a
+// total order, so the primary key is appended as the tie breaker.
+func (q *QuerySpec) sortFields(s *schema.Schema) ([]*schema.Field, []bool, error) {
+	var fields []*schema.Field
+	var desc []bool
+	seen := map[string]bool{}
+	if q != nil {
+		for _, sf := range q.Sort {
+			field, err := specField(s, sf.Field)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !seen[field.DBName] {
+				seen[field.DBName] = true
+				fields = append(fields, field)
+				desc = append(desc, sf.Desc)
+			}
+		}
+	}
+	if q != nil && q.Keyset {
+		for _, pk := range s.PrimaryFields {
+			if !seen[pk.DBName] {
+				fields = append(fields, pk)
+				desc = append(desc, false)
+			}
+		}
+	}
+	return fields, desc, nil
+}
+
+// ordered applies the sort and, in keyset mode, the cursor condition.
+func (q *QuerySpec) ordered(db *gorm.DB, s *schema.Schema) (*gorm.DB, error) {
+	fields, desc, err := q.sortFields(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		columns := make([]clause.OrderByColumn, len(fields))
+		for i, f := range fields {
+			columns[i] = clause.OrderByColumn{Column: specColumn(f), Desc: desc[i]}
+		}
+		db = db.Order(clause.OrderBy{Columns: columns})
+	}
+	if q == nil || !q.Keyset || q.Cursor == "" {
+		return db, nil
+	}
+	values, err := decodeCursor(q.Cursor, fields)
+	if err != nil {
+		return nil, err
+	}
+	// (a, b) after (x, y): a > x OR (a = x AND b > y), per direction
+	var or []clause.Expression
+	for i := range fields {
+		and := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: specColumn(fields[j]), Value: values[j]})
+		}
+		if desc[i] {
+			and = append(and, clause.Lt{Column: specColumn(fields[i]), Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: specColumn(fields[i]), Value: values[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	return db.Where(clause.Or(or...)), nil
+}
+
+// encodeCursor returns the opaque cursor of the page following row.
+func encodeCursor(ctx context.Context, row reflect.Value, fields []*schema.Field) (string, error) {
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		values[i], _ = f.ValueOf(ctx, row)
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor returns the values of a cursor, typed like the sort fields.
+func decodeCursor(cursor string, fields []*schema.Field) ([]any, error) {
+	invalid := fmt.Errorf("❌ Cursor de paginação inválido")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) != len(fields) {
+		return nil, invalid
+	}
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		v := reflect.New(f.FieldType)
+		if err := json.Unmarshal(parts[i], v.Interface()); err != nil {
+			return nil, invalid
+		}
+		values[i] = v.Elem().Interface()
+	}
+	return values, nil
+}
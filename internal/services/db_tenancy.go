@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	ti "github.com/kubex-ecosystem/gdbase/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Tenancy modes accepted in TenancyConfig.Mode.
+const (
+	// TenancyModeRow scopes the tenant tables by a tenant column (row-level tenancy).
+	TenancyModeRow = "row"
+	// TenancyModeSchema keeps the tables of each tenant in its own Postgres schema.
+	TenancyModeSchema = "schema"
+)
+
+const (
+	defaultTenantColumn       = "tenant_id"
+	defaultTenantSchemaPrefix = "tenant_"
+)
+
+var (
+	// ErrTenantRequired is returned for a query on a tenant table run without a
+	// tenant in the context (see WithTenant and WithoutTenant).
+	ErrTenantRequired = errors.New("tenant required")
+	// ErrTenantMismatch is returned when a record being created belongs to another tenant.
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+	// ErrTenantRawSQL is returned for a raw statement (Raw, Exec) reaching a
+	// tenant table, which cannot be scoped: in row mode it must run with
+	// WithoutTenant (or AllowUnscoped) and filter the tenant itself, in schema
+	// mode inside WithTx, which sets the search_path of the tenant. Joins are
+	// rejected the same way in schema mode.
+	ErrTenantRawSQL = errors.New("raw statement on a tenant table cannot be scoped")
+
+	tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,47}$`)
+)
+
+// TenancyConfig configures multi-tenancy (DBConfig.Tenancy).
+type TenancyConfig struct {
+	// Mode is row (tenant column) or schema (one Postgres schema per tenant)
+	Mode string `json:"mode" yaml:"mode" xml:"mode" toml:"mode" mapstructure:"mode"`
+	// Column is the tenant column of the row mode (default tenant_id)
+	Column string `json:"column,omitempty" yaml:"column,omitempty" xml:"column,omitempty" toml:"column,omitempty" mapstructure:"column,omitempty"`
+	// SchemaPrefix prefixes the schema of each tenant in schema mode (default tenant_)
+	SchemaPrefix string `json:"schema_prefix,omitempty" yaml:"schema_prefix,omitempty" xml:"schema_prefix,omitempty" toml:"schema_prefix,omitempty" mapstructure:"schema_prefix,omitempty"`
+	// Tables are the tenant tables; empty means the tables with the tenant column (row) or every table (schema)
+	Tables []string `json:"tables,omitempty" yaml:"tables,omitempty" xml:"tables,omitempty" toml:"tables,omitempty" mapstructure:"tables,omitempty"`
+	// Databases limits tenancy to these databases (keys or names); empty means all of them
+	Databases []string `json:"databases,omitempty" yaml:"databases,omitempty" xml:"databases,omitempty" toml:"databases,omitempty" mapstructure:"databases,omitempty"`
+	// AllowUnscoped lets queries without a tenant reach the tenant tables instead of failing with ErrTenantRequired
+	AllowUnscoped bool `json:"allow_unscoped,omitempty" yaml:"allow_unscoped,omitempty" xml:"allow_unscoped,omitempty" toml:"allow_unscoped,omitempty" mapstructure:"allow_unscoped,omitempty"`
+}
+
+func (c TenancyConfig) column() string {
+	if c.Column != "" {
+		return c.Column
+	}
+	return defaultTenantColumn
+}
+
+// Schema returns the Postgres schema holding the tables of tenant.
+func (c TenancyConfig) Schema(tenant string) (string, error) {
+	if err := ValidateTenantID(tenant); err != nil {
+		return "", err
+	}
+	prefix := c.SchemaPrefix
+	if prefix == "" {
+		prefix = defaultTenantSchemaPrefix
+	}
+	return strings.ToLower(prefix + strings.ReplaceAll(tenant, "-", "_")), nil
+}
+
+// appliesTo reports whether tenancy is enabled on the database.
+func (c TenancyConfig) appliesTo(dbConfig *ti.Database) bool {
+	if len(c.Databases) == 0 {
+		return true
+	}
+	for _, name := range c.Databases {
+		if strings.EqualFold(name, dbConfig.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTenantID checks that id can name a tenant (and its schema).
+func ValidateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) {
+		return fmt.Errorf("❌ Tenant inválido '%s': use letras, números, '_' ou '-' (até 48 caracteres)", id)
+	}
+	return nil
+}
+
+type tenantCtxKey struct{}
+type unscopedTenantCtxKey struct{}
+
+// WithTenant returns a copy of ctx scoped to tenant: the repositories built
+// from it (GetDB, GetDBByName, GetDBForRepo, DBFromContext) only see and write
+// the rows (or the schema) of that tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tenantCtxKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenant, ok := ctx.Value(tenantCtxKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// WithoutTenant marks ctx as deliberately crossing tenants (e.g. back-office
+// reports): queries on tenant tables run unscoped instead of failing with ErrTenantRequired.
+func WithoutTenant(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, unscopedTenantCtxKey{}, true)
+}
+
+func unscopedTenant(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(unscopedTenantCtxKey{}).(bool)
+	return v
+}
+
+// withTenant binds db to ctx when ctx carries a tenant (or WithoutTenant), so
+// the tenancy callbacks find it on the statements.
+func withTenant(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if db == nil || ctx == nil || db.Statement.Context == ctx {
+		return db
+	}
+	if _, ok := TenantFromContext(ctx); ok || unscopedTenant(ctx) {
+		return db.WithContext(ctx)
+	}
+	return db
+}
+
+const tenancyPluginName = "gdbase:tenancy"
+
+type tenancyPlugin struct {
+	cfg    TenancyConfig
+	tables map[string]bool
+	// seen holds the tables found with the tenant column when cfg.Tables is
+	// empty, to recognize them in raw statements.
+	seen *sync.Map
+}
+
+func (tenancyPlugin) Name() string { return tenancyPluginName }
+
+func (p tenancyPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("gdbase:tenant_create", p.scopeCreate),
+		cb.Query().Before("gorm:query").Register("gdbase:tenant_query", p.scope),
+		cb.Update().Before("gorm:update").Register("gdbase:tenant_update", p.scopeUpdate),
+		cb.Delete().Before("gorm:delete").Register("gdbase:tenant_delete", p.scopeDelete),
+		cb.Row().Before("gorm:row").Register("gdbase:tenant_row", p.scope),
+		cb.Raw().Before("gorm:raw").Register("gdbase:tenant_raw", p.guardRaw),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerTenancy installs the tenancy callbacks on a connection of a
+// database covered by DBConfig.Tenancy. Raw statements (Raw, Exec) are not
+// scoped: those reaching a tenant table fail with ErrTenantRawSQL.
+func (d *DBServiceImpl) registerTenancy(db *gorm.DB, dbConfig *ti.Database) error {
+	if d.config == nil || d.config.Tenancy == nil || !d.config.Tenancy.appliesTo(dbConfig) {
+		return nil
+	}
+	cfg := *d.config.Tenancy
+	switch cfg.Mode {
+	case TenancyModeRow:
+	case TenancyModeSchema:
+		if NormalizeDialect(db.Dialector.Name()) == DialectSQLite {
+			return fmt.Errorf("❌ Tenancy por schema não é suportado no SQLite ('%s')", dbConfig.Name)
+		}
+	default:
+		return fmt.Errorf("❌ Modo de tenancy inválido '%s' (use %s ou %s)", cfg.Mode, TenancyModeRow, TenancyModeSchema)
+	}
+	p := tenancyPlugin{cfg: cfg, tables: make(map[string]bool, len(cfg.Tables)), seen: &sync.Map{}}
+	for _, t := range cfg.Tables {
+		p.tables[strings.ToLower(t)] = true
+	}
+	if cfg.Mode == TenancyModeRow && len(p.tables) == 0 {
+		// the existing tenant tables; the ones created later are learned from
+		// the statements of their models
+		if tables, err := db.Migrator().GetTables(); err == nil {
+			for _, t := range tables {
+				if db.Migrator().HasColumn(t, cfg.column()) {
+					p.seen.Store(strings.ToLower(t), true)
+				}
+			}
+		}
+	}
+	if err := db.Use(p); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return err
+	}
+	return nil
+}
+
+func (p tenancyPlugin) isTenantTable(stmt *gorm.Statement) bool {
+	table := stmt.Table
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		table = table[i+1:]
+	}
+	if table == "" {
+		return false
+	}
+	if len(p.tables) > 0 {
+		return p.tables[strings.ToLower(table)]
+	}
+	if p.cfg.Mode == TenancyModeSchema {
+		return true
+	}
+	// the table decides, not the destination: Table("orders") scanned into a
+	// DTO or a map without the tenant field is still scoped by the column
+	if p.seen != nil {
+		if _, ok := p.seen.Load(strings.ToLower(table)); ok {
+			return true
+		}
+	}
+	if stmt.Schema == nil || stmt.Schema.LookUpField(p.cfg.column()) == nil {
+		return false
+	}
+	if p.seen != nil {
+		p.seen.Store(strings.ToLower(table), true)
+	}
+	return true
+}
+
+// rawDML lists the statements reading or writing rows; DDL (CREATE, ALTER,
+// PRAGMA...) is left to the migrations.
+var rawDML = map[string]bool{"select": true, "with": true, "insert": true, "update": true, "delete": true, "merge": true, "replace": true}
+
+// rawTableRe captures the tables read or written by a raw statement.
+var rawTableRe = regexp.MustCompile(`(?i)\b(?:from|join|into|update)\s+((?:"[^"]+"|\w+)(?:\.(?:"[^"]+"|\w+))?)`)
+
+// rawTenantTable returns the first tenant table named in a raw DML statement.
+// In schema mode the tables qualified by a schema and the catalogs are left alone.
+func (p tenancyPlugin) rawTenantTable(sql string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(sql), func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(words) == 0 || !rawDML[words[0]] {
+		return "", false
+	}
+	if p.cfg.Mode == TenancyModeSchema {
+		for _, m := range rawTableRe.FindAllStringSubmatch(sql, -1) {
+			if strings.Contains(m[1], ".") {
+				continue
+			}
+			table := strings.ToLower(strings.Trim(m[1], `"`))
+			if strings.HasPrefix(table, "pg_") || strings.HasPrefix(table, "sqlite_") {
+				// the catalogs read by the GORM migrator
+				continue
+			}
+			if len(p.tables) == 0 || p.tables[table] {
+				return table, true
+			}
+		}
+		return "", false
+	}
+	for _, w := range words[1:] {
+		if len(p.tables) > 0 {
+			if p.tables[w] {
+				return w, true
+			}
+			continue
+		}
+		if p.seen != nil {
+			if _, ok := p.seen.Load(w); ok {
+				return w, true
+			}
+		}
+	}
+	return "", false
+}
+
+// guardRaw fails the raw statements reaching a tenant table unless the
+// context is unscoped (WithoutTenant) or AllowUnscoped is set; in schema mode
+// they also run in a WithTx that set the search_path of the tenant.
+func (p tenancyPlugin) guardRaw(db *gorm.DB) {
+	if db.Error != nil || p.cfg.AllowUnscoped || unscopedTenant(db.Statement.Context) {
+		return
+	}
+	if p.cfg.Mode == TenancyModeSchema && p.searchPathSet(db) {
+		return
+	}
+	table, ok := p.rawTenantTable(db.Statement.SQL.String())
+	if !ok {
+		return
+	}
+	if p.cfg.Mode == TenancyModeSchema {
+		_ = db.AddError(fmt.Errorf("%w: %s (run it in WithTx or qualify the schema)", ErrTenantRawSQL, table))
+		return
+	}
+	_ = db.AddError(fmt.Errorf("%w: %s (use WithoutTenant and filter %s)", ErrTenantRawSQL, table, p.cfg.column()))
+}
+
+// searchPathSet reports whether the statement runs in a transaction whose
+// search_path is the schema of the tenant of its context.
+func (p tenancyPlugin) searchPathSet(db *gorm.DB) bool {
+	ctx := db.Statement.Context
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return false
+	}
+	st, ok := ctx.Value(txCtxKey{}).(*txState)
+	if !ok || st == nil || st.searchPath == "" || st.tx.Statement.ConnPool != db.Statement.ConnPool {
+		return false
+	}
+	s, err := p.cfg.Schema(tenant)
+	return err == nil && s == st.searchPath
+}
+
+// tenant returns the tenant of the statement, failing it when the table is a
+// tenant table and the context has none. ok is false when nothing must be scoped.
+func (p tenancyPlugin) tenant(db *gorm.DB) (string, bool) {
+	if db.Error != nil || !p.isTenantTable(db.Statement) {
+		return "", false
+	}
+	tenant, ok := TenantFromContext(db.Statement.Context)
+	if ok {
+		return tenant, true
+	}
+	if !p.cfg.AllowUnscoped && !unscopedTenant(db.Statement.Context) {
+		_ = db.AddError(fmt.Errorf("%w: %s", ErrTenantRequired, db.Statement.Table))
+	}
+	return "", false
+}
+
+func (p tenancyPlugin) scope(db *gorm.DB) {
+	if db.Statement.SQL.Len() > 0 {
+		// Raw(...).Scan/Rows/Find: the SQL is already written
+		p.guardRaw(db)
+		return
+	}
+	tenant, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	if p.cfg.Mode == TenancyModeSchema && len(db.Statement.Joins) > 0 && !p.searchPathSet(db) {
+		// the joined tables are not qualified: they would resolve to public
+		_ = db.AddError(fmt.Errorf("%w: joins on %s (run them in WithTx)", ErrTenantRawSQL, db.Statement.Table))
+		return
+	}
+	p.apply(db, tenant)
+}
+
+// apply restricts the statement to the tenant.
+func (p tenancyPlugin) apply(db *gorm.DB, tenant string) {
+	if p.cfg.Mode == TenancyModeSchema {
+		p.qualify(db, tenant)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{p.condition(tenant)}})
+}
+
+// condition matches the rows of the tenant.
+func (p tenancyPlugin) condition(tenant string) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: p.cfg.column()}, Value: tenant}
+}
+
+func (p tenancyPlugin) scopeUpdate(db *gorm.DB) {
+	tenant, ok := p.tenant(db)
+	if !ok || !p.restricted(db) {
+		return
+	}
+	if p.cfg.Mode == TenancyModeRow && db.Statement.Schema != nil && db.Statement.Schema.LookUpField(p.cfg.column()) != nil {
+		// a Save must not move the row out of the tenant
+		db.Statement.SetColumn(p.cfg.column(), tenant, true)
+	}
+	p.apply(db, tenant)
+}
+
+func (p tenancyPlugin) scopeDelete(db *gorm.DB) {
+	if tenant, ok := p.tenant(db); ok && p.restricted(db) {
+		p.apply(db, tenant)
+	}
+}
+
+// restricted keeps GORM refusing global updates and deletes: the tenant
+// condition alone must not turn them into "every row of the tenant".
+func (p tenancyPlugin) restricted(db *gorm.DB) bool {
+	return db.AllowGlobalUpdate || hasConditions(db.Statement)
+}
+
+func (p tenancyPlugin) scopeCreate(db *gorm.DB) {
+	tenant, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	if p.cfg.Mode == TenancyModeSchema {
+		p.qualify(db, tenant)
+		return
+	}
+	rv := db.Statement.ReflectValue
+	if rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Map {
+		// Table(...).Create(map[string]any{...})
+		p.setMapTenant(db, rv, tenant)
+		return
+	}
+	var field *schema.Field
+	if db.Statement.Schema != nil {
+		field = db.Statement.Schema.LookUpField(p.cfg.column())
+	}
+	if field == nil {
+		_ = db.AddError(fmt.Errorf("❌ Tabela '%s' sem a coluna de tenant '%s'", db.Statement.Table, p.cfg.column()))
+		return
+	}
+	// an upsert (Save of a missing row) must not overwrite the row of another tenant
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if oc, ok := c.Expression.(clause.OnConflict); ok && !oc.DoNothing {
+			oc.Where.Exprs = append(oc.Where.Exprs, p.condition(tenant))
+			db.Statement.AddClause(oc)
+		}
+	}
+	setTenant := func(rv reflect.Value) {
+		if current, zero := field.ValueOf(db.Statement.Context, rv); !zero {
+			if fmt.Sprint(reflect.Indirect(reflect.ValueOf(current))) != tenant {
+				_ = db.AddError(fmt.Errorf("%w: %s", ErrTenantMismatch, tenant))
+				return
+			}
+		}
+		if err := field.Set(db.Statement.Context, rv, tenant); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setTenant(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		setTenant(rv)
+	}
+}
+
+// setMapTenant sets the tenant column of the maps of a create.
+func (p tenancyPlugin) setMapTenant(db *gorm.DB, rv reflect.Value, tenant string) {
+	key := reflect.ValueOf(p.cfg.column())
+	set := func(m reflect.Value) {
+		if m.Type().Key().Kind() != reflect.String || !reflect.TypeOf(tenant).ConvertibleTo(m.Type().Elem()) {
+			_ = db.AddError(fmt.Errorf("❌ Tabela '%s': coluna de tenant '%s' não pode ser definida em %s", db.Statement.Table, p.cfg.column(), m.Type()))
+			return
+		}
+		if current := m.MapIndex(key); current.IsValid() && !current.IsZero() {
+			if fmt.Sprint(reflect.Indirect(reflect.ValueOf(current.Interface()))) != tenant {
+				_ = db.AddError(fmt.Errorf("%w: %s", ErrTenantMismatch, tenant))
+				return
+			}
+		}
+		m.SetMapIndex(key.Convert(m.Type().Key()), reflect.ValueOf(tenant).Convert(m.Type().Elem()))
+	}
+	if rv.Kind() == reflect.Map {
+		set(rv)
+		return
+	}
+	for i := 0; i < rv.Len(); i++ {
+		set(rv.Index(i))
+	}
+}
+
+// qualify points the statement at the schema of the tenant.
+func (p tenancyPlugin) qualify(db *gorm.DB, tenant string) {
+	if strings.Contains(db.Statement.Table, ".") {
+		return
+	}
+	s, err := p.cfg.Schema(tenant)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	db.Statement.Table = s + "." + db.Statement.Table
+}
+
+// hasConditions reports whether a statement is restricted by a WHERE clause
+// or by the primary key of its model.
+func hasConditions(stmt *gorm.Statement) bool {
+	if _, ok := stmt.Clauses["WHERE"]; ok {
+		return true
+	}
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFields) == 0 || !stmt.ReflectValue.IsValid() {
+		return false
+	}
+	_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	return len(values) > 0
+}
+
+// setTenantSearchPath makes the raw statements of a transaction resolve to the
+// schema of the tenant of ctx (schema mode on Postgres), returning that schema.
+func (d *DBServiceImpl) setTenantSearchPath(ctx context.Context, tx *gorm.DB) (string, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok || d.config == nil || d.config.Tenancy == nil || d.config.Tenancy.Mode != TenancyModeSchema {
+		return "", nil
+	}
+	if NormalizeDialect(tx.Dialector.Name()) != DialectPostgres {
+		return "", nil
+	}
+	if _, registered := tx.Config.Plugins[tenancyPluginName]; !registered {
+		return "", nil
+	}
+	s, err := d.config.Tenancy.Schema(tenant)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Exec(fmt.Sprintf(`SET LOCAL search_path TO "%s", public`, s)).Error; err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// tenancy returns the tenancy configuration, failing when none is set.
+func (d *DBServiceImpl) tenancy() (TenancyConfig, error) {
+	if d == nil || d.config == nil {
+		return TenancyConfig{}, fmt.Errorf("❌ Database Service não configurado")
+	}
+	if d.config.Tenancy == nil {
+		return TenancyConfig{}, fmt.Errorf("❌ Tenancy não configurado (DBConfig.Tenancy)")
+	}
+	return *d.config.Tenancy, nil
+}
+
+// ProvisionTenant prepares the database selected by ctx for a new tenant. In
+// schema mode it creates the schema of the tenant and applies the migrations
+// of source to it (embedded migrations when nil), returning how many were
+// applied; in row mode the tables are shared and there is nothing to create.
+func (d *DBServiceImpl) ProvisionTenant(ctx context.Context, tenant string, source MigrationSource) (int, error) {
+	cfg, err := d.tenancy()
+	if err != nil {
+		return 0, err
+	}
+	if err := ValidateTenantID(tenant); err != nil {
+		return 0, err
+	}
+	if cfg.Mode != TenancyModeSchema {
+		gl.Log("info", fmt.Sprintf("Tenant '%s': tenancy por linha, nada a provisionar", tenant))
+		return 0, nil
+	}
+	db, err := d.resolveDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if NormalizeDialect(db.Dialector.Name()) != DialectPostgres {
+		return 0, fmt.Errorf("❌ Tenancy por schema requer PostgreSQL")
+	}
+	s, _ := cfg.Schema(tenant)
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, s)).Error; err != nil {
+		return 0, fmt.Errorf("❌ Erro ao criar o schema '%s': %w", s, err)
+	}
+	return d.MigrateTenant(ctx, tenant, source)
+}
+
+// MigrateTenant applies the pending migrations of source (embedded migrations
+// when nil) to the schema of tenant, which keeps its own migrations table. In
+// row mode the migrations are shared and run once with RunMigrations.
+func (d *DBServiceImpl) MigrateTenant(ctx context.Context, tenant string, source MigrationSource) (int, error) {
+	cfg, err := d.tenancy()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Mode != TenancyModeSchema {
+		return 0, fmt.Errorf("❌ Migrations por tenant requerem tenancy por schema; use RunMigrations")
+	}
+	s, err := cfg.Schema(tenant)
+	if err != nil {
+		return 0, err
+	}
+	dbConf, err := d.selectedDatabase(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dbConf.Dsn != "" || dbConf.ConnectionString != "" {
+		return 0, fmt.Errorf("❌ Migrations por tenant indisponíveis para '%s': configure host, porta e usuário em vez de dsn/connection_string", dbConf.Name)
+	}
+	if source == nil {
+		source = EmbeddedMigrationSource()
+	}
+	migrations, err := source.Load()
+	if err != nil {
+		return 0, fmt.Errorf("❌ Erro ao carregar migrations: %w", err)
+	}
+
+	// a dedicated connection whose search_path starts at the tenant schema
+	cfgCopy := *dbConf
+	cfgCopy.Replicas = nil
+	cfgCopy.Params = make(map[string]string, len(dbConf.Params)+1)
+	for k, v := range dbConf.Params {
+		cfgCopy.Params[k] = v
+	}
+	// public stays on the path for the extensions (uuid_generate_v4...)
+	cfgCopy.Params["search_path"] = fmt.Sprintf(`"%s", public`, s)
+	_, sqlDB, _, err := openDialector(&cfgCopy)
+	if err != nil {
+		return 0, err
+	}
+	defer sqlDB.Close()
+
+	migrator, err := NewMigrator(sqlDB, dbConf.Type)
+	if err != nil {
+		return 0, err
+	}
+	applied, _, err := migrator.Up(ctx, migrations)
+	if err != nil {
+		return applied, fmt.Errorf("❌ Erro ao executar migrations do tenant '%s': %w", tenant, err)
+	}
+	gl.Log("info", fmt.Sprintf("Tenant '%s': %d migrations aplicadas", tenant, applied))
+	return applied, nil
+}
+
+// ListTenants returns the tenants provisioned on the database selected by ctx
+// (schema mode: the schemas named with the tenant prefix).
+func (d *DBServiceImpl) ListTenants(ctx context.Context) ([]string, error) {
+	cfg, err := d.tenancy()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Mode != TenancyModeSchema {
+		return nil, fmt.Errorf("❌ Listagem de tenants requer tenancy por schema")
+	}
+	db, err := d.resolveDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix, _ := cfg.Schema("x")
+	prefix = strings.TrimSuffix(prefix, "x")
+	var schemas []string
+	if err := db.WithContext(ctx).Raw(
+		"SELECT schema_name FROM information_schema.schemata WHERE schema_name LIKE ? ORDER BY schema_name",
+		strings.ReplaceAll(prefix, "_", `\_`)+"%").Scan(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("❌ Erro ao listar tenants: %w", err)
+	}
+	tenants := make([]string, 0, len(schemas))
+	for _, s := range schemas {
+		tenants = append(tenants, strings.TrimPrefix(s, prefix))
+	}
+	return tenants, nil
+}
+
+// MigrateTenants runs MigrateTenant for each tenant (every provisioned tenant
+// when none is given) and returns the migrations applied per tenant. It stops
+// at the first failure.
+func (d *DBServiceImpl) MigrateTenants(ctx context.Context, source MigrationSource, tenants ...string) (map[string]int, error) {
+	if len(tenants) == 0 {
+		var err error
+		if tenants, err = d.ListTenants(ctx); err != nil {
+			return nil, err
+		}
+	}
+	tenants = slices.Compact(slices.Sorted(slices.Values(tenants)))
+	out := make(map[string]int, len(tenants))
+	for _, tenant := range tenants {
+		applied, err := d.MigrateTenant(ctx, tenant, source)
+		out[tenant] = applied
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
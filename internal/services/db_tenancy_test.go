@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type tenantNote struct {
+	ID       uint
+	TenantID *string
+	Body     string
+}
+
+func TestTenancyRowScoping(t *testing.T) {
+	d := newRoutingTestService(t)
+	d.config.Tenancy = &TenancyConfig{Mode: TenancyModeRow}
+	conn := d.db["transactional"]
+	if err := d.registerTenancy(conn, d.config.Databases["transactional"]); err != nil {
+		t.Fatalf("registerTenancy: %v", err)
+	}
+	if err := conn.AutoMigrate(&tenantNote{}, &captureAccount{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	base := Using(context.Background(), "transactional")
+	dbFor := func(ctx context.Context) *gorm.DB {
+		db, err := GetDB(ctx, d)
+		if err != nil {
+			t.Fatalf("GetDB: %v", err)
+		}
+		return db
+	}
+	tenantA, tenantB := WithTenant(base, "acme"), WithTenant(base, "globex")
+
+	noteA := tenantNote{Body: "a"}
+	if err := dbFor(tenantA).Create(&noteA).Error; err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if noteA.TenantID == nil || *noteA.TenantID != "acme" {
+		t.Fatalf("tenant not set on create: %v", noteA.TenantID)
+	}
+	if err := dbFor(tenantB).Create(&[]tenantNote{{Body: "b1"}, {Body: "b2"}}).Error; err != nil {
+		t.Fatalf("Create batch: %v", err)
+	}
+	other := "acme"
+	if err := dbFor(tenantB).Create(&tenantNote{TenantID: &other}).Error; !errors.Is(err, ErrTenantMismatch) {
+		t.Fatalf("expected ErrTenantMismatch, got %v", err)
+	}
+
+	var notes []tenantNote
+	if err := dbFor(tenantB).Find(&notes).Error; err != nil || len(notes) != 2 {
+		t.Fatalf("tenant query = %d rows, %v", len(notes), err)
+	}
+	var count int64
+	if err := dbFor(tenantA).Model(&tenantNote{}).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("tenant count = %d, %v", count, err)
+	}
+	if err := dbFor(base).Find(&notes).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got %v", err)
+	}
+	if err := dbFor(WithoutTenant(base)).Find(&notes).Error; err != nil || len(notes) != 3 {
+		t.Fatalf("unscoped query = %d rows, %v", len(notes), err)
+	}
+	// raw statements on a tenant table cannot be scoped
+	if err := dbFor(tenantA).Raw("SELECT * FROM tenant_notes").Scan(&notes).Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL on Raw, got %v", err)
+	}
+	if err := dbFor(tenantA).Exec("UPDATE tenant_notes SET body = ?", "x").Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL on Exec, got %v", err)
+	}
+	if err := dbFor(WithoutTenant(base)).Raw("SELECT * FROM tenant_notes WHERE tenant_id = ?", "acme").Scan(&notes).Error; err != nil || len(notes) != 1 {
+		t.Fatalf("unscoped raw query = %d rows, %v", len(notes), err)
+	}
+	var one int
+	if err := dbFor(tenantA).Raw("SELECT 1").Scan(&one).Error; err != nil || one != 1 {
+		t.Fatalf("raw query without tenant table = %d, %v", one, err)
+	}
+	// tables without the tenant column are shared
+	if err := dbFor(base).Find(&[]captureAccount{}).Error; err != nil {
+		t.Fatalf("shared table: %v", err)
+	}
+
+	// another tenant can neither update, delete nor overwrite the row
+	res := dbFor(tenantB).Model(&tenantNote{}).Where("id = ?", noteA.ID).Update("body", "hijacked")
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Fatalf("cross-tenant update = %d, %v", res.RowsAffected, res.Error)
+	}
+	if err := dbFor(tenantB).Delete(&tenantNote{}, noteA.ID).Error; err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	stolen := tenantNote{ID: noteA.ID, Body: "overwritten"}
+	dbFor(tenantB).Save(&stolen)
+	var kept tenantNote
+	if err := dbFor(tenantA).First(&kept, noteA.ID).Error; err != nil || kept.Body != "a" || *kept.TenantID != "acme" {
+		t.Fatalf("row of acme changed: %+v, %v", kept, err)
+	}
+}
+
+// TestTenancyRowScopingByTable covers the queries naming the table with
+// Table() and scanning into destinations without the tenant field.
+func TestTenancyRowScopingByTable(t *testing.T) {
+	d := newRoutingTestService(t)
+	d.config.Tenancy = &TenancyConfig{Mode: TenancyModeRow}
+	conn := d.db["transactional"]
+	if err := conn.AutoMigrate(&tenantNote{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	if err := d.registerTenancy(conn, d.config.Databases["transactional"]); err != nil {
+		t.Fatalf("registerTenancy: %v", err)
+	}
+	base := Using(context.Background(), "transactional")
+	dbFor := func(ctx context.Context) *gorm.DB {
+		db, err := GetDB(ctx, d)
+		if err != nil {
+			t.Fatalf("GetDB: %v", err)
+		}
+		return db
+	}
+	tenantA, tenantB := WithTenant(base, "acme"), WithTenant(base, "globex")
+	if err := dbFor(tenantA).Table("tenant_notes").Create(map[string]any{"body": "a"}).Error; err != nil {
+		t.Fatalf("Create map: %v", err)
+	}
+	if err := dbFor(tenantB).Table("tenant_notes").Create(&[]map[string]any{{"body": "b1"}, {"body": "b2"}}).Error; err != nil {
+		t.Fatalf("Create maps: %v", err)
+	}
+	if err := dbFor(tenantB).Table("tenant_notes").Create(map[string]any{"body": "x", "tenant_id": "acme"}).Error; !errors.Is(err, ErrTenantMismatch) {
+		t.Fatalf("expected ErrTenantMismatch, got %v", err)
+	}
+
+	type noteDTO struct {
+		ID   uint
+		Body string
+	}
+	var dtos []noteDTO
+	if err := dbFor(tenantA).Table("tenant_notes").Find(&dtos).Error; err != nil || len(dtos) != 1 || dtos[0].Body != "a" {
+		t.Fatalf("DTO query = %+v, %v", dtos, err)
+	}
+	var rows []map[string]any
+	if err := dbFor(tenantB).Table("tenant_notes").Find(&rows).Error; err != nil || len(rows) != 2 {
+		t.Fatalf("map query = %d rows, %v", len(rows), err)
+	}
+	var count int64
+	if err := dbFor(tenantB).Table("tenant_notes").Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("count = %d, %v", count, err)
+	}
+	if err := dbFor(base).Table("tenant_notes").Find(&dtos).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got %v", err)
+	}
+	res := dbFor(tenantB).Table("tenant_notes").Where("body = ?", "a").Updates(map[string]any{"body": "hijacked"})
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Fatalf("cross-tenant update = %d, %v", res.RowsAffected, res.Error)
+	}
+	rows = nil
+	if err := dbFor(WithoutTenant(base)).Table("tenant_notes").Find(&rows).Error; err != nil || len(rows) != 3 {
+		t.Fatalf("unscoped query = %d rows, %v", len(rows), err)
+	}
+}
+
+// TestTenancySchemaRawSQL checks that the statements the schema mode cannot
+// qualify (raw SQL, joins) only run in a transaction whose search_path is the
+// schema of the tenant. The plugin is installed directly: registerTenancy
+// rejects the schema mode on SQLite.
+func TestTenancySchemaRawSQL(t *testing.T) {
+	d := newRoutingTestService(t)
+	conn := d.db["transactional"]
+	if err := conn.AutoMigrate(&tenantNote{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	p := tenancyPlugin{cfg: TenancyConfig{Mode: TenancyModeSchema}, tables: map[string]bool{}, seen: &sync.Map{}}
+	if err := conn.Use(p); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	base := Using(context.Background(), "transactional")
+	tenantA := WithTenant(base, "acme")
+	db := DBFromContext(tenantA, conn)
+
+	var n int64
+	if err := db.Raw("SELECT count(*) FROM tenant_notes").Scan(&n).Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL on Raw, got %v", err)
+	}
+	if err := db.Exec(`UPDATE "tenant_notes" SET body = ?`, "x").Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL on Exec, got %v", err)
+	}
+	if err := DBFromContext(base, conn).Raw("SELECT count(*) FROM tenant_notes").Scan(&n).Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL without tenant, got %v", err)
+	}
+	var notes []tenantNote
+	if err := db.Joins("JOIN capture_accounts ON capture_accounts.id = tenant_notes.id").Find(&notes).Error; !errors.Is(err, ErrTenantRawSQL) {
+		t.Fatalf("expected ErrTenantRawSQL on joins, got %v", err)
+	}
+	// qualified tables, statements without tables and unscoped contexts pass
+	if err := db.Raw("SELECT count(*) FROM main.tenant_notes").Scan(&n).Error; err != nil {
+		t.Fatalf("qualified raw query: %v", err)
+	}
+	if err := db.Raw("SELECT 1").Scan(&n).Error; err != nil {
+		t.Fatalf("raw query without table: %v", err)
+	}
+	if err := DBFromContext(WithoutTenant(base), conn).Raw("SELECT count(*) FROM tenant_notes").Scan(&n).Error; err != nil {
+		t.Fatalf("unscoped raw query: %v", err)
+	}
+
+	// in a transaction of the tenant (WithTx sets search_path on Postgres)
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		inTx := context.WithValue(tenantA, txCtxKey{}, &txState{root: conn, tx: tx, searchPath: "tenant_acme"})
+		if err := DBFromContext(inTx, conn).Raw("SELECT count(*) FROM tenant_notes").Scan(&n).Error; err != nil {
+			return err
+		}
+		other := WithTenant(inTx, "globex")
+		if err := DBFromContext(other, conn).Raw("SELECT count(*) FROM tenant_notes").Scan(&n).Error; !errors.Is(err, ErrTenantRawSQL) {
+			t.Errorf("expected ErrTenantRawSQL for another tenant, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("raw query in the tenant transaction: %v", err)
+	}
+}
+
+func TestTenancyConfig(t *testing.T) {
+	cfg := TenancyConfig{Mode: TenancyModeSchema}
+	if s, err := cfg.Schema("Acme-EU"); err != nil || s != "tenant_acme_eu" {
+		t.Fatalf("Schema = %q, %v", s, err)
+	}
+	if _, err := cfg.Schema(`x"; DROP SCHEMA public; --`); err == nil {
+		t.Fatalf("expected an invalid tenant error")
+	}
+
+	d := newRoutingTestService(t)
+	d.config.Tenancy = &cfg
+	if err := d.registerTenancy(d.db["analytics"], d.config.Databases["analytics"]); err == nil {
+		t.Fatalf("schema tenancy must be rejected on sqlite")
+	}
+	d.config.Tenancy = &TenancyConfig{Mode: TenancyModeRow, Databases: []string{"transactional"}}
+	if err := d.registerTenancy(d.db["analytics"], d.config.Databases["analytics"]); err != nil {
+		t.Fatalf("registerTenancy: %v", err)
+	}
+	if _, ok := d.db["analytics"].Config.Plugins[tenancyPluginName]; ok {
+		t.Fatalf("tenancy registered on a database outside Tenancy.Databases")
+	}
+}
@@ -0,0 +1,1348 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+)
+
+// The embedded migrations are written for Postgres. TranslateMigrations
+// rewrites them for SQLite (used by the localhost provider), MySQL and SQL
+// Server: the types without an equivalent are mapped to the closest one
+// (uuid, jsonb, arrays, enums...), arrays become JSON arrays,
+// uuid_generate_v4() and now() become native expressions, and the statements
+// the dialect cannot run (extensions, roles and grants, enum types, PL/pgSQL
+// functions, triggers and DO blocks, GIN/GiST indexes, column type changes)
+// are skipped and reported. MySQL and SQL Server cannot index TEXT, so the
+// text columns of the keys and indexes become VARCHAR(255); SQL Server also
+// loses the ON DELETE actions that would give a table several cascade paths.
+
+// sqliteUUIDExpr generates a random (version 4) UUID in SQLite.
+const sqliteUUIDExpr = "(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || " +
+	"substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + (abs(random()) % 4), 1) || " +
+	"substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))))"
+
+// keyTextLength is the length of the text columns used by keys and indexes
+// on MySQL and SQL Server.
+const keyTextLength = 255
+
+var (
+	sqlLiteralRe    = regexp.MustCompile("\x01(\\d+)\x01")
+	sqlUUIDFuncRe   = regexp.MustCompile(`(?i)\b(?:public\.)?(?:uuid_generate_v4|gen_random_uuid)\s*\(\s*\)`)
+	sqlNowShiftRe   = regexp.MustCompile("(?i)\\b(?:now\\s*\\(\\s*\\)|current_timestamp)\\s*([+-])\\s*interval\\s+\x01(\\d+)\x01")
+	sqlNowRe        = regexp.MustCompile(`(?i)\b(?:now\s*\(\s*\)|localtimestamp|current_timestamp)`)
+	sqlCastRe       = regexp.MustCompile(`::\s*"?[A-Za-z_]\w*"?(?:\s+(?:with|without)\s+time\s+zone|\s+precision|\s+varying)?(?:\s*\(\s*\d+(?:\s*,\s*\d+)?\s*\))?(?:\s*\[\s*\])*`)
+	sqlCastAsRe     = regexp.MustCompile(`(?i)\bAS\s+(uuid|jsonb|json|citext|timestamptz|inet|hstore)\b`)
+	sqlSchemaRe     = regexp.MustCompile(`(?i)\bpublic\.`)
+	sqlArrayRe      = regexp.MustCompile(`(?i)\bARRAY\s*\[`)
+	sqlDefaultEndRe = regexp.MustCompile(`(?i)\bDEFAULT\s*$`)
+	sqlIdentityRe   = regexp.MustCompile(`(?i)\s+GENERATED\s+(?:ALWAYS|BY\s+DEFAULT)\s+AS\s+IDENTITY(?:\s*\([^)]*\))?`)
+	sqlColumnRe     = regexp.MustCompile(`(?is)^("[^"]+"|\w+)\s+([A-Za-z_]\w*(?:\s+(?:with|without)\s+time\s+zone|\s+precision|\s+varying)?(?:\s*\(\s*\d+(?:\s*,\s*\d+)?\s*\))?(?:\s*\[\s*\d*\s*\])*)(.*)$`)
+	sqlEnumRe       = regexp.MustCompile(`(?is)^CREATE\s+TYPE\s+(?:public\.)?"?(\w+)"?\s+AS\s+ENUM\b`)
+	sqlAlterRe      = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(IF\s+EXISTS\s+)?(?:ONLY\s+)?("[^"]+"|[\w.]+)\s+(.*)$`)
+	sqlAddColumnRe  = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(IF\s+NOT\s+EXISTS\s+)?(.*)$`)
+	sqlDropColRe    = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(IF\s+EXISTS\s+)?("[^"]+"|\w+)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	sqlRenameRe     = regexp.MustCompile(`(?is)^RENAME\s+(?:(?:COLUMN\s+)?("[^"]+"|\w+)\s+)?TO\s+("[^"]+"|\w+)$`)
+	sqlIndexSkipRe  = regexp.MustCompile(`(?i)\bUSING\s+(?:gin|gist|brin|hash|spgist)\b|_ops\b|\bINCLUDE\s*\(`)
+	sqlIndexDropRe  = regexp.MustCompile(`(?i)\s+CONCURRENTLY\b|\s+USING\s+btree\b|\bON\s+ONLY\b`)
+	sqlIndexRe      = regexp.MustCompile(`(?is)^CREATE\s+(UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?("[^"]+"|\w+)\s+ON\s+(?:ONLY\s+)?("[^"]+"|[\w.]+)\s*(?:USING\s+btree\s*)?\(`)
+	sqlDropIndexRe  = regexp.MustCompile(`(?is)^DROP\s+INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?("[^"]+"|[\w.]+)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	sqlTableHeadRe  = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?("[^"]+"|[\w.]+)\s*$`)
+	sqlKeyColumnRe  = regexp.MustCompile(`(?i)\b(?:UNIQUE|PRIMARY\s+KEY|REFERENCES)\b`)
+	sqlKeyListRe    = regexp.MustCompile(`(?i)\b(?:PRIMARY\s+KEY|UNIQUE|FOREIGN\s+KEY)\s*\(([^)]*)\)`)
+	sqlReferencesRe = regexp.MustCompile(`(?i)\bREFERENCES\s+("[^"]+"|\[[^\]]+\]|` + "`[^`]+`" + `|[\w.]+)\s*(?:\(([^)]*)\))?`)
+	sqlInlineRefRe  = regexp.MustCompile(`(?i)\s+REFERENCES\s+(?:` + "`[^`]+`" + `|[\w.]+)\s*(?:\([^)]*\))?(?:\s+ON\s+(?:DELETE|UPDATE)\s+(?:CASCADE|SET\s+NULL|SET\s+DEFAULT|RESTRICT|NO\s+ACTION))*`)
+	sqlConstraintRe = regexp.MustCompile(`(?i)^(?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK)\b`)
+	sqlRefActionRe  = regexp.MustCompile(`(?i)\s+ON\s+(?:DELETE|UPDATE)\s+(?:CASCADE|SET\s+NULL|SET\s+DEFAULT)`)
+	sqlRestrictRe   = regexp.MustCompile(`(?i)\b(ON\s+(?:DELETE|UPDATE))\s+RESTRICT\b`)
+	sqlDefaultRe    = regexp.MustCompile(`(?i)\bDEFAULT\s+`)
+	sqlColumnTailRe = regexp.MustCompile(`(?i)\s+(?:NOT\s+NULL|NULL|CHECK|REFERENCES|UNIQUE|PRIMARY\s+KEY|CONSTRAINT|COLLATE)\b`)
+	sqlNumberRe     = regexp.MustCompile(`^[+-]?\d+(?:\.\d+)?$`)
+	sqlIntervalRe   = regexp.MustCompile(`(?i)^'\s*(\d+)\s+(second|minute|hour|day|week|month|year)s?\s*'$`)
+	sqlOnConflictRe = regexp.MustCompile(`(?is)\s+ON\s+CONFLICT\s*(?:\(([^)]*)\))?.*?\bDO\s+(NOTHING|UPDATE\s+SET)\b`)
+	sqlInsertIntoRe = regexp.MustCompile(`(?is)^INSERT\s+INTO\s+(\S+?)\s*\(`)
+	sqlValuesRe     = regexp.MustCompile(`(?is)^\s*VALUES\s*\(`)
+	sqlReturningRe  = regexp.MustCompile(`(?is)\s+RETURNING\b.*$`)
+	sqlExcludedRe   = regexp.MustCompile(`(?i)\bEXCLUDED\.("[^"]+"|\[[^\]]+\]|` + "`[^`]+`" + `|\w+)`)
+	sqlInsertRe     = regexp.MustCompile(`(?i)^INSERT\b`)
+	sqlLimitRe      = regexp.MustCompile(`(?i)\s+LIMIT\s+(\d+)`)
+	sqlSelectRe     = regexp.MustCompile(`(?i)\bSELECT(?:\s+DISTINCT)?\b`)
+	sqlBoolRe       = regexp.MustCompile(`(?i)\b(TRUE|FALSE)\b`)
+	sqlCurDateRe    = regexp.MustCompile(`(?i)\bCURRENT_DATE\b`)
+	sqlQuotedRe     = regexp.MustCompile(`"([^"]+)"`)
+	sqlMySQLWordRe  = regexp.MustCompile("`?\\b(condition|rank|usage|groups|window)\\b`?")
+	sqlDropTailRe   = regexp.MustCompile(`(?i)\s+(?:CASCADE|RESTRICT)\s*$`)
+	sqlUnloggedRe   = regexp.MustCompile(`(?i)\s+UNLOGGED\b`)
+	sqlILikeRe      = regexp.MustCompile(`(?i)\bILIKE\b`)
+	sqlDollarTagRe  = regexp.MustCompile(`^\$\w*\$`)
+)
+
+// TranslateMigrations returns the migrations of dialect, one per version:
+// the files written for the dialect as they are and the others translated
+// from Postgres. The notes list the statements skipped by the translation.
+// A translated migration keeps its source script in Source, which its
+// checksum covers.
+func TranslateMigrations(migrations []Migration, dialect string) ([]Migration, []string, error) {
+	dialect = NormalizeDialect(dialect)
+	selected := SelectMigrations(migrations, dialect)
+	switch dialect {
+	case DialectPostgres:
+		return selected, nil, nil
+	case DialectSQLite, DialectMySQL, DialectSQLServer:
+	default:
+		return nil, nil, fmt.Errorf("❌ Tradução de migrations para '%s' não suportada", dialect)
+	}
+
+	tr := newMigrationTranslator(dialect)
+	for _, m := range selected {
+		if m.Dialect != dialect {
+			tr.scanKeys(m.Up)
+		}
+	}
+	var notes []string
+	out := make([]Migration, 0, len(selected))
+	for _, m := range selected {
+		if m.Dialect == dialect {
+			out = append(out, m)
+			continue
+		}
+		name := fmt.Sprintf("%d_%s", m.Version, m.Name)
+		up, skipped := tr.translate(name, m.Up, false)
+		for _, s := range skipped {
+			notes = append(notes, name+": "+s)
+		}
+		if m.Source == "" {
+			m.Source = m.Up
+		}
+		m.Up = up
+		m.Down, _ = tr.translate(name, m.Down, true)
+		m.Dialect = dialect
+		out = append(out, m)
+	}
+	return out, notes, nil
+}
+
+// dialectMigrations translates migrations for dialect (see
+// TranslateMigrations), warning about the skipped statements: the migrations
+// are recorded as applied without them.
+func dialectMigrations(migrations []Migration, dialect string) ([]Migration, error) {
+	out, notes, err := TranslateMigrations(migrations, dialect)
+	if err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		gl.Log("warn", fmt.Sprintf("Migration %s", note))
+	}
+	return out, nil
+}
+
+// migrationTranslator translates Postgres scripts to a dialect, remembering
+// the schema built by the previous scripts.
+type migrationTranslator struct {
+	dialect string
+	// enums maps the enum types to their values
+	enums map[string][]string
+	// keyed holds the columns (table.column) of the keys and indexes
+	keyed map[string]bool
+	// tables and columns (table.column, with their translated type) created so far
+	tables  map[string]bool
+	columns map[string]string
+	// indexes maps the indexes to their table and columns
+	indexes map[string]sqlIndex
+	// cascades maps the tables to the tables deleted with them (SQL Server)
+	cascades map[string][]string
+
+	// lits are the literals of the statement being translated, lossy tells
+	// that a part of it was dropped and down that it belongs to a down script,
+	// which leaves the schema above untouched
+	lits  []string
+	lossy bool
+	down  bool
+}
+
+// sqlIndex is an index created by the translated scripts.
+type sqlIndex struct {
+	table   string
+	columns []string
+}
+
+func newMigrationTranslator(dialect string) *migrationTranslator {
+	return &migrationTranslator{
+		dialect:  dialect,
+		enums:    map[string][]string{},
+		keyed:    map[string]bool{},
+		tables:   map[string]bool{},
+		columns:  map[string]string{},
+		indexes:  map[string]sqlIndex{},
+		cascades: map[string][]string{},
+	}
+}
+
+// translate returns the script translated to the dialect and its skipped
+// statements.
+func (t *migrationTranslator) translate(name, script string, down bool) (string, []string) {
+	if strings.TrimSpace(script) == "" {
+		return "", nil
+	}
+	t.down = down
+	terminator := ";\n\n"
+	if t.dialect == DialectSQLServer {
+		terminator = ";\nGO\n\n"
+	}
+	var b strings.Builder
+	var skipped []string
+	fmt.Fprintf(&b, "-- %s translated from postgres\n\n", name)
+	for _, stmt := range SplitSQLStatements(script) {
+		masked, lits := maskSQL(stmt.SQL)
+		if masked == "" {
+			continue
+		}
+		t.lits, t.lossy = lits, false
+		out, complete := t.statement(masked)
+		if !complete || t.lossy {
+			how := "skipped"
+			if len(out) > 0 {
+				how = "partially translated"
+			}
+			skipped = append(skipped, fmt.Sprintf("line %d %s: %s", stmt.Line, how, sqlSummary(unmaskSQL(masked, lits))))
+		}
+		for _, s := range out {
+			b.WriteString(unmaskSQL(s, t.lits))
+			b.WriteString(terminator)
+		}
+	}
+	return b.String(), skipped
+}
+
+// scanKeys marks the columns of the keys, foreign keys and indexes of a
+// script, before the translation of their table.
+func (t *migrationTranslator) scanKeys(script string) {
+	if t.dialect == DialectSQLite {
+		return
+	}
+	for _, stmt := range SplitSQLStatements(script) {
+		s, _ := maskSQL(stmt.SQL)
+		if table, items, ok := splitCreateTable(s); ok {
+			for _, item := range items {
+				t.scanItem(table, item)
+			}
+		} else if m := sqlAlterRe.FindStringSubmatch(s); m != nil {
+			for _, action := range splitTopLevel(m[3]) {
+				if add := sqlAddColumnRe.FindStringSubmatch(action); add != nil {
+					t.scanItem(sqlIdent(m[2]), add[2])
+				}
+			}
+		} else if m := sqlIndexRe.FindStringSubmatch(s); m != nil {
+			open := len(m[0]) - 1
+			if end := matchingParen(s, open); end > 0 {
+				for _, col := range splitTopLevel(s[open+1 : end]) {
+					t.keyed[sqlIdent(m[3])+"."+sqlIdent(strings.Fields(col)[0])] = true
+				}
+			}
+		}
+	}
+}
+
+// scanItem marks the key columns of a column definition or table constraint.
+func (t *migrationTranslator) scanItem(table, item string) {
+	if ref := sqlReferencesRe.FindStringSubmatch(item); ref != nil {
+		cols := "id"
+		if ref[2] != "" {
+			cols = ref[2]
+		}
+		for _, col := range strings.Split(cols, ",") {
+			t.keyed[sqlIdent(ref[1])+"."+sqlIdent(col)] = true
+		}
+	}
+	if isTableConstraint(item) {
+		for _, m := range sqlKeyListRe.FindAllStringSubmatch(item, -1) {
+			for _, col := range strings.Split(m[1], ",") {
+				t.keyed[table+"."+sqlIdent(col)] = true
+			}
+		}
+		return
+	}
+	if m := sqlColumnRe.FindStringSubmatch(strings.TrimSpace(item)); m != nil && sqlKeyColumnRe.MatchString(m[3]) {
+		t.keyed[table+"."+sqlIdent(m[1])] = true
+	}
+}
+
+// statement translates a masked statement; complete is false when the
+// statement (or some of its parts) has no equivalent in the dialect.
+func (t *migrationTranslator) statement(s string) (out []string, complete bool) {
+	words := strings.Fields(strings.ToUpper(s))
+	first := func(kw ...string) bool {
+		if len(words) < len(kw) {
+			return false
+		}
+		for i, w := range kw {
+			if words[i] != w {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case first("CREATE", "TYPE"):
+		if m := sqlEnumRe.FindStringSubmatch(s); m != nil {
+			var values []string
+			if open := strings.IndexByte(s[len(m[0]):], '('); open >= 0 {
+				open += len(m[0])
+				if end := matchingParen(s, open); end > 0 {
+					for _, v := range splitTopLevel(s[open+1 : end]) {
+						values = append(values, unmaskSQL(v, t.lits))
+					}
+				}
+			}
+			t.enums[strings.ToLower(m[1])] = values
+		}
+		return nil, false
+	case first("CREATE", "TABLE"), first("CREATE", "UNLOGGED", "TABLE"):
+		return t.createTable(s)
+	case first("CREATE", "INDEX"), first("CREATE", "UNIQUE", "INDEX"):
+		if sqlIndexSkipRe.MatchString(s) {
+			return nil, false
+		}
+		if t.dialect == DialectSQLite {
+			return []string{t.expr(sqlIndexDropRe.ReplaceAllString(s, ""))}, true
+		}
+		return t.createIndex(s)
+	case first("ALTER", "TABLE"):
+		return t.alterTable(s)
+	case first("DROP", "INDEX"):
+		if t.dialect == DialectSQLite {
+			return []string{t.expr(sqlDropTailRe.ReplaceAllString(s, ""))}, true
+		}
+		return t.dropIndex(s)
+	case first("DROP", "TABLE"), first("DROP", "VIEW"):
+		return []string{t.expr(sqlDropTailRe.ReplaceAllString(s, ""))}, true
+	case first("INSERT"):
+		return t.insert(s)
+	case first("CREATE", "VIEW"), first("UPDATE"), first("DELETE"), first("SELECT"), first("WITH"):
+		return []string{t.top(t.expr(s))}, true
+	default:
+		// extensions, roles, grants, functions, triggers, DO blocks,
+		// transaction control...
+		return nil, false
+	}
+}
+
+// createTable translates the columns of a CREATE TABLE.
+func (t *migrationTranslator) createTable(s string) ([]string, bool) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return nil, false
+	}
+	end := matchingParen(s, open)
+	if end < 0 {
+		return nil, false
+	}
+	head := sqlUnloggedRe.ReplaceAllString(s[:open], "")
+	table := ""
+	if m := sqlTableHeadRe.FindStringSubmatch(strings.TrimSpace(head)); m != nil {
+		table = sqlIdent(m[2])
+	}
+	complete := strings.TrimSpace(s[end+1:]) == ""
+	var items []string
+	for _, item := range splitTopLevel(s[open+1 : end]) {
+		upper := strings.ToUpper(item)
+		switch {
+		case strings.HasPrefix(upper, "EXCLUDE"), strings.HasPrefix(upper, "LIKE "):
+			complete = false
+		case isTableConstraint(item):
+			items = append(items, t.constraint(table, item))
+		default:
+			def, fk := t.foreignKey(t.column(table, item))
+			items = append(items, def)
+			if fk != "" {
+				items = append(items, fk)
+			}
+		}
+	}
+	if !t.down {
+		t.tables[table] = true
+	}
+	body := "(\n    " + strings.Join(items, ",\n    ") + "\n)"
+	if t.dialect == DialectSQLServer {
+		m := sqlTableHeadRe.FindStringSubmatch(strings.TrimSpace(head))
+		if m != nil && m[1] != "" {
+			return []string{fmt.Sprintf("IF OBJECT_ID(N'%s', N'U') IS NULL\nCREATE TABLE %s ", table, t.expr(m[2])) + body}, complete
+		}
+	}
+	return []string{t.expr(head) + body}, complete
+}
+
+// createIndex translates a btree CREATE INDEX for MySQL and SQL Server. The
+// indexes on expressions and on the columns these dialects cannot index are
+// skipped, like the partial indexes on MySQL. As in Postgres, the index
+// names are unique: a second index of the same name is ignored.
+func (t *migrationTranslator) createIndex(s string) ([]string, bool) {
+	m := sqlIndexRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	open := len(m[0]) - 1
+	end := matchingParen(s, open)
+	if end < 0 {
+		return nil, false
+	}
+	name, table := sqlIdent(m[2]), sqlIdent(m[3])
+	if _, ok := t.indexes[name]; ok {
+		return nil, true
+	}
+	where := strings.TrimSpace(s[end+1:])
+	if where != "" && t.dialect == DialectMySQL {
+		return nil, false
+	}
+	var cols, names []string
+	for _, col := range splitTopLevel(s[open+1 : end]) {
+		if strings.ContainsAny(col, "()") || !t.indexable(t.columns[table+"."+sqlIdent(strings.Fields(col)[0])]) {
+			return nil, false
+		}
+		cols = append(cols, t.expr(col))
+		names = append(names, sqlIdent(strings.Fields(col)[0]))
+	}
+	if !t.down {
+		t.indexes[name] = sqlIndex{table: table, columns: names}
+	}
+
+	unique := ""
+	if m[1] != "" {
+		unique = "UNIQUE "
+	}
+	out := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, t.expr(m[2]), t.expr(m[3]), strings.Join(cols, ", "))
+	if t.dialect == DialectSQLServer {
+		if where == "" && unique != "" {
+			// unique indexes accept several NULLs in Postgres, not in SQL Server
+			var notNull []string
+			for _, col := range names {
+				notNull = append(notNull, col+" IS NOT NULL")
+			}
+			where = "WHERE " + strings.Join(notNull, " AND ")
+		}
+		if where != "" {
+			out += " " + t.expr(where)
+		}
+		out = fmt.Sprintf("IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'%s' AND object_id = OBJECT_ID(N'%s'))\n", name, table) + out
+	}
+	return []string{out}, true
+}
+
+// dropIndex translates a DROP INDEX, which names the table of the index on
+// MySQL and SQL Server.
+func (t *migrationTranslator) dropIndex(s string) ([]string, bool) {
+	m := sqlDropIndexRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	name := sqlIdent(m[1])
+	idx, ok := t.indexes[name]
+	if !ok {
+		return nil, false
+	}
+	return []string{t.dropIndexSQL(name, idx)}, true
+}
+
+// dropIndexSQL drops the index name of idx.table.
+func (t *migrationTranslator) dropIndexSQL(name string, idx sqlIndex) string {
+	if !t.down {
+		delete(t.indexes, name)
+	}
+	if t.dialect == DialectSQLServer {
+		return fmt.Sprintf("DROP INDEX IF EXISTS %s ON %s", name, idx.table)
+	}
+	return fmt.Sprintf("DROP INDEX %s ON %s", name, idx.table)
+}
+
+// alterTable keeps the actions of an ALTER TABLE that the dialect supports,
+// one statement per action.
+func (t *migrationTranslator) alterTable(s string) ([]string, bool) {
+	m := sqlAlterRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	key := sqlIdent(m[2])
+	table := sqlSchemaRe.ReplaceAllString(m[2], "")
+	if t.dialect != DialectSQLite {
+		if m[1] != "" && !t.tables[key] {
+			// ALTER TABLE IF EXISTS of a table created out of the migrations
+			return nil, false
+		}
+		table = t.expr(table)
+	}
+	alter := "ALTER TABLE " + table + " "
+	var out []string
+	complete := true
+	for _, action := range splitTopLevel(m[3]) {
+		upper := strings.ToUpper(action)
+		switch {
+		case strings.HasPrefix(upper, "ADD") && isTableConstraint(action[3:]):
+			if t.dialect == DialectSQLite {
+				complete = false
+				continue
+			}
+			out = append(out, alter+"ADD "+t.constraint(key, strings.TrimSpace(action[3:])))
+		case strings.HasPrefix(upper, "ADD"):
+			add := sqlAddColumnRe.FindStringSubmatch(action)
+			if col := sqlColumnRe.FindStringSubmatch(strings.TrimSpace(add[2])); col != nil && add[1] != "" && t.dialect != DialectSQLite {
+				if _, ok := t.columns[key+"."+sqlIdent(col[1])]; ok {
+					// ADD COLUMN IF NOT EXISTS of an existing column
+					continue
+				}
+			}
+			def, fk := t.foreignKey(t.column(key, add[2]))
+			switch t.dialect {
+			case DialectSQLServer:
+				out = append(out, alter+"ADD "+def)
+			default:
+				out = append(out, alter+"ADD COLUMN "+def)
+			}
+			if fk != "" {
+				out = append(out, alter+"ADD "+fk)
+			}
+		case strings.HasPrefix(upper, "DROP CONSTRAINT"):
+			switch t.dialect {
+			case DialectSQLite:
+				complete = false
+			case DialectMySQL:
+				out = append(out, alter+t.expr(strings.Replace(action, " IF EXISTS", "", 1)))
+			default:
+				out = append(out, alter+t.expr(action))
+			}
+		case strings.HasPrefix(upper, "DROP") && sqlDropColRe.MatchString(action):
+			out = append(out, t.dropColumn(key, table, sqlDropColRe.FindStringSubmatch(action))...)
+		case strings.HasPrefix(upper, "RENAME"):
+			stmt, ok := t.rename(key, table, action)
+			if !ok {
+				complete = false
+				continue
+			}
+			out = append(out, stmt)
+		default:
+			complete = false
+		}
+	}
+	return out, complete
+}
+
+// dropColumn drops a column; on SQL Server its indexes and its default
+// constraint are dropped first, as they would block the DROP COLUMN.
+func (t *migrationTranslator) dropColumn(key, table string, m []string) []string {
+	if t.dialect == DialectSQLite {
+		return []string{"ALTER TABLE " + table + " DROP COLUMN " + m[2]}
+	}
+	col := sqlIdent(m[2])
+	if _, ok := t.columns[key+"."+col]; !ok && m[1] != "" {
+		// DROP COLUMN IF EXISTS of a column created out of the migrations
+		t.lossy = true
+		return nil
+	}
+	var out []string
+	if t.dialect == DialectSQLServer {
+		names := make([]string, 0, len(t.indexes))
+		for name := range t.indexes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if idx := t.indexes[name]; idx.table == key && slices.Contains(idx.columns, col) {
+				out = append(out, t.dropIndexSQL(name, idx))
+			}
+		}
+		out = append(out, fmt.Sprintf(`DECLARE @df sysname = (SELECT d.name FROM sys.default_constraints d
+    JOIN sys.columns c ON c.object_id = d.parent_object_id AND c.column_id = d.parent_column_id
+    WHERE d.parent_object_id = OBJECT_ID(N'%s') AND c.name = N'%s');
+IF @df IS NOT NULL EXEC (N'ALTER TABLE %s DROP CONSTRAINT ' + QUOTENAME(@df))`, key, col, table))
+	}
+	if !t.down {
+		delete(t.columns, key+"."+col)
+	}
+	return append(out, "ALTER TABLE "+table+" DROP COLUMN "+t.expr(m[2]))
+}
+
+// rename translates the RENAME actions of an ALTER TABLE (sp_rename on SQL
+// Server).
+func (t *migrationTranslator) rename(key, table, action string) (string, bool) {
+	m := sqlRenameRe.FindStringSubmatch(action)
+	if m != nil && m[1] != "" && !t.down {
+		if typ, ok := t.columns[key+"."+sqlIdent(m[1])]; ok {
+			delete(t.columns, key+"."+sqlIdent(m[1]))
+			t.columns[key+"."+sqlIdent(m[2])] = typ
+		}
+	}
+	switch t.dialect {
+	case DialectSQLite:
+		return "ALTER TABLE " + table + " " + action, true
+	case DialectMySQL:
+		return "ALTER TABLE " + table + " " + t.expr(action), true
+	}
+	if m == nil {
+		return "", false
+	}
+	if m[1] == "" {
+		return fmt.Sprintf("EXEC sp_rename N'%s', N'%s'", key, sqlIdent(m[2])), true
+	}
+	return fmt.Sprintf("EXEC sp_rename N'%s.%s', N'%s', N'COLUMN'", key, sqlIdent(m[1]), sqlIdent(m[2])), true
+}
+
+// column translates a column definition of table.
+func (t *migrationTranslator) column(table, def string) string {
+	m := sqlColumnRe.FindStringSubmatch(strings.TrimSpace(def))
+	if m == nil {
+		return t.expr(def)
+	}
+	if t.dialect == DialectSQLite {
+		rest := sqlIdentityRe.ReplaceAllString(m[3], "")
+		return m[1] + " " + t.sqliteType(m[2]) + t.expr(rest)
+	}
+
+	key := table + "." + sqlIdent(m[1])
+	typ := t.columnType(m[2], t.keyed[key])
+	rest := m[3]
+	if sqlIdentityRe.MatchString(rest) {
+		rest = sqlIdentityRe.ReplaceAllString(rest, "")
+		if t.dialect == DialectMySQL {
+			typ += " AUTO_INCREMENT"
+		} else {
+			typ += " IDENTITY(1,1)"
+		}
+	}
+	if !t.down {
+		t.columns[key] = typ
+	}
+	if head, value, tail, ok := splitDefault(rest); ok {
+		rest = t.expr(head) + t.defaultValue(m[2], typ, value) + t.expr(tail)
+	} else {
+		rest = t.expr(rest)
+	}
+	return t.expr(m[1]) + " " + typ + t.references(table, rest)
+}
+
+// constraint translates a table constraint of table.
+func (t *migrationTranslator) constraint(table, item string) string {
+	if t.dialect == DialectSQLite {
+		return t.expr(item)
+	}
+	return t.references(table, t.expr(item))
+}
+
+// references handles the foreign key of table in a translated clause: SQL
+// Server has no RESTRICT action and rejects the ON DELETE/UPDATE actions
+// giving a table several cascade paths (or a cycle), which are dropped.
+func (t *migrationTranslator) references(table, clause string) string {
+	if t.dialect != DialectSQLServer {
+		return clause
+	}
+	clause = sqlRestrictRe.ReplaceAllString(clause, "$1 NO ACTION")
+	ref := sqlReferencesRe.FindStringSubmatch(clause)
+	if ref == nil || !sqlRefActionRe.MatchString(clause) {
+		return clause
+	}
+	parent := sqlIdent(ref[1])
+	if t.cascadeAllowed(parent, table) {
+		if !t.down {
+			t.cascades[parent] = append(t.cascades[parent], table)
+		}
+		return clause
+	}
+	t.lossy = true
+	return sqlRefActionRe.ReplaceAllString(clause, "")
+}
+
+// foreignKey splits the REFERENCES of a translated column definition into a
+// FOREIGN KEY constraint on MySQL, which ignores the inline references.
+func (t *migrationTranslator) foreignKey(def string) (string, string) {
+	if t.dialect != DialectMySQL {
+		return def, ""
+	}
+	loc := sqlInlineRefRe.FindStringIndex(def)
+	if loc == nil {
+		return def, ""
+	}
+	name := strings.Fields(def)[0]
+	return def[:loc[0]] + def[loc[1]:], "FOREIGN KEY (" + name + ") " + strings.TrimSpace(def[loc[0]:loc[1]])
+}
+
+// cascadeAllowed tells whether deleting parent may cascade to child without
+// giving a table two cascade paths, or making a cycle.
+func (t *migrationTranslator) cascadeAllowed(parent, child string) bool {
+	from := t.cascadeReach(parent, true)
+	for table := range t.cascadeReach(child, false) {
+		for ancestor := range t.cascadeReach(table, true) {
+			if from[ancestor] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cascadeReach returns table with the tables whose deletion cascades to it
+// (up) or the tables its deletion cascades to.
+func (t *migrationTranslator) cascadeReach(table string, up bool) map[string]bool {
+	seen := map[string]bool{table: true}
+	queue := []string{table}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		next := t.cascades[cur]
+		if up {
+			next = nil
+			for parent, children := range t.cascades {
+				if slices.Contains(children, cur) {
+					next = append(next, parent)
+				}
+			}
+		}
+		for _, n := range next {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return seen
+}
+
+// defaultValue translates the DEFAULT of a column of type typ (pgType in
+// Postgres). MySQL takes the expressions, and the literals of the TEXT and
+// JSON columns, in parentheses.
+func (t *migrationTranslator) defaultValue(pgType, typ, value string) string {
+	if strings.Contains(pgType, "[") {
+		if lit, ok := t.pgArray(value); ok {
+			value = lit
+		}
+	}
+	value = t.expr(value)
+	if t.dialect != DialectMySQL {
+		return value
+	}
+	upper := strings.ToUpper(value)
+	literal := upper == "NULL" || upper == "TRUE" || upper == "FALSE" || sqlNumberRe.MatchString(value) ||
+		sqlLiteralRe.FindString(value) == value || (upper == "NOW(6)" && strings.HasPrefix(typ, "DATETIME"))
+	blob := typ == "TEXT" || typ == "JSON" || typ == "LONGBLOB"
+	if upper == "NULL" || (literal && !blob) || (strings.HasPrefix(value, "(") && matchingParen(value, 0) == len(value)-1) {
+		return value
+	}
+	return "(" + value + ")"
+}
+
+// pgArray converts a Postgres array literal ('{a,b}') to a JSON array.
+func (t *migrationTranslator) pgArray(value string) (string, bool) {
+	m := sqlLiteralRe.FindStringSubmatch(value)
+	if m == nil || m[0] != value {
+		return "", false
+	}
+	i, _ := strconv.Atoi(m[1])
+	lit := t.lits[i]
+	if !strings.HasPrefix(lit, "'{") || !strings.HasSuffix(lit, "}'") {
+		return "", false
+	}
+	items := []any{}
+	if inner := strings.TrimSpace(lit[2 : len(lit)-2]); inner != "" {
+		for _, item := range strings.Split(inner, ",") {
+			items = append(items, strings.Trim(strings.TrimSpace(item), `"`))
+		}
+	}
+	return t.jsonLiteral(items), true
+}
+
+// jsonArray translates the elements of ARRAY[...] to a JSON array literal
+// (SQL Server), or NULL when an element is not a literal.
+func (t *migrationTranslator) jsonArray(elems string) string {
+	items := []any{}
+	for _, e := range splitTopLevel(elems) {
+		m := sqlLiteralRe.FindStringSubmatch(e)
+		switch {
+		case m != nil && m[0] == e && strings.HasPrefix(t.lits[atoi(m[1])], "'"):
+			lit := t.lits[atoi(m[1])]
+			items = append(items, strings.ReplaceAll(lit[1:len(lit)-1], "''", "'"))
+		case sqlNumberRe.MatchString(e):
+			items = append(items, json.Number(e))
+		default:
+			t.lossy = true
+			return "NULL"
+		}
+	}
+	return t.jsonLiteral(items)
+}
+
+// jsonLiteral adds a literal holding the JSON array of items to the
+// statement and returns its placeholder.
+func (t *migrationTranslator) jsonLiteral(items []any) string {
+	js, _ := json.Marshal(items)
+	prefix := ""
+	if t.dialect == DialectSQLServer {
+		prefix = "N"
+	}
+	t.lits = append(t.lits, prefix+"'"+strings.ReplaceAll(string(js), "'", "''")+"'")
+	return fmt.Sprintf("\x01%d\x01", len(t.lits)-1)
+}
+
+// insert translates an INSERT. ON CONFLICT becomes INSERT IGNORE or ON
+// DUPLICATE KEY UPDATE on MySQL, and a conditional INSERT or UPDATE on SQL
+// Server (which loses the ON CONFLICT DO NOTHING of the multi-row inserts).
+// RETURNING is dropped on both.
+func (t *migrationTranslator) insert(s string) ([]string, bool) {
+	s = t.expr(s)
+	if t.dialect == DialectSQLite {
+		return []string{s}, true
+	}
+	if loc := sqlReturningRe.FindStringIndex(s); loc != nil {
+		s = s[:loc[0]]
+		t.lossy = true
+	}
+	if m := sqlOnConflictRe.FindStringSubmatchIndex(s); m != nil {
+		nothing := strings.EqualFold(s[m[4]:m[5]], "NOTHING")
+		target := ""
+		if m[2] >= 0 {
+			target = s[m[2]:m[3]]
+		}
+		base, rest := s[:m[0]], s[m[1]:]
+		switch {
+		case t.dialect == DialectMySQL && nothing:
+			s = sqlInsertRe.ReplaceAllString(base, "INSERT IGNORE") + rest
+		case t.dialect == DialectMySQL:
+			s = base + " ON DUPLICATE KEY UPDATE" + sqlExcludedRe.ReplaceAllString(rest, "VALUES($1)")
+		default:
+			upsert, ok := sqlServerUpsert(base, target, rest)
+			switch {
+			case ok:
+				s = upsert
+			case nothing:
+				s = base
+				t.lossy = true
+			default:
+				return nil, false
+			}
+		}
+	}
+	return []string{t.top(s)}, true
+}
+
+// sqlServerUpsert rewrites the single-row insert of an ON CONFLICT (target)
+// DO UPDATE SET set (DO NOTHING when set is empty) for SQL Server.
+func sqlServerUpsert(insert, target, set string) (string, bool) {
+	m := sqlInsertIntoRe.FindStringSubmatch(insert)
+	if m == nil || strings.TrimSpace(target) == "" {
+		return "", false
+	}
+	open := len(m[0]) - 1
+	end := matchingParen(insert, open)
+	if end < 0 {
+		return "", false
+	}
+	cols := splitTopLevel(insert[open+1 : end])
+	rest := insert[end+1:]
+	v := sqlValuesRe.FindStringIndex(rest)
+	if v == nil {
+		return "", false
+	}
+	vend := matchingParen(rest, v[1]-1)
+	if vend < 0 || strings.TrimSpace(rest[vend+1:]) != "" {
+		return "", false
+	}
+	vals := splitTopLevel(rest[v[1]:vend])
+	if len(vals) != len(cols) {
+		return "", false
+	}
+	values := make(map[string]string, len(cols))
+	for i, col := range cols {
+		values[sqlIdent(col)] = vals[i]
+	}
+	var where []string
+	for _, col := range strings.Split(target, ",") {
+		val, ok := values[sqlIdent(col)]
+		if !ok {
+			return "", false
+		}
+		where = append(where, strings.TrimSpace(col)+" = "+val)
+	}
+	cond := " WHERE " + strings.Join(where, " AND ")
+	if strings.TrimSpace(set) == "" {
+		return "IF NOT EXISTS (SELECT 1 FROM " + m[1] + cond + ")\n" + insert, true
+	}
+	set = sqlExcludedRe.ReplaceAllStringFunc(set, func(x string) string {
+		if val, ok := values[sqlIdent(sqlExcludedRe.FindStringSubmatch(x)[1])]; ok {
+			return val
+		}
+		return "NULL"
+	})
+	return "IF EXISTS (SELECT 1 FROM " + m[1] + cond + ")\nUPDATE " + m[1] + " SET" + set + cond + "\nELSE\n" + insert, true
+}
+
+// top turns the LIMIT n of the SELECTs into SELECT TOP n on SQL Server.
+func (t *migrationTranslator) top(s string) string {
+	if t.dialect != DialectSQLServer {
+		return s
+	}
+	for {
+		loc := sqlLimitRe.FindStringSubmatchIndex(s)
+		if loc == nil {
+			return s
+		}
+		// the SELECT of the parentheses enclosing the LIMIT
+		start := 0
+		for i, depth := loc[0]-1, 0; i >= 0; i-- {
+			if s[i] == ')' {
+				depth++
+			} else if s[i] == '(' {
+				if depth == 0 {
+					start = i + 1
+					break
+				}
+				depth--
+			}
+		}
+		sel := sqlSelectRe.FindStringIndex(s[start:loc[0]])
+		if sel == nil {
+			return s
+		}
+		at := start + sel[1]
+		s = s[:at] + " TOP " + s[loc[2]:loc[3]] + s[at:loc[0]] + s[loc[1]:]
+	}
+}
+
+// sqliteType maps a Postgres column type to SQLite.
+func (t *migrationTranslator) sqliteType(typ string) string {
+	base := strings.ToLower(strings.Join(strings.Fields(typ), " "))
+	if strings.Contains(base, "[") {
+		return "TEXT"
+	}
+	name := strings.TrimSpace(strings.SplitN(base, "(", 2)[0])
+	if _, ok := t.enums[name]; ok {
+		return "TEXT"
+	}
+	switch {
+	case name == "serial", name == "bigserial", name == "smallserial":
+		return "INTEGER"
+	case name == "citext":
+		return "TEXT COLLATE NOCASE"
+	case name == "bytea":
+		return "BLOB"
+	case strings.HasPrefix(name, "timestamp"), strings.HasSuffix(name, "time zone"):
+		return "DATETIME"
+	}
+	switch name {
+	case "uuid", "json", "jsonb", "hstore", "tsvector", "tsquery", "inet", "cidr", "macaddr", "interval", "xml", "money":
+		return "TEXT"
+	}
+	return typ
+}
+
+// columnType maps a Postgres column type to MySQL or SQL Server; keyed tells
+// that the column belongs to a key or an index.
+func (t *migrationTranslator) columnType(typ string, keyed bool) string {
+	mysql := t.dialect == DialectMySQL
+	pick := func(my, ms string) string {
+		if mysql {
+			return my
+		}
+		return ms
+	}
+	base := strings.ToLower(strings.Join(strings.Fields(typ), " "))
+	if strings.Contains(base, "[") {
+		return pick("JSON", "NVARCHAR(MAX)")
+	}
+	name := strings.TrimSpace(strings.SplitN(base, "(", 2)[0])
+	size := ""
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		size = strings.ReplaceAll(base[i:], " ", "")
+	}
+	if values, ok := t.enums[name]; ok {
+		if mysql {
+			return "ENUM(" + strings.Join(values, ", ") + ")"
+		}
+		longest := 1
+		for _, v := range values {
+			longest = max(longest, len(v)-2)
+		}
+		return fmt.Sprintf("NVARCHAR(%d)", longest)
+	}
+
+	switch name {
+	case "serial", "serial4":
+		return pick("INT AUTO_INCREMENT", "INT IDENTITY(1,1)")
+	case "bigserial", "serial8":
+		return pick("BIGINT AUTO_INCREMENT", "BIGINT IDENTITY(1,1)")
+	case "smallserial", "serial2":
+		return pick("SMALLINT AUTO_INCREMENT", "SMALLINT IDENTITY(1,1)")
+	case "uuid":
+		return pick("CHAR(36)", "UNIQUEIDENTIFIER")
+	case "json", "jsonb", "hstore":
+		return pick("JSON", "NVARCHAR(MAX)")
+	case "text", "tsvector", "tsquery", "xml":
+		if keyed {
+			return pick(fmt.Sprintf("VARCHAR(%d)", keyTextLength), fmt.Sprintf("NVARCHAR(%d)", keyTextLength))
+		}
+		return pick("TEXT", "NVARCHAR(MAX)")
+	case "citext":
+		return pick(fmt.Sprintf("VARCHAR(%d)", keyTextLength), fmt.Sprintf("NVARCHAR(%d)", keyTextLength))
+	case "varchar", "character varying":
+		if size == "" {
+			return pick(fmt.Sprintf("VARCHAR(%d)", keyTextLength), fmt.Sprintf("NVARCHAR(%d)", keyTextLength))
+		}
+		if n, _ := strconv.Atoi(strings.Trim(size, "()")); n > 4000 && !mysql {
+			return "NVARCHAR(MAX)"
+		}
+		return pick("VARCHAR", "NVARCHAR") + size
+	case "char", "character":
+		return pick("CHAR", "NCHAR") + size
+	case "bytea":
+		return pick("LONGBLOB", "VARBINARY(MAX)")
+	case "inet", "cidr", "macaddr":
+		return pick("VARCHAR(45)", "NVARCHAR(45)")
+	case "interval":
+		return pick("VARCHAR(64)", "NVARCHAR(64)")
+	case "money":
+		return "DECIMAL(19,4)"
+	case "boolean", "bool":
+		return pick("BOOLEAN", "BIT")
+	case "double precision", "float8":
+		return pick("DOUBLE", "FLOAT")
+	case "real", "float4":
+		return pick("FLOAT", "REAL")
+	case "integer", "int", "int4":
+		return "INT"
+	case "int8":
+		return "BIGINT"
+	case "int2":
+		return "SMALLINT"
+	}
+	switch {
+	case strings.HasPrefix(name, "timestamp"):
+		return pick("DATETIME(6)", "DATETIME2")
+	case strings.HasPrefix(name, "time"):
+		return pick("TIME(6)", "TIME")
+	}
+	return typ
+}
+
+// indexable tells whether the dialect can index a column of type typ.
+func (t *migrationTranslator) indexable(typ string) bool {
+	if t.dialect == DialectSQLServer {
+		return !strings.Contains(typ, "(MAX)")
+	}
+	return typ != "TEXT" && typ != "JSON" && typ != "LONGBLOB"
+}
+
+// expr translates the functions, casts, arrays and quoted identifiers of a
+// masked fragment.
+func (t *migrationTranslator) expr(s string) string {
+	s = sqlSchemaRe.ReplaceAllString(s, "")
+	s = sqlCastRe.ReplaceAllString(s, "")
+	s = sqlCastAsRe.ReplaceAllStringFunc(s, func(m string) string {
+		return "AS " + t.castType(strings.ToLower(sqlCastAsRe.FindStringSubmatch(m)[1]))
+	})
+	s = sqlILikeRe.ReplaceAllString(s, "LIKE")
+	s = sqlUUIDFuncRe.ReplaceAllLiteralString(s, t.uuidExpr())
+	s = sqlNowShiftRe.ReplaceAllStringFunc(s, t.nowShift)
+	s = sqlNowRe.ReplaceAllLiteralString(s, t.nowExpr())
+
+	// ARRAY[a, b] -> json_array(a, b), parenthesized after DEFAULT
+	for {
+		loc := sqlArrayRe.FindStringIndex(s)
+		if loc == nil {
+			break
+		}
+		end := matchingBracket(s, loc[1]-1)
+		if end < 0 {
+			break
+		}
+		var arr string
+		switch t.dialect {
+		case DialectMySQL:
+			arr = "JSON_ARRAY(" + s[loc[1]:end] + ")"
+		case DialectSQLServer:
+			arr = t.jsonArray(s[loc[1]:end])
+		default:
+			arr = "json_array(" + s[loc[1]:end] + ")"
+		}
+		if sqlDefaultEndRe.MatchString(s[:loc[0]]) {
+			arr = "(" + arr + ")"
+		}
+		s = s[:loc[0]] + arr + s[end+1:]
+	}
+
+	switch t.dialect {
+	case DialectMySQL:
+		s = sqlQuotedRe.ReplaceAllString(s, "`$1`")
+		s = sqlMySQLWordRe.ReplaceAllString(s, "`$1`")
+	case DialectSQLServer:
+		s = sqlQuotedRe.ReplaceAllString(s, "[$1]")
+		s = sqlBoolRe.ReplaceAllStringFunc(s, func(b string) string {
+			if strings.EqualFold(b, "TRUE") {
+				return "1"
+			}
+			return "0"
+		})
+		s = sqlCurDateRe.ReplaceAllLiteralString(s, "CAST(SYSDATETIME() AS DATE)")
+	}
+	return s
+}
+
+// nowShift translates now() +/- interval 'n unit'.
+func (t *migrationTranslator) nowShift(m string) string {
+	sub := sqlNowShiftRe.FindStringSubmatch(m)
+	if t.dialect == DialectSQLite {
+		return "(datetime('now', '" + sub[1] + "' || \x01" + sub[2] + "\x01))"
+	}
+	iv := sqlIntervalRe.FindStringSubmatch(t.lits[atoi(sub[2])])
+	if iv == nil {
+		t.lossy = true
+		return t.nowExpr()
+	}
+	if t.dialect == DialectMySQL {
+		return "(" + t.nowExpr() + " " + sub[1] + " INTERVAL " + iv[1] + " " + strings.ToUpper(iv[2]) + ")"
+	}
+	n := iv[1]
+	if sub[1] == "-" {
+		n = "-" + n
+	}
+	return "DATEADD(" + strings.ToLower(iv[2]) + ", " + n + ", " + t.nowExpr() + ")"
+}
+
+// nowExpr is the current time in the dialect.
+func (t *migrationTranslator) nowExpr() string {
+	switch t.dialect {
+	case DialectMySQL:
+		return "NOW(6)"
+	case DialectSQLServer:
+		return "SYSDATETIME()"
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+// uuidExpr generates a random UUID in the dialect.
+func (t *migrationTranslator) uuidExpr() string {
+	switch t.dialect {
+	case DialectMySQL:
+		return "UUID()"
+	case DialectSQLServer:
+		return "NEWID()"
+	}
+	return sqliteUUIDExpr
+}
+
+// castType maps the target type of a CAST(... AS type).
+func (t *migrationTranslator) castType(typ string) string {
+	switch t.dialect {
+	case DialectMySQL:
+		switch typ {
+		case "uuid":
+			return "CHAR(36)"
+		case "jsonb", "json", "hstore":
+			return "JSON"
+		case "timestamptz":
+			return "DATETIME(6)"
+		}
+		return "CHAR(255)"
+	case DialectSQLServer:
+		switch typ {
+		case "uuid":
+			return "UNIQUEIDENTIFIER"
+		case "jsonb", "json", "hstore":
+			return "NVARCHAR(MAX)"
+		case "timestamptz":
+			return "DATETIME2"
+		}
+		return "NVARCHAR(255)"
+	}
+	return "TEXT"
+}
+
+// splitCreateTable returns the table and the items of a masked CREATE TABLE.
+func splitCreateTable(s string) (string, []string, bool) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return "", nil, false
+	}
+	m := sqlTableHeadRe.FindStringSubmatch(strings.TrimSpace(sqlUnloggedRe.ReplaceAllString(s[:open], "")))
+	end := matchingParen(s, open)
+	if m == nil || end < 0 {
+		return "", nil, false
+	}
+	return sqlIdent(m[2]), splitTopLevel(s[open+1 : end]), true
+}
+
+// isTableConstraint tells whether an item of a CREATE TABLE (or the action
+// of an ALTER TABLE ADD) is a constraint rather than a column.
+func isTableConstraint(item string) bool {
+	return sqlConstraintRe.MatchString(strings.TrimSpace(item))
+}
+
+// splitDefault splits the rest of a column definition around the expression
+// of its DEFAULT.
+func splitDefault(rest string) (head, value, tail string, ok bool) {
+	loc := sqlDefaultRe.FindStringIndex(rest)
+	if loc == nil {
+		return rest, "", "", false
+	}
+	body := rest[loc[1]:]
+	end := len(body)
+	for _, m := range sqlColumnTailRe.FindAllStringIndex(body, -1) {
+		if strings.Count(body[:m[0]], "(") == strings.Count(body[:m[0]], ")") {
+			end = m[0]
+			break
+		}
+	}
+	return rest[:loc[1]], strings.TrimSpace(body[:end]), body[end:], true
+}
+
+// sqlIdent normalizes an identifier (unquoted, without the public schema, in
+// lower case) for the lookups of the translator.
+func sqlIdent(s string) string {
+	s = sqlSchemaRe.ReplaceAllString(strings.TrimSpace(s), "")
+	return strings.ToLower(strings.Trim(s, "\"`[]"))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// maskSQL drops the comments and the terminator of a statement and
+// replaces its string literals (and dollar-quoted bodies) with numbered
+// placeholders, so that the translation never touches them.
+func maskSQL(stmt string) (string, []string) {
+	var b strings.Builder
+	var lits []string
+	placeholder := func(lit string) {
+		fmt.Fprintf(&b, "\x01%d\x01", len(lits))
+		lits = append(lits, lit)
+	}
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == '-' && strings.HasPrefix(stmt[i:], "--"):
+			j := strings.IndexByte(stmt[i:], '\n')
+			if j < 0 {
+				i = len(stmt)
+			} else {
+				i += j
+			}
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			j := strings.Index(stmt[i+2:], "*/")
+			if j < 0 {
+				i = len(stmt)
+			} else {
+				i += j + 4
+			}
+			b.WriteByte(' ')
+		case c == '\'' || c == '"':
+			j := i + 1
+			for j < len(stmt) {
+				if stmt[j] == c {
+					if j+1 < len(stmt) && stmt[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			j = min(j+1, len(stmt))
+			if c == '"' {
+				b.WriteString(stmt[i:j])
+			} else {
+				placeholder(stmt[i:j])
+			}
+			i = j
+		case c == '$':
+			tag := sqlDollarTagRe.FindString(stmt[i:])
+			if tag == "" {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			j := strings.Index(stmt[i+len(tag):], tag)
+			if j < 0 {
+				j = len(stmt)
+			} else {
+				j = i + len(tag) + j + len(tag)
+			}
+			placeholder(stmt[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(b.String()), ";")), lits
+}
+
+// unmaskSQL puts back the literals replaced by maskSQL.
+func unmaskSQL(s string, lits []string) string {
+	return sqlLiteralRe.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(strings.Trim(m, "\x01"))
+		if i < len(lits) {
+			return lits[i]
+		}
+		return m
+	})
+}
+
+// sqlSummary shortens a statement to its first line for the notes.
+func sqlSummary(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 80 {
+		s = s[:77] + "..."
+	}
+	return s
+}
+
+// splitTopLevel splits a masked list on the commas outside parentheses.
+func splitTopLevel(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		out = append(out, last)
+	}
+	return out
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open.
+func matchingParen(s string, open int) int {
+	return matchingDelim(s, open, '(', ')')
+}
+
+// matchingBracket returns the index of the bracket closing the one at open.
+func matchingBracket(s string, open int) int {
+	return matchingDelim(s, open, '[', ']')
+}
+
+func matchingDelim(s string, open int, o, c byte) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case o:
+			depth++
+		case c:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestTranslateMigrationsSQLite(t *testing.T) {
+	migs, err := ParseMigrationFiles(map[string]string{
+		"001_init.sql": `CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE TYPE order_status AS ENUM ('draft', 'paid');
+-- the orders
+CREATE TABLE IF NOT EXISTS public.orders (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    seq SERIAL,
+    status order_status NOT NULL DEFAULT 'draft',
+    tags text [] DEFAULT ARRAY['a', 'b'],
+    meta jsonb DEFAULT '{}'::jsonb,
+    note text DEFAULT 'now() -- kept',
+    due_at timestamp without time zone DEFAULT now() + INTERVAL '1 hour',
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_orders_tags ON orders USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+INSERT INTO orders (status, tags) VALUES ('paid', ARRAY['x']) ON CONFLICT DO NOTHING;
+DO $$ BEGIN RAISE NOTICE 'x;'; END$$;
+`,
+		"002_lock.sql": `ALTER TABLE IF EXISTS orders ADD COLUMN IF NOT EXISTS lock_version INTEGER NOT NULL DEFAULT 0, ALTER COLUMN note TYPE citext;
+-- migrate:down
+ALTER TABLE IF EXISTS orders DROP COLUMN IF EXISTS lock_version;
+`,
+		"002_lock.sqlite.sql": "ALTER TABLE orders ADD COLUMN lock_version INTEGER NOT NULL DEFAULT 1;",
+	})
+	if err != nil {
+		t.Fatalf("ParseMigrationFiles: %v", err)
+	}
+	out, notes, err := TranslateMigrations(migs, "sqlite3")
+	if err != nil {
+		t.Fatalf("TranslateMigrations: %v", err)
+	}
+	if len(out) != 2 || out[0].Dialect != DialectSQLite || !strings.Contains(out[1].Up, "DEFAULT 1") {
+		t.Fatalf("unexpected migrations %+v", out)
+	}
+	if len(notes) != 4 || !strings.Contains(notes[0], "CREATE EXTENSION") || !strings.Contains(notes[3], "DO $$") {
+		t.Fatalf("unexpected notes %q", notes)
+	}
+	if !strings.Contains(out[0].Up, "'now() -- kept'") || strings.Contains(out[0].Up, "::") {
+		t.Fatalf("unexpected translation:\n%s", out[0].Up)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	m, _ := NewMigrator(db, DialectSQLite)
+	if _, _, err := m.Up(context.Background(), out); err != nil {
+		t.Fatalf("Up: %v\n%s", err, out[0].Up)
+	}
+	var id, status, tags, meta string
+	var lock int
+	err = db.QueryRow(`SELECT id, status, tags, meta, lock_version FROM orders`).Scan(&id, &status, &tags, &meta, &lock)
+	if err != nil || len(id) != 36 || id[14] != '4' || status != "paid" || tags != `["x"]` || meta != "{}" || lock != 1 {
+		t.Fatalf("unexpected row %q %q %q %q %d: %v", id, status, tags, meta, lock, err)
+	}
+
+	if _, _, err := TranslateMigrations(migs, "oracle"); err == nil {
+		t.Fatalf("expected an error for oracle")
+	}
+}
+
+// TestTranslatedMigrationChecksum checks that the checksum of a translated
+// migration is the one of its source: a later migration changing how it is
+// translated must not flag it as modified.
+func TestTranslatedMigrationChecksum(t *testing.T) {
+	files := map[string]string{
+		"001_notes.sql": "CREATE TABLE notes (id uuid PRIMARY KEY DEFAULT gen_random_uuid(), title text);",
+	}
+	before, _ := ParseMigrationFiles(files)
+	files["002_title.sql"] = "CREATE INDEX idx_notes_title ON notes (title);"
+	after, _ := ParseMigrationFiles(files)
+
+	first, _, err := TranslateMigrations(before, DialectMySQL)
+	if err != nil {
+		t.Fatalf("TranslateMigrations: %v", err)
+	}
+	second, _, err := TranslateMigrations(after, DialectMySQL)
+	if err != nil {
+		t.Fatalf("TranslateMigrations: %v", err)
+	}
+	if first[0].Up == second[0].Up {
+		t.Fatalf("expected the indexed column to change the translation:\n%s", second[0].Up)
+	}
+	if first[0].Checksum() != before[0].Checksum() || second[0].Checksum() != before[0].Checksum() {
+		t.Fatalf("the checksum of a translated migration must be the one of its source")
+	}
+}
+
+// TestTranslateEmbeddedMigrationsDialects translates the embedded migrations
+// to MySQL and SQL Server, keeping the files written for them.
+func TestTranslateEmbeddedMigrationsDialects(t *testing.T) {
+	migs, err := EmbeddedMigrationSource().Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cases := map[string]struct{ want, tenancy []string }{
+		DialectMySQL:     {want: []string{"INSERT IGNORE", "CHAR(36)", "`condition`"}, tenancy: []string{"tenant_id VARCHAR(64)"}},
+		DialectSQLServer: {want: []string{"\nGO\n", "IF OBJECT_ID", "NEWID()", "TOP 1", "IF EXISTS (SELECT 1 FROM [users]"}, tenancy: []string{"tenant_id NVARCHAR(64)", "\nGO\n"}},
+	}
+	for dialect, c := range cases {
+		out, _, err := TranslateMigrations(migs, dialect)
+		if err != nil {
+			t.Fatalf("%s: TranslateMigrations: %v", dialect, err)
+		}
+		if len(out) < 3 || out[0].Dialect != dialect {
+			t.Fatalf("%s: unexpected migrations %+v", dialect, out)
+		}
+		for _, w := range c.want {
+			if !strings.Contains(out[0].Up, w) {
+				t.Errorf("%s: %q missing from 001", dialect, w)
+			}
+		}
+		for _, bad := range []string{"::", "uuid_generate", "ON CONFLICT", "RETURNING"} {
+			if strings.Contains(out[0].Up, bad) {
+				t.Errorf("%s: %q left in 001", dialect, bad)
+			}
+		}
+		if out[2].Version != 3 {
+			t.Fatalf("%s: unexpected version %d", dialect, out[2].Version)
+		}
+		for _, w := range c.tenancy {
+			if !strings.Contains(out[2].Up, w) {
+				t.Errorf("%s: %q missing from the tenancy migration", dialect, w)
+			}
+		}
+	}
+}
+
+// TestTranslateEmbeddedMigrations applies the embedded (Postgres) migrations
+// to SQLite.
+func TestTranslateEmbeddedMigrations(t *testing.T) {
+	migs, err := EmbeddedMigrationSource().Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	out, _, err := TranslateMigrations(migs, DialectSQLite)
+	if err != nil {
+		t.Fatalf("TranslateMigrations: %v", err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "embedded.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	m, _ := NewMigrator(db, DialectSQLite)
+	applied, _, err := m.Up(context.Background(), out)
+	if err != nil || applied != len(out) {
+		t.Fatalf("Up: %d, %v", applied, err)
+	}
+	var users, rules int
+	db.QueryRow(`SELECT count(*) FROM users`).Scan(&users)
+	db.QueryRow(`SELECT count(*) FROM mcp_notification_rules WHERE template_id IS NOT NULL`).Scan(&rules)
+	if users == 0 || rules == 0 {
+		t.Fatalf("seed rows missing: %d users, %d rules", users, rules)
+	}
+	// the tenant column of the row-level tenancy (003_tenancy.sqlite.sql)
+	for _, table := range []string{"orders", "products", "mcp_conversations", "mcp_messages"} {
+		if _, err := db.Exec(`SELECT tenant_id FROM ` + table + ` LIMIT 1`); err != nil {
+			t.Fatalf("%s.tenant_id: %v", table, err)
+		}
+	}
+	if _, err := m.Down(context.Background(), out, 1); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+}
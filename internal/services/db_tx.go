@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTxMaxRetries   = 3
+	defaultTxRetryBackoff = 50 * time.Millisecond
+)
+
+// TxOption configures a transaction started with WithTx.
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	isolation  sql.IsolationLevel
+	readOnly   bool
+	maxRetries int
+	backoff    time.Duration
+}
+
+// WithIsolation sets the isolation level of the transaction.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) { o.isolation = level }
+}
+
+// WithReadOnly starts a read-only transaction.
+func WithReadOnly() TxOption {
+	return func(o *txOptions) { o.readOnly = true }
+}
+
+// WithMaxRetries sets how many times the transaction is retried after a
+// serialization failure or deadlock (0 disables retries).
+func WithMaxRetries(n int) TxOption {
+	return func(o *txOptions) {
+		if n >= 0 {
+			o.maxRetries = n
+		}
+	}
+}
+
+// WithRetryBackoff sets the base delay between retries; it doubles on each attempt.
+func WithRetryBackoff(d time.Duration) TxOption {
+	return func(o *txOptions) {
+		if d > 0 {
+			o.backoff = d
+		}
+	}
+}
+
+type txCtxKey struct{}
+
+// txState is the transaction carried by a context: root is the connection it
+// was started on, tx the handle to use (the transaction or a savepoint of it).
+type txState struct {
+	root *gorm.DB
+	tx   *gorm.DB
+	// searchPath is the tenant schema set as search_path of the transaction
+	// (schema tenancy on Postgres)
+	searchPath string
+}
+
+// TxFromContext returns the transaction started with WithTx that ctx carries.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	st, ok := ctx.Value(txCtxKey{}).(*txState)
+	if !ok || st == nil {
+		return nil, false
+	}
+	return st.tx, true
+}
+
+// DBFromContext returns the transaction carried by ctx when it was started on
+// the connection of db, or db bound to ctx otherwise. Repositories holding a
+// *gorm.DB use it to join a WithTx: their WithContext(ctx) returns a copy of
+// the repo on DBFromContext(ctx, db), so the calls made with the copy inside
+// the WithTx callback run in its transaction, and the calls made outside it
+// carry the cancellation, SQL capture and tenant of ctx.
+func DBFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := txFor(ctx, db); ok {
+		return bindDB(ctx, tx)
+	}
+	if ctx == nil || db == nil {
+		return db
+	}
+	return bindDB(ctx, db.WithContext(ctx))
+}
+
+// bindDB applies the state carried by ctx to a connection handed to a
+// repository: the SQL capture (WithSQLCapture) and the tenant (WithTenant).
+func bindDB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return withTenant(ctx, withCapture(ctx, db))
+}
+
+// txFor returns the transaction of ctx when it was started on the connection
+// of db (db itself or a session derived from it, as held by repositories).
+func txFor(ctx context.Context, db *gorm.DB) (*gorm.DB, bool) {
+	if ctx == nil || db == nil {
+		return nil, false
+	}
+	st, ok := ctx.Value(txCtxKey{}).(*txState)
+	if !ok || st == nil || !sameConnection(st.root, db) {
+		return nil, false
+	}
+	return st.tx, true
+}
+
+// sameConnection reports whether a and b share the same connection pool.
+func sameConnection(a, b *gorm.DB) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.Statement == nil || b.Statement == nil {
+		return false
+	}
+	return a.Statement.ConnPool != nil && a.Statement.ConnPool == b.Statement.ConnPool
+}
+
+// WithTx runs fn in a transaction on the connection selected by ctx (see GetDB).
+// The transaction rides on the context handed to fn: GetDB, GetDBByName and
+// GetDBForRepo return it for that connection, so repositories built from that
+// context write atomically. A WithTx nested in another on the same connection
+// becomes a savepoint. The outermost transaction is retried when it fails with a
+// serialization failure or a deadlock.
+func (d *DBServiceImpl) WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if d == nil {
+		return fmt.Errorf("❌ Serviço de banco de dados não inicializado")
+	}
+	if fn == nil {
+		return fmt.Errorf("❌ Função da transação não informada")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	o := txOptions{maxRetries: defaultTxMaxRetries, backoff: defaultTxRetryBackoff}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	root, err := d.resolveDB(ctx)
+	if err != nil {
+		return err
+	}
+	if parent, ok := txFor(ctx, root); ok {
+		// nested: GORM turns a transaction inside a transaction into a savepoint
+		searchPath := ctx.Value(txCtxKey{}).(*txState).searchPath
+		return parent.Transaction(func(sp *gorm.DB) error {
+			return fn(context.WithValue(ctx, txCtxKey{}, &txState{root: root, tx: sp, searchPath: searchPath}))
+		})
+	}
+
+	var txOpts *sql.TxOptions
+	if o.isolation != sql.LevelDefault || o.readOnly {
+		txOpts = &sql.TxOptions{Isolation: o.isolation, ReadOnly: o.readOnly}
+	}
+	run := func(tx *gorm.DB) error {
+		searchPath, err := d.setTenantSearchPath(ctx, tx)
+		if err != nil {
+			return err
+		}
+		return fn(context.WithValue(ctx, txCtxKey{}, &txState{root: root, tx: tx, searchPath: searchPath}))
+	}
+
+	for attempt := 0; ; attempt++ {
+		if txOpts != nil {
+			err = root.WithContext(ctx).Transaction(run, txOpts)
+		} else {
+			err = root.WithContext(ctx).Transaction(run)
+		}
+		if err == nil || attempt >= o.maxRetries || !IsRetryableTxError(err) {
+			return err
+		}
+		wait := o.backoff << attempt
+		wait += time.Duration(rand.Int63n(int64(wait)/2 + 1))
+		gl.Log("warn", fmt.Sprintf("Transação abortada (%v), nova tentativa %d/%d em %s", err, attempt+1, o.maxRetries, wait))
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// WithTx runs fn in a transaction of d (see DBServiceImpl.WithTx).
+func WithTx(ctx context.Context, d *DBServiceImpl, fn func(ctx context.Context) error, opts ...TxOption) error {
+	return d.WithTx(ctx, fn, opts...)
+}
+
+// IsRetryableTxError reports whether err is a serialization failure or a
+// deadlock, i.e. the transaction can be run again.
+func IsRetryableTxError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// PostgreSQL: serialization_failure, deadlock_detected
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+	// MySQL/MariaDB: ER_LOCK_DEADLOCK, ER_LOCK_WAIT_TIMEOUT
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case 1213, 1205:
+			return true
+		}
+	}
+	// SQL Server: deadlock victim
+	var msErr interface{ SQLErrorNumber() int32 }
+	if errors.As(err, &msErr) && msErr.SQLErrorNumber() == 1205 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"database is locked", "sqlite_busy", "deadlock", "could not serialize access"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
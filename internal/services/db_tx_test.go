@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+type txItem struct {
+	ID   uint
+	Name string
+}
+
+func TestWithTx(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+	root := d.db["transactional"]
+	if err := root.AutoMigrate(&txItem{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	count := func() int64 {
+		var n int64
+		root.Model(&txItem{}).Count(&n)
+		return n
+	}
+
+	boom := errors.New("boom")
+	err := d.WithTx(ctx, func(ctx context.Context) error {
+		db, err := GetDB(ctx, d)
+		if err != nil {
+			return err
+		}
+		if tx, ok := TxFromContext(ctx); !ok || db != tx {
+			t.Fatalf("GetDB must return the transaction of the context")
+		}
+		if err := db.Create(&txItem{Name: "outer"}).Error; err != nil {
+			return err
+		}
+		// a failing nested WithTx only rolls back to its savepoint
+		nestedErr := d.WithTx(ctx, func(ctx context.Context) error {
+			db, _ := GetDBByName(ctx, d, "transactional")
+			db.Create(&txItem{Name: "inner"})
+			return boom
+		})
+		if !errors.Is(nestedErr, boom) {
+			t.Fatalf("nested error = %v", nestedErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("expected only the outer row, got %d rows", n)
+	}
+
+	// a repository joins the transaction of its own database only
+	repoDB := root.WithContext(context.Background())
+	analytics := d.db["analytics"].Session(&gorm.Session{})
+	err = d.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		if DBFromContext(ctx, repoDB) != tx {
+			t.Fatalf("a repository of the transactional database must join its transaction")
+		}
+		if other := DBFromContext(ctx, analytics); other == tx || !sameConnection(other, d.db["analytics"]) {
+			t.Fatalf("a repository of another database must not join the transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	err = d.WithTx(ctx, func(ctx context.Context) error {
+		db, _ := GetDB(ctx, d)
+		db.Create(&txItem{Name: "rolled back"})
+		return boom
+	})
+	if !errors.Is(err, boom) || count() != 1 {
+		t.Fatalf("failed transaction must roll back: err=%v rows=%d", err, count())
+	}
+}
+
+func TestWithTxRetry(t *testing.T) {
+	d := newRoutingTestService(t)
+	ctx := Using(context.Background(), "transactional")
+
+	attempts := 0
+	err := d.WithTx(ctx, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+		}
+		return nil
+	}, WithRetryBackoff(1))
+	if err != nil || attempts != 3 {
+		t.Fatalf("expected success on the third attempt, got %d attempts, err=%v", attempts, err)
+	}
+
+	attempts = 0
+	err = d.WithTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return errors.New("constraint violation")
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("non-retryable errors must not be retried: %d attempts", attempts)
+	}
+}
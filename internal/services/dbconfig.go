@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	ci "github.com/kubex-ecosystem/gdbase/internal/interfaces"
 	gl "github.com/kubex-ecosystem/gdbase/internal/module/kbx"
@@ -62,6 +63,9 @@ type DBConfig struct {
 	// FilePath is used to configure the file path of the database
 	FilePath string `json:"file_path" yaml:"file_path" xml:"file_path" toml:"file_path" mapstructure:"file_path"`
 
+	// WatchFile reloads the databases whenever the file at FilePath changes (see DBServiceImpl.WatchConfig)
+	WatchFile bool `json:"watch_file,omitempty" yaml:"watch_file,omitempty" xml:"watch_file,omitempty" toml:"watch_file,omitempty" mapstructure:"watch_file,omitempty"`
+
 	// Logger is used to configure the logger
 	Logger l.Logger `json:"logger,omitempty" yaml:"logger,omitempty" xml:"logger,omitempty" toml:"logger,omitempty" mapstructure:"logger,omitempty"`
 
@@ -98,6 +102,15 @@ type DBConfig struct {
 	// Databases is used to configure the databases (Postgres, MySQL, SQLite, SQLServer, Oracle)
 	Databases map[string]*ti.Database `json:"databases" yaml:"databases" xml:"databases" toml:"databases" mapstructure:"databases"`
 
+	// Bindings is used to bind repositories to a named database (repo -> key in Databases)
+	Bindings map[string]string `json:"bindings,omitempty" yaml:"bindings,omitempty" xml:"bindings,omitempty" toml:"bindings,omitempty" mapstructure:"bindings,omitempty"`
+
+	// Tenancy is used to configure multi-tenancy (row-level tenant column or schema per tenant)
+	Tenancy *TenancyConfig `json:"tenancy,omitempty" yaml:"tenancy,omitempty" xml:"tenancy,omitempty" toml:"tenancy,omitempty" mapstructure:"tenancy,omitempty"`
+
+	// QueryLog is used to configure the logging of the GORM statements (levels, slow queries, sampling, redaction)
+	QueryLog *QueryLogConfig `json:"query_log,omitempty" yaml:"query_log,omitempty" xml:"query_log,omitempty" toml:"query_log,omitempty" mapstructure:"query_log,omitempty"`
+
 	// Messagery is used to configure the messagery database
 	Messagery *ti.Messagery `json:"messagery,omitempty" yaml:"messagery,omitempty" xml:"messagery,omitempty" toml:"messagery,omitempty" mapstructure:"messagery,omitempty"`
 
@@ -207,6 +220,10 @@ func newDBConfig(name, filePath string, enabled bool, logger l.Logger, debug boo
 			}
 		}
 	}
+	dbConfig.Mapper = ti.NewMapperType(&dbConfig, filePath)
+	if err := dbConfig.Mapper.ResolveSecrets(context.Background()); err != nil {
+		gl.Log("error", fmt.Sprintf("Error resolving secrets: %v", err))
+	}
 	if dbConfig.Logger == nil {
 		dbConfig.Logger = logger
 	}
@@ -249,6 +266,9 @@ func NewDBConfig(dbConfig *DBConfig) *DBConfig {
 			if err != nil {
 				gl.Log("error", fmt.Sprintf("Error deserializing file: %v", err))
 			}
+			if err := dbConfig.Mapper.ResolveSecrets(context.Background()); err != nil {
+				gl.Log("error", fmt.Sprintf("Error resolving secrets: %v", err))
+			}
 		}
 	}
 	if willWrite {
@@ -262,14 +282,69 @@ func NewDBConfigWithDBConnection(db *gorm.DB) *DBConfig {
 func NewDBConfigWithFilePath(name, filePath string) *DBConfig {
 	return newDBConfig(name, filePath, true, nil, false)
 }
+
+// NewDBConfigFromFile loads the configuration file at dbConfigFilePath (json,
+// yaml, toml or xml, by extension). Set WatchFile (or call
+// DBServiceImpl.WatchConfig) to apply later changes of the file to a running service.
 func NewDBConfigFromFile(ctx context.Context, dbConfigFilePath string, autoMigrate bool, logger l.Logger, debug bool) (*DBConfig, error) {
-	var dbConfig *DBConfig
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	dbConfig, err := loadDBConfigFile(dbConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if logger != nil {
+		dbConfig.Logger = logger
+	}
+	dbConfig.AutoMigrate = dbConfig.AutoMigrate || autoMigrate
+	dbConfig.Debug = dbConfig.Debug || debug
+	if dbConfig.Debug {
+		gl.SetDebugMode(true)
+	}
 	return dbConfig, nil
 }
 
+// loadDBConfigFile reads and decodes a configuration file.
+func loadDBConfigFile(path string) (*DBConfig, error) {
+	path = os.ExpandEnv(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	dbConfig := &DBConfig{FilePath: path}
+	mapper := ti.NewMapperType(&dbConfig, path)
+	if _, err := mapper.Deserialize(data, configFileFormat(path)); err != nil {
+		return nil, fmt.Errorf("error decoding config file %s: %w", path, err)
+	}
+	dbConfig.Mapper = mapper
+	if err := mapper.ResolveSecrets(context.Background()); err != nil {
+		return nil, fmt.Errorf("error resolving secrets of %s: %w", path, err)
+	}
+	if dbConfig.Logger == nil {
+		dbConfig.Logger = l.NewLogger("GDBase")
+	}
+	if dbConfig.Mutexes == nil {
+		dbConfig.Mutexes = ti.NewMutexesType()
+	}
+	if dbConfig.Databases == nil {
+		dbConfig.Databases = map[string]*ti.Database{}
+	}
+	if dbConfig.Messagery == nil {
+		dbConfig.Messagery = &ti.Messagery{}
+	}
+	return dbConfig, nil
+}
+
+func configFileFormat(path string) string {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "yaml", "yml", "toml", "tml", "xml":
+		return ext
+	default:
+		return "json"
+	}
+}
+
 func getPasswordFromKeyring(name string) (string, error) {
 	krPass, pgPassErr := krs.NewKeyringService(KeyringService, fmt.Sprintf("gdbase-%s", name)).RetrievePassword()
 	if pgPassErr != nil && pgPassErr.Error() != "keyring: item not found" {
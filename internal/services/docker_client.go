@@ -19,5 +19,6 @@ type IDockerClient interface {
 	ContainerStart(ctx context.Context, containerID string, options c.StartOptions) error
 	VolumeCreate(ctx context.Context, options v.CreateOptions) (v.Volume, error)
 	VolumeList(ctx context.Context, options v.ListOptions) (v.ListResponse, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
 	ImagePull(ctx context.Context, image string, options i.PullOptions) (io.ReadCloser, error)
 }
@@ -0,0 +1,193 @@
+package services
+
+import "strings"
+
+// SQLStatement represents a parsed SQL statement with line information
+type SQLStatement struct {
+	SQL  string
+	Line int
+}
+
+// SplitSQLStatements splits SQL content into individual statements, preserving line numbers.
+// It understands line and block comments, quoted strings/identifiers and
+// PostgreSQL dollar-quoted bodies, so semicolons inside them are not treated as terminators.
+func SplitSQLStatements(content string) []SQLStatement {
+	var stmts []SQLStatement
+	var b strings.Builder
+	line := 1
+	stmtStartLine := 1
+
+	// state
+	var inLineComment bool
+	var inBlockComment bool
+	var dollarTag string
+
+	runes := []rune(content)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+
+		// track line numbers
+		if r == '\n' {
+			line++
+		}
+
+		// handle end of line comment
+		if inLineComment {
+			b.WriteRune(r)
+			if r == '\n' {
+				inLineComment = false
+			}
+			i++
+			continue
+		}
+
+		// handle end of block comment
+		if inBlockComment {
+			b.WriteRune(r)
+			if r == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+				b.WriteRune(runes[i+1])
+				i += 2
+				inBlockComment = false
+				continue
+			}
+			i++
+			continue
+		}
+
+		// handle dollar-quote content
+		if dollarTag != "" {
+			b.WriteRune(r)
+			tagRunes := []rune(dollarTag)
+			if r == tagRunes[0] && i+len(tagRunes) <= len(runes) {
+				match := true
+				for k := 0; k < len(tagRunes); k++ {
+					if runes[i+k] != tagRunes[k] {
+						match = false
+						break
+					}
+				}
+				if match {
+					for k := 1; k < len(tagRunes); k++ {
+						b.WriteRune(runes[i+k])
+					}
+					i += len(tagRunes)
+					dollarTag = ""
+					continue
+				}
+			}
+			i++
+			continue
+		}
+
+		// detect start of line comment --
+		if r == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+			inLineComment = true
+			b.WriteRune(r)
+			b.WriteRune(runes[i+1])
+			i += 2
+			continue
+		}
+
+		// detect start of block comment /*
+		if r == '/' && i+1 < len(runes) && runes[i+1] == '*' {
+			inBlockComment = true
+			b.WriteRune(r)
+			b.WriteRune(runes[i+1])
+			i += 2
+			continue
+		}
+
+		// detect dollar quote start $tag$
+		if r == '$' {
+			j := i + 1
+			for j < len(runes) && runes[j] != '$' && ((runes[j] >= 'a' && runes[j] <= 'z') || (runes[j] >= 'A' && runes[j] <= 'Z') || (runes[j] >= '0' && runes[j] <= '9') || runes[j] == '_') {
+				j++
+			}
+			if j < len(runes) && runes[j] == '$' {
+				tagRunes := runes[i : j+1]
+				dollarTag = string(tagRunes)
+				for k := 0; k < len(tagRunes); k++ {
+					b.WriteRune(tagRunes[k])
+				}
+				i = j + 1
+				continue
+			}
+		}
+
+		// single-quote start
+		if r == '\'' {
+			b.WriteRune(r)
+			i++
+			for i < len(runes) {
+				b.WriteRune(runes[i])
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune(runes[i+1])
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			continue
+		}
+
+		// double-quote start (identifiers)
+		if r == '"' {
+			b.WriteRune(r)
+			i++
+			for i < len(runes) {
+				b.WriteRune(runes[i])
+				if runes[i] == '"' {
+					if i+1 < len(runes) && runes[i+1] == '"' {
+						b.WriteRune(runes[i+1])
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			continue
+		}
+
+		// semicolon at top level -> end statement
+		if r == ';' {
+			b.WriteRune(r)
+			stmt := strings.TrimSpace(b.String())
+			if stmt != "" && stmt != ";" {
+				stmts = append(stmts, SQLStatement{
+					SQL:  stmt,
+					Line: stmtStartLine,
+				})
+			}
+			b.Reset()
+			i++
+			for i < len(runes) && runes[i] == '\n' {
+				i++
+				line++
+			}
+			stmtStartLine = line
+			continue
+		}
+
+		if b.Len() == 0 {
+			stmtStartLine = line
+		}
+		b.WriteRune(r)
+		i++
+	}
+
+	if strings.TrimSpace(b.String()) != "" {
+		stmts = append(stmts, SQLStatement{
+			SQL:  strings.TrimSpace(b.String()),
+			Line: stmtStartLine,
+		})
+	}
+
+	return stmts
+}
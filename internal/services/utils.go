@@ -151,9 +151,9 @@ func SetupDatabaseServices(ctx context.Context, d IDockerService, config *DBConf
 							continue
 						} else {
 							// Check if Password is empty, if so, try to retrieve it from keyring
-							// if not found, generate a new one
+							// (the entry BuildDSN reads) if not found, generate a new one
 							if dbConfig.Password == "" {
-								pgPassKey, pgPassErr := gl.GetOrGenPasswordKeyringPass("pgpass")
+								pgPassKey, pgPassErr := getPasswordFromKeyring(DatabaseKeyringEntry(dbConfig))
 								if pgPassErr != nil {
 									gl.Log("error", fmt.Sprintf("Error generating key: %v", pgPassErr))
 									continue
@@ -176,6 +176,10 @@ func SetupDatabaseServices(ctx context.Context, d IDockerService, config *DBConf
 								continue
 							} else {
 								for _, initDBSQL := range initDBSQLs {
+									if dialect := migrationFileDialect(initDBSQL.Name()); dialect != "" && dialect != DialectPostgres {
+										// the versions of the migrations for the other dialects
+										continue
+									}
 									initDBSQLData, initDBSQLErr := embed.FS.ReadFile(initDBSQLFiles, filepath.Join("embedded", initDBSQL.Name()))
 									if initDBSQLErr != nil {
 										gl.Log("error", fmt.Sprintf("❌ Erro ao ler script SQL %s: %v", initDBSQL.Name(), initDBSQLErr))
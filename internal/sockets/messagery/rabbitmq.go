@@ -320,11 +320,15 @@ func (a *AMQP) Publish(exchange, key string, body []byte) error {
 	})
 }
 
-func GetRabbitMQURL(dbService svc.DBServiceImpl) string {
+func GetRabbitMQURL(dbService *svc.DBServiceImpl) string {
 	var host = ""
 	var port = ""
 	var username = ""
 	var password = ""
+	if dbService == nil {
+		gl.Log("error", "DBService is nil, cannot get RabbitMQ URL")
+		return ""
+	}
 	properties := dbService.GetProperties(context.Background())
 	dbConfig := properties["dbconfig"].(*svc.DBConfig)
 	if dbConfig == nil {
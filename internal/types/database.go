@@ -1,20 +1,42 @@
 package types
 
 type Database struct {
-	Reference        *Reference         `json:"reference" yaml:"reference" xml:"reference" toml:"reference" mapstructure:"reference,squash"`
-	IsDefault        bool               `gorm:"default:false" json:"is_default" yaml:"is_default" xml:"is_default" toml:"is_default" mapstructure:"is_default"`
-	Enabled          bool               `gorm:"default:true" json:"enabled" yaml:"enabled" xml:"enabled" toml:"enabled" mapstructure:"enabled"`
-	FilePath         string             `json:"file_path" yaml:"file_path" xml:"file_path" toml:"file_path" mapstructure:"file_path"`
-	Type             string             `gorm:"not null" json:"type" yaml:"type" xml:"type" toml:"type" mapstructure:"type"`
-	Driver           string             `gorm:"not null" json:"driver" yaml:"driver" xml:"driver" toml:"driver" mapstructure:"driver"`
-	ConnectionString string             `gorm:"omitempty" json:"connection_string" yaml:"connection_string" xml:"connection_string" toml:"connection_string" mapstructure:"connection_string"`
-	Dsn              string             `gorm:"omitempty" json:"dsn" yaml:"dsn" xml:"dsn" toml:"dsn" mapstructure:"dsn"`
-	Path             string             `gorm:"omitempty" json:"path" yaml:"path" xml:"path" toml:"path" mapstructure:"path"`
-	Host             string             `gorm:"omitempty" json:"host" yaml:"host" xml:"host" toml:"host" mapstructure:"host"`
-	Port             any                `gorm:"omitempty" json:"port" yaml:"port" xml:"port" toml:"port" mapstructure:"port"`
-	Username         string             `gorm:"omitempty" json:"username" yaml:"username" xml:"username" toml:"username" mapstructure:"username"`
-	Password         string             `gorm:"omitempty" json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password"`
-	Name             string             `gorm:"omitempty" json:"name" yaml:"name" xml:"name" toml:"name" mapstructure:"name"`
-	Volume           string             `gorm:"omitempty" json:"volume" yaml:"volume" xml:"volume" toml:"volume" mapstructure:"volume"`
-	Mapper           *Mapper[*Database] `json:"-" yaml:"-" xml:"-" toml:"-" mapstructure:"-"`
+	Reference        *Reference `json:"reference" yaml:"reference" xml:"reference" toml:"reference" mapstructure:"reference,squash"`
+	IsDefault        bool       `gorm:"default:false" json:"is_default" yaml:"is_default" xml:"is_default" toml:"is_default" mapstructure:"is_default"`
+	Enabled          bool       `gorm:"default:true" json:"enabled" yaml:"enabled" xml:"enabled" toml:"enabled" mapstructure:"enabled"`
+	FilePath         string     `json:"file_path" yaml:"file_path" xml:"file_path" toml:"file_path" mapstructure:"file_path"`
+	Type             string     `gorm:"not null" json:"type" yaml:"type" xml:"type" toml:"type" mapstructure:"type"`
+	Driver           string     `gorm:"not null" json:"driver" yaml:"driver" xml:"driver" toml:"driver" mapstructure:"driver"`
+	ConnectionString string     `gorm:"omitempty" json:"connection_string" yaml:"connection_string" xml:"connection_string" toml:"connection_string" mapstructure:"connection_string" secret:"true"`
+	Dsn              string     `gorm:"omitempty" json:"dsn" yaml:"dsn" xml:"dsn" toml:"dsn" mapstructure:"dsn" secret:"true"`
+	Path             string     `gorm:"omitempty" json:"path" yaml:"path" xml:"path" toml:"path" mapstructure:"path"`
+	Host             string     `gorm:"omitempty" json:"host" yaml:"host" xml:"host" toml:"host" mapstructure:"host"`
+	Port             any        `gorm:"omitempty" json:"port" yaml:"port" xml:"port" toml:"port" mapstructure:"port"`
+	Username         string     `gorm:"omitempty" json:"username" yaml:"username" xml:"username" toml:"username" mapstructure:"username"`
+	Password         string     `gorm:"omitempty" json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password" secret:"true"`
+	Name             string     `gorm:"omitempty" json:"name" yaml:"name" xml:"name" toml:"name" mapstructure:"name"`
+	Volume           string     `gorm:"omitempty" json:"volume" yaml:"volume" xml:"volume" toml:"volume" mapstructure:"volume"`
+	// SSLMode is the TLS mode (disable, require, verify-ca, verify-full); defaults to disable for postgres.
+	SSLMode     string `gorm:"-" json:"ssl_mode,omitempty" yaml:"ssl_mode,omitempty" xml:"ssl_mode,omitempty" toml:"ssl_mode,omitempty" mapstructure:"ssl_mode,omitempty"`
+	SSLCert     string `gorm:"-" json:"ssl_cert,omitempty" yaml:"ssl_cert,omitempty" xml:"ssl_cert,omitempty" toml:"ssl_cert,omitempty" mapstructure:"ssl_cert,omitempty"`
+	SSLKey      string `gorm:"-" json:"ssl_key,omitempty" yaml:"ssl_key,omitempty" xml:"ssl_key,omitempty" toml:"ssl_key,omitempty" mapstructure:"ssl_key,omitempty"`
+	SSLRootCert string `gorm:"-" json:"ssl_root_cert,omitempty" yaml:"ssl_root_cert,omitempty" xml:"ssl_root_cert,omitempty" toml:"ssl_root_cert,omitempty" mapstructure:"ssl_root_cert,omitempty"`
+	// TimeZone is the session timezone (e.g. UTC, America/Sao_Paulo); empty keeps the server default.
+	TimeZone string `gorm:"-" json:"timezone,omitempty" yaml:"timezone,omitempty" xml:"timezone,omitempty" toml:"timezone,omitempty" mapstructure:"timezone,omitempty"`
+	// ConnectTimeout is the connection timeout in seconds.
+	ConnectTimeout  int    `gorm:"-" json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty" xml:"connect_timeout,omitempty" toml:"connect_timeout,omitempty" mapstructure:"connect_timeout,omitempty"`
+	ApplicationName string `gorm:"-" json:"application_name,omitempty" yaml:"application_name,omitempty" xml:"application_name,omitempty" toml:"application_name,omitempty" mapstructure:"application_name,omitempty"`
+	// Params are extra driver parameters appended to the DSN.
+	Params map[string]string `gorm:"-" json:"params,omitempty" yaml:"params,omitempty" xml:"-" toml:"params,omitempty" mapstructure:"params,omitempty"`
+	// KeyringEntry is the keyring entry holding the password; defaults to "<name>_<Engine>".
+	KeyringEntry string `gorm:"-" json:"keyring_entry,omitempty" yaml:"keyring_entry,omitempty" xml:"keyring_entry,omitempty" toml:"keyring_entry,omitempty" mapstructure:"keyring_entry,omitempty"`
+	// Replicas are read-only copies of this database; empty fields are inherited from the primary.
+	Replicas []*Database `gorm:"-" json:"replicas,omitempty" yaml:"replicas,omitempty" xml:"replicas,omitempty" toml:"replicas,omitempty" mapstructure:"replicas,omitempty"`
+	// ReplicaPolicy selects the replica used for reads: round_robin (default) or least_latency.
+	ReplicaPolicy string `gorm:"-" json:"replica_policy,omitempty" yaml:"replica_policy,omitempty" xml:"replica_policy,omitempty" toml:"replica_policy,omitempty" mapstructure:"replica_policy,omitempty"`
+	// ReplicaHealthInterval is the interval between replica health probes (e.g. "10s").
+	ReplicaHealthInterval string `gorm:"-" json:"replica_health_interval,omitempty" yaml:"replica_health_interval,omitempty" xml:"replica_health_interval,omitempty" toml:"replica_health_interval,omitempty" mapstructure:"replica_health_interval,omitempty"`
+	// SlowQueryThreshold flags the statements slower than it as slow queries (e.g. "500ms"); overrides query_log.slow_threshold.
+	SlowQueryThreshold string             `gorm:"-" json:"slow_query_threshold,omitempty" yaml:"slow_query_threshold,omitempty" xml:"slow_query_threshold,omitempty" toml:"slow_query_threshold,omitempty" mapstructure:"slow_query_threshold,omitempty"`
+	Mapper             *Mapper[*Database] `json:"-" yaml:"-" xml:"-" toml:"-" mapstructure:"-"`
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bufio"
+	"context"
 	"encoding/asn1"
 	"encoding/json"
 	"encoding/xml"
@@ -21,6 +22,7 @@ import (
 type Mapper[T any] struct {
 	filePath string
 	object   T
+	secrets  secretRefs
 }
 
 // NewMapperTypeWithObject creates a new instance of Mapper.
@@ -43,19 +45,30 @@ func NewMapper[T any](object *T, filePath string) ci.IMapper[T] {
 	return NewMapperType[T](object, filePath)
 }
 
+// ResolveSecrets resolves the secret references of the object (see the
+// ResolveSecrets func) and keeps them, so Serialize writes them back.
+func (m *Mapper[T]) ResolveSecrets(ctx context.Context) error {
+	if m.secrets == nil {
+		m.secrets = secretRefs{}
+	}
+	return resolveSecrets(ctx, &m.object, m.secrets)
+}
+
 // Serialize converts an object of type T to a byte array in the specified format.
+// The secret references resolved by ResolveSecrets are written back as references.
 func (m *Mapper[T]) Serialize(format string) ([]byte, error) {
+	object := m.secrets.mask(m.object)
 	switch format {
 	case "json":
-		return json.Marshal(m.object)
+		return json.Marshal(object)
 	case "yaml":
-		return yaml.Marshal(m.object)
+		return yaml.Marshal(object)
 	case "xml":
-		return xml.Marshal(m.object)
+		return xml.Marshal(object)
 	case "toml":
-		return toml.Marshal(m.object)
+		return toml.Marshal(object)
 	case "asn":
-		return asn1.Marshal(m.object)
+		return asn1.Marshal(object)
 	case "env":
 		if env, ok := reflect.ValueOf(m.object).Interface().(map[string]string); ok {
 			if strM, strMErr := gotenv.Marshal(env); strMErr != nil {
@@ -1,6 +1,25 @@
 // Package types provides interfaces and functions for various types used in the application.
 package types
 
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
 type DataExporter interface {
 	ExportFromYAML(filename string) error
 	ExportFromJSON(filename string) error
@@ -17,64 +36,453 @@ type DataExporter interface {
 	ExportFromExcel(filename string) error
 	ExportFromPDF(filename string) error
 	ExportFromMarkdown(filename string) error
+
+	// ExportFromJSONL writes a JSON Lines file, one object per line.
+	ExportFromJSONL(filename string) error
+	// ExportFile writes filename in the format of its extension.
+	ExportFile(filename string) error
+	// Export writes the records to w in format.
+	Export(w io.Writer, format string) error
+	// Report returns the counts of the exports so far.
+	Report() *DataReport
 }
-type dataExporter struct{}
 
-func NewDataExporter() DataExporter {
-	return &dataExporter{}
+// DataSource emits the records to export, stopping at the first error of
+// emit.
+type DataSource func(emit func(DataRecord) error) error
+
+// dataExporter writes the records of the source in the field order of the
+// mapping. CSV, JSON and JSON Lines are streamed; the other formats are
+// collected and written through a Mapper.
+type dataExporter struct {
+	source  DataSource
+	mapping DataMapping
+	report  DataReport
 }
 
-func (e dataExporter) ExportFromYAML(filename string) error {
-	// Implementation for exporting to CSV
-	return nil
+// NewDataExporter returns an exporter of the records of source; mapping may
+// be nil.
+func NewDataExporter(source DataSource, mapping *DataMapping) DataExporter {
+	e := &dataExporter{source: source}
+	if mapping != nil {
+		e.mapping = *mapping
+	}
+	return e
 }
-func (e dataExporter) ExportFromJSON(filename string) error {
-	// Implementation for exporting to YAML
-	return nil
+
+func (e *dataExporter) ExportFromYAML(filename string) error { return e.exportFile(filename, "yaml") }
+func (e *dataExporter) ExportFromJSON(filename string) error { return e.exportFile(filename, "json") }
+func (e *dataExporter) ExportFromXML(filename string) error  { return e.exportFile(filename, "xml") }
+func (e *dataExporter) ExportFromTOML(filename string) error { return e.exportFile(filename, "toml") }
+func (e *dataExporter) ExportFromENV(filename string) error  { return e.exportFile(filename, "env") }
+func (e *dataExporter) ExportFromINI(filename string) error  { return e.exportFile(filename, "ini") }
+func (e *dataExporter) ExportFromCSV(filename string) error  { return e.exportFile(filename, "csv") }
+func (e *dataExporter) ExportFromJSONL(filename string) error {
+	return e.exportFile(filename, "jsonl")
 }
-func (e dataExporter) ExportFromXML(filename string) error {
-	// Implementation for exporting to JSON
-	return nil
+func (e *dataExporter) ExportFromProperties(filename string) error {
+	return e.exportFile(filename, "properties")
 }
-func (e dataExporter) ExportFromTOML(filename string) error {
-	// Implementation for exporting to XML
-	return nil
+func (e *dataExporter) ExportFromText(filename string) error { return e.exportFile(filename, "text") }
+func (e *dataExporter) ExportFromASN(filename string) error  { return e.exportFile(filename, "asn") }
+func (e *dataExporter) ExportFromHTML(filename string) error { return e.exportFile(filename, "html") }
+func (e *dataExporter) ExportFromMarkdown(filename string) error {
+	return e.exportFile(filename, "markdown")
 }
-func (e dataExporter) ExportFromENV(filename string) error {
-	// Implementation for exporting to Excel
-	return nil
+func (e *dataExporter) ExportFromBinary(filename string) error {
+	return e.exportFile(filename, "binary")
 }
-func (e dataExporter) ExportFromINI(filename string) error {
-	// Implementation for exporting to PDF
-	return nil
+func (e *dataExporter) ExportFromExcel(filename string) error {
+	return e.exportFile(filename, "excel")
 }
+func (e *dataExporter) ExportFromPDF(filename string) error { return e.exportFile(filename, "pdf") }
 
-func (e dataExporter) ExportFromCSV(filename string) error {
-	// Implementation for exporting to Markdown
-	return nil
+func (e *dataExporter) ExportFile(filename string) error {
+	return e.exportFile(filename, DataFormatOf(filename))
 }
-func (e dataExporter) ExportFromProperties(filename string) error {
-	return nil
+
+func (e *dataExporter) Report() *DataReport { return &e.report }
+
+func (e *dataExporter) exportFile(filename, format string) error {
+	if !dataExportFormats[format] {
+		return fmt.Errorf("❌ %w: %s", ErrDataFormat, format)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao criar '%s': %v", filename, err)
+	}
+	if err := e.Export(f, format); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
-func (e dataExporter) ExportFromText(filename string) error {
-	return nil
+
+var dataExportFormats = map[string]bool{
+	"csv": true, "jsonl": true, "json": true, "yaml": true, "toml": true,
+	"ini": true, "properties": true, "env": true, "xml": true,
 }
-func (e dataExporter) ExportFromASN(filename string) error {
+
+func (e *dataExporter) Export(w io.Writer, format string) error {
+	if !dataExportFormats[format] {
+		return fmt.Errorf("❌ %w: %s", ErrDataFormat, format)
+	}
+	e.report.Format = format
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case "csv":
+		err = e.writeCSV(bw)
+	case "jsonl", "json":
+		err = e.writeJSON(bw, format == "json")
+	case "xml":
+		err = e.writeXML(bw)
+	default:
+		err = e.writeDocument(bw, format)
+	}
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("❌ Erro ao gravar os dados: %v", err)
+	}
 	return nil
 }
-func (e dataExporter) ExportFromHTML(filename string) error {
-	return nil
+
+// each calls fn with the fields and columns of the export and each record.
+func (e *dataExporter) each(fn func(fields, columns []string, rec DataRecord) error) error {
+	var fields, columns []string
+	return e.source(func(rec DataRecord) error {
+		if fields == nil {
+			fields = e.mapping.Fields
+			if len(fields) == 0 {
+				fields = slices.Sorted(maps.Keys(rec))
+			}
+			for _, field := range fields {
+				column := field
+				if to, ok := e.mapping.Columns[field]; ok {
+					column = to
+				}
+				columns = append(columns, column)
+			}
+		}
+		e.report.Rows++
+		if err := fn(fields, columns, rec); err != nil {
+			return err
+		}
+		e.report.Processed++
+		return nil
+	})
 }
-func (e dataExporter) ExportFromMarkdown(filename string) error {
-	return nil
+
+func (e *dataExporter) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	values := []string{}
+	err := e.each(func(fields, columns []string, rec DataRecord) error {
+		if e.report.Rows == 1 {
+			if err := cw.Write(dropColumns(columns, columns)); err != nil {
+				return err
+			}
+		}
+		values = values[:0]
+		for i, field := range fields {
+			if columns[i] == "-" {
+				continue
+			}
+			s, err := FormatDataValue(rec[field])
+			if err != nil {
+				return fmt.Errorf("❌ Linha %d, campo '%s': %v", e.report.Rows, field, err)
+			}
+			values = append(values, s)
+		}
+		return cw.Write(values)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
 }
 
-func (e dataExporter) ExportFromBinary(filename string) error {
-	return nil
+// writeJSON writes a JSON object per line, or a JSON array, keeping the
+// field order.
+func (e *dataExporter) writeJSON(w io.Writer, array bool) error {
+	if array {
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+	}
+	err := e.each(func(fields, columns []string, rec DataRecord) error {
+		buf := []byte{}
+		if array {
+			if e.report.Rows > 1 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, '{')
+		n := 0
+		for i, field := range fields {
+			if columns[i] == "-" {
+				continue
+			}
+			if n > 0 {
+				buf = append(buf, ',')
+			}
+			n++
+			key, _ := json.Marshal(columns[i])
+			value, err := json.Marshal(rec[field])
+			if err != nil {
+				return fmt.Errorf("❌ Linha %d, campo '%s': %v", e.report.Rows, field, err)
+			}
+			buf = append(append(append(buf, key...), ':'), value...)
+		}
+		buf = append(buf, '}')
+		if !array {
+			buf = append(buf, '\n')
+		}
+		_, err := w.Write(buf)
+		return err
+	})
+	if err != nil || !array {
+		return err
+	}
+	end := "]\n"
+	if e.report.Rows > 0 {
+		end = "\n]\n"
+	}
+	_, err = io.WriteString(w, end)
+	return err
 }
-func (e dataExporter) ExportFromExcel(filename string) error {
-	return nil
+
+func (e *dataExporter) writeXML(w io.Writer) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	rows := xml.StartElement{Name: xml.Name{Local: "rows"}}
+	if err := enc.EncodeToken(rows); err != nil {
+		return err
+	}
+	err := e.each(func(fields, columns []string, rec DataRecord) error {
+		row := xml.StartElement{Name: xml.Name{Local: "row"}}
+		if err := enc.EncodeToken(row); err != nil {
+			return err
+		}
+		for i, field := range fields {
+			if columns[i] == "-" || rec[field] == nil {
+				continue
+			}
+			s, err := FormatDataValue(rec[field])
+			if err != nil {
+				return fmt.Errorf("❌ Linha %d, campo '%s': %v", e.report.Rows, field, err)
+			}
+			if err := enc.EncodeElement(s, xml.StartElement{Name: xml.Name{Local: columns[i]}}); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(row.End())
+	})
+	if err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(rows.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
 }
-func (e dataExporter) ExportFromPDF(filename string) error {
-	return nil
+
+// writeDocument collects the records and writes them as a YAML list, TOML
+// [[rows]] tables, INI sections (one per record, named by its row) or, for a
+// single record, as .env or properties.
+func (e *dataExporter) writeDocument(w io.Writer, format string) error {
+	var records []DataRecord
+	var order []string
+	err := e.each(func(fields, columns []string, rec DataRecord) error {
+		order = dropColumns(columns, columns)
+		out := make(DataRecord, len(fields))
+		for i, field := range fields {
+			if columns[i] == "-" {
+				continue
+			}
+			v, err := plainDataValue(rec[field])
+			if err == nil && format != "yaml" && format != "toml" {
+				v, err = FormatDataValue(v)
+			}
+			if err != nil {
+				return fmt.Errorf("❌ Linha %d, campo '%s': %v", e.report.Rows, field, err)
+			}
+			if v == nil && format != "yaml" {
+				continue
+			}
+			out[columns[i]] = v
+		}
+		records = append(records, out)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch format {
+	case "yaml":
+		if records == nil {
+			records = []DataRecord{}
+		}
+		data, err = NewMapperPtr(&records, "").Serialize("yaml")
+	case "toml":
+		doc := map[string][]DataRecord{"rows": records}
+		data, err = NewMapperPtr(&doc, "").Serialize("toml")
+	case "env":
+		if len(records) > 1 {
+			return fmt.Errorf("❌ O formato env comporta um único registro (%d exportados)", len(records))
+		}
+		env := map[string]string{}
+		for _, rec := range records {
+			for k, v := range rec {
+				env[k] = v.(string)
+			}
+		}
+		data, err = NewMapperType(&env, "").Serialize("env")
+		data = append(data, '\n')
+	case "ini", "properties":
+		if format == "properties" && len(records) > 1 {
+			return fmt.Errorf("❌ O formato properties comporta um único registro (%d exportados)", len(records))
+		}
+		file := ini.Empty()
+		for i, rec := range records {
+			section := file.Section(ini.DefaultSection)
+			if format == "ini" {
+				section = file.Section(strconv.Itoa(i + 1))
+			}
+			for _, column := range order {
+				if v, ok := rec[column]; ok {
+					section.Key(column).SetValue(v.(string))
+				}
+			}
+		}
+		_, err = file.WriteTo(w)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// dropColumns returns the names not dropped ("-") by the mapping.
+func dropColumns(names, columns []string) []string {
+	out := make([]string, 0, len(names))
+	for i, name := range names {
+		if columns[i] != "-" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// FormatDataValue formats a value as the text of the CSV, INI, .env and XML
+// exports: dates in RFC 3339, lists and objects as JSON, nil as "".
+func FormatDataValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		return v.Format(time.RFC3339Nano), nil
+	case json.Number:
+		return v.String(), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), err
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		if _, loop := value.(driver.Valuer); !loop {
+			return FormatDataValue(value)
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", nil
+		}
+		return FormatDataValue(rv.Elem().Interface())
+	} else if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// plainDataValue reduces v to the values YAML and TOML encode as such:
+// strings, numbers, booleans, dates, and lists and maps of them.
+func plainDataValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case []byte:
+		return string(v), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), err
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, loop := value.(driver.Valuer); !loop {
+			return plainDataValue(value)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return plainDataValue(rv.Elem().Interface())
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	// lists, maps and structs, as their JSON
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
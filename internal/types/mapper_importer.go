@@ -1,5 +1,103 @@
 package types
 
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DataRecord is a row read by a DataImporter or written by a DataExporter,
+// keyed by field name.
+type DataRecord = map[string]any
+
+// DataMapping maps the columns of a file to the fields of a model.
+type DataMapping struct {
+	// Columns renames the columns of the file (column -> field on import,
+	// field -> column on export); "-" drops the column
+	Columns map[string]string `json:"columns,omitempty" yaml:"columns,omitempty"`
+	// Types, when set, lists the accepted fields, whose values are coerced to
+	// the given types on import
+	Types map[string]reflect.Type `json:"-" yaml:"-"`
+	// Fields are the fields exported, in order (default: the fields of the
+	// first record, sorted)
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// BatchSize is the number of records passed at once to the DataSink
+	// (default DefaultDataBatchSize)
+	BatchSize int `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+	// MaxErrors stops the import after as many failed rows (0 never stops)
+	MaxErrors int `json:"maxErrors,omitempty" yaml:"maxErrors,omitempty"`
+}
+
+// DefaultDataBatchSize is the batch size of the importers.
+const DefaultDataBatchSize = 100
+
+// DataRowError is the failure of a row (1-based, in file order) and, when
+// known, of a field.
+type DataRowError struct {
+	Row     int    `json:"row" yaml:"row"`
+	Field   string `json:"field,omitempty" yaml:"field,omitempty"`
+	Message string `json:"error" yaml:"error"`
+}
+
+func (e DataRowError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("linha %d, campo '%s': %s", e.Row, e.Field, e.Message)
+	}
+	return fmt.Sprintf("linha %d: %s", e.Row, e.Message)
+}
+
+// DataReport counts the rows of an import or export.
+type DataReport struct {
+	Format    string         `json:"format" yaml:"format"`
+	Rows      int            `json:"rows" yaml:"rows"`
+	Processed int            `json:"processed" yaml:"processed"`
+	Failed    int            `json:"failed" yaml:"failed"`
+	Errors    []DataRowError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// DataSink receives the records accepted by a DataImporter, in batches. It
+// returns the errors of the records that failed (indexed like batch, nil
+// entries for the ones written), and a non-nil error to stop the import.
+type DataSink func(batch []DataRecord) ([]error, error)
+
+// ErrDataFormat is returned for the formats an importer or exporter does not
+// handle.
+var ErrDataFormat = errors.New("formato de dados não suportado")
+
+// DataFormatOf returns the format of filename by its extension (.csv, .jsonl,
+// .ndjson, .json, .yaml, .yml, .toml, .ini, .properties, .env, .xml).
+func DataFormatOf(filename string) string {
+	base := filepath.Base(filename)
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return "env"
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(base), ".")); ext {
+	case "ndjson":
+		return "jsonl"
+	case "yml":
+		return "yaml"
+	default:
+		return ext
+	}
+}
+
 type DataImporter interface {
 	ImportFromYAML(filename string) error
 	ImportFromJSON(filename string) error
@@ -16,55 +114,646 @@ type DataImporter interface {
 	ImportFromExcel(filename string) error
 	ImportFromPDF(filename string) error
 	ImportFromMarkdown(filename string) error
+
+	// ImportFromJSONL imports a JSON Lines file, one object per line.
+	ImportFromJSONL(filename string) error
+	// ImportFile imports filename in the format of its extension.
+	ImportFile(filename string) error
+	// Import reads the records of r in format.
+	Import(r io.Reader, format string) error
+	// Report returns the counts and row errors of the imports so far.
+	Report() *DataReport
 }
-type dataImporter struct{}
 
-func NewDataImporter() DataImporter { return &dataImporter{} }
+// dataImporter reads the records of a file, maps and coerces them with the
+// mapping and hands them to the sink. CSV, JSON and JSON Lines are streamed;
+// the other formats are read whole through a Mapper.
+type dataImporter struct {
+	sink    DataSink
+	mapping DataMapping
+	report  DataReport
 
-func (d dataImporter) ImportFromYAML(filename string) error {
-	return nil
+	batch []DataRecord
+	rows  []int
 }
-func (d dataImporter) ImportFromJSON(filename string) error {
-	return nil
+
+// NewDataImporter returns an importer sending the records to sink; mapping
+// may be nil.
+func NewDataImporter(sink DataSink, mapping *DataMapping) DataImporter {
+	d := &dataImporter{sink: sink}
+	if mapping != nil {
+		d.mapping = *mapping
+	}
+	if d.mapping.BatchSize <= 0 {
+		d.mapping.BatchSize = DefaultDataBatchSize
+	}
+	return d
 }
-func (d dataImporter) ImportFromXML(filename string) error {
-	return nil
+
+func (d *dataImporter) ImportFromYAML(filename string) error { return d.importFile(filename, "yaml") }
+func (d *dataImporter) ImportFromJSON(filename string) error { return d.importFile(filename, "json") }
+func (d *dataImporter) ImportFromXML(filename string) error  { return d.importFile(filename, "xml") }
+func (d *dataImporter) ImportFromTOML(filename string) error { return d.importFile(filename, "toml") }
+func (d *dataImporter) ImportFromENV(filename string) error  { return d.importFile(filename, "env") }
+func (d *dataImporter) ImportFromINI(filename string) error  { return d.importFile(filename, "ini") }
+func (d *dataImporter) ImportFromCSV(filename string) error  { return d.importFile(filename, "csv") }
+func (d *dataImporter) ImportFromJSONL(filename string) error {
+	return d.importFile(filename, "jsonl")
 }
-func (d dataImporter) ImportFromTOML(filename string) error {
-	return nil
+func (d *dataImporter) ImportFromProperties(filename string) error {
+	return d.importFile(filename, "properties")
 }
-func (d dataImporter) ImportFromENV(filename string) error {
-	return nil
+func (d *dataImporter) ImportFromText(filename string) error { return d.importFile(filename, "text") }
+func (d *dataImporter) ImportFromASN(filename string) error  { return d.importFile(filename, "asn") }
+func (d *dataImporter) ImportFromHTML(filename string) error { return d.importFile(filename, "html") }
+func (d *dataImporter) ImportFromMarkdown(filename string) error {
+	return d.importFile(filename, "markdown")
 }
-func (d dataImporter) ImportFromINI(filename string) error {
-	return nil
+func (d *dataImporter) ImportFromBinary(filename string) error {
+	return d.importFile(filename, "binary")
 }
+func (d *dataImporter) ImportFromExcel(filename string) error {
+	return d.importFile(filename, "excel")
+}
+func (d *dataImporter) ImportFromPDF(filename string) error { return d.importFile(filename, "pdf") }
 
-func (d dataImporter) ImportFromCSV(filename string) error {
-	return nil
+func (d *dataImporter) ImportFile(filename string) error {
+	return d.importFile(filename, DataFormatOf(filename))
 }
-func (d dataImporter) ImportFromProperties(filename string) error {
-	return nil
+
+func (d *dataImporter) Report() *DataReport { return &d.report }
+
+func (d *dataImporter) importFile(filename, format string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao abrir '%s': %v", filename, err)
+	}
+	defer f.Close()
+	return d.Import(f, format)
 }
-func (d dataImporter) ImportFromText(filename string) error {
-	return nil
+
+func (d *dataImporter) Import(r io.Reader, format string) error {
+	d.report.Format = format
+	var err error
+	switch format {
+	case "csv":
+		err = d.readCSV(r)
+	case "jsonl":
+		err = d.readJSONL(r)
+	case "json":
+		err = d.readJSON(r)
+	case "yaml", "toml":
+		err = d.readDocument(r, format)
+	case "ini", "properties":
+		err = d.readINI(r)
+	case "env":
+		err = d.readEnv(r)
+	case "xml":
+		err = d.readXML(r)
+	default:
+		return fmt.Errorf("❌ %w: %s", ErrDataFormat, format)
+	}
+	if err != nil {
+		return err
+	}
+	return d.flush()
+}
+
+func (d *dataImporter) readCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("❌ Cabeçalho CSV inválido: %v", err)
+	}
+	header = slices.Clone(header)
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for {
+		values, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if err := d.addRow(nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(values) != len(header) {
+			if err := d.addRow(nil, fmt.Errorf("%d colunas, esperadas %d", len(values), len(header))); err != nil {
+				return err
+			}
+			continue
+		}
+		rec := make(DataRecord, len(header))
+		for i, column := range header {
+			rec[column] = values[i]
+		}
+		if err := d.addRow(rec, nil); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *dataImporter) readJSONL(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var rec DataRecord
+			err := json.Unmarshal(line, &rec)
+			if err == nil && rec == nil {
+				err = fmt.Errorf("a linha não é um objeto JSON")
+			}
+			if err := d.addRow(rec, err); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		} else if readErr != nil {
+			return fmt.Errorf("❌ Erro ao ler os dados: %v", readErr)
+		}
+	}
+}
+
+// readJSON streams a JSON array of objects, or reads a single object.
+func (d *dataImporter) readJSON(r io.Reader) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("❌ Erro ao ler os dados: %v", err)
+	}
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	switch first {
+	case '{':
+		var rec DataRecord
+		if err := dec.Decode(&rec); err != nil {
+			return fmt.Errorf("❌ JSON inválido: %v", err)
+		}
+		return d.addRow(rec, nil)
+	case '[':
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("❌ JSON inválido: %v", err)
+		}
+		for dec.More() {
+			var rec DataRecord
+			if err := dec.Decode(&rec); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					return fmt.Errorf("❌ JSON inválido após a linha %d: %v", d.report.Rows, err)
+				}
+				err = fmt.Errorf("o item não é um objeto JSON")
+				rec = nil
+			}
+			if err := d.addRow(rec, err); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("❌ JSON inválido: esperado um objeto ou uma lista de objetos")
+	}
 }
-func (d dataImporter) ImportFromASN(filename string) error {
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// readDocument reads a YAML or TOML document: a list of records, a record, or
+// a document whose only key holds a list of records ([[products]] in TOML).
+func (d *dataImporter) readDocument(r io.Reader, format string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler os dados: %v", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var doc any
+	if _, err := NewMapperPtr(&doc, "").Deserialize(data, format); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if m, ok := doc.(map[string]any); ok && len(m) == 1 {
+		for _, v := range m {
+			if list, ok := v.([]any); ok {
+				doc = list
+			} else if list, ok := v.([]map[string]any); ok {
+				doc = list
+			}
+		}
+	}
+	switch v := doc.(type) {
+	case []map[string]any:
+		for _, rec := range v {
+			if err := d.addRow(rec, nil); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			rec, ok := item.(map[string]any)
+			var err error
+			if !ok {
+				err = fmt.Errorf("o item não é um objeto")
+			}
+			if err := d.addRow(rec, err); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return d.addRow(v, nil)
+	default:
+		return fmt.Errorf("❌ Documento %s inválido: esperado um objeto ou uma lista de objetos", format)
+	}
 	return nil
 }
-func (d dataImporter) ImportFromHTML(filename string) error {
+
+// readINI reads one record per section, or the keys without a section as a
+// single record when the file has no sections.
+func (d *dataImporter) readINI(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler os dados: %v", err)
+	}
+	file, err := ini.Load(data)
+	if err != nil {
+		return fmt.Errorf("❌ INI inválido: %v", err)
+	}
+	sections := file.Sections()
+	if len(sections) > 1 {
+		sections = sections[1:]
+	}
+	for _, section := range sections {
+		if len(section.Keys()) == 0 && section.Name() == ini.DefaultSection {
+			continue
+		}
+		rec := DataRecord{}
+		for _, key := range section.Keys() {
+			rec[key.Name()] = key.Value()
+		}
+		if err := d.addRow(rec, nil); err != nil {
+			return err
+		}
+	}
 	return nil
 }
-func (d dataImporter) ImportFromMarkdown(filename string) error {
+
+// readEnv reads a .env file as a single record.
+func (d *dataImporter) readEnv(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("❌ Erro ao ler os dados: %v", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	env := map[string]string{}
+	if _, err := NewMapperPtr(&env, "").Deserialize(data, "env"); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	rec := make(DataRecord, len(env))
+	for k, v := range env {
+		rec[k] = v
+	}
+	return d.addRow(rec, nil)
+}
+
+// readXML streams the children of the root element as records, their child
+// elements as fields: <rows><row><name>Bolt</name></row></rows>.
+func (d *dataImporter) readXML(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	var rec DataRecord
+	var field string
+	var text strings.Builder
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("❌ XML inválido após a linha %d: %v", d.report.Rows, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 2:
+				rec = DataRecord{}
+			case 3:
+				field = t.Name.Local
+				text.Reset()
+			}
+		case xml.CharData:
+			if depth == 3 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch depth {
+			case 2:
+				if err := d.addRow(rec, nil); err != nil {
+					return err
+				}
+			case 3:
+				rec[field] = text.String()
+			}
+			depth--
+		}
+	}
+}
+
+// addRow maps and coerces rec (or records err for it) and queues it for the
+// sink.
+func (d *dataImporter) addRow(rec DataRecord, err error) error {
+	d.report.Rows++
+	row := d.report.Rows
+	if err != nil {
+		return d.fail(row, "", err)
+	}
+	out := make(DataRecord, len(rec))
+	var failed bool
+	for column, v := range rec {
+		field := column
+		if to, ok := d.mapping.Columns[column]; ok {
+			field = to
+		}
+		if field == "-" {
+			continue
+		}
+		if d.mapping.Types != nil {
+			t, ok := d.mapping.Types[field]
+			if !ok {
+				failed = true
+				if err := d.fail(row, column, fmt.Errorf("campo desconhecido")); err != nil {
+					return err
+				}
+				continue
+			}
+			if v, err = CoerceDataValue(v, t); err != nil {
+				failed = true
+				if err := d.fail(row, column, err); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		out[field] = v
+	}
+	if failed {
+		d.report.Failed++
+		return d.checkErrors()
+	}
+	d.batch = append(d.batch, out)
+	d.rows = append(d.rows, row)
+	if len(d.batch) >= d.mapping.BatchSize {
+		return d.flush()
+	}
 	return nil
 }
 
-func (d dataImporter) ImportFromBinary(filename string) error {
+// fail records the error of a row; a row-level error (field "") counts the
+// row as failed.
+func (d *dataImporter) fail(row int, field string, err error) error {
+	d.report.Errors = append(d.report.Errors, DataRowError{Row: row, Field: field, Message: err.Error()})
+	if field == "" {
+		d.report.Failed++
+		return d.checkErrors()
+	}
 	return nil
 }
-func (d dataImporter) ImportFromExcel(filename string) error {
+
+func (d *dataImporter) checkErrors() error {
+	if d.mapping.MaxErrors > 0 && d.report.Failed >= d.mapping.MaxErrors {
+		return fmt.Errorf("❌ Importação interrompida após %d linhas com erro", d.report.Failed)
+	}
 	return nil
 }
-func (d dataImporter) ImportFromPDF(filename string) error {
+
+func (d *dataImporter) flush() error {
+	if len(d.batch) == 0 {
+		return nil
+	}
+	batch, rows := d.batch, d.rows
+	d.batch, d.rows = nil, nil
+	errs, err := d.sink(batch)
+	if err != nil {
+		return err
+	}
+	for i := range batch {
+		if i < len(errs) && errs[i] != nil {
+			if err := d.fail(rows[i], "", errs[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		d.report.Processed++
+	}
 	return nil
 }
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	textUnmarshalerTyp = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	scannerType        = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// CoerceDataValue converts a value read from a file (a string from CSV, INI
+// or .env, a number, bool, date, list or object from the other formats) to
+// t. An empty string is the zero value (nil for pointers) of the non-string
+// types.
+func CoerceDataValue(v any, t reflect.Type) (any, error) {
+	if n, ok := v.(json.Number); ok {
+		v = string(n)
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(n.String()).Convert(t).Interface(), nil
+		}
+	}
+	if v == nil {
+		return reflect.Zero(t).Interface(), nil
+	}
+	if t.Kind() == reflect.Pointer {
+		if s, ok := v.(string); ok && s == "" && t.Elem().Kind() != reflect.String {
+			return reflect.Zero(t).Interface(), nil
+		}
+		elem, err := CoerceDataValue(v, t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(elem))
+		return ptr.Interface(), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() == t {
+		return v, nil
+	}
+	invalid := func(err error) error {
+		if err != nil {
+			return fmt.Errorf("valor '%v' inválido para %s: %v", v, t, err)
+		}
+		return fmt.Errorf("valor '%v' inválido para %s", v, t)
+	}
+
+	if s, ok := v.(string); ok {
+		if s == "" && t.Kind() != reflect.String {
+			return reflect.Zero(t).Interface(), nil
+		}
+		ptr := reflect.New(t)
+		switch {
+		case t.Implements(textUnmarshalerTyp) || ptr.Type().Implements(textUnmarshalerTyp):
+			if t == timeType {
+				break
+			}
+			if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+				return nil, invalid(err)
+			}
+			return ptr.Elem().Interface(), nil
+		case ptr.Type().Implements(scannerType):
+			if err := ptr.Interface().(sql.Scanner).Scan(s); err != nil {
+				return nil, invalid(err)
+			}
+			return ptr.Elem().Interface(), nil
+		}
+		s = strings.TrimSpace(s)
+		switch t.Kind() {
+		case reflect.String:
+			return reflect.ValueOf(v).Convert(t).Interface(), nil
+		case reflect.Bool:
+			b, err := parseDataBool(s)
+			if err != nil {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(b).Convert(t).Interface(), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, t.Bits())
+			if err != nil {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(n).Convert(t).Interface(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, t.Bits())
+			if err != nil {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(n).Convert(t).Interface(), nil
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(s, t.Bits())
+			if err != nil {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(f).Convert(t).Interface(), nil
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				return reflect.ValueOf([]byte(s)).Convert(t).Interface(), nil
+			}
+		}
+		if t == timeType {
+			for _, layout := range []string{time.RFC3339Nano, time.DateTime, "2006-01-02T15:04:05", time.DateOnly} {
+				if at, err := time.Parse(layout, s); err == nil {
+					return at, nil
+				}
+			}
+			return nil, invalid(nil)
+		}
+		// lists and objects written as JSON
+		if err := json.Unmarshal([]byte(s), ptr.Interface()); err != nil {
+			return nil, invalid(nil)
+		}
+		return ptr.Elem().Interface(), nil
+	}
+
+	switch {
+	case rv.Kind() == reflect.Bool && t.Kind() == reflect.Bool:
+		return rv.Convert(t).Interface(), nil
+	case rv.CanInt() || rv.CanUint() || rv.CanFloat():
+		var f float64
+		switch {
+		case rv.CanInt():
+			f = float64(rv.Int())
+		case rv.CanUint():
+			f = float64(rv.Uint())
+		default:
+			f = rv.Float()
+		}
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n := int64(f)
+			if rv.CanInt() {
+				n = rv.Int()
+			} else if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || (rv.CanUint() && rv.Uint() > math.MaxInt64) {
+				return nil, invalid(nil)
+			}
+			if reflect.New(t).Elem().OverflowInt(n) {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(n).Convert(t).Interface(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if f < 0 || f != math.Trunc(f) || f >= math.MaxUint64 {
+				return nil, invalid(nil)
+			}
+			n := uint64(f)
+			if rv.CanUint() {
+				n = rv.Uint()
+			}
+			if reflect.New(t).Elem().OverflowUint(n) {
+				return nil, invalid(nil)
+			}
+			return reflect.ValueOf(n).Convert(t).Interface(), nil
+		case reflect.Float32, reflect.Float64:
+			return rv.Convert(t).Interface(), nil
+		case reflect.String:
+			return reflect.ValueOf(fmt.Sprint(v)).Convert(t).Interface(), nil
+		}
+		if ptr := reflect.New(t); ptr.Type().Implements(scannerType) {
+			if err := ptr.Interface().(sql.Scanner).Scan(f); err != nil {
+				return nil, invalid(err)
+			}
+			return ptr.Elem().Interface(), nil
+		}
+	case rv.Type() == timeType:
+		switch {
+		case t.Kind() == reflect.String:
+			return reflect.ValueOf(v.(time.Time).Format(time.RFC3339Nano)).Convert(t).Interface(), nil
+		case rv.Type().ConvertibleTo(t):
+			return rv.Convert(t).Interface(), nil
+		}
+		return nil, invalid(nil)
+	}
+	if t.Kind() == reflect.String {
+		return nil, invalid(nil)
+	}
+	// lists and objects
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, invalid(nil)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+func parseDataBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "sim", "s", "on":
+		return true, nil
+	case "no", "n", "não", "nao", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
@@ -0,0 +1,204 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var dataTestTypes = map[string]reflect.Type{
+	"id":     reflect.TypeOf(uuid.UUID{}),
+	"name":   reflect.TypeOf(""),
+	"price":  reflect.TypeOf(float64(0)),
+	"stock":  reflect.TypeOf(int(0)),
+	"active": reflect.TypeOf(false),
+	"tags":   reflect.TypeOf([]string{}),
+	"since":  reflect.TypeOf(&time.Time{}),
+}
+
+// collect returns a sink storing the records, failing the ones named "fail".
+func collect(out *[]DataRecord) DataSink {
+	return func(batch []DataRecord) ([]error, error) {
+		errs := make([]error, len(batch))
+		for i, rec := range batch {
+			if rec["name"] == "fail" {
+				errs[i] = errors.New("rejected")
+				continue
+			}
+			*out = append(*out, rec)
+		}
+		return errs, nil
+	}
+}
+
+func TestDataImporterCSV(t *testing.T) {
+	var got []DataRecord
+	imp := NewDataImporter(collect(&got), &DataMapping{
+		Columns:   map[string]string{"Product": "name", "notes": "-"},
+		Types:     dataTestTypes,
+		BatchSize: 2,
+	})
+	csv := "Product,price,stock,active,tags,since,notes\n" +
+		"Bolt,0.25,10,yes,\"[\"\"a\"\",\"\"b\"\"]\",2026-01-02,x\n" +
+		"Nut,abc,1,true,,,\n" +
+		"Washer,1,2\n" +
+		"fail,1,1,no,,,\n" +
+		"Screw,,,,,,\n"
+	if err := imp.Import(strings.NewReader(csv), "csv"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	r := imp.Report()
+	if r.Rows != 5 || r.Processed != 2 || r.Failed != 3 || len(got) != 2 {
+		t.Fatalf("report %+v, %d records", r, len(got))
+	}
+	bolt := got[0]
+	since := bolt["since"].(*time.Time)
+	if bolt["name"] != "Bolt" || bolt["price"] != 0.25 || bolt["stock"] != 10 || bolt["active"] != true ||
+		!reflect.DeepEqual(bolt["tags"], []string{"a", "b"}) || since.Day() != 2 || bolt["notes"] != nil {
+		t.Fatalf("unexpected record %#v", bolt)
+	}
+	if screw := got[1]; screw["stock"] != 0 || screw["since"].(*time.Time) != nil {
+		t.Fatalf("empty cells are zero: %#v", screw)
+	}
+	want := []DataRowError{
+		{Row: 2, Field: "price"},
+		{Row: 3},
+		{Row: 4, Message: "rejected"},
+	}
+	for i, w := range want {
+		e := r.Errors[i]
+		if e.Row != w.Row || e.Field != w.Field || (w.Message != "" && e.Message != w.Message) {
+			t.Fatalf("error %d: %+v", i, e)
+		}
+	}
+}
+
+func TestDataImporterFormats(t *testing.T) {
+	for format, data := range map[string]string{
+		"json":       `[{"name": "Bolt", "stock": 10}, {"name": "Nut", "stock": 2}]`,
+		"jsonl":      "{\"name\": \"Bolt\", \"stock\": 10}\n\n{\"name\": \"Nut\", \"stock\": 2}\n",
+		"yaml":       "- name: Bolt\n  stock: 10\n- name: Nut\n  stock: 2\n",
+		"toml":       "[[products]]\nname = \"Bolt\"\nstock = 10\n[[products]]\nname = \"Nut\"\nstock = 2\n",
+		"ini":        "[bolt]\nname = Bolt\nstock = 10\n[nut]\nname = Nut\nstock = 2\n",
+		"xml":        "<rows><row><name>Bolt</name><stock>10</stock></row><row><name>Nut</name><stock>2</stock></row></rows>",
+		"properties": "name = Bolt\nstock = 10\n",
+		"env":        "name=Bolt\nstock=10\n",
+	} {
+		var got []DataRecord
+		imp := NewDataImporter(collect(&got), &DataMapping{Types: dataTestTypes})
+		if err := imp.Import(strings.NewReader(data), format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(got) == 0 || got[0]["name"] != "Bolt" || got[0]["stock"] != 10 {
+			t.Fatalf("%s: %#v %+v", format, got, imp.Report())
+		}
+		if format != "properties" && format != "env" && (len(got) != 2 || got[1]["stock"] != 2) {
+			t.Fatalf("%s: %#v", format, got)
+		}
+	}
+
+	imp := NewDataImporter(collect(new([]DataRecord)), &DataMapping{Types: dataTestTypes, MaxErrors: 2})
+	err := imp.Import(strings.NewReader("{\"name\": \"a\"}\nnot json\n[1]\n{\"other\": 1}\n"), "jsonl")
+	if err == nil || imp.Report().Failed != 2 || imp.Report().Rows != 3 {
+		t.Fatalf("expected a stop after 2 errors, got %v, %+v", err, imp.Report())
+	}
+	if err := NewDataImporter(collect(new([]DataRecord)), nil).ImportFromPDF("x.pdf"); err == nil {
+		t.Fatalf("expected an error for pdf")
+	}
+	if err := NewDataImporter(collect(new([]DataRecord)), nil).Import(strings.NewReader(""), "pdf"); !errors.Is(err, ErrDataFormat) {
+		t.Fatalf("expected ErrDataFormat, got %v", err)
+	}
+}
+
+func TestDataExporterRoundTrip(t *testing.T) {
+	id := uuid.New()
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []DataRecord{
+		{"id": id, "name": "Bolt, \"M6\"", "price": 0.25, "stock": 10, "active": true, "tags": []string{"a"}, "since": &since},
+		{"id": uuid.Nil, "name": "Nut", "price": 1.5, "stock": 0, "active": false, "tags": []string{}, "since": nil},
+	}
+	source := func(emit func(DataRecord) error) error {
+		for _, rec := range records {
+			if err := emit(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	mapping := &DataMapping{Fields: []string{"id", "name", "price", "stock", "active", "tags", "since"}}
+	for _, format := range []string{"csv", "jsonl", "json", "yaml", "toml", "ini", "xml"} {
+		var buf bytes.Buffer
+		exp := NewDataExporter(source, mapping)
+		if err := exp.Export(&buf, format); err != nil {
+			t.Fatalf("%s: Export: %v", format, err)
+		}
+		if exp.Report().Processed != 2 {
+			t.Fatalf("%s: %+v", format, exp.Report())
+		}
+		var got []DataRecord
+		imp := NewDataImporter(collect(&got), &DataMapping{Types: dataTestTypes})
+		if err := imp.Import(&buf, format); err != nil || len(got) != 2 {
+			t.Fatalf("%s: Import: %v, %+v", format, err, imp.Report())
+		}
+		for i, rec := range got {
+			for field, want := range records[i] {
+				v := rec[field]
+				if field == "since" {
+					if p, _ := v.(*time.Time); (p == nil) != (want == nil) || (p != nil && !p.Equal(since)) {
+						t.Fatalf("%s: row %d since %v", format, i, p)
+					}
+					continue
+				}
+				if field == "tags" && format != "csv" && format != "json" && format != "jsonl" && format != "yaml" && format != "toml" {
+					continue
+				}
+				if fmt.Sprint(v) != fmt.Sprint(want) {
+					t.Fatalf("%s: row %d %s = %#v, want %#v", format, i, field, v, want)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := NewDataExporter(source, mapping).Export(&buf, "env"); err == nil {
+		t.Fatalf("expected an error for several records in env")
+	}
+	buf.Reset()
+	exp := NewDataExporter(source, &DataMapping{Fields: []string{"name", "stock"}, Columns: map[string]string{"name": "Product", "stock": "-"}})
+	if err := exp.Export(&buf, "csv"); err != nil || buf.String() != "Product\n\"Bolt, \"\"M6\"\"\"\nNut\n" {
+		t.Fatalf("csv with mapping: %q, %v", buf.String(), err)
+	}
+}
+
+func TestCoerceDataValue(t *testing.T) {
+	intType, uintType := reflect.TypeOf(int8(0)), reflect.TypeOf(uint(0))
+	for _, c := range []struct {
+		v    any
+		t    reflect.Type
+		want any
+		ok   bool
+	}{
+		{"42", intType, int8(42), true},
+		{"300", intType, nil, false},
+		{float64(3), uintType, uint(3), true},
+		{float64(3.5), uintType, nil, false},
+		{float64(-1), uintType, nil, false},
+		{int64(7), reflect.TypeOf(""), "7", true},
+		{"off", reflect.TypeOf(false), false, true},
+		{"maybe", reflect.TypeOf(false), nil, false},
+		{map[string]any{"a": 1}, reflect.TypeOf(map[string]int{}), map[string]int{"a": 1}, true},
+		{"", reflect.TypeOf(0.0), 0.0, true},
+		{nil, reflect.TypeOf(""), "", true},
+	} {
+		got, err := CoerceDataValue(c.v, c.t)
+		if (err == nil) != c.ok || (c.ok && !reflect.DeepEqual(got, c.want)) {
+			t.Fatalf("CoerceDataValue(%#v, %s) = %#v, %v", c.v, c.t, got, err)
+		}
+	}
+}
@@ -7,6 +7,6 @@ type MongoDB struct {
 	Host      string            `json:"host" yaml:"host" xml:"host" toml:"host" mapstructure:"host"`
 	Port      interface{}       `json:"port" yaml:"port" xml:"port" toml:"port" mapstructure:"port"`
 	Username  string            `json:"username" yaml:"username" xml:"username" toml:"username" mapstructure:"username"`
-	Password  string            `json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password"`
+	Password  string            `json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password" secret:"true"`
 	Mapper    *Mapper[*MongoDB] `json:"-" yaml:"-" xml:"-" toml:"-" mapstructure:"-"`
 }
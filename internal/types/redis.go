@@ -7,7 +7,7 @@ type Redis struct {
 	Addr      string          `gorm:"omitempty" json:"addr" yaml:"addr" xml:"addr" toml:"addr" mapstructure:"addr"`
 	Port      any             `gorm:"omitempty" json:"port" yaml:"port" xml:"port" toml:"port" mapstructure:"port"`
 	Username  string          `gorm:"omitempty" json:"username" yaml:"username" xml:"username" toml:"username" mapstructure:"username"`
-	Password  string          `gorm:"omitempty" json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password"`
+	Password  string          `gorm:"omitempty" json:"password" yaml:"password" xml:"password" toml:"password" mapstructure:"password" secret:"true"`
 	DB        any             `gorm:"omitempty" json:"db" yaml:"db" xml:"db" toml:"db" mapstructure:"db"`
 	Volume    string          `gorm:"omitempty" json:"volume" yaml:"volume" xml:"volume" toml:"volume" mapstructure:"volume"`
 	Mapper    *Mapper[*Redis] `json:"-" yaml:"-" xml:"-" toml:"-" mapstructure:"-"`
@@ -0,0 +1,375 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	gl "github.com/kubex-ecosystem/gdbase/internal/module/logger"
+	crp "github.com/kubex-ecosystem/gdbase/internal/security/crypto"
+	"github.com/zalando/go-keyring"
+)
+
+// Secret references are config values of the form "<scheme>://<ref>" that are
+// replaced by the secret they point to when the config is loaded:
+//
+//	keyring://service/name   entry of the OS keyring (service defaults to "kubex")
+//	env://VAR                environment variable
+//	file:///run/secrets/x    file contents, without the trailing newline
+//	enc://<base64>           value encrypted with EncryptSecret
+//
+// Only the fields tagged `secret:"true"` are resolved. Mapper.ResolveSecrets
+// keeps the references so Mapper.Serialize writes them back instead of the
+// resolved values.
+
+const (
+	// SecretsKeyEnv holds the key used by enc:// references; when unset the key
+	// is read from (or created in) the keyring entry SecretsKeyName.
+	SecretsKeyEnv     = "GDBASE_SECRETS_KEY"
+	SecretsKeyService = "kubex"
+	SecretsKeyName    = "gdbase-secrets-key"
+)
+
+// SecretResolver resolves the references of one scheme; ref is the part after "<scheme>://".
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":     SecretResolverFunc(resolveEnvSecret),
+		"file":    SecretResolverFunc(resolveFileSecret),
+		"keyring": SecretResolverFunc(resolveKeyringSecret),
+		"enc":     SecretResolverFunc(resolveEncryptedSecret),
+	}
+)
+
+// secretRefs maps the address of a resolved field to its reference.
+type secretRefs map[*string]resolvedSecret
+
+type resolvedSecret struct {
+	ref   string
+	value string
+}
+
+// RegisterSecretResolver registers (or replaces) the resolver of a scheme.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	scheme = strings.ToLower(strings.TrimSuffix(scheme, "://"))
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	if resolver == nil {
+		delete(secretResolvers, scheme)
+		return
+	}
+	secretResolvers[scheme] = resolver
+}
+
+// SecretSchemes returns the registered schemes, sorted.
+func SecretSchemes() []string {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	schemes := make([]string, 0, len(secretResolvers))
+	for scheme := range secretResolvers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func secretResolverFor(value string) (SecretResolver, string, bool) {
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok || scheme == "" {
+		return nil, "", false
+	}
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	resolver, ok := secretResolvers[strings.ToLower(scheme)]
+	return resolver, ref, ok
+}
+
+// IsSecretRef reports whether value is a reference of a registered scheme.
+func IsSecretRef(value string) bool {
+	_, _, ok := secretResolverFor(value)
+	return ok
+}
+
+// ResolveSecret resolves a secret reference; other values are returned unchanged.
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	resolver, ref, ok := secretResolverFor(value)
+	if !ok {
+		return value, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	secret, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", RedactSecretRef(value), err)
+	}
+	return secret, nil
+}
+
+// RedactSecretRef hides the payload of enc:// references, which is the only
+// scheme whose reference carries the secret itself.
+func RedactSecretRef(value string) string {
+	if scheme, _, ok := strings.Cut(value, "://"); ok && strings.EqualFold(scheme, "enc") {
+		return "enc://***"
+	}
+	return value
+}
+
+// ResolveSecrets resolves in place the secret references held by the fields
+// tagged `secret:"true"` of obj (a pointer), walking nested structs, pointers,
+// maps and slices. Every failing reference is reported; the field keeps it.
+// Use Mapper.ResolveSecrets to serialize the references back.
+func ResolveSecrets(ctx context.Context, obj any) error {
+	return resolveSecrets(ctx, obj, nil)
+}
+
+// resolveSecrets resolves the secrets of obj, recording their references in
+// refs when it is not nil.
+func resolveSecrets(ctx context.Context, obj any, refs secretRefs) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("ResolveSecrets: expected a non-nil pointer, got %T", obj)
+	}
+	var errs []error
+	walkSecrets(v, make(map[uintptr]bool), func(field *string) {
+		ref := *field
+		if !IsSecretRef(ref) {
+			return
+		}
+		secret, err := ResolveSecret(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		*field = secret
+		if refs != nil {
+			refs[field] = resolvedSecret{ref: ref, value: secret}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walkSecrets calls fn with the address of every secret field reachable from v.
+func walkSecrets(v reflect.Value, seen map[uintptr]bool, fn func(*string)) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		walkSecrets(v.Elem(), seen, fn)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			f := v.Field(i)
+			if f.Kind() == reflect.String {
+				if sf.Tag.Get("secret") == "true" && f.CanAddr() {
+					fn(f.Addr().Interface().(*string))
+				}
+				continue
+			}
+			walkSecrets(f, seen, fn)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.Pointer {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			walkSecrets(iter.Value(), seen, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkSecrets(v.Index(i), seen, fn)
+		}
+	}
+}
+
+// refOf returns the reference a field was resolved from, when it still
+// holds the resolved value.
+func (refs secretRefs) refOf(field *string) (string, bool) {
+	r, ok := refs[field]
+	if !ok || *field != r.value {
+		return "", false
+	}
+	return r.ref, true
+}
+
+// mask returns obj, or a copy of it where the resolved secret fields hold
+// their references again. obj itself is never modified.
+func (refs secretRefs) mask(obj any) any {
+	v := reflect.ValueOf(obj)
+	if len(refs) == 0 || !v.IsValid() || v.Kind() != reflect.Pointer {
+		return obj
+	}
+	return refs.cloneMasked(v, make(map[uintptr]reflect.Value)).Interface()
+}
+
+// cloneMasked copies the pointers, maps and slices leading to secret fields,
+// so the masked values never reach the original object.
+func (refs secretRefs) cloneMasked(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return v
+		}
+		if c, ok := seen[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Elem().Type())
+		seen[v.Pointer()] = c
+		c.Elem().Set(v.Elem())
+		refs.maskStruct(v.Elem(), c.Elem(), seen)
+		return c
+	case reflect.Map:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.Pointer {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), refs.cloneMasked(iter.Value(), seen))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.Pointer {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(refs.cloneMasked(v.Index(i), seen))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+func (refs secretRefs) maskStruct(src, dst reflect.Value, seen map[uintptr]reflect.Value) {
+	t := src.Type()
+	for i := 0; i < src.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		f, out := src.Field(i), dst.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			if f.CanAddr() {
+				if ref, ok := refs.refOf(f.Addr().Interface().(*string)); ok {
+					out.SetString(ref)
+				}
+			}
+		case reflect.Struct:
+			refs.maskStruct(f, out, seen)
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			out.Set(refs.cloneMasked(f, seen))
+		}
+	}
+}
+
+func resolveEnvSecret(_ context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+func resolveFileSecret(_ context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(os.ExpandEnv(ref))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveKeyringSecret(_ context.Context, ref string) (string, error) {
+	service, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		service, name = SecretsKeyService, ref
+	}
+	if service == "" || name == "" {
+		return "", fmt.Errorf("invalid keyring reference %q", ref)
+	}
+	return keyring.Get(service, name)
+}
+
+func resolveEncryptedSecret(_ context.Context, ref string) (string, error) {
+	key, err := secretsKey(false)
+	if err != nil {
+		return "", err
+	}
+	plain, _, err := crp.NewCryptoService().Decrypt([]byte(ref), key)
+	if err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// EncryptSecret encrypts value with the secrets key and returns its enc:// reference.
+func EncryptSecret(value string) (string, error) {
+	key, err := secretsKey(true)
+	if err != nil {
+		return "", err
+	}
+	_, encoded, err := crp.NewCryptoService().Encrypt([]byte(value), key)
+	if err != nil {
+		return "", err
+	}
+	return "enc://" + encoded, nil
+}
+
+// NewSecretsKey generates a base64 key for enc:// references (e.g. for
+// GDBASE_SECRETS_KEY).
+func NewSecretsKey() (string, error) {
+	cs := crp.NewCryptoService()
+	raw, err := cs.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return cs.EncodeBase64(raw), nil
+}
+
+// secretsKey returns the base64 key of enc:// references, creating it in the
+// keyring when create is set and there is none yet.
+func secretsKey(create bool) ([]byte, error) {
+	if key := os.Getenv(SecretsKeyEnv); key != "" {
+		return []byte(key), nil
+	}
+	key, err := keyring.Get(SecretsKeyService, SecretsKeyName)
+	if err == nil {
+		return []byte(key), nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) || !create {
+		return nil, fmt.Errorf("secrets key not available (set %s): %w", SecretsKeyEnv, err)
+	}
+	encoded, err := NewSecretsKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := keyring.Set(SecretsKeyService, SecretsKeyName, encoded); err != nil {
+		return nil, fmt.Errorf("failed to store secrets key: %w", err)
+	}
+	gl.Log("info", fmt.Sprintf("Secrets key created in keyring entry %s/%s", SecretsKeyService, SecretsKeyName))
+	return []byte(encoded), nil
+}
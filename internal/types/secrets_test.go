@@ -0,0 +1,106 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type secretsConfig struct {
+	Databases map[string]*Database `json:"databases"`
+	Redis     *Redis               `json:"redis"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "pg")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GDBASE_TEST_REDIS_PASS", "from-env")
+	key, _ := NewSecretsKey()
+	t.Setenv(SecretsKeyEnv, key)
+	encrypted, err := EncryptSecret("from-enc")
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+	RegisterSecretResolver("vault", SecretResolverFunc(func(_ context.Context, ref string) (string, error) {
+		return "vault:" + ref, nil
+	}))
+	defer RegisterSecretResolver("vault", nil)
+
+	cfg := &secretsConfig{
+		Databases: map[string]*Database{
+			"main":  {Name: "main", Password: "file://" + secretFile, Path: "file://not-a-secret-field"},
+			"other": {Name: "other", Password: encrypted, ConnectionString: "vault://db/dsn"},
+		},
+		Redis: &Redis{Password: "env://GDBASE_TEST_REDIS_PASS"},
+	}
+	mapper := NewMapperType(&cfg, filepath.Join(dir, "cfg.json"))
+	if err := mapper.ResolveSecrets(context.Background()); err != nil {
+		t.Fatalf("ResolveSecrets: %v", err)
+	}
+	main, other := cfg.Databases["main"], cfg.Databases["other"]
+	if main.Password != "from-file" || cfg.Redis.Password != "from-env" || other.Password != "from-enc" {
+		t.Fatalf("unexpected values: %q %q %q", main.Password, cfg.Redis.Password, other.Password)
+	}
+	if other.ConnectionString != "vault:db/dsn" || main.Path != "file://not-a-secret-field" {
+		t.Fatalf("unexpected values: %q %q", other.ConnectionString, main.Path)
+	}
+
+	data, err := mapper.Serialize("json")
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	for _, secret := range []string{"from-file", "from-env", "from-enc", "vault:db/dsn"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("resolved secret %q written back: %s", secret, data)
+		}
+	}
+	var back secretsConfig
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Redis.Password != "env://GDBASE_TEST_REDIS_PASS" || back.Databases["other"].Password != encrypted {
+		t.Fatalf("references not preserved: %s", data)
+	}
+	if main.Password != "from-file" {
+		t.Fatalf("Serialize must not modify the object")
+	}
+
+	// a value changed after loading is a plain value again
+	main.Password = "changed"
+	data, _ = mapper.Serialize("json")
+	if !strings.Contains(string(data), `"changed"`) {
+		t.Fatalf("changed value not serialized: %s", data)
+	}
+	// the references belong to the mapper that resolved them
+	data, _ = NewMapperType(&cfg, "").Serialize("json")
+	if !strings.Contains(string(data), "from-env") {
+		t.Fatalf("another mapper must not know the references: %s", data)
+	}
+
+	// keys and secrets are used as they are, whitespace included
+	for range 64 {
+		key, err := NewSecretsKey()
+		if err != nil {
+			t.Fatalf("NewSecretsKey: %v", err)
+		}
+		t.Setenv(SecretsKeyEnv, key)
+		ref, err := EncryptSecret(" padded\n")
+		if err != nil {
+			t.Fatalf("EncryptSecret: %v", err)
+		}
+		if plain, err := ResolveSecret(context.Background(), ref); err != nil || plain != " padded\n" {
+			t.Fatalf("round trip = %q, %v", plain, err)
+		}
+	}
+
+	bad := &Database{Password: "env://GDBASE_TEST_MISSING_VAR"}
+	if err := ResolveSecrets(context.Background(), bad); err == nil || bad.Password != "env://GDBASE_TEST_MISSING_VAR" {
+		t.Fatalf("missing variable must fail and keep the reference: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	svc "github.com/kubex-ecosystem/gdbase/internal/services"
 	t "github.com/kubex-ecosystem/gdbase/internal/types"
 	l "github.com/kubex-ecosystem/logz"
+	"gorm.io/gorm"
 )
 
 type DBConfig = svc.DBConfig
@@ -26,6 +27,16 @@ func NewDatabaseService(ctx context.Context, config *svc.DBConfig, logger l.Logg
 	return svc.NewDatabaseService(ctx, config, logger)
 }
 
+// GetDBForRepo returns the connection of a repository (see the Bindings of DBConfig).
+func GetDBForRepo(ctx context.Context, d *DBServiceImpl, repo string) (*gorm.DB, error) {
+	return svc.GetDBForRepo(ctx, d, repo)
+}
+
+// DBFromContext returns the transaction carried by ctx, or db bound to ctx.
+func DBFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return svc.DBFromContext(ctx, db)
+}
+
 func SetupDatabaseServices(ctx context.Context, d svc.IDockerService, config *svc.DBConfig) error {
 	return svc.SetupDatabaseServices(ctx, d, config)
 }
@@ -36,6 +36,10 @@ type SQLiteConfig = t.Database
 type MongoDBConfig = t.Database
 type RedisConfig = t.Database
 
+// JSONBImpl and Money are the column types of the generated models (gdbase gen models)
+type JSONBImpl = t.JSONBImpl
+type Money = t.Money
+
 // NewDBConfig creates a new DBConfig instance
 
 func NewDBConfig(name, filePath string, enabled bool, logger l.Logger, debug bool) *DBConfig {